@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/kevinzwang/air-traffic-control/internal/config"
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/events"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// runAdopt registers an existing worktree, created by hand or by another
+// tool, as a session of its repository
+func runAdopt(args []string) error {
+	fs := flag.NewFlagSet("adopt", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: atc adopt <worktree path>")
+	}
+	path, err := filepath.Abs(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if !isGitRepo(path) {
+		return fmt.Errorf("%s is not in a git repository", path)
+	}
+	repoPath, err := worktree.RepoRoot(path)
+	if err != nil {
+		return fmt.Errorf("failed to get git root: %w", err)
+	}
+
+	dir, err := atcDir()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadGlobal(dir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	db, err := database.Open(filepath.Join(dir, "sessions.db"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	service, err := session.NewService(db, repoPath, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create session service: %w", err)
+	}
+	if err := db.AddProject(repoPath, service.RepoName()); err != nil {
+		return err
+	}
+	sess, err := service.AdoptWorktree(path)
+	if err != nil {
+		return err
+	}
+
+	db.InsertEvent(&database.SessionEvent{
+		RepoPath:    repoPath,
+		SessionName: sess.Name,
+		Kind:        events.KindAdopted,
+		Detail:      sess.WorktreePath,
+		CreatedAt:   time.Now(),
+	})
+	if eventLog, err := events.NewLog(filepath.Join(dir, "events.log")); err == nil {
+		eventLog.Append(events.Event{Repo: service.RepoName(), Session: sess.Name, Kind: events.KindAdopted, Detail: sess.WorktreePath})
+	}
+	fmt.Printf("Adopted %s as session %s of %s; its agent starts when you open it in ATC\n", sess.WorktreePath, sess.Name, service.RepoName())
+	return nil
+}
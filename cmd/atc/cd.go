@@ -0,0 +1,135 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// runCd prints a session's worktree path, for cd "$(atc cd <session>)" or
+// the atc shell function from atc shell-init
+func runCd(args []string) error {
+	fs := flag.NewFlagSet("cd", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: atc cd <session> (or <project>/<session>)")
+	}
+	name := fs.Arg(0)
+
+	dir, err := atcDir()
+	if err != nil {
+		return err
+	}
+	db, err := database.Open(filepath.Join(dir, "sessions.db"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	sessions, err := db.ListSessions("", "")
+	if err != nil {
+		return err
+	}
+	var repoPath string
+	if cwd, err := os.Getwd(); err == nil && isGitRepo(cwd) {
+		repoPath, _ = worktree.RepoRoot(cwd)
+	}
+	sess, err := findSession(sessions, name, repoPath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(sess.WorktreePath); err != nil {
+		return fmt.Errorf("session %s's worktree %s no longer exists", sess.Name, sess.WorktreePath)
+	}
+	fmt.Println(sess.WorktreePath)
+	return nil
+}
+
+// findSession picks the session called name, preferring the current
+// repository's over other projects' sessions of the same name. A name that
+// matches no session is tried as <project>/<session>.
+func findSession(sessions []*database.Session, name, repoPath string) (*database.Session, error) {
+	var matches []*database.Session
+	for _, s := range sessions {
+		if s.Name == name {
+			matches = append(matches, s)
+		}
+	}
+	if len(matches) == 0 {
+		if project, rest, ok := strings.Cut(name, "/"); ok {
+			for _, s := range sessions {
+				if s.RepoName == project && s.Name == rest {
+					matches = append(matches, s)
+				}
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no session named %s", name)
+	case 1:
+		return matches[0], nil
+	}
+	for _, s := range matches {
+		if s.RepoPath == repoPath {
+			return s, nil
+		}
+	}
+	var names []string
+	for _, s := range matches {
+		names = append(names, s.RepoName+"/"+s.Name)
+	}
+	return nil, fmt.Errorf("%s is a session of several projects; use one of %s", name, strings.Join(names, ", "))
+}
+
+// Shell functions wrapping atc so that atc cd changes directory
+const (
+	shellInitPOSIX = `atc() {
+  if [ "$1" = cd ]; then
+    shift
+    local dir
+    dir="$(command atc cd "$@")" && builtin cd -- "$dir"
+  else
+    command atc "$@"
+  fi
+}
+`
+	shellInitFish = `function atc
+    if test "$argv[1]" = cd
+        set -l dir (command atc cd $argv[2..-1]); and builtin cd -- $dir
+    else
+        command atc $argv
+    end
+end
+`
+)
+
+// runShellInit prints a shell function for the user's shell rc file that
+// makes atc cd <session> change the shell's directory
+func runShellInit(args []string) error {
+	fs := flag.NewFlagSet("shell-init", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	shell := filepath.Base(os.Getenv("SHELL"))
+	if fs.NArg() > 0 {
+		shell = fs.Arg(0)
+	}
+	switch shell {
+	case "bash", "zsh", "sh":
+		fmt.Print(shellInitPOSIX)
+	case "fish":
+		fmt.Print(shellInitFish)
+	default:
+		return fmt.Errorf("usage: atc shell-init bash|zsh|fish")
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinzwang/air-traffic-control/internal/config"
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/reconcile"
+)
+
+// runGC finds worktrees, sessions and tmux sessions that have fallen out of
+// sync and asks what to do with each one
+func runGC(args []string) error {
+	fs := flag.NewFlagSet("gc", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "list orphans without changing anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	dir, err := atcDir()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadGlobal(dir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	db, err := database.Open(filepath.Join(dir, "sessions.db"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	orphans, err := reconcile.Scan(db, cfg.WorktreeRoot)
+	if err != nil {
+		return err
+	}
+	if len(orphans) == 0 {
+		fmt.Println("Nothing to clean up")
+		return nil
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	for _, o := range orphans {
+		fmt.Println(o.String())
+		if *dryRun {
+			continue
+		}
+
+		action, err := promptAction(in, o.Actions())
+		if err != nil {
+			return err
+		}
+		if action == "" {
+			continue
+		}
+		if err := reconcile.Resolve(db, o, action); err != nil {
+			fmt.Fprintf(os.Stderr, "  failed to %s: %v\n", action, err)
+			continue
+		}
+		fmt.Printf("  %s done\n", action)
+	}
+	return nil
+}
+
+// promptAction asks which of the actions to take, returning "" to skip
+func promptAction(in *bufio.Reader, actions []reconcile.Action) (reconcile.Action, error) {
+	var options []string
+	for _, a := range actions {
+		options = append(options, "["+string(a[0])+"]"+string(a[1:]))
+	}
+	options = append(options, "[s]kip")
+
+	for {
+		fmt.Printf("  %s? ", strings.Join(options, " "))
+		line, err := in.ReadString('\n')
+		if err != nil {
+			// EOF: skip the rest rather than acting on them
+			fmt.Println()
+			return "", nil
+		}
+		answer := strings.ToLower(strings.TrimSpace(line))
+		if answer == "" || answer == "s" || answer == "skip" {
+			return "", nil
+		}
+		for _, a := range actions {
+			if answer == string(a) || answer == string(a[0]) {
+				return a, nil
+			}
+		}
+	}
+}
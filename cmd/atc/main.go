@@ -8,61 +8,169 @@ import (
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kevinzwang/air-traffic-control/internal/config"
 	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/events"
+	"github.com/kevinzwang/air-traffic-control/internal/logging"
 	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/shell"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
 	"github.com/kevinzwang/air-traffic-control/internal/tui"
+	"github.com/kevinzwang/air-traffic-control/internal/watch"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
 )
 
 func main() {
-	if err := run(); err != nil {
+	if err := run(os.Args[1:]); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func run() error {
-	// Check that tmux is available
-	if _, err := exec.LookPath("tmux"); err != nil {
-		return fmt.Errorf("tmux is required but not found in PATH. Install it with: brew install tmux")
+// uiFlags are the interface options given on the command line, applied
+// over the config
+var uiFlags struct {
+	accessible bool
+	noColor    bool
+}
+
+// run dispatches to a subcommand, or launches the TUI when none is given
+func run(args []string) error {
+	// --debug (or ATC_DEBUG), --accessible and --no-color come before any
+	// subcommand
+	debug := logging.FromEnv()
+flags:
+	for len(args) > 0 {
+		switch args[0] {
+		case "--debug", "-debug":
+			debug = true
+		case "--accessible", "-accessible":
+			uiFlags.accessible = true
+		case "--no-color", "-no-color":
+			uiFlags.noColor = true
+		default:
+			break flags
+		}
+		args = args[1:]
+	}
+	if debug {
+		dir, err := atcDir()
+		if err != nil {
+			return err
+		}
+		log, err := logging.Setup(dir)
+		if err != nil {
+			return err
+		}
+		defer log.Close()
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "adopt":
+			return runAdopt(args[1:])
+		case "cd":
+			return runCd(args[1:])
+		case "gc":
+			return runGC(args[1:])
+		case "prune":
+			return runPrune(args[1:])
+		case "shell-init":
+			return runShellInit(args[1:])
+		case "status":
+			return runStatus(args[1:])
+		case "tail":
+			return runTail(args[1:])
+		case "tutorial":
+			return runTutorial(args[1:])
+		case "upgrade":
+			return runUpgrade(args[1:])
+		}
 	}
+	return runTUI()
+}
 
-	// Get home directory for ATC database
+// atcDir returns the ATC state directory (~/.atc)
+func atcDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".atc"), nil
+}
+
+// runTUI launches the interactive TUI for the current directory
+func runTUI() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	return launchTUI(cwd, false)
+}
+
+// launchTUI runs the TUI for the repository containing cwd. When tutorial is
+// set, the guided walkthrough callouts are shown.
+func launchTUI(cwd string, tutorial bool) error {
+	// Without tmux, agents run on ATC's own PTYs and end when ATC exits
+	if _, err := exec.LookPath("tmux"); err != nil {
+		terminal.Default = terminal.PTY
+	}
+
+	// Get ATC state directory for config and database
+	atcDir, err := atcDir()
+	if err != nil {
+		return err
+	}
+
+	// Load user-level config (defaults apply if none exists)
+	cfg, err := config.LoadGlobal(atcDir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	terminal.PollInterval = cfg.PollInterval
+	terminal.OutputMode = cfg.TerminalOutput
+	if err := tui.ApplyTheme(cfg.Theme, cfg.Themes); err != nil {
+		return fmt.Errorf("failed to load theme: %w", err)
 	}
+	// NO_COLOR counts when set to anything (https://no-color.org)
+	if uiFlags.noColor || os.Getenv("NO_COLOR") != "" {
+		tui.SetMonochrome(true)
+	}
+	tui.SetAccessible(cfg.Accessible || uiFlags.accessible)
+	tui.SetASCII(cfg.ASCII)
+	tui.SetReducedMotion(cfg.ReducedMotion)
 
 	// Open database first (it's global across all repos)
-	dbPath := filepath.Join(homeDir, ".atc", "sessions.db")
+	dbPath := filepath.Join(atcDir, "sessions.db")
 	db, err := database.Open(dbPath)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
 	defer db.Close()
 
-	// Get current directory
-	cwd, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get current directory: %w", err)
-	}
-
 	var service *session.Service
 	var repoName string
 	var invokingBranch string
 
 	// If we're in a git repository, set up the service
 	if isGitRepo(cwd) {
-		repoPath, err := getGitRoot(cwd)
+		repoPath, err := worktree.RepoRoot(cwd)
 		if err != nil {
 			return fmt.Errorf("failed to get git root: %w", err)
 		}
 
-		service, err = session.NewService(db, repoPath)
+		service, err = session.NewService(db, repoPath, cfg)
 		if err != nil {
 			return fmt.Errorf("failed to create session service: %w", err)
 		}
 
 		repoName = filepath.Base(repoPath)
+		if err := db.AddProject(repoPath, repoName); err != nil {
+			return err
+		}
+		if err := db.TouchProject(repoPath); err != nil {
+			return err
+		}
 
 		invokingBranch, err = getCurrentBranch(cwd)
 		if err != nil {
@@ -71,14 +179,38 @@ func run() error {
 	}
 
 	// Launch TUI (service may be nil if not in a git repo)
-	model := tui.NewModel(db, service, repoName, invokingBranch)
-	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	model := tui.NewModel(db, cfg, service, repoName, invokingBranch)
+	if tutorial {
+		model.EnableTutorial()
+	}
+	opts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithReportFocus()}
+	if tui.Accessible() {
+		// Lines printed above the view are lost on the alternate screen
+		opts = []tea.ProgramOption{tea.WithReportFocus()}
+	}
+	p := tea.NewProgram(model, opts...)
 	model.SetProgram(p)
+	if eventLog, err := events.NewLog(filepath.Join(atcDir, "events.log")); err == nil {
+		model.SetEventLog(eventLog)
+	}
+	// Without file watching (e.g. out of inotify watches), polling still
+	// picks up other instances' changes
+	if watcher, err := watch.New(dbPath); err == nil {
+		defer watcher.Close()
+		model.SetWatcher(watcher)
+	}
 
-	if _, err := p.Run(); err != nil {
+	_, err = p.Run()
+	tui.ResetWindowTitle()
+	if err != nil {
 		return fmt.Errorf("TUI error: %w", err)
 	}
 
+	// Run the shell or agent the user quit to in ATC's place
+	if next := model.ExitCommand(); next != nil {
+		db.Close()
+		return shell.Exec(next.Dir, next.Args, next.Env)
+	}
 	return nil
 }
 
@@ -86,57 +218,14 @@ func run() error {
 func isGitRepo(dir string) bool {
 	cmd := exec.Command("git", "rev-parse", "--git-dir")
 	cmd.Dir = dir
-	return cmd.Run() == nil
-}
-
-// getGitRoot returns the root directory of the git repository
-// If invoked from a worktree, it returns the main repository's path
-func getGitRoot(dir string) (string, error) {
-	// First, get the common git directory (main repo's .git, even in worktrees)
-	cmdCommon := exec.Command("git", "rev-parse", "--git-common-dir")
-	cmdCommon.Dir = dir
-	commonOutput, err := cmdCommon.Output()
-	if err != nil {
-		return "", err
-	}
-	commonDir := strings.TrimSpace(string(commonOutput))
-
-	// Get the regular git directory
-	cmdGitDir := exec.Command("git", "rev-parse", "--git-dir")
-	cmdGitDir.Dir = dir
-	gitDirOutput, err := cmdGitDir.Output()
-	if err != nil {
-		return "", err
-	}
-	gitDir := strings.TrimSpace(string(gitDirOutput))
-
-	// If they differ, we're in a worktree - use the main repo path
-	if commonDir != gitDir {
-		if strings.HasSuffix(commonDir, "/.git") {
-			return strings.TrimSuffix(commonDir, "/.git"), nil
-		}
-		if commonDir == ".git" {
-			// Fall through to use --show-toplevel
-		} else {
-			return filepath.Dir(commonDir), nil
-		}
-	}
-
-	// Not in a worktree, use regular toplevel
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	cmd.Dir = dir
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(string(output)), nil
+	return logging.Run(cmd) == nil
 }
 
 // getCurrentBranch returns the current branch name for the given directory
 func getCurrentBranch(dir string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
 	cmd.Dir = dir
-	output, err := cmd.Output()
+	output, err := logging.Output(cmd)
 	if err != nil {
 		return "", err
 	}
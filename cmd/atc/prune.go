@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kevinzwang/air-traffic-control/internal/config"
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/reconcile"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+)
+
+// runPrune deletes old archived sessions with their worktrees, empties the
+// trash of sessions deleted more than trash_days ago, forgets detached
+// sessions whose worktree is gone, kills tmux sessions and servers that no
+// session owns, and compacts the database
+func runPrune(args []string) error {
+	fs := flag.NewFlagSet("prune", flag.ContinueOnError)
+	days := fs.Int("days", 30, "remove sessions archived more than this many days ago")
+	dryRun := fs.Bool("dry-run", false, "print what would be removed without removing it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	dir, err := atcDir()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadGlobal(dir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	db, err := database.Open(filepath.Join(dir, "sessions.db"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	verb := "Removed"
+	if *dryRun {
+		verb = "Would remove"
+	}
+
+	// Archived sessions past the cutoff
+	sessions, err := reconcile.ArchivedBefore(db, time.Now().AddDate(0, 0, -*days))
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		archivedAt := s.CreatedAt
+		if s.ArchivedAt != nil {
+			archivedAt = *s.ArchivedAt
+		}
+		if !*dryRun {
+			if err := reconcile.RemoveArchived(db, cfg, s); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to remove session %s (%s): %v\n", s.Name, s.RepoName, err)
+				continue
+			}
+		}
+		fmt.Printf("%s session %s (%s), archived %s, and its worktree %s\n",
+			verb, s.Name, s.RepoName, archivedAt.Local().Format("2006-01-02"), s.WorktreePath)
+	}
+
+	// Deleted sessions whose time in the trash is up
+	trashed, err := db.ListTrash("")
+	if err != nil {
+		return err
+	}
+	trashCutoff := time.Now().AddDate(0, 0, -cfg.TrashDays)
+	for _, t := range trashed {
+		if t.TrashedAt.After(trashCutoff) {
+			continue
+		}
+		if !*dryRun {
+			if err := session.PurgeTrashed(db, t); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to remove trashed session %s (%s): %v\n", t.Name, t.RepoName, err)
+				continue
+			}
+		}
+		fmt.Printf("%s trashed session %s (%s), deleted %s, and its worktree %s\n",
+			verb, t.Name, t.RepoName, t.TrashedAt.Local().Format("2006-01-02"), t.TrashPath)
+	}
+
+	// Detached sessions whose worktree is gone, so that their tmux sessions
+	// are cleaned up below like any other
+	detached, err := db.ListDetached("")
+	if err != nil {
+		return err
+	}
+	for _, d := range detached {
+		if _, err := os.Stat(d.WorktreePath); err == nil {
+			continue
+		}
+		if !*dryRun {
+			if err := db.ForgetDetached(d.WorktreePath); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to forget detached session %s: %v\n", d.Name, err)
+				continue
+			}
+		}
+		fmt.Printf("%s the record of detached session %s (%s), whose worktree %s is gone\n",
+			verb, d.Name, filepath.Base(d.RepoPath), d.WorktreePath)
+	}
+
+	// tmux sessions and servers no session owns
+	orphans, err := reconcile.Scan(db, cfg.WorktreeRoot)
+	if err != nil {
+		return err
+	}
+	for _, o := range orphans {
+		if o.Kind != reconcile.StrayTmuxSession && o.Kind != reconcile.StrayTmuxServer {
+			continue
+		}
+		if !*dryRun {
+			if err := reconcile.Resolve(db, o, reconcile.Clean); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to clean %s: %v\n", o, err)
+				continue
+			}
+		}
+		fmt.Printf("%s %s\n", verb, o)
+	}
+
+	if *dryRun {
+		fmt.Println("Would vacuum the database")
+		return nil
+	}
+	if err := db.Vacuum(); err != nil {
+		return err
+	}
+	fmt.Println("Vacuumed the database")
+	return nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/kevinzwang/air-traffic-control/internal/config"
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// sessionStatus is one line of atc status
+type sessionStatus struct {
+	Name    string `json:"name"`
+	Project string `json:"project"`
+	State   string `json:"state"` // working, waiting, exited or stopped
+	Branch  string `json:"branch"`
+	Base    string `json:"base,omitempty"` // what ahead and behind count against
+	Ahead   int    `json:"ahead"`
+	Behind  int    `json:"behind"`
+	Dirty   int    `json:"dirty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// runStatus prints the agent state and git state of each active session, as
+// a table or as JSON for scripts
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	all := fs.Bool("all", false, "include every project, not just the current one")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var repoPath string
+	if !*all {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		if !isGitRepo(cwd) {
+			return fmt.Errorf("not in a git repository (use --all for every project)")
+		}
+		if repoPath, err = worktree.RepoRoot(cwd); err != nil {
+			return fmt.Errorf("failed to get git root: %w", err)
+		}
+	}
+
+	dir, err := atcDir()
+	if err != nil {
+		return err
+	}
+	db, err := database.Open(filepath.Join(dir, "sessions.db"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	sessions, err := db.ListSessions("", "")
+	if err != nil {
+		return err
+	}
+
+	statuses := []sessionStatus{}
+	bases := make(map[string]string)
+	for _, s := range sessions {
+		if s.ArchivedAt != nil || (repoPath != "" && s.RepoPath != repoPath) {
+			continue
+		}
+		base := s.BaseBranch
+		if base == "" {
+			var ok bool
+			if base, ok = bases[s.RepoPath]; !ok {
+				base = statusBase(db, s.RepoPath)
+				bases[s.RepoPath] = base
+			}
+		}
+		statuses = append(statuses, getSessionStatus(s, base))
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	}
+	printStatusTable(statuses, *all)
+	return nil
+}
+
+// statusBase returns the branch a project's sessions are compared against
+// when they don't know what they were started from: the project's default
+// base branch, or else the branch the main checkout is on
+func statusBase(db *database.DB, repoPath string) string {
+	if branch, err := db.ProjectBaseBranch(repoPath); err == nil && branch != "" {
+		return branch
+	}
+	if cfg, err := config.Load(repoPath); err == nil && cfg.BaseBranch != "" {
+		return cfg.BaseBranch
+	}
+	if branch, err := worktree.GetCurrentBranch(repoPath); err == nil && branch != "HEAD" {
+		return branch
+	}
+	return ""
+}
+
+// getSessionStatus reads a session's agent state from its tmux session, the
+// way the TUI does for sessions it isn't showing, and its git state from its
+// worktree
+func getSessionStatus(s *database.Session, base string) sessionStatus {
+	st := sessionStatus{
+		Name:    s.Name,
+		Project: s.RepoName,
+		State:   "stopped",
+		Branch:  s.BranchName,
+		Base:    base,
+	}
+	if state, ok := terminal.SessionState(terminal.SocketName(s.RepoPath), terminal.TmuxName(s.Name)); ok {
+		st.State = state.String()
+	}
+	if base == s.BranchName {
+		base = ""
+		st.Base = ""
+	}
+	gs, err := worktree.GetStatus(s.WorktreePath, base)
+	if err != nil {
+		st.Error = err.Error()
+	}
+	st.Ahead, st.Behind, st.Dirty = gs.Ahead, gs.Behind, gs.Dirty
+	return st
+}
+
+// printStatusTable prints statuses as aligned columns
+func printStatusTable(statuses []sessionStatus, showProject bool) {
+	if len(statuses) == 0 {
+		fmt.Println("No active sessions")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if showProject {
+		fmt.Fprint(w, "PROJECT\t")
+	}
+	fmt.Fprintln(w, "SESSION\tSTATE\tBRANCH\tAHEAD\tBEHIND\tDIRTY")
+	for _, st := range statuses {
+		if showProject {
+			fmt.Fprintf(w, "%s\t", st.Project)
+		}
+		ahead, behind := fmt.Sprint(st.Ahead), fmt.Sprint(st.Behind)
+		if st.Base == "" {
+			ahead, behind = "-", "-"
+		}
+		dirty := fmt.Sprint(st.Dirty)
+		if st.Error != "" {
+			ahead, behind, dirty = "?", "?", "?"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", st.Name, st.State, st.Branch, ahead, behind, dirty)
+	}
+	w.Flush()
+}
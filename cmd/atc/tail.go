@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/kevinzwang/air-traffic-control/internal/events"
+)
+
+// runTail streams session status changes as plain text lines, for use in a
+// second terminal or with a screen reader
+func runTail(args []string) error {
+	fs := flag.NewFlagSet("tail", flag.ContinueOnError)
+	history := fs.Int("n", 10, "number of past events to print before following")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	dir, err := atcDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "events.log")
+
+	past, err := events.Tail(path, *history)
+	if err != nil {
+		return err
+	}
+	for _, e := range past {
+		fmt.Println(e.String())
+	}
+
+	stop := make(chan struct{})
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	go func() {
+		<-sigs
+		close(stop)
+	}()
+
+	return events.Follow(path, os.Stdout, stop)
+}
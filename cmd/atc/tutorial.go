@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/kevinzwang/air-traffic-control/internal/config"
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+// demoReadme is the content of the tutorial project's only file
+const demoReadme = `# ATC demo project
+
+This throwaway repository was created by ` + "`atc tutorial`" + `.
+Ask the agent to summarize this file, add a function, or write a test —
+nothing here matters, and it is deleted when you quit.
+`
+
+// runTutorial creates a throwaway git repository and launches the TUI on it
+// with guided callouts, cleaning up the repo and its sessions afterwards
+func runTutorial(args []string) error {
+	fs := flag.NewFlagSet("tutorial", flag.ContinueOnError)
+	keep := fs.Bool("keep", false, "keep the demo project after quitting")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	repoPath, err := createDemoRepo()
+	if err != nil {
+		return err
+	}
+	if !*keep {
+		defer cleanupDemoRepo(repoPath)
+	}
+
+	if err := launchTUI(repoPath, true); err != nil {
+		return err
+	}
+	if *keep {
+		fmt.Printf("Demo project kept at %s\n", repoPath)
+	}
+	return nil
+}
+
+// createDemoRepo initializes a git repository with a single commit in a temp dir
+func createDemoRepo() (string, error) {
+	dir, err := os.MkdirTemp("", "atc-tutorial-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create demo project: %w", err)
+	}
+	// Resolve symlinks (e.g. /tmp on macOS) so the path matches git's view
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(demoReadme), 0644); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to create demo project: %w", err)
+	}
+
+	steps := [][]string{
+		{"init", "-q", "-b", "main"},
+		{"add", "README.md"},
+		{"-c", "user.name=ATC Tutorial", "-c", "user.email=tutorial@atc.invalid", "commit", "-q", "-m", "Initial commit"},
+	}
+	for _, args := range steps {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("failed to create demo project: git %s: %w\nOutput: %s", args[0], err, string(out))
+		}
+	}
+	return dir, nil
+}
+
+// cleanupDemoRepo removes any sessions left in the demo project, its tmux
+// server, and the repository itself. Best effort.
+func cleanupDemoRepo(repoPath string) {
+	terminal.KillServer(terminal.SocketName(repoPath))
+	if err := deleteDemoSessions(repoPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to clean up tutorial sessions: %v\n", err)
+	}
+	os.RemoveAll(repoPath)
+}
+
+// deleteDemoSessions deletes the demo project's sessions and worktrees
+func deleteDemoSessions(repoPath string) error {
+	dir, err := atcDir()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadGlobal(dir)
+	if err != nil {
+		return err
+	}
+	db, err := database.Open(filepath.Join(dir, "sessions.db"))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	service, err := session.NewService(db, repoPath, cfg)
+	if err != nil {
+		return err
+	}
+	sessions, err := service.ListSessions("")
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		if err := service.DeleteSession(s.Name); err != nil {
+			return err
+		}
+	}
+	return db.RemoveProject(repoPath)
+}
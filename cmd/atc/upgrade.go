@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kevinzwang/air-traffic-control/internal/tui"
+	"github.com/kevinzwang/air-traffic-control/internal/update"
+)
+
+// runUpgrade replaces the atc binary with the latest release, or the
+// version given
+func runUpgrade(args []string) error {
+	fs := flag.NewFlagSet("upgrade", flag.ContinueOnError)
+	check := fs.Bool("check", false, "only report whether a new version is out")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("usage: atc upgrade [--check] [version]")
+	}
+
+	version := fs.Arg(0)
+	if version == "" {
+		latest, err := update.Latest()
+		if err != nil {
+			return err
+		}
+		if tui.Version == "dev" {
+			return fmt.Errorf("this atc was built from source; run atc upgrade %s to replace it with the latest release", latest)
+		}
+		if !update.Newer(tui.Version, latest) {
+			fmt.Printf("ATC %s is up to date (latest release: %s)\n", tui.Version, latest)
+			return nil
+		}
+		version = latest
+	}
+	if *check {
+		fmt.Printf("ATC %s is out (you have %s); run atc upgrade to install it\n", version, tui.Version)
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("can't find the atc binary: %w", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return fmt.Errorf("can't find the atc binary: %w", err)
+	}
+	fmt.Printf("Downloading ATC %s (%s)...\n", version, update.AssetName())
+	if err := update.Install(version, exe); err != nil {
+		return err
+	}
+	fmt.Printf("Installed ATC %s to %s\n", version, exe)
+	return nil
+}
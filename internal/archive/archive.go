@@ -0,0 +1,150 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kevinzwang/air-traffic-control/internal/logging"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// Destination schemes supported by Upload
+const (
+	schemeS3       = "s3://"
+	schemeGCS      = "gs://"
+	schemeGitNotes = "git-notes:"
+)
+
+// Record describes an archived session for the exported report
+type Record struct {
+	Name         string
+	RepoName     string
+	RepoPath     string
+	BranchName   string
+	WorktreePath string
+	CreatedAt    time.Time
+	ArchivedAt   time.Time
+}
+
+// ValidateDestination checks that a destination uses a supported scheme
+func ValidateDestination(dest string) error {
+	switch {
+	case strings.HasPrefix(dest, schemeS3), strings.HasPrefix(dest, schemeGCS), strings.HasPrefix(dest, schemeGitNotes):
+		return nil
+	}
+	return fmt.Errorf("unsupported archive destination %q (use s3://, gs://, or git-notes:<ref>)", dest)
+}
+
+// Upload exports the session report and conversation transcripts and sends
+// them to dest:
+//   - s3://bucket/prefix   copies the export with `aws s3 cp --recursive`
+//   - gs://bucket/prefix   copies the export with `gcloud storage cp -r`
+//   - git-notes:<ref>      attaches the report as a git note on the branch tip
+//
+// Object storage uploads land under <prefix>/<repo>/<session>-<timestamp>/.
+func Upload(dest string, rec Record) error {
+	if err := ValidateDestination(dest); err != nil {
+		return err
+	}
+
+	report := Report(rec)
+
+	if strings.HasPrefix(dest, schemeGitNotes) {
+		ref := strings.TrimPrefix(dest, schemeGitNotes)
+		if ref == "" {
+			ref = "refs/notes/atc"
+		}
+		return run(rec.RepoPath, "git", "notes", "--ref", ref, "add", "-f", "-m", report, rec.BranchName)
+	}
+
+	exportDir, err := os.MkdirTemp("", "atc-archive-")
+	if err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	defer os.RemoveAll(exportDir)
+
+	if err := export(exportDir, rec, report); err != nil {
+		return err
+	}
+
+	target := fmt.Sprintf("%s/%s/%s-%s/", strings.TrimSuffix(dest, "/"),
+		rec.RepoName, strings.ReplaceAll(rec.Name, "/", "-"), rec.ArchivedAt.Format("20060102-150405"))
+
+	if strings.HasPrefix(dest, schemeS3) {
+		return run("", "aws", "s3", "cp", "--recursive", "--only-show-errors", exportDir, target)
+	}
+	return run("", "gcloud", "storage", "cp", "-r", exportDir+"/*", target)
+}
+
+// Report renders a plain-text summary of the session's work
+func Report(rec Record) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ATC session: %s\n", rec.Name)
+	fmt.Fprintf(&b, "Repository:  %s (%s)\n", rec.RepoName, rec.RepoPath)
+	fmt.Fprintf(&b, "Branch:      %s\n", rec.BranchName)
+	fmt.Fprintf(&b, "Created:     %s\n", rec.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Archived:    %s\n", rec.ArchivedAt.Format(time.RFC3339))
+
+	if out, err := output(rec.RepoPath, "git", "log", "--oneline", "-n", "50", rec.BranchName); err == nil && out != "" {
+		b.WriteString("\nRecent commits:\n")
+		b.WriteString(out)
+		b.WriteString("\n")
+	}
+
+	if out, err := output(rec.WorktreePath, "git", "status", "--short"); err == nil && out != "" {
+		b.WriteString("\nUncommitted changes:\n")
+		b.WriteString(out)
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// export writes the report and copies transcripts into dir
+func export(dir string, rec Record, report string) error {
+	if err := os.WriteFile(filepath.Join(dir, "report.txt"), []byte(report), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	transcripts := worktree.ConversationFiles(rec.WorktreePath)
+	if len(transcripts) == 0 {
+		return nil
+	}
+	transcriptDir := filepath.Join(dir, "transcripts")
+	if err := os.MkdirAll(transcriptDir, 0755); err != nil {
+		return fmt.Errorf("failed to create transcript directory: %w", err)
+	}
+	for _, src := range transcripts {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return fmt.Errorf("failed to read transcript: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(transcriptDir, filepath.Base(src)), data, 0644); err != nil {
+			return fmt.Errorf("failed to write transcript: %w", err)
+		}
+	}
+	return nil
+}
+
+// run executes a command, returning its output in the error on failure
+func run(dir, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	out, err := logging.CombinedOutput(cmd)
+	if err != nil {
+		return fmt.Errorf("%s failed: %w\nOutput: %s", name, err, string(out))
+	}
+	return nil
+}
+
+// output executes a command and returns its trimmed stdout
+func output(dir, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	out, err := logging.Output(cmd)
+	return strings.TrimSpace(string(out)), err
+}
@@ -1,57 +1,364 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// repoConfigNames lists the ATC-native repo config files in lookup order.
+// .cursor/worktrees.json is only consulted when none of these exist.
+var repoConfigNames = []string{".atc.yaml", ".atc.yml", ".atc.json"}
+
+// cursorConfigPath is the Cursor-compatible config location, relative to a directory
+var cursorConfigPath = filepath.Join(".cursor", "worktrees.json")
+
+// Cursor config modes for the `cursor` repo setting
+const (
+	CursorFallback = "fallback" // use .cursor/worktrees.json only when no .atc.* file exists (default)
+	CursorIgnore   = "ignore"   // never use .cursor/worktrees.json
+	CursorBefore   = "before"   // run Cursor's setup commands before ATC's
+	CursorAfter    = "after"    // run Cursor's setup commands after ATC's
 )
 
+// RepoConfig represents the per-repository settings from .atc.{yaml,json}
+type RepoConfig struct {
+	Setup      []string `yaml:"setup" json:"setup"`
+	Teardown   []string `yaml:"teardown" json:"teardown"`
+	BaseBranch string   `yaml:"base_branch" json:"base_branch"`
+	Agent      string   `yaml:"agent" json:"agent"`
+	CopyFiles  []string `yaml:"copy_files" json:"copy_files"`
+	Shell      string   `yaml:"shell" json:"shell"`
+	Locale     string   `yaml:"locale" json:"locale"`
+	Term       string   `yaml:"term" json:"term"`
+
+	// Verify is the test or lint command run in a session's worktree on
+	// request, with its pass/fail shown in the sidebar
+	Verify string `yaml:"verify" json:"verify"`
+
+	// Schedule creates sessions at set times, e.g. a nightly dependency
+	// update, while ATC is running
+	Schedule []ScheduleConfig `yaml:"schedule" json:"schedule"`
+
+	// AgentFlags override the user's agent_flags one flag at a time
+	AgentFlags AgentFlags `yaml:"agent_flags" json:"agent_flags"`
+
+	// Container optionally runs each session's agent in a container
+	Container ContainerConfig `yaml:"container" json:"container"`
+
+	// ArchiveDestination optionally uploads a report and the conversation
+	// transcripts when a session is archived: s3://bucket/prefix,
+	// gs://bucket/prefix, or git-notes:<ref>
+	ArchiveDestination string `yaml:"archive_destination" json:"archive_destination"`
+
+	// Cursor controls how .cursor/worktrees.json is combined with this file
+	// (fallback, ignore, before, after)
+	Cursor string `yaml:"cursor" json:"cursor"`
+
+	// Sources lists the config files that contributed, filled in by Load
+	Sources []string `yaml:"-" json:"-"`
+}
+
+// ContainerConfig runs each session's agent in its own container, with the
+// worktree mounted at the same path: a plain `docker run` of Image, or the
+// worktree's devcontainer.json through the devcontainer CLI
+type ContainerConfig struct {
+	Image        string   `yaml:"image" json:"image"`
+	Devcontainer bool     `yaml:"devcontainer" json:"devcontainer"`
+	Args         []string `yaml:"args" json:"args"`   // extra `docker run` arguments
+	Shell        string   `yaml:"shell" json:"shell"` // shell opened in the container (default sh)
+}
+
+// Enabled reports whether sessions run in containers
+func (c ContainerConfig) Enabled() bool {
+	return c.Image != "" || c.Devcontainer
+}
+
+// ScheduleConfig creates a session named <Name>-<yyyymmdd> at a time of day
+// and sends its agent Prompt. A run missed because ATC wasn't running is
+// caught up later the same day.
+type ScheduleConfig struct {
+	Name       string   `yaml:"name" json:"name"`
+	At         string   `yaml:"at" json:"at"`     // local time, HH:MM
+	Days       []string `yaml:"days" json:"days"` // mon to sun; every day if empty
+	Prompt     string   `yaml:"prompt" json:"prompt"`
+	BaseBranch string   `yaml:"base_branch" json:"base_branch"` // "" = the repo's base branch
+}
+
+// weekdays maps the day names schedules use to time.Weekday
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// validate checks the schedule's fields
+func (s ScheduleConfig) validate() error {
+	if s.Name == "" || s.At == "" || s.Prompt == "" {
+		return fmt.Errorf("name, at and prompt are all required")
+	}
+	if _, err := time.Parse("15:04", s.At); err != nil {
+		return fmt.Errorf("at %q is not a time of day (HH:MM)", s.At)
+	}
+	for _, d := range s.Days {
+		if _, ok := weekdays[strings.ToLower(d)]; !ok {
+			return fmt.Errorf("unknown day %q (use mon, tue, ... sun)", d)
+		}
+	}
+	return nil
+}
+
+// Due reports whether the schedule's run for now's day has come: now is on
+// one of its days and at or after its time
+func (s ScheduleConfig) Due(now time.Time) bool {
+	at, err := time.Parse("15:04", s.At)
+	if err != nil {
+		return false
+	}
+	if len(s.Days) > 0 && !slices.ContainsFunc(s.Days, func(d string) bool { return weekdays[strings.ToLower(d)] == now.Weekday() }) {
+		return false
+	}
+	return now.Hour()*60+now.Minute() >= at.Hour()*60+at.Minute()
+}
+
+// SessionName is the name of the session the schedule creates on now's day
+func (s ScheduleConfig) SessionName(now time.Time) string {
+	return s.Name + "-" + now.Format("20060102")
+}
+
 // WorktreeConfig represents the structure of .cursor/worktrees.json
 type WorktreeConfig struct {
 	SetupWorktree []string `json:"setup-worktree"`
 }
 
-// Load finds and parses .cursor/worktrees.json starting from the given directory
+// Load finds and parses the repo config starting from the given directory.
+// .atc.yaml/.atc.yml/.atc.json hold ATC's settings; .cursor/worktrees.json is
+// used for setup commands according to the `cursor` setting (by default only
+// when no ATC config exists, for compatibility).
 // Returns an empty config if no file is found (graceful degradation)
-func Load(startDir string) (*WorktreeConfig, error) {
-	configPath, err := findConfig(startDir)
-	if err != nil {
-		// Return empty config if not found
-		return &WorktreeConfig{
-			SetupWorktree: []string{},
-		}, nil
+func Load(startDir string) (*RepoConfig, error) {
+	config := &RepoConfig{Setup: []string{}}
+
+	if configPath, ok := findFile(startDir, repoConfigNames); ok {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if strings.HasSuffix(configPath, ".json") {
+			err = json.Unmarshal(data, config)
+		} else {
+			err = yaml.Unmarshal(data, config)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		config.Sources = append(config.Sources, configPath)
+	}
+
+	if config.Container.Image != "" && config.Container.Devcontainer {
+		return nil, fmt.Errorf("container: set image or devcontainer, not both")
+	}
+	for i, sc := range config.Schedule {
+		if err := sc.validate(); err != nil {
+			return nil, fmt.Errorf("schedule[%d]: %w", i, err)
+		}
+	}
+
+	mode := config.Cursor
+	if mode == "" {
+		mode = CursorFallback
+	}
+	switch mode {
+	case CursorFallback, CursorIgnore, CursorBefore, CursorAfter:
+	default:
+		return nil, fmt.Errorf("invalid cursor setting %q (use fallback, ignore, before, or after)", config.Cursor)
+	}
+	if mode == CursorIgnore || (mode == CursorFallback && len(config.Sources) > 0) {
+		return config, nil
 	}
 
-	data, err := os.ReadFile(configPath)
+	cursorPath, ok := findFile(startDir, []string{cursorConfigPath})
+	if !ok {
+		return config, nil
+	}
+	data, err := os.ReadFile(cursorPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
-
-	var config WorktreeConfig
-	if err := json.Unmarshal(data, &config); err != nil {
+	var cursor WorktreeConfig
+	if err := json.Unmarshal(data, &cursor); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	return &config, nil
+	if mode == CursorAfter {
+		config.Setup = append(config.Setup, cursor.SetupWorktree...)
+	} else {
+		config.Setup = append(cursor.SetupWorktree, config.Setup...)
+	}
+	config.Sources = append(config.Sources, cursorPath)
+	return config, nil
+}
+
+// CommandsFingerprint identifies what the repo's config has ATC run: its
+// commands (setup, teardown and verify), the scheduled prompts it sends agents
+// unattended, and its RunSettings. Trust granted to a repository is tied to
+// this value, so it must be re-confirmed whenever they change.
+func (c *RepoConfig) CommandsFingerprint() string {
+	settings := c.RunSettings()
+	if len(c.Setup) == 0 && len(c.Teardown) == 0 && c.Verify == "" && len(c.Schedule) == 0 && len(settings) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	for _, cmd := range c.Setup {
+		h.Write([]byte("setup\x00" + cmd + "\x00"))
+	}
+	for _, cmd := range c.Teardown {
+		h.Write([]byte("teardown\x00" + cmd + "\x00"))
+	}
+	if c.Verify != "" {
+		h.Write([]byte("verify\x00" + c.Verify + "\x00"))
+	}
+	for _, sc := range c.Schedule {
+		h.Write([]byte("schedule\x00" + sc.Name + "\x00" + sc.At + "\x00" + strings.Join(sc.Days, ",") + "\x00" + sc.BaseBranch + "\x00" + sc.Prompt + "\x00"))
+	}
+	// Only set settings are written, so trust granted before they were
+	// covered still holds for repos that don't use them
+	for _, setting := range settings {
+		h.Write([]byte("setting\x00" + setting + "\x00"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
 }
 
-// findConfig searches for .cursor/worktrees.json in the directory tree
-func findConfig(startDir string) (string, error) {
+// RunSettings lists the repo's settings other than commands that decide what
+// ATC runs (the agent and shell it starts and the agent's flags, which can
+// bypass its permission prompts; the container it runs the agent in; where it
+// uploads archives), as "name: value" lines to show when asking for trust
+func (c *RepoConfig) RunSettings() []string {
+	var settings []string
+	if strings.TrimSpace(c.Agent) != "" {
+		settings = append(settings, "agent: "+c.Agent)
+	}
+	if c.Shell != "" {
+		settings = append(settings, "shell: "+c.Shell)
+	}
+	if args := c.AgentFlags.Args(); len(args) > 0 {
+		settings = append(settings, "agent_flags: "+strings.Join(args, " "))
+	}
+	if c.Container.Image != "" {
+		settings = append(settings, "container.image: "+c.Container.Image)
+	}
+	if c.Container.Devcontainer {
+		settings = append(settings, "container.devcontainer: true")
+	}
+	if len(c.Container.Args) > 0 {
+		settings = append(settings, "container.args: "+strings.Join(c.Container.Args, " "))
+	}
+	if c.Container.Shell != "" {
+		settings = append(settings, "container.shell: "+c.Container.Shell)
+	}
+	if c.ArchiveDestination != "" {
+		settings = append(settings, "archive_destination: "+c.ArchiveDestination)
+	}
+	return settings
+}
+
+// Untrusted returns a copy of the config without anything CommandsFingerprint
+// covers, for running sessions in a repository the user hasn't trusted
+func (c *RepoConfig) Untrusted() *RepoConfig {
+	u := *c
+	u.Setup, u.Teardown, u.Verify, u.Schedule = nil, nil, "", nil
+	u.Agent, u.Shell, u.ArchiveDestination = "", "", ""
+	u.AgentFlags = AgentFlags{}
+	u.Container = ContainerConfig{}
+	return &u
+}
+
+// findFile searches up the directory tree for the first of names that exists
+func findFile(startDir string, names []string) (string, bool) {
 	dir := startDir
 
 	for {
-		configPath := filepath.Join(dir, ".cursor", "worktrees.json")
-		if _, err := os.Stat(configPath); err == nil {
-			return configPath, nil
+		for _, name := range names {
+			configPath := filepath.Join(dir, name)
+			if _, err := os.Stat(configPath); err == nil {
+				return configPath, true
+			}
 		}
 
 		// Move up one directory
 		parent := filepath.Dir(dir)
 		if parent == dir {
 			// Reached root
-			return "", fmt.Errorf("config file not found")
+			return "", false
 		}
 		dir = parent
 	}
 }
+
+// Config is the effective configuration for a repository: the user-level
+// settings with the repository's own settings layered on top
+type Config struct {
+	GlobalConfig
+	Setup      []string
+	Teardown   []string
+	Verify     string
+	Schedule   []ScheduleConfig
+	BaseBranch string
+	CopyFiles  []string
+	Container  ContainerConfig
+
+	ArchiveDestination string
+
+	// SetupSources lists the repo config files the settings came from
+	SetupSources []string
+}
+
+// Merge combines the user config with a repository's config.
+// Repository values take precedence where both specify a setting.
+func Merge(global *GlobalConfig, repo *RepoConfig) *Config {
+	cfg := &Config{GlobalConfig: *global}
+	if repo == nil {
+		return cfg
+	}
+	cfg.Setup = repo.Setup
+	cfg.Teardown = repo.Teardown
+	cfg.Verify = repo.Verify
+	cfg.Schedule = repo.Schedule
+	cfg.BaseBranch = repo.BaseBranch
+	cfg.CopyFiles = repo.CopyFiles
+	cfg.Container = repo.Container
+	cfg.ArchiveDestination = repo.ArchiveDestination
+	cfg.SetupSources = repo.Sources
+	if strings.TrimSpace(repo.Agent) != "" {
+		cfg.Agent = repo.Agent
+	}
+	if repo.Shell != "" {
+		cfg.Shell = repo.Shell
+	}
+	if repo.Locale != "" {
+		cfg.Locale = repo.Locale
+	}
+	if repo.Term != "" {
+		cfg.Term = repo.Term
+	}
+	if repo.AgentFlags.Model != "" {
+		cfg.AgentFlags.Model = repo.AgentFlags.Model
+	}
+	if repo.AgentFlags.PermissionMode != "" {
+		cfg.AgentFlags.PermissionMode = repo.AgentFlags.PermissionMode
+	}
+	if len(repo.AgentFlags.AllowedTools) > 0 {
+		cfg.AgentFlags.AllowedTools = repo.AgentFlags.AllowedTools
+	}
+	if repo.AgentFlags.Extra != "" {
+		cfg.AgentFlags.Extra = repo.AgentFlags.Extra
+	}
+	return cfg
+}
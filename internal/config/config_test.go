@@ -0,0 +1,214 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadCursorFallback(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".cursor", "worktrees.json"), `{"setup-worktree": ["npm install"]}`)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if want := []string{"npm install"}; !reflect.DeepEqual(cfg.Setup, want) {
+		t.Errorf("Setup = %v, want %v", cfg.Setup, want)
+	}
+}
+
+func TestLoadPrefersATCConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".cursor", "worktrees.json"), `{"setup-worktree": ["npm install"]}`)
+	writeFile(t, filepath.Join(dir, ".atc.yaml"), "setup:\n  - make deps\nteardown:\n  - make clean\nbase_branch: develop\nagent: claude --model opus\ncopy_files:\n  - .env\n")
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := &RepoConfig{
+		Setup:      []string{"make deps"},
+		Teardown:   []string{"make clean"},
+		BaseBranch: "develop",
+		Agent:      "claude --model opus",
+		CopyFiles:  []string{".env"},
+		Sources:    []string{filepath.Join(dir, ".atc.yaml")},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Load = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoadCursorModes(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    []string
+		wantErr bool
+	}{
+		{"", []string{"make deps"}, false},
+		{"fallback", []string{"make deps"}, false},
+		{"ignore", []string{"make deps"}, false},
+		{"before", []string{"npm install", "make deps"}, false},
+		{"after", []string{"make deps", "npm install"}, false},
+		{"sometimes", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, ".cursor", "worktrees.json"), `{"setup-worktree": ["npm install"]}`)
+			writeFile(t, filepath.Join(dir, ".atc.yaml"), "cursor: "+tt.mode+"\nsetup:\n  - make deps\n")
+
+			cfg, err := Load(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(cfg.Setup, tt.want) {
+				t.Errorf("Setup = %v, want %v", cfg.Setup, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadCursorIgnoreWithoutSetup(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".cursor", "worktrees.json"), `{"setup-worktree": ["npm install"]}`)
+	writeFile(t, filepath.Join(dir, ".atc.yaml"), "cursor: ignore\n")
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Setup) != 0 {
+		t.Errorf("Setup = %v, want none", cfg.Setup)
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".atc.json"), `{"setup": ["go mod download"], "base_branch": "main"}`)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.BaseBranch != "main" || len(cfg.Setup) != 1 {
+		t.Errorf("Load = %+v", cfg)
+	}
+}
+
+func TestMergeRepoOverridesAgent(t *testing.T) {
+	global := DefaultGlobalConfig(t.TempDir())
+	merged := Merge(global, &RepoConfig{Agent: "codex", BaseBranch: "develop"})
+	if merged.Agent != "codex" {
+		t.Errorf("Agent = %q, want codex", merged.Agent)
+	}
+	if merged.BaseBranch != "develop" {
+		t.Errorf("BaseBranch = %q, want develop", merged.BaseBranch)
+	}
+
+	merged = Merge(global, &RepoConfig{})
+	if merged.Agent != DefaultAgent {
+		t.Errorf("Agent = %q, want %q", merged.Agent, DefaultAgent)
+	}
+
+	global.AgentFlags = AgentFlags{Model: "sonnet", AllowedTools: []string{"Bash"}}
+	merged = Merge(global, &RepoConfig{AgentFlags: AgentFlags{Model: "opus"}})
+	if got := strings.Join(merged.AgentFlags.Args(), " "); got != "--model opus --allowedTools Bash" {
+		t.Errorf("AgentFlags.Args() = %q, want the repo's model and the user's tools", got)
+	}
+}
+
+func TestCommandsFingerprint(t *testing.T) {
+	base := &RepoConfig{Setup: []string{"npm ci"}, Teardown: []string{"docker compose down"}}
+	if (&RepoConfig{}).CommandsFingerprint() != "" {
+		t.Error("fingerprint of a config without commands should be empty")
+	}
+	if base.CommandsFingerprint() != (&RepoConfig{Setup: []string{"npm ci"}, Teardown: []string{"docker compose down"}, BaseBranch: "dev"}).CommandsFingerprint() {
+		t.Error("fingerprint should only depend on what the config runs")
+	}
+	changed := []*RepoConfig{
+		{Setup: []string{"npm ci", "make"}, Teardown: base.Teardown},
+		{Setup: base.Teardown, Teardown: base.Setup},
+		{Setup: []string{"npm", "ci"}, Teardown: base.Teardown},
+		{Setup: base.Setup, Teardown: base.Teardown, Verify: "npm test"},
+		{Setup: base.Setup, Teardown: base.Teardown, Schedule: []ScheduleConfig{{Name: "deps", At: "02:00", Prompt: "update deps"}}},
+		{Setup: base.Setup, Teardown: base.Teardown, Agent: "codex"},
+		{Setup: base.Setup, Teardown: base.Teardown, Shell: "/bin/zsh"},
+		{Setup: base.Setup, Teardown: base.Teardown, AgentFlags: AgentFlags{PermissionMode: "bypassPermissions"}},
+		{Setup: base.Setup, Teardown: base.Teardown, AgentFlags: AgentFlags{Extra: "--dangerously-skip-permissions"}},
+		{Setup: base.Setup, Teardown: base.Teardown, Container: ContainerConfig{Image: "node:22"}},
+		{Setup: base.Setup, Teardown: base.Teardown, Container: ContainerConfig{Devcontainer: true, Args: []string{"-v", "/:/host"}}},
+		{Setup: base.Setup, Teardown: base.Teardown, Container: ContainerConfig{Devcontainer: true, Shell: "bash"}},
+		{Setup: base.Setup, Teardown: base.Teardown, ArchiveDestination: "s3://bucket/atc"},
+	}
+	for _, c := range changed {
+		if c.CommandsFingerprint() == base.CommandsFingerprint() {
+			t.Errorf("fingerprint of %+v should differ", c)
+		}
+		if fp := c.Untrusted().CommandsFingerprint(); fp != "" {
+			t.Errorf("untrusted copy of %+v should have nothing to trust", c)
+		}
+	}
+}
+
+func TestScheduleDue(t *testing.T) {
+	// A Saturday
+	day := time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name  string
+		sched ScheduleConfig
+		at    time.Duration
+		want  bool
+	}{
+		{"before", ScheduleConfig{At: "02:00"}, time.Hour + 59*time.Minute, false},
+		{"at", ScheduleConfig{At: "02:00"}, 2 * time.Hour, true},
+		{"caught up later", ScheduleConfig{At: "02:00"}, 23 * time.Hour, true},
+		{"on its day", ScheduleConfig{At: "02:00", Days: []string{"Sat", "sun"}}, 3 * time.Hour, true},
+		{"not its day", ScheduleConfig{At: "02:00", Days: []string{"mon", "fri"}}, 3 * time.Hour, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sched.Due(day.Add(tt.at)); got != tt.want {
+				t.Errorf("Due = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got := (ScheduleConfig{Name: "deps"}).SessionName(day); got != "deps-20261017" {
+		t.Errorf("SessionName = %q, want deps-20261017", got)
+	}
+}
+
+func TestLoadScheduleValidation(t *testing.T) {
+	tests := []struct {
+		schedule string
+		wantErr  bool
+	}{
+		{"  - name: deps\n    at: \"02:00\"\n    days: [mon, fri]\n    prompt: update deps\n", false},
+		{"  - name: deps\n    at: \"02:00\"\n", true},
+		{"  - name: deps\n    at: 2am\n    prompt: update deps\n", true},
+		{"  - name: deps\n    at: \"02:00\"\n    days: [monday]\n    prompt: update deps\n", true},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, ".atc.yaml"), "schedule:\n"+tt.schedule)
+		if _, err := Load(dir); (err != nil) != tt.wantErr {
+			t.Errorf("Load(%q) error = %v, wantErr %v", tt.schedule, err, tt.wantErr)
+		}
+	}
+}
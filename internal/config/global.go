@@ -0,0 +1,382 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Default values applied when the user config omits a setting
+const (
+	DefaultAgent        = "claude"
+	DefaultPollInterval = 50 * time.Millisecond
+	DefaultTheme        = "auto"
+	DefaultTerm         = "xterm-256color"
+	DefaultOutput       = "control"
+	DefaultOnQuit       = "ask"
+	DefaultSyncStrategy = "rebase"
+
+	DefaultRespawnDelay = 2 * time.Second
+	DefaultMaxRestarts  = 5
+
+	DefaultPortBase  = 3100
+	DefaultPortCount = 10
+
+	DefaultTrashDays = 7
+
+	DefaultPluginTimeout = 10 * time.Second
+)
+
+// terminalOutputs lists the valid terminal_output settings
+var terminalOutputs = []string{"control", "pipe", "poll"}
+
+// quitActions lists the valid on_quit settings
+var quitActions = []string{"ask", "detach", "kill"}
+
+// syncStrategies lists the valid sync_strategy settings
+var syncStrategies = []string{"rebase", "merge"}
+
+// globalConfigNames lists the user config file names in lookup order
+var globalConfigNames = []string{"config.yaml", "config.yml", "config.toml"}
+
+// GlobalConfig represents the user-level configuration in ~/.atc/config.{yaml,toml}
+type GlobalConfig struct {
+	Agent         string                 `yaml:"agent" toml:"agent"`
+	PollInterval  time.Duration          `yaml:"poll_interval" toml:"poll_interval"`
+	Theme         string                 `yaml:"theme" toml:"theme"`
+	Themes        map[string]ThemeConfig `yaml:"themes" toml:"themes"`
+	Keybindings   map[string]string      `yaml:"keybindings" toml:"keybindings"`
+	WorktreeRoot  string                 `yaml:"worktree_root" toml:"worktree_root"`
+	Notifications NotificationConfig     `yaml:"notifications" toml:"notifications"`
+
+	// How terminal panes are watched: control (tmux control mode), pipe
+	// (pipe-pane stream) or poll
+	TerminalOutput string `yaml:"terminal_output" toml:"terminal_output"`
+
+	// What quitting does with running agents: ask, detach (leave them
+	// running in tmux) or kill
+	OnQuit string `yaml:"on_quit" toml:"on_quit"`
+
+	// Whether new sessions fetch their base branch from origin first and
+	// start from the remote state (toggled per session in the TUI)
+	FetchBeforeCreate bool `yaml:"fetch_before_create" toml:"fetch_before_create"`
+
+	// How syncing brings a session's branch up to date with its base:
+	// rebase or merge
+	SyncStrategy string `yaml:"sync_strategy" toml:"sync_strategy"`
+
+	// Command that opens a session in a new terminal window. It runs with
+	// sh -c and gets the command that attaches to the session in
+	// $ATC_ATTACH_COMMAND ("" = Terminal.app on macOS, $TERMINAL or
+	// x-terminal-emulator elsewhere)
+	ExternalTerminal string `yaml:"external_terminal" toml:"external_terminal"`
+
+	// Editor command a session's worktree path is passed to. "" = $VISUAL
+	// or $EDITOR, which are taken to be terminal editors and run in place
+	// of ATC until they exit
+	Editor string `yaml:"editor" toml:"editor"`
+
+	// Git UI toggled into a session's terminal pane, run in the worktree
+	// with sh -c ("" = lazygit)
+	GitUI string `yaml:"git_ui" toml:"git_ui"`
+
+	// More windows sessions' terminal panes can show, such as a dev server
+	// or logs
+	Windows []WindowConfig `yaml:"windows" toml:"windows"`
+
+	// Restarting agents that crash
+	AutoRespawn AutoRespawnConfig `yaml:"auto_respawn" toml:"auto_respawn"`
+
+	// Ports each session gets for its dev servers
+	Ports PortsConfig `yaml:"ports" toml:"ports"`
+
+	// Whether queued prompts wait while an agent is held up by a Claude
+	// usage limit, until it resets
+	PauseQueueOnLimit bool `yaml:"pause_queue_on_limit" toml:"pause_queue_on_limit"`
+
+	// How many days deleted sessions' worktrees stay in the trash before
+	// `atc prune` removes them (0 = delete sessions right away)
+	TrashDays int `yaml:"trash_days" toml:"trash_days"`
+
+	// How long archived sessions are kept before ATC deletes them for good
+	ArchiveRetention ArchiveRetentionConfig `yaml:"archive_retention" toml:"archive_retention"`
+
+	// Executables in <atc dir>/plugins told about sessions' events
+	Plugins PluginsConfig `yaml:"plugins" toml:"plugins"`
+
+	// Whether ATC runs in accessible mode, for screen readers: plain text,
+	// one pane at a time, with state changes printed as lines (also
+	// --accessible)
+	Accessible bool `yaml:"accessible" toml:"accessible"`
+
+	// Whether ATC draws only ASCII characters, for terminals and fonts
+	// without Unicode
+	ASCII bool `yaml:"ascii" toml:"ascii"`
+
+	// Whether nothing on screen animates and background polling slows
+	// down, for motion sensitivity or slow SSH links
+	ReducedMotion bool `yaml:"reduced_motion" toml:"reduced_motion"`
+
+	// Whether ATC looks for a new release once a day and notes it in the
+	// sidebar
+	CheckForUpdates bool `yaml:"check_for_updates" toml:"check_for_updates"`
+
+	// Where deleted sessions' worktrees are kept (<atc dir>/trash)
+	TrashRoot string `yaml:"-" toml:"-"`
+
+	// Flags added to the agent command whenever it starts (overridable per
+	// repo)
+	AgentFlags AgentFlags `yaml:"agent_flags" toml:"agent_flags"`
+
+	// Agent process environment (overridable per repo)
+	Shell  string `yaml:"shell" toml:"shell"`
+	Locale string `yaml:"locale" toml:"locale"`
+	Term   string `yaml:"term" toml:"term"`
+}
+
+// NotificationConfig controls how ATC gets the user's attention when a
+// session needs it (agent exited, setup finished or failed)
+type NotificationConfig struct {
+	Bell    bool   `yaml:"bell" toml:"bell"`
+	Command string `yaml:"command" toml:"command"`
+}
+
+// AgentFlags are common agent (claude) command-line flags. Extra holds any
+// others, split on spaces.
+type AgentFlags struct {
+	Model          string   `yaml:"model" toml:"model" json:"model"`
+	PermissionMode string   `yaml:"permission_mode" toml:"permission_mode" json:"permission_mode"`
+	AllowedTools   []string `yaml:"allowed_tools" toml:"allowed_tools" json:"allowed_tools"`
+	Extra          string   `yaml:"extra" toml:"extra" json:"extra"`
+}
+
+// Args returns the flags as command-line arguments
+func (f AgentFlags) Args() []string {
+	var args []string
+	if f.Model != "" {
+		args = append(args, "--model", f.Model)
+	}
+	if f.PermissionMode != "" {
+		args = append(args, "--permission-mode", f.PermissionMode)
+	}
+	if len(f.AllowedTools) > 0 {
+		args = append(args, "--allowedTools", strings.Join(f.AllowedTools, ","))
+	}
+	return append(args, strings.Fields(f.Extra)...)
+}
+
+// AutoRespawnConfig restarts an agent, continuing its conversation, when it
+// exits with an error or is killed. Each crash in a row doubles the wait
+// before the next restart, and after MaxRestarts of them the session is left
+// stopped and flagged in the sidebar.
+type AutoRespawnConfig struct {
+	Enabled     bool          `yaml:"enabled" toml:"enabled"`
+	Delay       time.Duration `yaml:"delay" toml:"delay"`
+	MaxRestarts int           `yaml:"max_restarts" toml:"max_restarts"`
+}
+
+// PortsConfig sets the range of ports handed out to sessions: Count ports
+// each, from Base on, passed to the agent, setup commands and windows as
+// $ATC_PORT (the first) and $ATC_PORT_0 to $ATC_PORT_<Count-1>, so parallel
+// sessions' dev servers don't collide
+type PortsConfig struct {
+	Base  int `yaml:"base" toml:"base"`
+	Count int `yaml:"count" toml:"count"`
+}
+
+// ArchiveRetentionConfig deletes sessions archived more than Days ago, with
+// their worktrees and, with DeleteBranches, their branches, each time ATC
+// starts (0 days = keep them until `atc prune`)
+type ArchiveRetentionConfig struct {
+	Days           int  `yaml:"days" toml:"days"`
+	DeleteBranches bool `yaml:"delete_branches" toml:"delete_branches"`
+}
+
+// PluginsConfig sets what plugins are told about and how long they may take.
+// Lines containing one of Keywords that appear on an agent's screen are sent
+// to them as output_matched events.
+type PluginsConfig struct {
+	Keywords []string      `yaml:"keywords" toml:"keywords"`
+	Timeout  time.Duration `yaml:"timeout" toml:"timeout"`
+
+	// Where plugins are looked for (<atc dir>/plugins)
+	Dir string `yaml:"-" toml:"-"`
+}
+
+// WindowConfig is a command run in a window of its own in each session's
+// tmux session. Its key toggles the terminal pane between the window and the
+// agent, starting the command in the worktree if it isn't running.
+type WindowConfig struct {
+	Name    string `yaml:"name" toml:"name"`
+	Command string `yaml:"command" toml:"command"`
+	Key     string `yaml:"key" toml:"key"`
+}
+
+// ThemeConfig is a user-defined color palette. Colors are hex strings
+// ("#rrggbb"); any color left empty is taken from the Base built-in theme
+// (auto, dark, light, or high-contrast; default auto).
+type ThemeConfig struct {
+	Base         string `yaml:"base" toml:"base"`
+	Primary      string `yaml:"primary" toml:"primary"`
+	Success      string `yaml:"success" toml:"success"`
+	Danger       string `yaml:"danger" toml:"danger"`
+	Text         string `yaml:"text" toml:"text"`
+	Muted        string `yaml:"muted" toml:"muted"`
+	Dim          string `yaml:"dim" toml:"dim"`
+	SelectedText string `yaml:"selected_text" toml:"selected_text"`
+	Foreground   string `yaml:"foreground" toml:"foreground"`
+	Background   string `yaml:"background" toml:"background"`
+}
+
+// DefaultGlobalConfig returns the configuration used when no user config exists
+func DefaultGlobalConfig(atcDir string) *GlobalConfig {
+	return &GlobalConfig{
+		Agent:          DefaultAgent,
+		PollInterval:   DefaultPollInterval,
+		TerminalOutput: DefaultOutput,
+		OnQuit:         DefaultOnQuit,
+		SyncStrategy:   DefaultSyncStrategy,
+		Theme:          DefaultTheme,
+		Keybindings:    map[string]string{},
+		WorktreeRoot:   filepath.Join(atcDir, "worktrees"),
+		Term:           DefaultTerm,
+		AutoRespawn: AutoRespawnConfig{
+			Delay:       DefaultRespawnDelay,
+			MaxRestarts: DefaultMaxRestarts,
+		},
+		Ports: PortsConfig{
+			Base:  DefaultPortBase,
+			Count: DefaultPortCount,
+		},
+		PauseQueueOnLimit: true,
+		TrashDays:         DefaultTrashDays,
+		CheckForUpdates:   true,
+		TrashRoot:         filepath.Join(atcDir, "trash"),
+		Plugins: PluginsConfig{
+			Timeout: DefaultPluginTimeout,
+			Dir:     filepath.Join(atcDir, "plugins"),
+		},
+	}
+}
+
+// LoadGlobal reads the user config from atcDir (usually ~/.atc).
+// Returns the defaults if no config file exists.
+func LoadGlobal(atcDir string) (*GlobalConfig, error) {
+	cfg := DefaultGlobalConfig(atcDir)
+
+	for _, name := range globalConfigNames {
+		path := filepath.Join(atcDir, name)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		if strings.HasSuffix(name, ".toml") {
+			err = toml.Unmarshal(data, cfg)
+		} else {
+			err = yaml.Unmarshal(data, cfg)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		break
+	}
+
+	cfg.applyDefaults(atcDir)
+	if !slices.Contains(terminalOutputs, cfg.TerminalOutput) {
+		return nil, fmt.Errorf("unknown terminal_output %q (want one of %s)", cfg.TerminalOutput, strings.Join(terminalOutputs, ", "))
+	}
+	if !slices.Contains(quitActions, cfg.OnQuit) {
+		return nil, fmt.Errorf("unknown on_quit %q (want one of %s)", cfg.OnQuit, strings.Join(quitActions, ", "))
+	}
+	if !slices.Contains(syncStrategies, cfg.SyncStrategy) {
+		return nil, fmt.Errorf("unknown sync_strategy %q (want one of %s)", cfg.SyncStrategy, strings.Join(syncStrategies, ", "))
+	}
+	if cfg.TrashDays < 0 {
+		return nil, fmt.Errorf("trash_days must not be negative")
+	}
+	if cfg.ArchiveRetention.Days < 0 {
+		return nil, fmt.Errorf("archive_retention: days must not be negative")
+	}
+	if cfg.Ports.Base+cfg.Ports.Count-1 > 65535 {
+		return nil, fmt.Errorf("ports: base %d is too high for %d ports", cfg.Ports.Base, cfg.Ports.Count)
+	}
+	for i, w := range cfg.Windows {
+		if w.Name == "" || w.Command == "" || w.Key == "" {
+			return nil, fmt.Errorf("windows[%d]: name, command and key are all required", i)
+		}
+	}
+	return cfg, nil
+}
+
+// applyDefaults fills in zero values and expands ~ in paths
+func (c *GlobalConfig) applyDefaults(atcDir string) {
+	defaults := DefaultGlobalConfig(atcDir)
+	if strings.TrimSpace(c.Agent) == "" {
+		c.Agent = defaults.Agent
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = defaults.PollInterval
+	}
+	if c.TerminalOutput == "" {
+		c.TerminalOutput = defaults.TerminalOutput
+	}
+	if c.Theme == "" {
+		c.Theme = defaults.Theme
+	}
+	if c.OnQuit == "" {
+		c.OnQuit = defaults.OnQuit
+	}
+	if c.SyncStrategy == "" {
+		c.SyncStrategy = defaults.SyncStrategy
+	}
+	if c.Keybindings == nil {
+		c.Keybindings = defaults.Keybindings
+	}
+	if c.Term == "" {
+		c.Term = defaults.Term
+	}
+	if c.WorktreeRoot == "" {
+		c.WorktreeRoot = defaults.WorktreeRoot
+	}
+	if c.AutoRespawn.Delay <= 0 {
+		c.AutoRespawn.Delay = defaults.AutoRespawn.Delay
+	}
+	if c.AutoRespawn.MaxRestarts <= 0 {
+		c.AutoRespawn.MaxRestarts = defaults.AutoRespawn.MaxRestarts
+	}
+	if c.Ports.Base <= 0 {
+		c.Ports.Base = defaults.Ports.Base
+	}
+	if c.Ports.Count <= 0 {
+		c.Ports.Count = defaults.Ports.Count
+	}
+	if c.Plugins.Timeout <= 0 {
+		c.Plugins.Timeout = defaults.Plugins.Timeout
+	}
+	if c.Plugins.Dir == "" {
+		c.Plugins.Dir = defaults.Plugins.Dir
+	}
+	c.WorktreeRoot = expandHome(c.WorktreeRoot)
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadGlobalDefaults(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := LoadGlobal(dir)
+	if err != nil {
+		t.Fatalf("LoadGlobal: %v", err)
+	}
+	if cfg.Agent != DefaultAgent {
+		t.Errorf("Agent = %q, want %q", cfg.Agent, DefaultAgent)
+	}
+	if cfg.PollInterval != DefaultPollInterval {
+		t.Errorf("PollInterval = %v, want %v", cfg.PollInterval, DefaultPollInterval)
+	}
+	if want := filepath.Join(dir, "worktrees"); cfg.WorktreeRoot != want {
+		t.Errorf("WorktreeRoot = %q, want %q", cfg.WorktreeRoot, want)
+	}
+}
+
+func TestLoadGlobalFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"yaml", "config.yaml", "agent: codex\npoll_interval: 200ms\nkeybindings:\n  new: ctrl+n\nnotifications:\n  bell: true\n"},
+		{"toml", "config.toml", "agent = \"codex\"\npoll_interval = \"200ms\"\n[keybindings]\nnew = \"ctrl+n\"\n[notifications]\nbell = true\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, tt.file), []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadGlobal(dir)
+			if err != nil {
+				t.Fatalf("LoadGlobal: %v", err)
+			}
+			if cfg.Agent != "codex" {
+				t.Errorf("Agent = %q, want codex", cfg.Agent)
+			}
+			if cfg.PollInterval != 200*time.Millisecond {
+				t.Errorf("PollInterval = %v, want 200ms", cfg.PollInterval)
+			}
+			if cfg.Keybindings["new"] != "ctrl+n" {
+				t.Errorf("Keybindings[new] = %q, want ctrl+n", cfg.Keybindings["new"])
+			}
+			if !cfg.Notifications.Bell {
+				t.Error("Notifications.Bell = false, want true")
+			}
+			if cfg.Theme != DefaultTheme {
+				t.Errorf("Theme = %q, want default %q", cfg.Theme, DefaultTheme)
+			}
+		})
+	}
+}
+
+func TestLoadGlobalTerminalOutput(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("terminal_output: telepathy\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadGlobal(dir); err == nil {
+		t.Error("LoadGlobal accepted an unknown terminal_output")
+	}
+}
+
+func TestLoadGlobalWindows(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"complete", "windows:\n  - name: server\n    command: npm run dev\n    key: D\n", false},
+		{"missing key", "windows:\n  - name: server\n    command: npm run dev\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadGlobal(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadGlobal error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (len(cfg.Windows) != 1 || cfg.Windows[0].Command != "npm run dev") {
+				t.Errorf("Windows = %+v", cfg.Windows)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Package container runs session agents in Docker containers or
+// devcontainers, one per worktree, with the worktree mounted at the same path
+// so paths in the agent's output and transcripts match the host's.
+package container
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kevinzwang/air-traffic-control/internal/config"
+	"github.com/kevinzwang/air-traffic-control/internal/logging"
+	"github.com/kevinzwang/air-traffic-control/internal/shell"
+)
+
+// worktreeLabel marks the containers ATC starts with their worktree.
+// devcontainerLabel is the one the devcontainer CLI sets.
+const (
+	worktreeLabel     = "atc.worktree"
+	devcontainerLabel = "devcontainer.local_folder"
+)
+
+// unsafeNameChars are the characters Docker doesn't allow in names
+var unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// Name returns the name of the container for a worktree, readable and unique
+// per path
+func Name(worktreePath string) string {
+	hash := sha256.Sum256([]byte(worktreePath))
+	base := strings.Trim(unsafeNameChars.ReplaceAllString(filepath.Base(worktreePath), "-"), "-.")
+	return fmt.Sprintf("atc-%s-%x", base, hash[:4])
+}
+
+// AgentWrapper returns the command that runs a command line, given as one
+// more (quoted) argument, in the worktree's container, passing the
+// environment variables named in env on. A container left from an earlier run
+// is replaced so a restarted agent gets a fresh one
+func AgentWrapper(cfg config.ContainerConfig, worktreePath string, env []string) string {
+	wt := shell.Quote(worktreePath)
+	var script string
+	if cfg.Devcontainer {
+		remoteEnv := ""
+		for _, name := range env {
+			remoteEnv += ` --remote-env "` + name + `=$` + name + `"`
+		}
+		script = "devcontainer up --workspace-folder " + wt + " >/dev/null && " +
+			"exec devcontainer exec --workspace-folder " + wt + remoteEnv + ` sh -c "$1"`
+	} else {
+		name := Name(worktreePath)
+		run := []string{"docker", "run", "--rm", "-it", "--init",
+			"--name", name,
+			"--label", worktreeLabel + "=" + worktreePath,
+			"-v", worktreePath + ":" + worktreePath,
+			"-w", worktreePath,
+			"-e", "TERM", "-e", "LANG", "-e", "LC_ALL",
+		}
+		for _, name := range env {
+			run = append(run, "-e", name)
+		}
+		run = append(run, cfg.Args...)
+		run = append(run, cfg.Image)
+		script = "docker rm -f " + shell.Quote(name) + " >/dev/null 2>&1; exec " +
+			quoteAll(run) + ` sh -c "$1"`
+	}
+	return "sh -c " + shell.Quote(script) + " sh"
+}
+
+// ShellCommand returns the command that opens an interactive shell in the
+// worktree's running container
+func ShellCommand(cfg config.ContainerConfig, worktreePath string) string {
+	sh := cfg.Shell
+	if sh == "" {
+		sh = "sh"
+	}
+	if cfg.Devcontainer {
+		return "devcontainer exec --workspace-folder " + shell.Quote(worktreePath) + " " + shell.Quote(sh)
+	}
+	return "docker exec -it -w " + shell.Quote(worktreePath) + " " + shell.Quote(Name(worktreePath)) + " " + shell.Quote(sh)
+}
+
+// States returns the state of each worktree's container ("running",
+// "exited", ...), keyed by worktree path. A running container wins over
+// stopped ones for the same worktree
+func States() (map[string]string, error) {
+	out, err := logging.Output(exec.Command("docker", "ps", "-a", "--format",
+		`{{.Label "`+worktreeLabel+`"}}	{{.Label "`+devcontainerLabel+`"}}	{{.State}}`))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	return parseStates(string(out)), nil
+}
+
+// parseStates reads `docker ps` lines of worktree label, devcontainer label
+// and state
+func parseStates(out string) map[string]string {
+	states := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		path := fields[0]
+		if path == "" {
+			path = fields[1]
+		}
+		if path == "" || states[path] == "running" {
+			continue
+		}
+		states[path] = fields[2]
+	}
+	return states
+}
+
+// Remove deletes the worktree's containers, running or not
+func Remove(worktreePath string) error {
+	for _, label := range []string{worktreeLabel, devcontainerLabel} {
+		out, err := logging.Output(exec.Command("docker", "ps", "-aq", "--filter", "label="+label+"="+worktreePath))
+		if err != nil {
+			return fmt.Errorf("failed to list containers: %w", err)
+		}
+		ids := strings.Fields(string(out))
+		if len(ids) == 0 {
+			continue
+		}
+		if out, err := logging.CombinedOutput(exec.Command("docker", append([]string{"rm", "-f"}, ids...)...)); err != nil {
+			return fmt.Errorf("failed to remove containers: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// quoteAll quotes each word and joins them into a command line
+func quoteAll(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = shell.Quote(w)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,81 @@
+package container
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kevinzwang/air-traffic-control/internal/config"
+)
+
+func TestAgentWrapper(t *testing.T) {
+	// A fake docker that prints its arguments, one per line
+	bin := t.TempDir()
+	fake := "#!/bin/sh\n[ \"$1\" = rm ] && exit 0\nprintf '%s\\n' \"$@\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	wt := "/work/my tree"
+	cfg := config.ContainerConfig{Image: "node:20", Args: []string{"--network=host"}}
+	out, err := exec.Command("sh", "-c", AgentWrapper(cfg, wt, []string{"ATC_PORT"})+" 'claude --continue'").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	if args[0] != "run" {
+		t.Fatalf("args = %q, want docker run", args)
+	}
+	if got, want := args[len(args)-4:], []string{"node:20", "sh", "-c", "claude --continue"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("command = %q, want %q", got, want)
+	}
+	for _, want := range []string{"--network=host", wt + ":" + wt, Name(wt), "ATC_PORT"} {
+		found := false
+		for _, a := range args {
+			found = found || a == want
+		}
+		if !found {
+			t.Errorf("args %q are missing %q", args, want)
+		}
+	}
+}
+
+func TestShellCommand(t *testing.T) {
+	bin := t.TempDir()
+	fake := "#!/bin/sh\nprintf '%s\\n' \"$@\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// The shell is one argument, however it's spelled
+	wt := t.TempDir()
+	cfg := config.ContainerConfig{Image: "node:20", Shell: "bash; touch " + filepath.Join(wt, "pwned")}
+	out, err := exec.Command("sh", "-c", ShellCommand(cfg, wt)).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	if got := args[len(args)-1]; got != cfg.Shell {
+		t.Errorf("shell = %q, want %q", got, cfg.Shell)
+	}
+	if _, err := os.Stat(filepath.Join(wt, "pwned")); err == nil {
+		t.Error("the shell setting ran a command on the host")
+	}
+}
+
+func TestParseStates(t *testing.T) {
+	out := "/wt/a\t\texited\n" +
+		"/wt/a\t\trunning\n" +
+		"/wt/a\t\texited\n" +
+		"\t/wt/b\trunning\n" +
+		"\t\trunning\n"
+	want := map[string]string{"/wt/a": "running", "/wt/b": "running"}
+	if got := parseStates(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseStates() = %v, want %v", got, want)
+	}
+}
@@ -21,13 +21,21 @@ func Open(path string) (*DB, error) {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
 
-	conn, err := sql.Open("sqlite3", path)
+	// WAL lets readers proceed while another ATC process writes, and the busy
+	// timeout makes SQLite wait for locks instead of failing with SQLITE_BUSY
+	dsn := fmt.Sprintf("%s?_journal_mode=WAL&_busy_timeout=%d&_foreign_keys=on", path, busyTimeout.Milliseconds())
+	conn, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	db := &DB{conn: conn}
 
+	if err := db.checkIntegrity(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
 	// Run migrations
 	if err := db.Migrate(); err != nil {
 		conn.Close()
@@ -42,27 +50,77 @@ func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
-// Migrate creates the database schema
+// Migrate brings the schema up to date by applying, in order, each
+// migration newer than the version recorded in schema_version. Every
+// migration runs in its own transaction together with the version bump.
 func (db *DB) Migrate() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS sessions (
-		id TEXT PRIMARY KEY,
-		name TEXT NOT NULL UNIQUE,
-		repo_path TEXT NOT NULL,
-		repo_name TEXT NOT NULL,
-		worktree_path TEXT NOT NULL,
-		branch_name TEXT NOT NULL,
-		created_at TIMESTAMP NOT NULL,
-		last_accessed TIMESTAMP,
-		archived_at TIMESTAMP,
-		status TEXT DEFAULT 'active'
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_sessions_repo ON sessions(repo_name);
-	CREATE INDEX IF NOT EXISTS idx_sessions_status ON sessions(status);
-	CREATE INDEX IF NOT EXISTS idx_sessions_archived ON sessions(archived_at);
-	`
-
-	_, err := db.conn.Exec(schema)
-	return err
+	if _, err := db.exec(`CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)`); err != nil {
+		return fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this version of atc supports (%d); upgrade atc", current, len(migrations))
+	}
+
+	for v := current + 1; v <= len(migrations); v++ {
+		if err := db.applyMigration(v, migrations[v-1]); err != nil {
+			return fmt.Errorf("migration %d failed: %w", v, err)
+		}
+	}
+	return nil
+}
+
+// SchemaVersion returns the number of migrations applied to the database
+func (db *DB) SchemaVersion() (int, error) {
+	var version int
+	err := db.queryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`, nil, &version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// applyMigration runs a single migration and records its version
+func (db *DB) applyMigration(version int, schema string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(schema); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM schema_version`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_version (version) VALUES (?)`, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkIntegrity runs SQLite's quick integrity check so a corrupted database
+// is reported on open instead of failing in confusing ways later
+func (db *DB) checkIntegrity() error {
+	var result string
+	if err := db.conn.QueryRow(`PRAGMA quick_check`).Scan(&result); err != nil {
+		return fmt.Errorf("failed to check database integrity: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("database is corrupted: %s", result)
+	}
+	return nil
+}
+
+// Vacuum rebuilds the database file, reclaiming the space left by deleted rows
+func (db *DB) Vacuum() error {
+	if _, err := db.exec(`VACUUM`); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,433 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestMigrateFreshDatabase(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != len(migrations) {
+		t.Errorf("SchemaVersion = %d, want %d", version, len(migrations))
+	}
+
+	// Migrating again is a no-op
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("second Migrate: %v", err)
+	}
+}
+
+func TestMigrateUnversionedDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.db")
+
+	// A database from before schema versioning: tables but no schema_version
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(migrations[0] + migrations[1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(`INSERT INTO settings (key, value) VALUES ('sidebar_width', '40')`); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+
+	if v, _ := db.GetSetting("sidebar_width"); v != "40" {
+		t.Errorf("setting lost in migration: got %q", v)
+	}
+	if version, _ := db.SchemaVersion(); version != len(migrations) {
+		t.Errorf("SchemaVersion = %d, want %d", version, len(migrations))
+	}
+}
+
+func TestMigrateRejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.conn.Exec(`UPDATE schema_version SET version = ?`, len(migrations)+1); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	if _, err := Open(path); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Open = %v, want error about a newer schema", err)
+	}
+}
+
+func TestOpenUsesWAL(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var mode string
+	if err := db.queryRow(`PRAGMA journal_mode`, nil, &mode); err != nil {
+		t.Fatal(err)
+	}
+	if mode != "wal" {
+		t.Errorf("journal_mode = %q, want wal", mode)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	busy := sqlite3.Error{Code: sqlite3.ErrBusy}
+
+	calls := 0
+	err := retry(func() error {
+		calls++
+		if calls < 3 {
+			return busy
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("retry = %v after %d calls, want success after 3", err, calls)
+	}
+
+	calls = 0
+	other := errors.New("constraint failed")
+	if err := retry(func() error { calls++; return other }); err != other || calls != 1 {
+		t.Errorf("retry = %v after %d calls, want other error after 1", err, calls)
+	}
+}
+
+func TestAllocatePorts(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	allocate := func(id string, count int) int {
+		t.Helper()
+		port, err := db.AllocatePorts(id, 3100, count)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return port
+	}
+
+	if got := allocate("a", 10); got != 3100 {
+		t.Errorf("first range starts at %d, want 3100", got)
+	}
+	if got := allocate("b", 10); got != 3110 {
+		t.Errorf("second range starts at %d, want 3110", got)
+	}
+	if got := allocate("a", 10); got != 3100 {
+		t.Errorf("allocating again moved the range to %d", got)
+	}
+	// A wider range skips past the ranges it would overlap
+	if got := allocate("c", 20); got != 3120 {
+		t.Errorf("wider range starts at %d, want 3120", got)
+	}
+
+	// Freed ranges are reused
+	if err := db.DeletePorts("a"); err != nil {
+		t.Fatal(err)
+	}
+	if port, count, _ := db.SessionPorts("a"); port != 0 || count != 0 {
+		t.Errorf("SessionPorts after delete = %d, %d", port, count)
+	}
+	if got := allocate("d", 10); got != 3100 {
+		t.Errorf("range after delete starts at %d, want 3100", got)
+	}
+}
+
+func TestWorkTime(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, add := range []struct {
+		id string
+		d  time.Duration
+	}{{"a", 90 * time.Second}, {"b", time.Minute}, {"a", 30 * time.Second}} {
+		if err := db.AddWorkTime(add.id, add.d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.DeleteWorkTime("b"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := db.WorkTimes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]time.Duration{"a": 2 * time.Minute}; !maps.Equal(got, want) {
+		t.Errorf("WorkTimes = %v, want %v", got, want)
+	}
+}
+
+func TestSessionsVersion(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	version := func() int64 {
+		t.Helper()
+		v, err := db.SessionsVersion()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	v0 := version()
+	s := &Session{ID: "1", Name: "s", RepoPath: "/r", RepoName: "r", WorktreePath: "/w", BranchName: "s", CreatedAt: time.Now(), Status: "active"}
+	if err := db.InsertSession(s); err != nil {
+		t.Fatal(err)
+	}
+	v1 := version()
+	if v1 == v0 {
+		t.Fatal("insert didn't change the version")
+	}
+
+	now := time.Now()
+	s.LastAccessed = &now
+	if err := db.UpdateSession(s); err != nil {
+		t.Fatal(err)
+	}
+	if version() != v1 {
+		t.Error("touching a session changed the version")
+	}
+
+	if err := db.ArchiveSession(s.ID); err != nil {
+		t.Fatal(err)
+	}
+	if version() == v1 {
+		t.Error("archiving didn't change the version")
+	}
+}
+
+func TestBranchesWithSessions(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, s := range []*Session{
+		{ID: "1", Name: "api", RepoPath: "/r", RepoName: "r", WorktreePath: "/w/api", BranchName: "api", CreatedAt: time.Now(), Status: "active"},
+		{ID: "2", Name: "web", RepoPath: "/r", RepoName: "r", WorktreePath: "/w/web", BranchName: "feature/web", CreatedAt: time.Now(), Status: "active"},
+		{ID: "3", Name: "cli", RepoPath: "/other", RepoName: "other", WorktreePath: "/w/cli", BranchName: "cli", CreatedAt: time.Now(), Status: "active"},
+	} {
+		if err := db.InsertSession(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := db.BranchesWithSessions("/r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"api": true, "feature/web": true}; !maps.Equal(got, want) {
+		t.Errorf("BranchesWithSessions = %v, want %v", got, want)
+	}
+}
+
+func TestListSessionPages(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	start := time.Now()
+	for i := range 5 {
+		name := fmt.Sprintf("s%d", i)
+		s := &Session{ID: name, Name: name, RepoPath: "/r", RepoName: "r", WorktreePath: "/w/" + name, BranchName: name, CreatedAt: start.Add(time.Duration(i) * time.Minute), Status: "active"}
+		if err := db.InsertSession(s); err != nil {
+			t.Fatal(err)
+		}
+		if i < 4 {
+			if err := db.ArchiveSession(s.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	names := func(sessions []*Session, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, s := range sessions {
+			out = append(out, s.Name)
+		}
+		return strings.Join(out, " ")
+	}
+	if got := names(db.ListActiveSessions("r")); got != "s4" {
+		t.Errorf("active = %q, want s4", got)
+	}
+	if got := names(db.ListArchivedSessions("r", 3, 0)); got != "s3 s2 s1" {
+		t.Errorf("first archived page = %q, want s3 s2 s1", got)
+	}
+	if got := names(db.ListArchivedSessions("r", 3, 3)); got != "s0" {
+		t.Errorf("second archived page = %q, want s0", got)
+	}
+	if n, err := db.CountArchivedSessions("r"); err != nil || n != 4 {
+		t.Errorf("CountArchivedSessions = %d, %v; want 4", n, err)
+	}
+}
+
+func TestListProjectsOrder(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, name := range []string{"a", "b", "c"} {
+		if err := db.AddProject("/"+name, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	order := func() string {
+		t.Helper()
+		projects, err := db.ListProjects()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, p := range projects {
+			names = append(names, p.RepoName)
+		}
+		return strings.Join(names, " ")
+	}
+
+	if got := order(); got != "c b a" {
+		t.Errorf("new projects listed %q, want %q", got, "c b a")
+	}
+	if err := db.TouchProject("/a"); err != nil {
+		t.Fatal(err)
+	}
+	if got := order(); got != "a c b" {
+		t.Errorf("after opening a, listed %q, want %q", got, "a c b")
+	}
+	if err := db.SetProjectPinned("/b", true); err != nil {
+		t.Fatal(err)
+	}
+	if got := order(); got != "b a c" {
+		t.Errorf("after pinning b, listed %q, want %q", got, "b a c")
+	}
+}
+
+func TestTrashRoundTrip(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s := &Session{ID: "1", Name: "s", RepoPath: "/r", RepoName: "r", WorktreePath: "/w", BranchName: "s", BaseBranch: "main", CreatedAt: time.Now(), Status: "active"}
+	if err := db.InsertSession(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.TrashSession(s.ID, "/trash/s"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetSessionByName("s", "/r"); err == nil {
+		t.Error("trashed session is still listed")
+	}
+	trashed, err := db.ListTrash("/r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trashed) != 1 || trashed[0].TrashPath != "/trash/s" || trashed[0].BaseBranch != "main" {
+		t.Fatalf("ListTrash = %+v, want s in /trash/s", trashed)
+	}
+	if other, _ := db.ListTrash("/other"); len(other) != 0 {
+		t.Errorf("ListTrash(/other) = %+v, want none", other)
+	}
+
+	if err := db.RestoreSession(s.ID); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := db.GetSessionByName("s", "/r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.WorktreePath != "/w" || restored.BaseBranch != "main" {
+		t.Errorf("restored session = %+v", restored)
+	}
+	if trashed, _ := db.ListTrash(""); len(trashed) != 0 {
+		t.Errorf("trash still has %+v", trashed)
+	}
+	if err := db.RestoreSession(s.ID); err == nil {
+		t.Error("restored a session that isn't in the trash")
+	}
+}
+
+func TestDetachSession(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s := &Session{ID: "1", Name: "s", RepoPath: "/r", RepoName: "r", WorktreePath: "/w", BranchName: "s", CreatedAt: time.Now(), Status: "active"}
+	if err := db.InsertSession(s); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.AllocatePorts(s.ID, 3100, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DetachSession(s.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetSessionByName("s", "/r"); err == nil {
+		t.Error("detached session is still listed")
+	}
+	if _, count, _ := db.SessionPorts(s.ID); count != 0 {
+		t.Error("detached session kept its ports")
+	}
+	detached, err := db.ListDetached("/r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detached) != 1 || detached[0].Name != "s" || detached[0].WorktreePath != "/w" {
+		t.Fatalf("ListDetached = %+v, want s in /w", detached)
+	}
+
+	if err := db.ForgetDetached("/w"); err != nil {
+		t.Fatal(err)
+	}
+	if detached, _ := db.ListDetached(""); len(detached) != 0 {
+		t.Errorf("still detached: %+v", detached)
+	}
+	if err := db.DetachSession(s.ID); err == nil {
+		t.Error("detached a session that doesn't exist")
+	}
+}
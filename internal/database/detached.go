@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// DetachedSession is a session ATC no longer tracks, whose worktree and tmux
+// session were left for the user to take over
+type DetachedSession struct {
+	Name         string
+	RepoPath     string
+	WorktreePath string
+	DetachedAt   time.Time
+}
+
+// DetachSession removes a session's record, port range and usage, keeping
+// note of its worktree so its tmux session isn't cleaned up as a stray
+func (db *DB) DetachSession(id string) error {
+	err := retry(func() error {
+		tx, err := db.conn.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		query := `INSERT OR REPLACE INTO detached (worktree_path, name, repo_path, detached_at)
+			SELECT worktree_path, name, repo_path, ? FROM sessions WHERE id = ?`
+		result, err := tx.Exec(query, time.Now(), id)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return fmt.Errorf("session not found")
+		}
+		if _, err := tx.Exec(`DELETE FROM sessions WHERE id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM session_ports WHERE session_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM session_usage WHERE session_id = ?`, id); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to detach session: %w", err)
+	}
+	return nil
+}
+
+// ListDetached returns the detached sessions of a repository, or of all of
+// them if repoPath is empty
+func (db *DB) ListDetached(repoPath string) ([]*DetachedSession, error) {
+	query := `
+		SELECT name, repo_path, worktree_path, detached_at
+		FROM detached
+		WHERE ? = '' OR repo_path = ?
+		ORDER BY detached_at DESC
+	`
+	rows, err := db.query(query, repoPath, repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list detached sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var detached []*DetachedSession
+	for rows.Next() {
+		var d DetachedSession
+		if err := rows.Scan(&d.Name, &d.RepoPath, &d.WorktreePath, &d.DetachedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan detached session: %w", err)
+		}
+		detached = append(detached, &d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating detached sessions: %w", err)
+	}
+	return detached, nil
+}
+
+// ForgetDetached drops the note of a detached session's worktree
+func (db *DB) ForgetDetached(worktreePath string) error {
+	if _, err := db.exec(`DELETE FROM detached WHERE worktree_path = ?`, worktreePath); err != nil {
+		return fmt.Errorf("failed to forget detached session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// SessionEvent is a lifecycle event in a session's activity log
+type SessionEvent struct {
+	ID          int64
+	RepoPath    string
+	SessionName string
+	Kind        string
+	Detail      string
+	CreatedAt   time.Time
+}
+
+// InsertEvent appends an event to the activity log
+func (db *DB) InsertEvent(e *SessionEvent) error {
+	query := `
+		INSERT INTO session_events (repo_path, session_name, kind, detail, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	_, err := db.exec(query, e.RepoPath, e.SessionName, e.Kind, e.Detail, e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record event: %w", err)
+	}
+	return nil
+}
+
+// ListEvents returns the most recent events for a repository, newest first.
+// If sessionName is non-empty only that session's events are returned; events
+// outlive their session, so deleted sessions can still be looked up.
+func (db *DB) ListEvents(repoPath, sessionName string, limit int) ([]*SessionEvent, error) {
+	query := `
+		SELECT id, repo_path, session_name, kind, detail, created_at
+		FROM session_events
+		WHERE repo_path = ? AND (? = '' OR session_name = ?)
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`
+	rows, err := db.query(query, repoPath, sessionName, sessionName, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*SessionEvent
+	for rows.Next() {
+		e := &SessionEvent{}
+		if err := rows.Scan(&e.ID, &e.RepoPath, &e.SessionName, &e.Kind, &e.Detail, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
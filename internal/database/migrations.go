@@ -0,0 +1,178 @@
+package database
+
+// migrations are the schema changes applied by Migrate, in order; migration
+// N brings the schema to version N. Never edit or reorder a released
+// migration — append a new one instead.
+//
+// Databases created before versioning already contain some of these tables,
+// so the early migrations use IF NOT EXISTS and are safe to re-apply.
+var migrations = []string{
+	// 1: sessions
+	`
+	CREATE TABLE IF NOT EXISTS sessions (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		repo_path TEXT NOT NULL,
+		repo_name TEXT NOT NULL,
+		worktree_path TEXT NOT NULL,
+		branch_name TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		last_accessed TIMESTAMP,
+		archived_at TIMESTAMP,
+		status TEXT DEFAULT 'active'
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_sessions_repo ON sessions(repo_name);
+	CREATE INDEX IF NOT EXISTS idx_sessions_status ON sessions(status);
+	CREATE INDEX IF NOT EXISTS idx_sessions_archived ON sessions(archived_at);
+	`,
+
+	// 2: persisted UI settings
+	`
+	CREATE TABLE IF NOT EXISTS settings (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+	`,
+
+	// 3: setup command trust store
+	`
+	CREATE TABLE IF NOT EXISTS trusted_repos (
+		repo_path TEXT PRIMARY KEY,
+		fingerprint TEXT NOT NULL,
+		trusted_at TIMESTAMP NOT NULL
+	);
+	`,
+
+	// 4: token usage rollups
+	`
+	CREATE TABLE IF NOT EXISTS session_usage (
+		session_id TEXT PRIMARY KEY,
+		input_tokens INTEGER NOT NULL DEFAULT 0,
+		output_tokens INTEGER NOT NULL DEFAULT 0,
+		cache_write_tokens INTEGER NOT NULL DEFAULT 0,
+		cache_read_tokens INTEGER NOT NULL DEFAULT 0,
+		cost_usd REAL NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP NOT NULL
+	);
+	`,
+
+	// 5: session activity log
+	`
+	CREATE TABLE IF NOT EXISTS session_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_path TEXT NOT NULL,
+		session_name TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		detail TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_session_events_session ON session_events(repo_path, session_name);
+	`,
+
+	// 6: per-session port ranges
+	`
+	CREATE TABLE IF NOT EXISTS session_ports (
+		session_id TEXT PRIMARY KEY,
+		port INTEGER NOT NULL UNIQUE,
+		count INTEGER NOT NULL
+	);
+	`,
+
+	// 7: change counter other ATC instances poll to notice session changes
+	`
+	CREATE TABLE IF NOT EXISTS sessions_version (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		version INTEGER NOT NULL
+	);
+	INSERT OR IGNORE INTO sessions_version (id, version) VALUES (1, 0);
+
+	CREATE TRIGGER IF NOT EXISTS sessions_version_insert AFTER INSERT ON sessions BEGIN
+		UPDATE sessions_version SET version = version + 1;
+	END;
+	CREATE TRIGGER IF NOT EXISTS sessions_version_delete AFTER DELETE ON sessions BEGIN
+		UPDATE sessions_version SET version = version + 1;
+	END;
+	CREATE TRIGGER IF NOT EXISTS sessions_version_update AFTER UPDATE ON sessions
+	WHEN OLD.name IS NOT NEW.name OR OLD.worktree_path IS NOT NEW.worktree_path
+		OR OLD.branch_name IS NOT NEW.branch_name OR OLD.archived_at IS NOT NEW.archived_at
+		OR OLD.status IS NOT NEW.status
+	BEGIN
+		UPDATE sessions_version SET version = version + 1;
+	END;
+	`,
+
+	// 8: known projects, including ones without sessions
+	`
+	CREATE TABLE IF NOT EXISTS projects (
+		repo_path TEXT PRIMARY KEY,
+		repo_name TEXT NOT NULL,
+		added_at TIMESTAMP NOT NULL
+	);
+
+	INSERT OR IGNORE INTO projects (repo_path, repo_name, added_at)
+		SELECT repo_path, repo_name, MIN(created_at) FROM sessions GROUP BY repo_path;
+	`,
+
+	// 9: pinned projects and when each project was last opened
+	`
+	ALTER TABLE projects ADD COLUMN pinned INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE projects ADD COLUMN last_opened TIMESTAMP;
+
+	UPDATE projects SET last_opened = (
+		SELECT MAX(COALESCE(s.last_accessed, s.created_at)) FROM sessions s
+		WHERE s.repo_path = projects.repo_path
+	);
+	`,
+
+	// 10: per-user default base branch for new sessions
+	`
+	ALTER TABLE projects ADD COLUMN base_branch TEXT;
+	`,
+
+	// 11: the branch each session's branch was started from
+	`
+	ALTER TABLE sessions ADD COLUMN base_branch TEXT;
+	`,
+
+	// 12: deleted sessions whose worktrees wait in the trash
+	`
+	CREATE TABLE IF NOT EXISTS trash (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		repo_path TEXT NOT NULL,
+		repo_name TEXT NOT NULL,
+		worktree_path TEXT NOT NULL,
+		branch_name TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		last_accessed TIMESTAMP,
+		archived_at TIMESTAMP,
+		status TEXT DEFAULT 'active',
+		base_branch TEXT,
+		trash_path TEXT NOT NULL,
+		trashed_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_trash_repo ON trash(repo_path);
+	`,
+
+	// 13: sessions detached from ATC, whose worktrees and tmux sessions are
+	// left alone
+	`
+	CREATE TABLE IF NOT EXISTS detached (
+		worktree_path TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		repo_path TEXT NOT NULL,
+		detached_at TIMESTAMP NOT NULL
+	);
+	`,
+
+	// 14: how long each session's agent has spent working
+	`
+	CREATE TABLE IF NOT EXISTS session_work_time (
+		session_id TEXT PRIMARY KEY,
+		seconds REAL NOT NULL DEFAULT 0
+	);
+	`,
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// SessionPorts returns the first port and size of a session's port range,
+// or 0, 0 if it has none yet
+func (db *DB) SessionPorts(sessionID string) (port, count int, err error) {
+	err = db.queryRow(`SELECT port, count FROM session_ports WHERE session_id = ?`, []any{sessionID}, &port, &count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get ports: %w", err)
+	}
+	return port, count, nil
+}
+
+// AllocatePorts gives a session the lowest range of count ports from base on
+// that doesn't overlap another session's, in any repository, and returns its
+// first port. A session that already has a range keeps it.
+func (db *DB) AllocatePorts(sessionID string, base, count int) (int, error) {
+	var port int
+	err := retry(func() error {
+		tx, err := db.conn.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		var existing int
+		err = tx.QueryRow(`SELECT port FROM session_ports WHERE session_id = ?`, sessionID).Scan(&existing)
+		if err == nil {
+			port = existing
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		rows, err := tx.Query(`SELECT port, count FROM session_ports`)
+		if err != nil {
+			return err
+		}
+		var taken [][2]int
+		for rows.Next() {
+			var start, n int
+			if err := rows.Scan(&start, &n); err != nil {
+				rows.Close()
+				return err
+			}
+			taken = append(taken, [2]int{start, start + n})
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		port = freeRange(taken, base, count)
+		if port+count-1 > 65535 {
+			return fmt.Errorf("no free range of %d ports from %d", count, base)
+		}
+		if _, err := tx.Exec(`INSERT INTO session_ports (session_id, port, count) VALUES (?, ?, ?)`, sessionID, port, count); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate ports: %w", err)
+	}
+	return port, nil
+}
+
+// freeRange returns the first start of count ports from base on, in steps of
+// count, that overlaps none of the taken [start, end) ranges
+func freeRange(taken [][2]int, base, count int) int {
+	for start := base; ; start += count {
+		free := true
+		for _, r := range taken {
+			if start < r[1] && r[0] < start+count {
+				free = false
+				break
+			}
+		}
+		if free {
+			return start
+		}
+	}
+}
+
+// DeletePorts frees a session's port range
+func (db *DB) DeletePorts(sessionID string) error {
+	if _, err := db.exec(`DELETE FROM session_ports WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("failed to delete ports: %w", err)
+	}
+	return nil
+}
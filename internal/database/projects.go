@@ -0,0 +1,68 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AddProject records a repository as a known project, so it is offered in
+// the project picker even before it has sessions. Adding a known project
+// again is a no-op
+func (db *DB) AddProject(repoPath, repoName string) error {
+	query := `INSERT OR IGNORE INTO projects (repo_path, repo_name, added_at) VALUES (?, ?, ?)`
+	if _, err := db.exec(query, repoPath, repoName, time.Now()); err != nil {
+		return fmt.Errorf("failed to add project: %w", err)
+	}
+	return nil
+}
+
+// RemoveProject forgets a project. Its sessions, if any, are left alone
+func (db *DB) RemoveProject(repoPath string) error {
+	if _, err := db.exec(`DELETE FROM projects WHERE repo_path = ?`, repoPath); err != nil {
+		return fmt.Errorf("failed to remove project: %w", err)
+	}
+	return nil
+}
+
+// TouchProject records that a project was just opened, moving it up the
+// project picker
+func (db *DB) TouchProject(repoPath string) error {
+	if _, err := db.exec(`UPDATE projects SET last_opened = ? WHERE repo_path = ?`, time.Now(), repoPath); err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+	return nil
+}
+
+// SetProjectPinned pins or unpins a project. Pinned projects are listed
+// before all others
+func (db *DB) SetProjectPinned(repoPath string, pinned bool) error {
+	if _, err := db.exec(`UPDATE projects SET pinned = ? WHERE repo_path = ?`, pinned, repoPath); err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+	return nil
+}
+
+// ProjectBaseBranch returns the default base branch chosen for a project, or
+// "" if none was
+func (db *DB) ProjectBaseBranch(repoPath string) (string, error) {
+	var branch sql.NullString
+	err := db.queryRow(`SELECT base_branch FROM projects WHERE repo_path = ?`, []any{repoPath}, &branch)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get project base branch: %w", err)
+	}
+	return branch.String, nil
+}
+
+// SetProjectBaseBranch sets a project's default base branch. "" clears it
+func (db *DB) SetProjectBaseBranch(repoPath, branch string) error {
+	value := sql.NullString{String: branch, Valid: branch != ""}
+	if _, err := db.exec(`UPDATE projects SET base_branch = ? WHERE repo_path = ?`, value, repoPath); err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+	return nil
+}
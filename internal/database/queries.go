@@ -16,6 +16,7 @@ type Session struct {
 	RepoName      string
 	WorktreePath  string
 	BranchName    string
+	BaseBranch    string // what the branch was started from, "" if not known
 	CreatedAt     time.Time
 	LastAccessed  *time.Time
 	ArchivedAt    *time.Time
@@ -27,13 +28,13 @@ func (db *DB) InsertSession(s *Session) error {
 	query := `
 		INSERT INTO sessions (
 			id, name, repo_path, repo_name, worktree_path, branch_name,
-			created_at, last_accessed, archived_at, status
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			created_at, last_accessed, archived_at, status, base_branch
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := db.conn.Exec(query,
+	_, err := db.exec(query,
 		s.ID, s.Name, s.RepoPath, s.RepoName, s.WorktreePath, s.BranchName,
-		s.CreatedAt, s.LastAccessed, s.ArchivedAt, s.Status,
+		s.CreatedAt, s.LastAccessed, s.ArchivedAt, s.Status, s.BaseBranch,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert session: %w", err)
@@ -45,15 +46,15 @@ func (db *DB) InsertSession(s *Session) error {
 func (db *DB) GetSessionByName(name string, repoPath string) (*Session, error) {
 	query := `
 		SELECT id, name, repo_path, repo_name, worktree_path, branch_name,
-		       created_at, last_accessed, archived_at, status
+		       created_at, last_accessed, archived_at, status, COALESCE(base_branch, '')
 		FROM sessions
 		WHERE name = ? AND repo_path = ?
 	`
 
 	var s Session
-	err := db.conn.QueryRow(query, name, repoPath).Scan(
+	err := db.queryRow(query, []any{name, repoPath},
 		&s.ID, &s.Name, &s.RepoPath, &s.RepoName, &s.WorktreePath, &s.BranchName,
-		&s.CreatedAt, &s.LastAccessed, &s.ArchivedAt, &s.Status,
+		&s.CreatedAt, &s.LastAccessed, &s.ArchivedAt, &s.Status, &s.BaseBranch,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("session not found")
@@ -68,15 +69,15 @@ func (db *DB) GetSessionByName(name string, repoPath string) (*Session, error) {
 func (db *DB) GetSessionByBranchName(branchName string, repoPath string) (*Session, error) {
 	query := `
 		SELECT id, name, repo_path, repo_name, worktree_path, branch_name,
-		       created_at, last_accessed, archived_at, status
+		       created_at, last_accessed, archived_at, status, COALESCE(base_branch, '')
 		FROM sessions
 		WHERE branch_name = ? AND repo_path = ?
 	`
 
 	var s Session
-	err := db.conn.QueryRow(query, branchName, repoPath).Scan(
+	err := db.queryRow(query, []any{branchName, repoPath},
 		&s.ID, &s.Name, &s.RepoPath, &s.RepoName, &s.WorktreePath, &s.BranchName,
-		&s.CreatedAt, &s.LastAccessed, &s.ArchivedAt, &s.Status,
+		&s.CreatedAt, &s.LastAccessed, &s.ArchivedAt, &s.Status, &s.BaseBranch,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil // Return nil, nil when not found (branch has no session)
@@ -87,11 +88,34 @@ func (db *DB) GetSessionByBranchName(branchName string, repoPath string) (*Sessi
 	return &s, nil
 }
 
+// BranchesWithSessions returns the branch names of a repo's sessions, in one
+// query rather than one per branch
+func (db *DB) BranchesWithSessions(repoPath string) (map[string]bool, error) {
+	rows, err := db.query(`SELECT DISTINCT branch_name FROM sessions WHERE repo_path = ?`, repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session branches: %w", err)
+	}
+	defer rows.Close()
+
+	branches := make(map[string]bool)
+	for rows.Next() {
+		var branch string
+		if err := rows.Scan(&branch); err != nil {
+			return nil, fmt.Errorf("failed to scan session branch: %w", err)
+		}
+		branches[branch] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating session branches: %w", err)
+	}
+	return branches, nil
+}
+
 // ListSessions retrieves sessions with optional filtering
 func (db *DB) ListSessions(repoFilter string, query string) ([]*Session, error) {
 	querySQL := `
 		SELECT id, name, repo_path, repo_name, worktree_path, branch_name,
-		       created_at, last_accessed, archived_at, status
+		       created_at, last_accessed, archived_at, status, COALESCE(base_branch, '')
 		FROM sessions
 		WHERE 1=1
 	`
@@ -111,7 +135,48 @@ func (db *DB) ListSessions(repoFilter string, query string) ([]*Session, error)
 
 	querySQL += " ORDER BY created_at DESC"
 
-	rows, err := db.conn.Query(querySQL, args...)
+	return db.listSessions(querySQL, args...)
+}
+
+// ListActiveSessions retrieves a repo's sessions that aren't archived, newest
+// first
+func (db *DB) ListActiveSessions(repoName string) ([]*Session, error) {
+	return db.listSessions(`
+		SELECT id, name, repo_path, repo_name, worktree_path, branch_name,
+		       created_at, last_accessed, archived_at, status, COALESCE(base_branch, '')
+		FROM sessions
+		WHERE repo_name = ? AND status != 'archived'
+		ORDER BY created_at DESC, id
+	`, repoName)
+}
+
+// ListArchivedSessions retrieves up to limit of a repo's archived sessions,
+// newest first, skipping the first offset
+func (db *DB) ListArchivedSessions(repoName string, limit, offset int) ([]*Session, error) {
+	return db.listSessions(`
+		SELECT id, name, repo_path, repo_name, worktree_path, branch_name,
+		       created_at, last_accessed, archived_at, status, COALESCE(base_branch, '')
+		FROM sessions
+		WHERE repo_name = ? AND status = 'archived'
+		ORDER BY created_at DESC, id
+		LIMIT ? OFFSET ?
+	`, repoName, limit, offset)
+}
+
+// CountArchivedSessions returns how many archived sessions a repo has
+func (db *DB) CountArchivedSessions(repoName string) (int, error) {
+	var count int
+	err := db.queryRow(`SELECT COUNT(*) FROM sessions WHERE repo_name = ? AND status = 'archived'`,
+		[]any{repoName}, &count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count archived sessions: %w", err)
+	}
+	return count, nil
+}
+
+// listSessions runs a query selecting whole session rows
+func (db *DB) listSessions(query string, args ...any) ([]*Session, error) {
+	rows, err := db.query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list sessions: %w", err)
 	}
@@ -122,7 +187,7 @@ func (db *DB) ListSessions(repoFilter string, query string) ([]*Session, error)
 		var s Session
 		err := rows.Scan(
 			&s.ID, &s.Name, &s.RepoPath, &s.RepoName, &s.WorktreePath, &s.BranchName,
-			&s.CreatedAt, &s.LastAccessed, &s.ArchivedAt, &s.Status,
+			&s.CreatedAt, &s.LastAccessed, &s.ArchivedAt, &s.Status, &s.BaseBranch,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan session: %w", err)
@@ -142,13 +207,14 @@ func (db *DB) UpdateSession(s *Session) error {
 	query := `
 		UPDATE sessions
 		SET name = ?, repo_path = ?, repo_name = ?, worktree_path = ?,
-		    branch_name = ?, last_accessed = ?, archived_at = ?, status = ?
+		    branch_name = ?, last_accessed = ?, archived_at = ?, status = ?,
+		    base_branch = ?
 		WHERE id = ?
 	`
 
-	_, err := db.conn.Exec(query,
+	_, err := db.exec(query,
 		s.Name, s.RepoPath, s.RepoName, s.WorktreePath, s.BranchName,
-		s.LastAccessed, s.ArchivedAt, s.Status, s.ID,
+		s.LastAccessed, s.ArchivedAt, s.Status, s.BaseBranch, s.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update session: %w", err)
@@ -165,7 +231,7 @@ func (db *DB) ArchiveSession(id string) error {
 		WHERE id = ?
 	`
 
-	_, err := db.conn.Exec(query, now, id)
+	_, err := db.exec(query, now, id)
 	if err != nil {
 		return fmt.Errorf("failed to archive session: %w", err)
 	}
@@ -180,7 +246,7 @@ func (db *DB) UnarchiveSession(id string) error {
 		WHERE id = ?
 	`
 
-	_, err := db.conn.Exec(query, id)
+	_, err := db.exec(query, id)
 	if err != nil {
 		return fmt.Errorf("failed to unarchive session: %w", err)
 	}
@@ -191,18 +257,19 @@ func (db *DB) UnarchiveSession(id string) error {
 type Project struct {
 	RepoName string
 	RepoPath string
+	Pinned   bool
 }
 
-// ListProjects returns all unique projects ordered by most recently used
+// ListProjects returns all known projects, pinned ones first, then ordered
+// by most recently opened
 func (db *DB) ListProjects() ([]*Project, error) {
 	query := `
-		SELECT repo_name, repo_path
-		FROM sessions
-		GROUP BY repo_name, repo_path
-		ORDER BY MAX(COALESCE(last_accessed, created_at)) DESC
+		SELECT repo_name, repo_path, pinned
+		FROM projects
+		ORDER BY pinned DESC, COALESCE(last_opened, added_at) DESC
 	`
 
-	rows, err := db.conn.Query(query)
+	rows, err := db.query(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list projects: %w", err)
 	}
@@ -211,7 +278,7 @@ func (db *DB) ListProjects() ([]*Project, error) {
 	var projects []*Project
 	for rows.Next() {
 		var p Project
-		if err := rows.Scan(&p.RepoName, &p.RepoPath); err != nil {
+		if err := rows.Scan(&p.RepoName, &p.RepoPath, &p.Pinned); err != nil {
 			return nil, fmt.Errorf("failed to scan project: %w", err)
 		}
 		projects = append(projects, &p)
@@ -227,7 +294,7 @@ func (db *DB) ListProjects() ([]*Project, error) {
 func (db *DB) DeleteSession(id string) error {
 	query := `DELETE FROM sessions WHERE id = ?`
 
-	_, err := db.conn.Exec(query, id)
+	_, err := db.exec(query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/kevinzwang/air-traffic-control/internal/logging"
+)
+
+const (
+	// busyTimeout is how long SQLite waits for another connection's lock
+	busyTimeout = 5 * time.Second
+
+	// busyRetries is how many more times an operation is attempted when it
+	// still fails with SQLITE_BUSY/SQLITE_LOCKED, which SQLite reports without
+	// waiting in some cases (e.g. a read transaction upgrading to a write)
+	busyRetries = 5
+	busyBackoff = 50 * time.Millisecond
+)
+
+// isBusy reports whether err means the database was locked by another
+// connection
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
+
+// retry runs fn, retrying with backoff while the database is busy
+func retry(fn func() error) error {
+	backoff := busyBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !isBusy(err) || attempt == busyRetries {
+			return err
+		}
+		slog.Debug("db busy, retrying", "attempt", attempt+1, "backoff", backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// exec runs a statement, retrying while the database is busy
+func (db *DB) exec(query string, args ...any) (sql.Result, error) {
+	var result sql.Result
+	start := time.Now()
+	err := retry(func() error {
+		var err error
+		result, err = db.conn.Exec(query, args...)
+		return err
+	})
+	logging.Query(query, start, err)
+	return result, err
+}
+
+// query runs a query, retrying while the database is busy
+func (db *DB) query(query string, args ...any) (*sql.Rows, error) {
+	var rows *sql.Rows
+	start := time.Now()
+	err := retry(func() error {
+		var err error
+		rows, err = db.conn.Query(query, args...)
+		return err
+	})
+	logging.Query(query, start, err)
+	return rows, err
+}
+
+// queryRow runs a single-row query and scans it into dest, retrying while
+// the database is busy. Like sql.Row.Scan it returns sql.ErrNoRows when
+// there is no result.
+func (db *DB) queryRow(query string, args []any, dest ...any) error {
+	start := time.Now()
+	err := retry(func() error {
+		return db.conn.QueryRow(query, args...).Scan(dest...)
+	})
+	logging.Query(query, start, err)
+	return err
+}
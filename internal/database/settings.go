@@ -0,0 +1,32 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// GetSetting returns a persisted per-user UI setting, or "" if it is unset
+func (db *DB) GetSetting(key string) (string, error) {
+	var value string
+	err := db.queryRow(`SELECT value FROM settings WHERE key = ?`, []any{key}, &value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get setting %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// SetSetting persists a per-user UI setting, replacing any previous value
+func (db *DB) SetSetting(key, value string) error {
+	query := `
+		INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
+	`
+	if _, err := db.exec(query, key, value); err != nil {
+		return fmt.Errorf("failed to save setting %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// sessionColumns are the columns a session keeps while it is in the trash
+const sessionColumns = `id, name, repo_path, repo_name, worktree_path, branch_name,
+	created_at, last_accessed, archived_at, status, base_branch`
+
+// TrashedSession is a deleted session whose worktree was moved to the trash
+type TrashedSession struct {
+	Session
+	TrashPath string // where the worktree is until it is restored or purged
+	TrashedAt time.Time
+}
+
+// TrashSession moves a session's record to the trash, with where its
+// worktree was moved to
+func (db *DB) TrashSession(id, trashPath string) error {
+	err := retry(func() error {
+		tx, err := db.conn.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		query := `INSERT INTO trash (` + sessionColumns + `, trash_path, trashed_at)
+			SELECT ` + sessionColumns + `, ?, ? FROM sessions WHERE id = ?`
+		result, err := tx.Exec(query, trashPath, time.Now(), id)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return fmt.Errorf("session not found")
+		}
+		if _, err := tx.Exec(`DELETE FROM sessions WHERE id = ?`, id); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to move session to the trash: %w", err)
+	}
+	return nil
+}
+
+// RestoreSession moves a trashed session's record back to the sessions
+func (db *DB) RestoreSession(id string) error {
+	err := retry(func() error {
+		tx, err := db.conn.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		query := `INSERT INTO sessions (` + sessionColumns + `)
+			SELECT ` + sessionColumns + ` FROM trash WHERE id = ?`
+		result, err := tx.Exec(query, id)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return fmt.Errorf("session not found in the trash")
+		}
+		if _, err := tx.Exec(`DELETE FROM trash WHERE id = ?`, id); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore session: %w", err)
+	}
+	return nil
+}
+
+// ListTrash returns the trashed sessions of a repository, or of all of them
+// if repoPath is empty, most recently deleted first
+func (db *DB) ListTrash(repoPath string) ([]*TrashedSession, error) {
+	query := `
+		SELECT id, name, repo_path, repo_name, worktree_path, branch_name,
+		       created_at, last_accessed, archived_at, status, COALESCE(base_branch, ''),
+		       trash_path, trashed_at
+		FROM trash
+		WHERE ? = '' OR repo_path = ?
+		ORDER BY trashed_at DESC
+	`
+	rows, err := db.query(query, repoPath, repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+	defer rows.Close()
+
+	var trashed []*TrashedSession
+	for rows.Next() {
+		var t TrashedSession
+		err := rows.Scan(
+			&t.ID, &t.Name, &t.RepoPath, &t.RepoName, &t.WorktreePath, &t.BranchName,
+			&t.CreatedAt, &t.LastAccessed, &t.ArchivedAt, &t.Status, &t.BaseBranch,
+			&t.TrashPath, &t.TrashedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trashed session: %w", err)
+		}
+		trashed = append(trashed, &t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trash: %w", err)
+	}
+	return trashed, nil
+}
+
+// DeleteTrashed removes a trashed session's record for good
+func (db *DB) DeleteTrashed(id string) error {
+	if _, err := db.exec(`DELETE FROM trash WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete trashed session: %w", err)
+	}
+	return nil
+}
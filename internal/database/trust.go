@@ -0,0 +1,35 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TrustedCommands returns the fingerprint of the setup/teardown commands the
+// user last trusted for a repository, or "" if the repository isn't trusted
+func (db *DB) TrustedCommands(repoPath string) (string, error) {
+	var fingerprint string
+	err := db.queryRow(`SELECT fingerprint FROM trusted_repos WHERE repo_path = ?`, []any{repoPath}, &fingerprint)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check trust for %s: %w", repoPath, err)
+	}
+	return fingerprint, nil
+}
+
+// TrustCommands records that the user trusts a repository's setup/teardown
+// commands with the given fingerprint, replacing any earlier decision
+func (db *DB) TrustCommands(repoPath, fingerprint string) error {
+	query := `
+		INSERT INTO trusted_repos (repo_path, fingerprint, trusted_at) VALUES (?, ?, ?)
+		ON CONFLICT(repo_path) DO UPDATE SET fingerprint = excluded.fingerprint, trusted_at = excluded.trusted_at
+	`
+	if _, err := db.exec(query, repoPath, fingerprint, time.Now()); err != nil {
+		return fmt.Errorf("failed to trust %s: %w", repoPath, err)
+	}
+	return nil
+}
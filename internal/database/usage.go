@@ -0,0 +1,84 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// SessionUsage is the token usage rollup of a session's conversations
+type SessionUsage struct {
+	SessionID        string
+	SessionName      string // filled in by ListUsage
+	RepoName         string // filled in by ListUsage
+	Status           string // filled in by ListUsage
+	InputTokens      int64
+	OutputTokens     int64
+	CacheWriteTokens int64
+	CacheReadTokens  int64
+	CostUSD          float64
+	UpdatedAt        time.Time
+}
+
+// SaveUsage stores a session's usage rollup, replacing the previous one
+func (db *DB) SaveUsage(u *SessionUsage) error {
+	query := `
+		INSERT INTO session_usage (
+			session_id, input_tokens, output_tokens, cache_write_tokens,
+			cache_read_tokens, cost_usd, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(session_id) DO UPDATE SET
+			input_tokens = excluded.input_tokens,
+			output_tokens = excluded.output_tokens,
+			cache_write_tokens = excluded.cache_write_tokens,
+			cache_read_tokens = excluded.cache_read_tokens,
+			cost_usd = excluded.cost_usd,
+			updated_at = excluded.updated_at
+	`
+	_, err := db.exec(query,
+		u.SessionID, u.InputTokens, u.OutputTokens, u.CacheWriteTokens,
+		u.CacheReadTokens, u.CostUSD, u.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save usage: %w", err)
+	}
+	return nil
+}
+
+// ListUsage returns the usage rollups of all sessions in all repositories,
+// most expensive first
+func (db *DB) ListUsage() ([]*SessionUsage, error) {
+	query := `
+		SELECT u.session_id, s.name, s.repo_name, s.status,
+			u.input_tokens, u.output_tokens, u.cache_write_tokens,
+			u.cache_read_tokens, u.cost_usd, u.updated_at
+		FROM session_usage u
+		JOIN sessions s ON s.id = u.session_id
+		ORDER BY u.cost_usd DESC
+	`
+	rows, err := db.query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []*SessionUsage
+	for rows.Next() {
+		u := &SessionUsage{}
+		err := rows.Scan(&u.SessionID, &u.SessionName, &u.RepoName, &u.Status,
+			&u.InputTokens, &u.OutputTokens, &u.CacheWriteTokens,
+			&u.CacheReadTokens, &u.CostUSD, &u.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+// DeleteUsage removes a session's usage rollup
+func (db *DB) DeleteUsage(sessionID string) error {
+	if _, err := db.exec(`DELETE FROM session_usage WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("failed to delete usage: %w", err)
+	}
+	return nil
+}
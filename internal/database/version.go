@@ -0,0 +1,14 @@
+package database
+
+import "fmt"
+
+// SessionsVersion returns a counter that goes up whenever a session is
+// created, deleted, renamed, archived or unarchived, by any ATC instance.
+// Last-accessed times don't count.
+func (db *DB) SessionsVersion() (int64, error) {
+	var version int64
+	if err := db.queryRow(`SELECT version FROM sessions_version WHERE id = 1`, nil, &version); err != nil {
+		return 0, fmt.Errorf("failed to get sessions version: %w", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// AddWorkTime adds d to how long a session's agent has spent working
+func (db *DB) AddWorkTime(sessionID string, d time.Duration) error {
+	query := `
+		INSERT INTO session_work_time (session_id, seconds) VALUES (?, ?)
+		ON CONFLICT(session_id) DO UPDATE SET seconds = seconds + excluded.seconds
+	`
+	if _, err := db.exec(query, sessionID, d.Seconds()); err != nil {
+		return fmt.Errorf("failed to add work time: %w", err)
+	}
+	return nil
+}
+
+// WorkTimes returns how long each session's agent has spent working, by
+// session ID, for the sessions that have worked at all
+func (db *DB) WorkTimes() (map[string]time.Duration, error) {
+	rows, err := db.query(`SELECT session_id, seconds FROM session_work_time`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list work times: %w", err)
+	}
+	defer rows.Close()
+
+	times := make(map[string]time.Duration)
+	for rows.Next() {
+		var id string
+		var seconds float64
+		if err := rows.Scan(&id, &seconds); err != nil {
+			return nil, fmt.Errorf("failed to scan work time: %w", err)
+		}
+		times[id] = time.Duration(seconds * float64(time.Second))
+	}
+	return times, rows.Err()
+}
+
+// DeleteWorkTime removes a session's working time
+func (db *DB) DeleteWorkTime(sessionID string) error {
+	if _, err := db.exec(`DELETE FROM session_work_time WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("failed to delete work time: %w", err)
+	}
+	return nil
+}
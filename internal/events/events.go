@@ -0,0 +1,237 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxLogSize is the size at which the event log is rotated to <path>.1
+const maxLogSize = 5 * 1024 * 1024
+
+// Event kinds
+const (
+	KindCreated       = "created"
+	KindSetupComplete = "setup_complete"
+	KindSetupFailed   = "setup_failed"
+	KindWorking       = "working"
+	KindWaiting       = "waiting"
+	KindExited        = "exited"
+	KindArchived      = "archived"
+	KindLanded        = "landed"
+	KindUnarchived    = "unarchived"
+	KindDeleted       = "deleted"
+	KindRestored      = "restored"
+	KindAttached      = "attached"
+	KindRespawned     = "respawned"
+	KindLimited       = "limited"
+	KindDetached      = "detached"
+	KindAdopted       = "adopted"
+)
+
+// Event is a single session status change
+type Event struct {
+	Time    time.Time `json:"time"`
+	Repo    string    `json:"repo"`
+	Session string    `json:"session"`
+	Kind    string    `json:"kind"`
+	Detail  string    `json:"detail,omitempty"`
+}
+
+// String renders the event as a plain, screen-reader friendly line
+func (e Event) String() string {
+	line := fmt.Sprintf("%s %s: session %s %s", e.Time.Format("15:04:05"), e.Repo, e.Session, describe(e.Kind))
+	if e.Detail != "" {
+		line += " (" + e.Detail + ")"
+	}
+	return line
+}
+
+// describe returns the human-readable phrase for an event kind
+func describe(kind string) string {
+	switch kind {
+	case KindCreated:
+		return "was created"
+	case KindSetupComplete:
+		return "finished setup"
+	case KindSetupFailed:
+		return "setup failed"
+	case KindWorking:
+		return "is working"
+	case KindWaiting:
+		return "is waiting for input"
+	case KindExited:
+		return "agent exited"
+	case KindArchived:
+		return "was archived"
+	case KindLanded:
+		return "was landed and archived"
+	case KindUnarchived:
+		return "was unarchived"
+	case KindDeleted:
+		return "was deleted"
+	case KindRestored:
+		return "was restored from the trash"
+	case KindAttached:
+		return "was attached"
+	case KindRespawned:
+		return "agent was restarted"
+	case KindLimited:
+		return "agent hit a usage limit"
+	case KindDetached:
+		return "was detached from ATC"
+	case KindAdopted:
+		return "was adopted into ATC"
+	default:
+		return kind
+	}
+}
+
+// Log appends events to a JSON-lines file shared by all ATC instances
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewLog returns a Log writing to path, creating the parent directory if needed
+func NewLog(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create event log directory: %w", err)
+	}
+	return &Log{path: path}, nil
+}
+
+// Path returns the log file path
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append writes an event to the log. A nil Log discards events.
+func (l *Log) Append(e Event) error {
+	if l == nil {
+		return nil
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if info, err := os.Stat(l.path); err == nil && info.Size() > maxLogSize {
+		os.Rename(l.path, l.path+".1")
+	}
+
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	return nil
+}
+
+// Tail returns the last n events in the log
+func Tail(path string, n int) ([]Event, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer f.Close()
+
+	var all []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		all = append(all, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read event log: %w", err)
+	}
+
+	if len(all) > n {
+		all = all[len(all)-n:]
+	}
+	return all, nil
+}
+
+// Follow streams events appended to the log after the call, writing each as a
+// line to w, until stop is closed. It tolerates the file not existing yet and
+// rotation (the file shrinking or being replaced).
+func Follow(path string, w io.Writer, stop <-chan struct{}) error {
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	var partial []byte
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.Size() < offset {
+			// Rotated or truncated: start over from the beginning
+			offset = 0
+			partial = nil
+		}
+		if info.Size() == offset {
+			continue
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		f.Seek(offset, io.SeekStart)
+		data, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			continue
+		}
+		offset += int64(len(data))
+
+		data = append(partial, data...)
+		partial = nil
+		for len(data) > 0 {
+			idx := bytes.IndexByte(data, '\n')
+			if idx < 0 {
+				partial = data
+				break
+			}
+			var e Event
+			if err := json.Unmarshal(data[:idx], &e); err == nil {
+				fmt.Fprintln(w, e.String())
+			}
+			data = data[idx+1:]
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventString(t *testing.T) {
+	e := Event{
+		Time:    time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+		Repo:    "atc",
+		Session: "fix-login",
+		Kind:    KindWaiting,
+	}
+	want := "15:04:05 atc: session fix-login is waiting for input"
+	if got := e.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	e.Kind = KindSetupFailed
+	e.Detail = "exit status 1"
+	want = "15:04:05 atc: session fix-login setup failed (exit status 1)"
+	if got := e.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestAppendTailFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	log, err := NewLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"a", "b", "c"} {
+		if err := log.Append(Event{Repo: "r", Session: name, Kind: KindCreated}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	past, err := Tail(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(past) != 2 || past[0].Session != "b" || past[1].Session != "c" {
+		t.Fatalf("Tail = %+v, want sessions b, c", past)
+	}
+
+	var out bytes.Buffer
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		Follow(path, &out, stop)
+		close(done)
+	}()
+
+	time.Sleep(300 * time.Millisecond)
+	log.Append(Event{Repo: "r", Session: "d", Kind: KindExited})
+	time.Sleep(600 * time.Millisecond)
+	close(stop)
+	<-done
+
+	got := out.String()
+	if strings.Contains(got, "session c") {
+		t.Errorf("Follow replayed old events: %q", got)
+	}
+	if !strings.Contains(got, "session d agent exited") {
+		t.Errorf("Follow output = %q, want new event", got)
+	}
+}
@@ -0,0 +1,159 @@
+// Package github reads a repository's pull requests with the GitHub CLI (gh)
+// and fetches their commits into the local repository.
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+
+	"github.com/kevinzwang/air-traffic-control/internal/logging"
+)
+
+// PullRequest is an open pull request as listed by gh
+type PullRequest struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	HeadRefName string `json:"headRefName"`
+	BaseRefName string `json:"baseRefName"`
+	IsDraft     bool   `json:"isDraft"`
+	URL         string `json:"url"`
+	Author      struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// ListPullRequests returns the repository's open pull requests, newest first
+func ListPullRequests(repoPath string) ([]PullRequest, error) {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return nil, fmt.Errorf("listing pull requests needs the GitHub CLI (gh)")
+	}
+	cmd := exec.Command("gh", "pr", "list", "--state", "open", "--limit", "100",
+		"--json", "number,title,headRefName,baseRefName,isDraft,url,author")
+	cmd.Dir = repoPath
+	output, err := logging.Output(cmd)
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("gh pr list failed: %w\nOutput: %s", err, string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("gh pr list failed: %w", err)
+	}
+
+	var prs []PullRequest
+	if err := json.Unmarshal(output, &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse pull requests: %w", err)
+	}
+	return prs, nil
+}
+
+// FetchPullRequest fetches a pull request's head commit from origin, which
+// works for pull requests from forks too, and returns the remote-tracking
+// ref it was stored as (origin/pr/<number>)
+func FetchPullRequest(repoPath string, number int) (string, error) {
+	n := strconv.Itoa(number)
+	cmd := exec.Command("git", "fetch", "origin", "+refs/pull/"+n+"/head:refs/remotes/origin/pr/"+n)
+	cmd.Dir = repoPath
+	output, err := logging.CombinedOutput(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch pull request #%d: %w\nOutput: %s", number, err, string(output))
+	}
+	return "origin/pr/" + n, nil
+}
+
+// States of a pull request's checks, summarised
+const (
+	ChecksNone    = ""
+	ChecksPending = "pending"
+	ChecksPassing = "passing"
+	ChecksFailing = "failing"
+)
+
+// PullRequestStatus is where a branch's pull request stands
+type PullRequestStatus struct {
+	Number  int
+	State   string // OPEN, MERGED or CLOSED
+	IsDraft bool
+	Checks  string // one of the Checks constants
+}
+
+// checkResult is a check run or commit status in gh's statusCheckRollup. Check
+// runs have a status and a conclusion once completed; commit statuses only a
+// state
+type checkResult struct {
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	State      string `json:"state"`
+}
+
+// PullRequestStatuses returns the status of the latest pull request of each
+// branch with one, open or not, keyed by head branch. A pull request's number
+// also keys it as pr-<number>, the branch of a review session
+func PullRequestStatuses(repoPath string) (map[string]PullRequestStatus, error) {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return nil, fmt.Errorf("pull request status needs the GitHub CLI (gh)")
+	}
+	cmd := exec.Command("gh", "pr", "list", "--state", "all", "--limit", "100",
+		"--json", "number,headRefName,state,isDraft,statusCheckRollup")
+	cmd.Dir = repoPath
+	output, err := logging.Output(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("gh pr list failed: %w", err)
+	}
+
+	var prs []struct {
+		Number            int           `json:"number"`
+		HeadRefName       string        `json:"headRefName"`
+		State             string        `json:"state"`
+		IsDraft           bool          `json:"isDraft"`
+		StatusCheckRollup []checkResult `json:"statusCheckRollup"`
+	}
+	if err := json.Unmarshal(output, &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse pull requests: %w", err)
+	}
+
+	// gh lists the newest first, which wins for a reused branch name
+	statuses := make(map[string]PullRequestStatus)
+	for _, pr := range prs {
+		status := PullRequestStatus{
+			Number:  pr.Number,
+			State:   pr.State,
+			IsDraft: pr.IsDraft,
+			Checks:  summarizeChecks(pr.StatusCheckRollup),
+		}
+		if _, ok := statuses[pr.HeadRefName]; !ok {
+			statuses[pr.HeadRefName] = status
+		}
+		statuses["pr-"+strconv.Itoa(pr.Number)] = status
+	}
+	return statuses, nil
+}
+
+// summarizeChecks reduces a pull request's checks to one state: failing if
+// any failed, else pending if any haven't finished, else passing
+func summarizeChecks(checks []checkResult) string {
+	if len(checks) == 0 {
+		return ChecksNone
+	}
+	pending := false
+	for _, c := range checks {
+		result := c.State
+		if c.Status != "" {
+			if c.Status != "COMPLETED" {
+				pending = true
+				continue
+			}
+			result = c.Conclusion
+		}
+		switch result {
+		case "FAILURE", "ERROR", "TIMED_OUT", "CANCELLED", "ACTION_REQUIRED", "STARTUP_FAILURE":
+			return ChecksFailing
+		case "PENDING", "EXPECTED", "":
+			pending = true
+		}
+	}
+	if pending {
+		return ChecksPending
+	}
+	return ChecksPassing
+}
@@ -0,0 +1,35 @@
+package github
+
+import "testing"
+
+func TestSummarizeChecks(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []checkResult
+		want   string
+	}{
+		{"no checks", nil, ChecksNone},
+		{"all passed", []checkResult{
+			{Status: "COMPLETED", Conclusion: "SUCCESS"},
+			{Status: "COMPLETED", Conclusion: "SKIPPED"},
+			{State: "SUCCESS"},
+		}, ChecksPassing},
+		{"one running", []checkResult{
+			{Status: "COMPLETED", Conclusion: "SUCCESS"},
+			{Status: "IN_PROGRESS"},
+		}, ChecksPending},
+		{"pending status", []checkResult{{State: "PENDING"}}, ChecksPending},
+		{"failure beats pending", []checkResult{
+			{Status: "QUEUED"},
+			{Status: "COMPLETED", Conclusion: "FAILURE"},
+		}, ChecksFailing},
+		{"status error", []checkResult{{State: "ERROR"}}, ChecksFailing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeChecks(tt.checks); got != tt.want {
+				t.Errorf("summarizeChecks = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
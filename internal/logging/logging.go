@@ -0,0 +1,125 @@
+// Package logging writes ATC's debug log: the git and tmux commands it runs
+// and its database queries, with how long each took and how it failed, to
+// ~/.atc/logs/atc.log. It is only written with --debug or ATC_DEBUG set.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EnvVar turns debug logging on when set to anything but "" or "0"
+const EnvVar = "ATC_DEBUG"
+
+// maxLogSize is the size past which the log is moved to <path>.1 when ATC
+// starts
+const maxLogSize = 10 * 1024 * 1024
+
+// Path returns the debug log's path under the ATC state directory
+func Path(atcDir string) string {
+	return filepath.Join(atcDir, "logs", "atc.log")
+}
+
+// FromEnv reports whether ATC_DEBUG asks for debug logging
+func FromEnv() bool {
+	v := os.Getenv(EnvVar)
+	return v != "" && v != "0"
+}
+
+// Setup points the default slog logger at the debug log. The returned
+// closer closes the log file.
+func Setup(atcDir string) (io.Closer, error) {
+	path := Path(atcDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > maxLogSize {
+		os.Rename(path, path+".1")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open debug log: %w", err)
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	slog.Info("atc started", "pid", os.Getpid(), "args", os.Args[1:])
+	return f, nil
+}
+
+// Enabled reports whether debug records are written anywhere
+func Enabled() bool {
+	return slog.Default().Enabled(context.Background(), slog.LevelDebug)
+}
+
+// Run runs cmd like cmd.Run, logging it
+func Run(cmd *exec.Cmd) error {
+	if !Enabled() {
+		return cmd.Run()
+	}
+	start := time.Now()
+	err := cmd.Run()
+	logCommand(cmd, start, err)
+	return err
+}
+
+// Output runs cmd like cmd.Output, logging it
+func Output(cmd *exec.Cmd) ([]byte, error) {
+	if !Enabled() {
+		return cmd.Output()
+	}
+	start := time.Now()
+	out, err := cmd.Output()
+	logCommand(cmd, start, err)
+	return out, err
+}
+
+// CombinedOutput runs cmd like cmd.CombinedOutput, logging it
+func CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	if !Enabled() {
+		return cmd.CombinedOutput()
+	}
+	start := time.Now()
+	out, err := cmd.CombinedOutput()
+	logCommand(cmd, start, err)
+	return out, err
+}
+
+// Start starts cmd like cmd.Start, logging it
+func Start(cmd *exec.Cmd) error {
+	err := cmd.Start()
+	if Enabled() {
+		attrs := []any{"cmd", strings.Join(cmd.Args, " "), "dir", cmd.Dir}
+		if err != nil {
+			attrs = append(attrs, "err", err)
+		}
+		slog.Debug("start", attrs...)
+	}
+	return err
+}
+
+// logCommand records a command that finished
+func logCommand(cmd *exec.Cmd, start time.Time, err error) {
+	attrs := []any{"cmd", strings.Join(cmd.Args, " "), "dir", cmd.Dir, "took", time.Since(start)}
+	if err != nil {
+		attrs = append(attrs, "err", err)
+	}
+	slog.Debug("exec", attrs...)
+}
+
+// Query records a database statement
+func Query(query string, start time.Time, err error) {
+	if !Enabled() {
+		return
+	}
+	attrs := []any{"query", strings.Join(strings.Fields(query), " "), "took", time.Since(start)}
+	if err != nil {
+		attrs = append(attrs, "err", err)
+	}
+	slog.Debug("db", attrs...)
+}
@@ -0,0 +1,166 @@
+// Package plugin runs the user's plugins: executables in ~/.atc/plugins that
+// are told about what happens to sessions, as JSON on stdin, and can ask ATC
+// to act on it by printing actions, one JSON object per line.
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Kinds of events
+const (
+	// StateChanged means an agent started working, went back to waiting
+	// for input or exited
+	StateChanged = "state_changed"
+	// AgentIdle means an agent finished working and is waiting for input
+	AgentIdle = "agent_idle"
+	// OutputMatched means a line with one of the configured keywords
+	// appeared on an agent's screen
+	OutputMatched = "output_matched"
+)
+
+// Event is what a plugin reads on stdin
+type Event struct {
+	Event    string    `json:"event"`
+	Time     time.Time `json:"time"`
+	Repo     string    `json:"repo"` // repository path
+	Session  string    `json:"session"`
+	Branch   string    `json:"branch,omitempty"`
+	Worktree string    `json:"worktree,omitempty"`
+	State    string    `json:"state,omitempty"`   // working, waiting or exited
+	Keyword  string    `json:"keyword,omitempty"` // output_matched
+	Line     string    `json:"line,omitempty"`    // output_matched
+}
+
+// Kinds of actions
+const (
+	// Notify shows Title and Body in the status bar and notifies the user
+	// the way the notification settings say
+	Notify = "notify"
+	// Enqueue adds Prompt to the session's prompt queue
+	Enqueue = "enqueue"
+	// Tag labels the session with Tag in the sidebar; Untag removes it
+	Tag   = "tag"
+	Untag = "untag"
+)
+
+// Action is something a plugin asks ATC to do
+type Action struct {
+	Action  string `json:"action"`
+	Session string `json:"session,omitempty"` // the event's session if empty
+	Title   string `json:"title,omitempty"`
+	Body    string `json:"body,omitempty"`
+	Prompt  string `json:"prompt,omitempty"`
+	Tag     string `json:"tag,omitempty"`
+
+	// Plugin is the name of the plugin that asked
+	Plugin string `json:"-"`
+}
+
+// List returns the paths of the executables in dir, in name order. Hidden
+// files are skipped, and a missing dir has none.
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var plugins []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		// Windows has no executable bit
+		if runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		plugins = append(plugins, filepath.Join(dir, e.Name()))
+	}
+	return plugins, nil
+}
+
+// Run runs the plugin at path with ev on its stdin and returns the actions
+// it printed. A plugin still running after timeout is killed.
+func Run(path string, ev Event, timeout time.Duration) ([]Action, error) {
+	input, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	name := filepath.Base(path)
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(), "ATC_EVENT="+ev.Event)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	// Don't wait on children of a killed plugin that still hold its output
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("plugin %s timed out after %v", name, timeout)
+		}
+		return nil, fmt.Errorf("plugin %s failed: %w\nOutput: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var actions []Action
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var a Action
+		if err := json.Unmarshal([]byte(line), &a); err != nil {
+			return actions, fmt.Errorf("plugin %s printed something other than an action: %s", name, line)
+		}
+		switch a.Action {
+		case Notify, Enqueue, Tag, Untag:
+		default:
+			return actions, fmt.Errorf("plugin %s asked for unknown action %q", name, a.Action)
+		}
+		if a.Session == "" {
+			a.Session = ev.Session
+		}
+		a.Plugin = name
+		actions = append(actions, a)
+	}
+	return actions, scanner.Err()
+}
+
+// Dispatch runs every plugin in dir on ev, one after another, and returns
+// the actions they asked for and the errors of those that failed
+func Dispatch(dir string, ev Event, timeout time.Duration) ([]Action, []error) {
+	plugins, err := List(dir)
+	if err != nil {
+		return nil, []error{err}
+	}
+	var actions []Action
+	var errs []error
+	for _, path := range plugins {
+		a, err := Run(path, ev, timeout)
+		actions = append(actions, a...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return actions, errs
+}
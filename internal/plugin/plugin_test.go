@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDispatch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins here are shell scripts")
+	}
+	dir := t.TempDir()
+	write := func(name, script string, mode os.FileMode) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Echoes the event's session and state back in a notification
+	write("a-notify", `read ev
+state=$(echo "$ev" | sed 's/.*"state":"\([a-z]*\)".*/\1/')
+echo '{"action":"notify","title":"'"$ATC_EVENT"'","body":"'"$state"'"}'
+echo
+echo '{"action":"tag","session":"other","tag":"busy"}'
+`, 0755)
+	write("b-broken", "echo not json\n", 0755)
+	write("c-slow", "sleep 5\n", 0755)
+	write("d-not-executable", `echo '{"action":"notify"}'`+"\n", 0644)
+	write(".hidden", `echo '{"action":"notify"}'`+"\n", 0755)
+
+	plugins, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plugins) != 3 {
+		t.Fatalf("List found %d plugins, want 3: %v", len(plugins), plugins)
+	}
+
+	ev := Event{Event: StateChanged, Session: "s", State: "waiting"}
+	actions, errs := Dispatch(dir, ev, time.Second)
+	want := []Action{
+		{Action: Notify, Session: "s", Title: StateChanged, Body: "waiting", Plugin: "a-notify"},
+		{Action: Tag, Session: "other", Tag: "busy", Plugin: "a-notify"},
+	}
+	if len(actions) != len(want) {
+		t.Fatalf("actions = %+v, want %+v", actions, want)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Errorf("action %d = %+v, want %+v", i, actions[i], want[i])
+		}
+	}
+	if len(errs) != 2 {
+		t.Fatalf("errors = %v, want one from b-broken and one from c-slow", errs)
+	}
+	if !strings.Contains(errs[0].Error(), "b-broken") || !strings.Contains(errs[1].Error(), "timed out") {
+		t.Errorf("errors = %v", errs)
+	}
+
+	if plugins, err := List(filepath.Join(dir, "missing")); err != nil || plugins != nil {
+		t.Errorf("List of a missing directory = %v, %v", plugins, err)
+	}
+}
@@ -0,0 +1,110 @@
+// Package procstat measures the CPU time and memory used by process trees,
+// such as an agent and the tools it runs, using ps(1) so it works the same
+// on Linux and macOS.
+package procstat
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Usage is the resources used by a process and all of its descendants
+type Usage struct {
+	CPUTime time.Duration // CPU time used so far
+	RSS     int64         // resident memory in bytes
+}
+
+// proc is one line of ps output
+type proc struct {
+	ppid  int
+	usage Usage
+}
+
+// Trees returns the usage of each root's process tree, from a single ps run.
+// Roots that aren't running are left out.
+func Trees(roots []int) (map[int]Usage, error) {
+	out, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,time=,rss=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("ps failed: %w", err)
+	}
+	return treeUsage(parsePS(string(out)), roots), nil
+}
+
+// parsePS reads `ps -o pid=,ppid=,time=,rss=` output, skipping lines it
+// can't parse
+func parsePS(out string) map[int]proc {
+	procs := make(map[int]proc)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		cpu, err3 := parseCPUTime(fields[2])
+		rss, err4 := strconv.ParseInt(fields[3], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			continue
+		}
+		procs[pid] = proc{ppid: ppid, usage: Usage{CPUTime: cpu, RSS: rss * 1024}}
+	}
+	return procs
+}
+
+// treeUsage adds up the usage of each root and its descendants
+func treeUsage(procs map[int]proc, roots []int) map[int]Usage {
+	children := make(map[int][]int)
+	for pid, p := range procs {
+		children[p.ppid] = append(children[p.ppid], pid)
+	}
+	trees := make(map[int]Usage, len(roots))
+	for _, root := range roots {
+		if _, ok := procs[root]; !ok {
+			continue
+		}
+		var total Usage
+		stack := []int{root}
+		for len(stack) > 0 {
+			pid := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			total.CPUTime += procs[pid].usage.CPUTime
+			total.RSS += procs[pid].usage.RSS
+			stack = append(stack, children[pid]...)
+		}
+		trees[root] = total
+	}
+	return trees
+}
+
+// parseCPUTime reads ps's cumulative CPU time: [[dd-]hh:]mm:ss on Linux,
+// with fractional seconds on macOS
+func parseCPUTime(s string) (time.Duration, error) {
+	var days int
+	if d, rest, ok := strings.Cut(s, "-"); ok {
+		n, err := strconv.Atoi(d)
+		if err != nil {
+			return 0, fmt.Errorf("bad CPU time %q", s)
+		}
+		days, s = n, rest
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("bad CPU time %q", s)
+	}
+	seconds, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad CPU time %q", s)
+	}
+	total := time.Duration(days)*24*time.Hour + time.Duration(seconds*float64(time.Second))
+	for i, unit := range []time.Duration{time.Minute, time.Hour}[:len(parts)-1] {
+		n, err := strconv.Atoi(parts[len(parts)-2-i])
+		if err != nil {
+			return 0, fmt.Errorf("bad CPU time %q", s)
+		}
+		total += time.Duration(n) * unit
+	}
+	return total, nil
+}
@@ -0,0 +1,49 @@
+package procstat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCPUTime(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"00:00:07", 7 * time.Second},
+		{"01:02:03", time.Hour + 2*time.Minute + 3*time.Second},
+		{"2-00:00:01", 48*time.Hour + time.Second},
+		{"0:01.50", 1500 * time.Millisecond},
+		{"12:00.00", 12 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := parseCPUTime(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseCPUTime(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := parseCPUTime("soon"); err == nil {
+		t.Error("parseCPUTime(soon) should fail")
+	}
+}
+
+func TestTreeUsage(t *testing.T) {
+	// 10 is a pane shell running the agent (11), which runs a tool (12);
+	// 20 is another pane
+	ps := `
+   10     1 00:00:01   100
+   11    10 00:01:00  2000
+   12    11 00:00:30   500
+   20     1 00:00:05   300
+`
+	got := treeUsage(parsePS(ps), []int{10, 20, 99})
+	if want := (Usage{CPUTime: 91 * time.Second, RSS: 2600 * 1024}); got[10] != want {
+		t.Errorf("tree 10 = %+v, want %+v", got[10], want)
+	}
+	if want := (Usage{CPUTime: 5 * time.Second, RSS: 300 * 1024}); got[20] != want {
+		t.Errorf("tree 20 = %+v, want %+v", got[20], want)
+	}
+	if _, ok := got[99]; ok {
+		t.Error("a process that isn't running should be left out")
+	}
+}
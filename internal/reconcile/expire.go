@@ -0,0 +1,125 @@
+package reconcile
+
+import (
+	"os"
+	"time"
+
+	"github.com/kevinzwang/air-traffic-control/internal/config"
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// Expired is an archived session Expire deleted
+type Expired struct {
+	Name       string
+	RepoPath   string
+	ArchivedAt time.Time
+	Branch     string // the branch deleted with it, "" if it was kept
+}
+
+// ArchivedBefore returns every project's sessions archived before cutoff
+func ArchivedBefore(db *database.DB, cutoff time.Time) ([]*database.Session, error) {
+	sessions, err := db.ListSessions("", "")
+	if err != nil {
+		return nil, err
+	}
+	var old []*database.Session
+	for _, s := range sessions {
+		if s.Status == "archived" && archivedAt(s).Before(cutoff) {
+			old = append(old, s)
+		}
+	}
+	return old, nil
+}
+
+// archivedAt is when a session was archived, or created if that wasn't
+// recorded
+func archivedAt(s *database.Session) time.Time {
+	if s.ArchivedAt != nil {
+		return *s.ArchivedAt
+	}
+	return s.CreatedAt
+}
+
+// RemoveArchived deletes an archived session, its tmux session and its
+// worktree. Sessions whose repository or worktree is already gone only lose
+// their database record.
+func RemoveArchived(db *database.DB, cfg *config.GlobalConfig, s *database.Session) error {
+	socket := terminal.SocketName(s.RepoPath)
+	if name := terminal.TmuxName(s.Name); terminal.SessionExists(socket, name) {
+		terminal.KillSession(socket, name)
+	}
+
+	_, repoErr := os.Stat(s.RepoPath)
+	_, worktreeErr := os.Stat(s.WorktreePath)
+	if repoErr == nil && worktreeErr == nil {
+		svc, err := session.NewService(db, s.RepoPath, cfg)
+		if err != nil {
+			return err
+		}
+		return svc.DeleteSession(s.Name)
+	}
+
+	return Resolve(db, &Orphan{
+		Kind:         MissingWorktree,
+		RepoPath:     s.RepoPath,
+		SessionID:    s.ID,
+		SessionName:  s.Name,
+		WorktreePath: s.WorktreePath,
+	}, Clean)
+}
+
+// removeBranch deletes a removed session's branch, unless it is gone already
+// or another session of the project is on it. It reports whether the branch
+// was deleted.
+func removeBranch(db *database.DB, s *database.Session) (bool, error) {
+	if _, err := os.Stat(s.RepoPath); err != nil || !worktree.BranchExists(s.RepoPath, s.BranchName) {
+		return false, nil
+	}
+	if shared, err := db.GetSessionByBranchName(s.BranchName, s.RepoPath); err != nil || shared != nil {
+		return false, err
+	}
+	if err := worktree.DeleteBranch(s.RepoPath, s.BranchName); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Expire deletes the sessions archived more than cfg.ArchiveRetention.Days
+// ago, with their worktrees and, if configured, their branches. It carries on
+// past sessions it fails to delete and returns the first error with what it
+// deleted.
+func Expire(db *database.DB, cfg *config.GlobalConfig) ([]*Expired, error) {
+	retention := cfg.ArchiveRetention
+	if retention.Days <= 0 {
+		return nil, nil
+	}
+	old, err := ArchivedBefore(db, time.Now().AddDate(0, 0, -retention.Days))
+	if err != nil {
+		return nil, err
+	}
+	var expired []*Expired
+	var firstErr error
+	for _, s := range old {
+		if err := RemoveArchived(db, cfg, s); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		e := &Expired{Name: s.Name, RepoPath: s.RepoPath, ArchivedAt: archivedAt(s)}
+		if retention.DeleteBranches {
+			deleted, err := removeBranch(db, s)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			if deleted {
+				e.Branch = s.BranchName
+			}
+		}
+		expired = append(expired, e)
+	}
+	return expired, firstErr
+}
@@ -0,0 +1,316 @@
+// Package reconcile finds state that has drifted apart between the session
+// database, the worktrees on disk, and the tmux servers on ATC sockets, and
+// repairs it.
+package reconcile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// Kind identifies what is out of sync
+type Kind int
+
+const (
+	// UntrackedWorktree is a worktree under the worktree root with no session
+	UntrackedWorktree Kind = iota
+	// MissingWorktree is a session whose worktree no longer exists
+	MissingWorktree
+	// StrayTmuxSession is a tmux session on a project's socket with no session
+	StrayTmuxSession
+	// StrayTmuxServer is a tmux server (or stale socket) for no known project
+	StrayTmuxServer
+)
+
+// Action is a way of resolving an orphan
+type Action string
+
+const (
+	// Adopt creates a session record for an untracked worktree
+	Adopt Action = "adopt"
+	// Repair recreates a missing worktree from the session's branch
+	Repair Action = "repair"
+	// Clean removes the orphan: the worktree, session record, or tmux session
+	Clean Action = "clean"
+)
+
+// Orphan is one piece of state that is out of sync
+type Orphan struct {
+	Kind         Kind
+	RepoPath     string
+	SessionID    string
+	SessionName  string
+	WorktreePath string
+	BranchName   string
+	Socket       string
+}
+
+// String describes the orphan in one line
+func (o *Orphan) String() string {
+	switch o.Kind {
+	case UntrackedWorktree:
+		return fmt.Sprintf("worktree %s has no session", o.WorktreePath)
+	case MissingWorktree:
+		return fmt.Sprintf("session %s (%s) is missing its worktree %s", o.SessionName, filepath.Base(o.RepoPath), o.WorktreePath)
+	case StrayTmuxSession:
+		return fmt.Sprintf("tmux session %s on %s (%s) has no session", o.SessionName, o.Socket, filepath.Base(o.RepoPath))
+	case StrayTmuxServer:
+		return fmt.Sprintf("tmux socket %s belongs to no project", o.Socket)
+	}
+	return "unknown orphan"
+}
+
+// Actions returns the ways the orphan can be resolved
+func (o *Orphan) Actions() []Action {
+	switch o.Kind {
+	case UntrackedWorktree:
+		return []Action{Adopt, Clean}
+	case MissingWorktree:
+		return []Action{Repair, Clean}
+	default:
+		return []Action{Clean}
+	}
+}
+
+// Scan compares the database, the worktrees under worktreeRoot, and the tmux
+// servers on ATC sockets, and returns everything that doesn't line up.
+// Detached sessions' worktrees and tmux sessions are the user's, and left out.
+func Scan(db *database.DB, worktreeRoot string) ([]*Orphan, error) {
+	sessions, err := db.ListSessions("", "")
+	if err != nil {
+		return nil, err
+	}
+	detached, err := db.ListDetached("")
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []*Orphan
+
+	// Sessions whose worktree is gone
+	tracked := make(map[string]bool, len(sessions)+len(detached))
+	for _, d := range detached {
+		tracked[d.WorktreePath] = true
+	}
+	for _, s := range sessions {
+		tracked[s.WorktreePath] = true
+		if _, err := os.Stat(s.WorktreePath); os.IsNotExist(err) {
+			orphans = append(orphans, &Orphan{
+				Kind:         MissingWorktree,
+				RepoPath:     s.RepoPath,
+				SessionID:    s.ID,
+				SessionName:  s.Name,
+				WorktreePath: s.WorktreePath,
+				BranchName:   s.BranchName,
+			})
+		}
+	}
+
+	// Worktrees with no session, laid out as <root>/<repo>/<session>
+	repoDirs, err := os.ReadDir(worktreeRoot)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read worktree root: %w", err)
+	}
+	for _, repoDir := range repoDirs {
+		if !repoDir.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(worktreeRoot, repoDir.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read worktree root: %w", err)
+		}
+		for _, e := range entries {
+			path := filepath.Join(worktreeRoot, repoDir.Name(), e.Name())
+			if !e.IsDir() || tracked[path] {
+				continue
+			}
+			o := &Orphan{Kind: UntrackedWorktree, SessionName: e.Name(), WorktreePath: path}
+			if repoPath, err := worktree.MainRepoPath(path); err == nil {
+				o.RepoPath = repoPath
+				o.BranchName, _ = worktree.GetCurrentBranch(path)
+			}
+			orphans = append(orphans, o)
+		}
+	}
+
+	// tmux sessions with no session, per project socket
+	names := make(map[string]map[string]bool)
+	repos := make(map[string]string)
+	own := func(repoPath, name string) {
+		socket := terminal.SocketName(repoPath)
+		if names[socket] == nil {
+			names[socket] = map[string]bool{terminal.TmuxName(terminal.MainSessionName): true}
+		}
+		names[socket][terminal.TmuxName(name)] = true
+		repos[socket] = repoPath
+	}
+	for _, s := range sessions {
+		own(s.RepoPath, s.Name)
+	}
+	for _, d := range detached {
+		own(d.RepoPath, d.Name)
+	}
+	sockets, err := terminal.ListSockets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tmux sockets: %w", err)
+	}
+	for _, socket := range sockets {
+		tmuxSessions, err := terminal.ListSessions(socket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tmux sessions on %s: %w", socket, err)
+		}
+
+		if repoPath, ok := repos[socket]; ok {
+			for _, ts := range tmuxSessions {
+				if !names[socket][ts.Name] {
+					orphans = append(orphans, &Orphan{
+						Kind:        StrayTmuxSession,
+						RepoPath:    repoPath,
+						SessionName: ts.Name,
+						Socket:      socket,
+					})
+				}
+			}
+			continue
+		}
+
+		// A project with no sessions left may still have its main session
+		// running; only servers whose directories are all gone are stray
+		live := false
+		for _, ts := range tmuxSessions {
+			if _, err := os.Stat(ts.Path); err == nil {
+				live = true
+				break
+			}
+		}
+		if !live {
+			orphans = append(orphans, &Orphan{Kind: StrayTmuxServer, Socket: socket})
+		}
+	}
+
+	return orphans, nil
+}
+
+// CleanTmuxSessions kills the tmux sessions on a project's socket that no
+// session of the project owns, such as those of sessions deleted while ATC
+// wasn't running, and returns their names. Detached sessions' are spared.
+func CleanTmuxSessions(db *database.DB, repoPath string) ([]string, error) {
+	sessions, err := db.ListSessions("", "")
+	if err != nil {
+		return nil, err
+	}
+	detached, err := db.ListDetached(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	owned := map[string]bool{terminal.TmuxName(terminal.MainSessionName): true}
+	for _, s := range sessions {
+		if s.RepoPath == repoPath {
+			owned[terminal.TmuxName(s.Name)] = true
+		}
+	}
+	for _, d := range detached {
+		owned[terminal.TmuxName(d.Name)] = true
+	}
+
+	socket := terminal.SocketName(repoPath)
+	tmuxSessions, err := terminal.ListSessions(socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tmux sessions on %s: %w", socket, err)
+	}
+	var killed []string
+	for _, ts := range tmuxSessions {
+		if owned[ts.Name] {
+			continue
+		}
+		if err := terminal.KillSession(socket, ts.Name); err != nil {
+			return killed, fmt.Errorf("failed to kill tmux session %s: %w", ts.Name, err)
+		}
+		killed = append(killed, ts.Name)
+	}
+	return killed, nil
+}
+
+// Resolve applies an action to an orphan
+func Resolve(db *database.DB, o *Orphan, action Action) error {
+	switch {
+	case o.Kind == UntrackedWorktree && action == Adopt:
+		return adopt(db, o)
+	case o.Kind == UntrackedWorktree && action == Clean:
+		if o.RepoPath != "" {
+			if err := worktree.DeleteWorktree(o.WorktreePath); err == nil {
+				return nil
+			}
+		}
+		return os.RemoveAll(o.WorktreePath)
+	case o.Kind == MissingWorktree && action == Repair:
+		if err := worktree.PruneWorktrees(o.RepoPath); err != nil {
+			return err
+		}
+		if !worktree.BranchExists(o.RepoPath, o.BranchName) {
+			return fmt.Errorf("branch %s no longer exists", o.BranchName)
+		}
+		return worktree.CreateWorktree(o.RepoPath, o.SessionName, o.BranchName, o.WorktreePath, "", true)
+	case o.Kind == MissingWorktree && action == Clean:
+		socket := terminal.SocketName(o.RepoPath)
+		if name := terminal.TmuxName(o.SessionName); terminal.SessionExists(socket, name) {
+			terminal.KillSession(socket, name)
+		}
+		if err := db.DeleteSession(o.SessionID); err != nil {
+			return err
+		}
+		if err := db.DeleteUsage(o.SessionID); err != nil {
+			return err
+		}
+		if err := db.DeleteWorkTime(o.SessionID); err != nil {
+			return err
+		}
+		// Best effort: the repository itself may be gone too
+		worktree.PruneWorktrees(o.RepoPath)
+		return nil
+	case o.Kind == StrayTmuxSession && action == Clean:
+		return terminal.KillSession(o.Socket, o.SessionName)
+	case o.Kind == StrayTmuxServer && action == Clean:
+		terminal.KillServer(o.Socket)
+		if err := os.Remove(terminal.SocketPath(o.Socket)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot %s: %s", action, o)
+}
+
+// adopt records an untracked worktree as an active session of its repository
+func adopt(db *database.DB, o *Orphan) error {
+	if o.RepoPath == "" {
+		return fmt.Errorf("%s is not a git worktree", o.WorktreePath)
+	}
+	if _, err := os.Stat(o.RepoPath); err != nil {
+		return fmt.Errorf("repository %s no longer exists", o.RepoPath)
+	}
+	if o.BranchName == "" || o.BranchName == "HEAD" {
+		return fmt.Errorf("worktree %s is not on a branch", o.WorktreePath)
+	}
+	existing, _ := db.GetSessionByName(o.SessionName, o.RepoPath)
+	if existing != nil {
+		return fmt.Errorf("session with name '%s' already exists", o.SessionName)
+	}
+
+	return db.InsertSession(&database.Session{
+		ID:           uuid.New().String(),
+		Name:         o.SessionName,
+		RepoPath:     o.RepoPath,
+		RepoName:     filepath.Base(o.RepoPath),
+		WorktreePath: o.WorktreePath,
+		BranchName:   o.BranchName,
+		CreatedAt:    time.Now(),
+		Status:       "active",
+	})
+}
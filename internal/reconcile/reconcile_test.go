@@ -0,0 +1,180 @@
+package reconcile
+
+import (
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kevinzwang/air-traffic-control/internal/config"
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+func TestScanWorktrees(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMUX_TMPDIR", filepath.Join(dir, "tmux"))
+
+	db, err := database.Open(filepath.Join(dir, "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	root := filepath.Join(dir, "worktrees")
+	tracked := filepath.Join(root, "repo", "tracked")
+	untracked := filepath.Join(root, "repo", "untracked")
+	detached := filepath.Join(root, "repo", "detached")
+	for _, path := range []string{tracked, untracked, detached} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, s := range []*database.Session{
+		{ID: "1", Name: "tracked", WorktreePath: tracked},
+		{ID: "2", Name: "missing", WorktreePath: filepath.Join(root, "repo", "missing")},
+		{ID: "3", Name: "detached", WorktreePath: detached},
+	} {
+		s.RepoPath, s.RepoName, s.BranchName = "/src/repo", "repo", s.Name
+		s.CreatedAt, s.Status = time.Now(), "active"
+		if err := db.InsertSession(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.DetachSession("3"); err != nil {
+		t.Fatal(err)
+	}
+
+	orphans, err := Scan(db, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[Kind]string)
+	for _, o := range orphans {
+		got[o.Kind] = o.SessionName
+	}
+	want := map[Kind]string{MissingWorktree: "missing", UntrackedWorktree: "untracked"}
+	if len(orphans) != len(want) {
+		t.Fatalf("Scan found %d orphans, want %d: %v", len(orphans), len(want), orphans)
+	}
+	for kind, name := range want {
+		if got[kind] != name {
+			t.Errorf("orphan of kind %d = %q, want %q", kind, got[kind], name)
+		}
+	}
+}
+
+func TestCleanTmuxSessions(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("TMUX_TMPDIR", dir)
+
+	db, err := database.Open(filepath.Join(dir, "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repoPath := filepath.Join(dir, "repo")
+	if err := db.InsertSession(&database.Session{
+		ID: "1", Name: "kept", RepoPath: repoPath, RepoName: "repo", WorktreePath: dir,
+		BranchName: "kept", CreatedAt: time.Now(), Status: "active",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	socket := terminal.SocketName(repoPath)
+	defer terminal.KillServer(socket)
+	for _, name := range []string{"kept", terminal.MainSessionName, "deleted"} {
+		if err := exec.Command("tmux", "-L", socket, "new-session", "-d", "-s", name, "sleep 60").Run(); err != nil {
+			t.Fatalf("failed to start tmux session %s: %v", name, err)
+		}
+	}
+
+	killed, err := CleanTmuxSessions(db, repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(killed) != 1 || killed[0] != "deleted" {
+		t.Errorf("CleanTmuxSessions killed %v, want [deleted]", killed)
+	}
+	for _, name := range []string{"kept", terminal.MainSessionName} {
+		if !terminal.SessionExists(socket, name) {
+			t.Errorf("tmux session %s was killed", name)
+		}
+	}
+}
+
+func TestExpire(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMUX_TMPDIR", filepath.Join(dir, "tmux"))
+
+	repo := filepath.Join(dir, "repo")
+	for _, args := range [][]string{
+		{"init", "-q", repo},
+		{"-C", repo, "-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"-C", repo, "branch", "old"},
+		{"-C", repo, "branch", "shared"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	db, err := database.Open(filepath.Join(dir, "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	longAgo, recently := time.Now().AddDate(0, 0, -40), time.Now().AddDate(0, 0, -2)
+	for _, s := range []*database.Session{
+		{ID: "1", Name: "old", BranchName: "old", Status: "archived", ArchivedAt: &longAgo},
+		{ID: "2", Name: "recent", BranchName: "recent", Status: "archived", ArchivedAt: &recently},
+		{ID: "3", Name: "old-shared", BranchName: "shared", Status: "archived", ArchivedAt: &longAgo},
+		{ID: "4", Name: "active", BranchName: "shared", Status: "active"},
+	} {
+		s.RepoPath, s.RepoName, s.CreatedAt = repo, "repo", longAgo
+		s.WorktreePath = filepath.Join(dir, "worktrees", s.Name)
+		if err := db.InsertSession(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.DefaultGlobalConfig(dir)
+	if expired, err := Expire(db, cfg); err != nil || len(expired) != 0 {
+		t.Fatalf("Expire with no retention deleted %d sessions (err %v)", len(expired), err)
+	}
+
+	cfg.ArchiveRetention = config.ArchiveRetentionConfig{Days: 30, DeleteBranches: true}
+	expired, err := Expire(db, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, e := range expired {
+		got[e.Name] = e.Branch
+	}
+	want := map[string]string{"old": "old", "old-shared": ""}
+	if !maps.Equal(got, want) {
+		t.Errorf("expired sessions and branches = %v, want %v", got, want)
+	}
+	left, err := db.ListSessions("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 2 {
+		t.Errorf("%d sessions left, want 2 (recent and active)", len(left))
+	}
+	for branch, exists := range map[string]bool{"old": false, "shared": true} {
+		if worktree.BranchExists(repo, branch) != exists {
+			t.Errorf("branch %s exists = %v, want %v", branch, !exists, exists)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package session
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// DetachSession stops tracking a session without touching its branch,
+// worktree or tmux session, for the user to take over by hand. Its teardown
+// commands don't run.
+// The caller (TUI) is responsible for detaching from the terminal first.
+func (s *Service) DetachSession(name string) error {
+	unlock, err := s.lockProject()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	session, err := s.GetSession(name)
+	if err != nil {
+		return err
+	}
+	return s.db.DetachSession(session.ID)
+}
+
+// detachedNamed returns the project's detached session of the given name, or
+// nil if there is none
+func (s *Service) detachedNamed(name string) (*database.DetachedSession, error) {
+	detached, err := s.db.ListDetached(s.repoPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range detached {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+// AdoptableWorktrees returns the project's worktrees that no session or
+// trashed session has, such as ones created by hand or by another tool, or
+// those of detached sessions
+func (s *Service) AdoptableWorktrees() ([]worktree.Worktree, error) {
+	worktrees, err := worktree.ListWorktrees(s.repoPath)
+	if err != nil {
+		return nil, err
+	}
+	taken, err := s.takenWorktrees()
+	if err != nil {
+		return nil, err
+	}
+	var adoptable []worktree.Worktree
+	for _, wt := range worktrees {
+		if !taken[resolvePath(wt.Path)] {
+			adoptable = append(adoptable, wt)
+		}
+	}
+	return adoptable, nil
+}
+
+// takenWorktrees returns the resolved worktree paths of the project's
+// sessions, archived and trashed ones included
+func (s *Service) takenWorktrees() (map[string]bool, error) {
+	sessions, err := s.ListSessions("")
+	if err != nil {
+		return nil, err
+	}
+	trashed, err := s.ListTrash()
+	if err != nil {
+		return nil, err
+	}
+	taken := make(map[string]bool)
+	for _, sess := range sessions {
+		taken[resolvePath(sess.WorktreePath)] = true
+	}
+	for _, t := range trashed {
+		taken[resolvePath(t.TrashPath)] = true
+	}
+	return taken, nil
+}
+
+// AdoptWorktree registers an existing worktree of the project as a session
+// named after its branch. Setup commands don't run; the agent starts in the
+// worktree like in any other session's, or picks up the tmux session a
+// detached session left running.
+func (s *Service) AdoptWorktree(path string) (*Session, error) {
+	root, err := worktree.WorktreeRoot(path)
+	if err != nil {
+		return nil, err
+	}
+	repoPath, err := worktree.MainRepoPath(root)
+	if err != nil {
+		if resolvePath(root) == resolvePath(s.repoPath) {
+			return nil, fmt.Errorf("%s is the project's main checkout, not a worktree", root)
+		}
+		return nil, fmt.Errorf("%s is not a git worktree: %w", root, err)
+	}
+	if resolvePath(repoPath) != resolvePath(s.repoPath) {
+		return nil, fmt.Errorf("%s is a worktree of %s, not of %s", root, repoPath, s.repoPath)
+	}
+	branch, err := worktree.GetCurrentBranch(root)
+	if err != nil {
+		return nil, err
+	}
+	if branch == "HEAD" {
+		return nil, fmt.Errorf("%s is not on a branch; check one out first", root)
+	}
+	if err := worktree.ValidateBranchName(branch); err != nil {
+		return nil, fmt.Errorf("branch '%s' can't name a session: %w", branch, err)
+	}
+
+	unlock, err := s.lockProject()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	taken, err := s.takenWorktrees()
+	if err != nil {
+		return nil, err
+	}
+	if taken[resolvePath(root)] {
+		return nil, fmt.Errorf("%s already belongs to a session", root)
+	}
+	if existing, _ := s.db.GetSessionByName(branch, s.repoPath); existing != nil {
+		return nil, fmt.Errorf("session with name '%s' already exists", branch)
+	}
+	if trashed, err := s.findTrashed(func(t *TrashedSession) bool { return t.Name == branch }); err == nil && trashed != nil {
+		return nil, fmt.Errorf("session '%s' is in the trash; restore or purge it first", branch)
+	}
+	existingByBranch, err := s.db.GetSessionByBranchName(branch, s.repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check branch: %w", err)
+	}
+	if existingByBranch != nil {
+		return nil, fmt.Errorf("branch '%s' already has a session", branch)
+	}
+	// A session detached under this name may still have its tmux session
+	// running somewhere else
+	detached, err := s.detachedNamed(branch)
+	if err != nil {
+		return nil, err
+	}
+	if detached != nil && resolvePath(detached.WorktreePath) != resolvePath(root) {
+		return nil, fmt.Errorf("'%s' was detached from ATC with its worktree at %s; adopt that one instead", branch, detached.WorktreePath)
+	}
+
+	sess := &Session{
+		ID:           uuid.New().String(),
+		Name:         branch,
+		RepoPath:     s.repoPath,
+		RepoName:     s.repoName,
+		WorktreePath: root,
+		BranchName:   branch,
+		CreatedAt:    time.Now(),
+		Status:       "active",
+	}
+	if detached != nil {
+		sess.WorktreePath = detached.WorktreePath
+	}
+	if err := s.db.InsertSession(sess.toDBSession()); err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+	if detached != nil {
+		if err := s.db.ForgetDetached(detached.WorktreePath); err != nil {
+			return nil, err
+		}
+	}
+	return sess, nil
+}
+
+// resolvePath returns path with symlinks resolved, so that paths git and the
+// database spell differently compare equal
+func resolvePath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,34 @@
+package session
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lockProject takes the project's advisory lock, which ATC instances share
+// through a file under the worktree root, and returns the function that
+// releases it. Changes to a project's sessions and worktrees are made while
+// holding it, so two instances can't create the same session or run git
+// worktree commands in the repository at the same time.
+func (s *Service) lockProject() (func(), error) {
+	hash := sha256.Sum256([]byte(s.repoPath))
+	dir := filepath.Join(s.cfg.WorktreeRoot, ".locks")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%x.lock", hash[:8]))
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open project lock: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock project: %w", err)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
@@ -2,42 +2,115 @@ package session
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kevinzwang/air-traffic-control/internal/archive"
 	"github.com/kevinzwang/air-traffic-control/internal/config"
+	"github.com/kevinzwang/air-traffic-control/internal/container"
 	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/usage"
 	"github.com/kevinzwang/air-traffic-control/internal/worktree"
 )
 
 // Service manages session operations
 type Service struct {
-	db       *database.DB
-	atcDir   string
-	repoPath string
-	repoName string
+	db        *database.DB
+	cfg       *config.Config
+	repo      *config.RepoConfig // the repository's own settings in cfg
+	untrusted *config.Config     // cfg without what the repository would run
+	repoPath  string
+	repoName  string
 }
 
-// NewService creates a new session service
-func NewService(db *database.DB, repoPath string) (*Service, error) {
-	homeDir, err := os.UserHomeDir()
+// NewService creates a new session service. The user-level config is merged
+// with the repository's config to produce the effective settings. The
+// repository's config is read from its root once, here, so the setup commands
+// shown, trusted and run for new sessions all come from the same place.
+func NewService(db *database.DB, repoPath string, global *config.GlobalConfig) (*Service, error) {
+	repoCfg, err := config.Load(repoPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	atcDir := filepath.Join(homeDir, ".atc")
-	repoName := filepath.Base(repoPath)
-
 	return &Service{
-		db:       db,
-		atcDir:   atcDir,
-		repoPath: repoPath,
-		repoName: repoName,
+		db:        db,
+		cfg:       config.Merge(global, repoCfg),
+		repo:      repoCfg,
+		untrusted: config.Merge(global, repoCfg.Untrusted()),
+		repoPath:  repoPath,
+		repoName:  filepath.Base(repoPath),
 	}, nil
 }
 
+// Config returns the effective configuration for the repository
+func (s *Service) Config() *config.Config {
+	return s.cfg
+}
+
+// DefaultBaseBranch returns the branch new sessions start from by default:
+// the one chosen in the TUI, else base_branch from the repo config. "" means
+// the main checkout's HEAD
+func (s *Service) DefaultBaseBranch() string {
+	if branch, err := s.db.ProjectBaseBranch(s.repoPath); err == nil && branch != "" {
+		return branch
+	}
+	return s.cfg.BaseBranch
+}
+
+// SetDefaultBaseBranch changes the project's default base branch. "" clears
+// it, falling back to the repo config
+func (s *Service) SetDefaultBaseBranch(branch string) error {
+	return s.db.SetProjectBaseBranch(s.repoPath, branch)
+}
+
+// SessionBase returns the branch a session's branch is compared against,
+// synced with and landed on: the one it was started from, or for sessions
+// that don't know it, the project's default base branch or else the branch
+// the main checkout is on. "" means there is nothing to compare against
+func (s *Service) SessionBase(sess *Session) string {
+	base := sess.BaseBranch
+	if base == "" {
+		base = s.DefaultBaseBranch()
+	}
+	if base == "" {
+		if branch, err := worktree.GetCurrentBranch(s.repoPath); err == nil && branch != "HEAD" {
+			base = branch
+		}
+	}
+	if base == sess.BranchName {
+		return ""
+	}
+	return base
+}
+
+// startingBase returns the base to record for a new session: the branch it
+// starts from, with HEAD resolved to the main checkout's branch. An existing
+// branch's base isn't known
+func (s *Service) startingBase(baseBranch string, useExistingBranch bool) string {
+	if useExistingBranch {
+		return ""
+	}
+	if baseBranch == "" || baseBranch == "HEAD" {
+		branch, err := worktree.GetCurrentBranch(s.repoPath)
+		if err != nil || branch == "HEAD" {
+			return ""
+		}
+		return branch
+	}
+	return baseBranch
+}
+
+// SetSessionBase records the branch a session is compared against, synced
+// with and landed on, when it isn't the one it started from
+func (s *Service) SetSessionBase(sess *Session, base string) error {
+	sess.BaseBranch = base
+	return s.db.UpdateSession(sess.toDBSession())
+}
+
 // RepoName returns the repository name
 func (s *Service) RepoName() string {
 	return s.repoName
@@ -58,10 +131,23 @@ func (s *Service) CreateSession(name, baseBranch string, useExistingBranch bool)
 		return nil, nil, fmt.Errorf("invalid session name: %w", err)
 	}
 
+	unlock, err := s.lockProject()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+
 	existing, _ := s.db.GetSessionByName(name, s.repoPath)
 	if existing != nil {
 		return nil, nil, fmt.Errorf("session with name '%s' already exists", name)
 	}
+	if trashed, err := s.findTrashed(func(t *TrashedSession) bool { return t.Name == name }); err == nil && trashed != nil {
+		return nil, nil, fmt.Errorf("session '%s' is in the trash; restore or purge it first", name)
+	}
+	// Its tmux session would be taken for the new one's
+	if detached, err := s.detachedNamed(name); err == nil && detached != nil {
+		return nil, nil, fmt.Errorf("'%s' was detached from ATC; adopt its worktree again or pick another name", name)
+	}
 
 	if useExistingBranch {
 		existingByBranch, err := s.db.GetSessionByBranchName(name, s.repoPath)
@@ -78,8 +164,9 @@ func (s *Service) CreateSession(name, baseBranch string, useExistingBranch bool)
 		Name:         name,
 		RepoPath:     s.repoPath,
 		RepoName:     s.repoName,
-		WorktreePath: filepath.Join(s.atcDir, "worktrees", s.repoName, name),
+		WorktreePath: filepath.Join(s.cfg.WorktreeRoot, s.repoName, name),
 		BranchName:   name,
+		BaseBranch:   s.startingBase(baseBranch, useExistingBranch),
 		CreatedAt:    time.Now(),
 		Status:       "active",
 	}
@@ -91,10 +178,9 @@ func (s *Service) CreateSession(name, baseBranch string, useExistingBranch bool)
 	// cleanupWorktree ensures worktree is removed on any subsequent error
 	cleanupWorktree := func() { worktree.DeleteWorktree(sess.WorktreePath) }
 
-	cfg, err := config.Load(sess.WorktreePath)
-	if err != nil {
+	if err := worktree.CopyFiles(s.repoPath, sess.WorktreePath, s.cfg.CopyFiles); err != nil {
 		cleanupWorktree()
-		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+		return nil, nil, fmt.Errorf("failed to copy files: %w", err)
 	}
 
 	if err := s.db.InsertSession(sess.toDBSession()); err != nil {
@@ -102,7 +188,46 @@ func (s *Service) CreateSession(name, baseBranch string, useExistingBranch bool)
 		return nil, nil, fmt.Errorf("failed to save session: %w", err)
 	}
 
-	return sess, cfg.SetupWorktree, nil
+	return sess, s.cfg.Setup, nil
+}
+
+// CommandsTrusted reports whether the user has trusted the repository's
+// setup/teardown commands, as shown by Config. Commands come from files in
+// the repo, so they are only run automatically once the user has confirmed
+// them, and again whenever they change.
+func (s *Service) CommandsTrusted() (bool, error) {
+	fingerprint := s.repo.CommandsFingerprint()
+	if fingerprint == "" {
+		return true, nil
+	}
+	trusted, err := s.db.TrustedCommands(s.repoPath)
+	if err != nil {
+		return false, err
+	}
+	return trusted == fingerprint, nil
+}
+
+// TrustedConfig returns Config once the user has trusted the repository's
+// commands, and until then Config without the repository's commands and
+// run settings (see config.RepoConfig.RunSettings). Agents are started and
+// archives uploaded with it.
+func (s *Service) TrustedConfig() *config.Config {
+	if trusted, err := s.CommandsTrusted(); err == nil && trusted {
+		return s.cfg
+	}
+	return s.untrusted
+}
+
+// RunSettings lists the repository's settings, besides its commands, that
+// need the user's trust (see config.RepoConfig.RunSettings)
+func (s *Service) RunSettings() []string {
+	return s.repo.RunSettings()
+}
+
+// TrustCommands records that the user trusts the repository's setup/teardown
+// commands, as shown by Config
+func (s *Service) TrustCommands() error {
+	return s.db.TrustCommands(s.repoPath, s.repo.CommandsFingerprint())
 }
 
 // ListSessions returns all sessions, optionally filtered by query
@@ -112,11 +237,31 @@ func (s *Service) ListSessions(query string) ([]*Session, error) {
 		return nil, err
 	}
 
-	sessions := make([]*Session, len(dbSessions))
-	for i, dbs := range dbSessions {
-		sessions[i] = fromDBSession(dbs)
+	return fromDBSessions(dbSessions), nil
+}
+
+// ListActiveSessions returns the sessions that aren't archived, newest first
+func (s *Service) ListActiveSessions() ([]*Session, error) {
+	dbSessions, err := s.db.ListActiveSessions(s.repoName)
+	if err != nil {
+		return nil, err
 	}
-	return sessions, nil
+	return fromDBSessions(dbSessions), nil
+}
+
+// ListArchivedSessions returns up to limit archived sessions, newest first,
+// skipping the first offset
+func (s *Service) ListArchivedSessions(limit, offset int) ([]*Session, error) {
+	dbSessions, err := s.db.ListArchivedSessions(s.repoName, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return fromDBSessions(dbSessions), nil
+}
+
+// ArchivedCount returns how many sessions are archived
+func (s *Service) ArchivedCount() (int, error) {
+	return s.db.CountArchivedSessions(s.repoName)
 }
 
 // GetSession retrieves a session by name
@@ -131,26 +276,113 @@ func (s *Service) GetSession(name string) (*Session, error) {
 // DeleteSession removes a session and its worktree.
 // The caller (TUI) is responsible for closing the terminal process first.
 func (s *Service) DeleteSession(name string) error {
+	unlock, err := s.lockProject()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	session, err := s.GetSession(name)
 	if err != nil {
 		return err
 	}
 
-	// Remove worktree
-	if err := worktree.DeleteWorktree(session.WorktreePath); err != nil {
-		return fmt.Errorf("failed to remove worktree: %w", err)
+	if err := s.removeWorktree(session); err != nil {
+		return err
 	}
 
 	// Remove from database
 	if err := s.db.DeleteSession(session.ID); err != nil {
 		return fmt.Errorf("failed to delete session from database: %w", err)
 	}
+	if err := s.db.DeleteUsage(session.ID); err != nil {
+		return err
+	}
+	if err := s.db.DeleteWorkTime(session.ID); err != nil {
+		return err
+	}
+	if err := s.db.DeletePorts(session.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// removeWorktree runs a session's teardown commands, removes its container
+// and removes its worktree, unless it is already gone
+func (s *Service) removeWorktree(session *Session) error {
+	s.teardown(session)
+
+	// Remove worktree, unless it is already gone
+	if _, err := os.Stat(session.WorktreePath); err == nil {
+		if err := worktree.DeleteWorktree(session.WorktreePath); err != nil {
+			return fmt.Errorf("failed to remove worktree: %w", err)
+		}
+	}
 
 	return nil
 }
 
+// teardown runs a session's teardown commands and removes its container
+func (s *Service) teardown(session *Session) {
+	// Run teardown commands (best effort) while the worktree still exists,
+	// unless the user hasn't trusted the repo's current commands
+	if _, err := os.Stat(session.WorktreePath); err == nil && len(s.cfg.Teardown) > 0 {
+		if trusted, err := s.CommandsTrusted(); err == nil && trusted {
+			ports, _ := s.Ports(session)
+			worktree.RunSetupCommands(session.WorktreePath, s.cfg.Teardown, PortEnv(ports), io.Discard)
+		}
+	}
+
+	// Remove the session's container (best effort) so it doesn't outlive
+	// the worktree it mounts
+	if s.cfg.Container.Enabled() {
+		container.Remove(session.WorktreePath)
+	}
+}
+
+// Ports returns the ports a session's dev servers can use, giving it a range
+// of them first if it has none (sessions from before ports were handed out)
+func (s *Service) Ports(sess *Session) ([]int, error) {
+	port, count, err := s.db.SessionPorts(sess.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		count = s.cfg.Ports.Count
+		port, err = s.db.AllocatePorts(sess.ID, s.cfg.Ports.Base, count)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ports := make([]int, count)
+	for i := range ports {
+		ports[i] = port + i
+	}
+	return ports, nil
+}
+
+// PortEnv returns the environment variables that pass a session's ports to
+// the commands run for it: ATC_PORT (the first) and ATC_PORT_0, ATC_PORT_1...
+func PortEnv(ports []int) []string {
+	if len(ports) == 0 {
+		return nil
+	}
+	env := []string{fmt.Sprintf("ATC_PORT=%d", ports[0])}
+	for i, port := range ports {
+		env = append(env, fmt.Sprintf("ATC_PORT_%d=%d", i, port))
+	}
+	return env
+}
+
 // ArchiveSession marks a session as archived
 func (s *Service) ArchiveSession(name string) error {
+	unlock, err := s.lockProject()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	session, err := s.GetSession(name)
 	if err != nil {
 		return err
@@ -159,12 +391,182 @@ func (s *Service) ArchiveSession(name string) error {
 	return s.db.ArchiveSession(session.ID)
 }
 
+// LandResult says where LandSession put a session's changes
+type LandResult struct {
+	Target   string // local branch the squash commit was added to
+	Commit   string // "" if the target already had the changes
+	Upstream string // where the target was pushed, "" if it tracks nothing
+}
+
+// LandTarget returns the local branch a session's changes land on: its base,
+// or for a remote-tracking base (origin/main) the local branch of the same
+// name
+func (s *Service) LandTarget(sess *Session) (string, error) {
+	base := s.SessionBase(sess)
+	if base == "" {
+		return "", fmt.Errorf("%s has no base branch to land on", sess.Name)
+	}
+	if worktree.BranchExists(s.repoPath, base) {
+		return base, nil
+	}
+	if local := worktree.LocalBranchName(base); worktree.BranchExists(s.repoPath, local) {
+		return local, nil
+	}
+	return "", fmt.Errorf("there is no local branch %s to land %s on", base, sess.Name)
+}
+
+// LandSubject returns the default subject of the squash commit landing a
+// session: the subject of its first commit, or else its name
+func (s *Service) LandSubject(sess *Session, target string) string {
+	subjects, _ := worktree.CommitSubjects(s.repoPath, target, sess.BranchName)
+	if len(subjects) == 0 {
+		return sess.Name
+	}
+	return subjects[0]
+}
+
+// LandSession squash-merges a session's branch into its landing target as a
+// commit with subject, listing the squashed commits when there are several,
+// and pushes the target to its upstream. The session's worktree must be
+// clean. Nothing of the session is touched, so its agent can keep running
+// until this succeeds; RemoveLanded cleans it up then.
+func (s *Service) LandSession(name, subject string) (*LandResult, error) {
+	unlock, err := s.lockProject()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	session, err := s.GetSession(name)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.LandTarget(session)
+	if err != nil {
+		return nil, err
+	}
+	st, err := worktree.GetStatus(session.WorktreePath, "")
+	if err != nil {
+		return nil, err
+	}
+	if st.Dirty > 0 {
+		return nil, fmt.Errorf("%s has uncommitted changes; commit or discard them first", name)
+	}
+
+	message := subject
+	if subjects, err := worktree.CommitSubjects(s.repoPath, target, session.BranchName); err == nil && len(subjects) > 1 {
+		message += "\n"
+		for _, subj := range subjects {
+			message += "\n* " + subj
+		}
+	}
+
+	result := &LandResult{Target: target}
+	if result.Commit, err = worktree.SquashLand(s.repoPath, session.BranchName, target, message); err != nil {
+		return nil, err
+	}
+	if result.Upstream, err = worktree.PushBranch(s.repoPath, target); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RemoveLanded removes the worktree of a session LandSession landed, deletes
+// its branch and archives the session.
+// The caller (TUI) is responsible for closing the terminal process first.
+func (s *Service) RemoveLanded(name string) error {
+	unlock, err := s.lockProject()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	session, err := s.GetSession(name)
+	if err != nil {
+		return err
+	}
+	if err := s.removeWorktree(session); err != nil {
+		return err
+	}
+	if err := worktree.DeleteBranch(s.repoPath, session.BranchName); err != nil {
+		return err
+	}
+	return s.db.ArchiveSession(session.ID)
+}
+
+// UploadArchive exports the session's report and transcripts to the
+// repository's archive_destination. It is a no-op when none is configured or
+// the user hasn't trusted the repository.
+func (s *Service) UploadArchive(name string) error {
+	destination := s.TrustedConfig().ArchiveDestination
+	if destination == "" {
+		return nil
+	}
+
+	session, err := s.GetSession(name)
+	if err != nil {
+		return err
+	}
+
+	archivedAt := time.Now()
+	if session.ArchivedAt != nil {
+		archivedAt = *session.ArchivedAt
+	}
+
+	return archive.Upload(destination, archive.Record{
+		Name:         session.Name,
+		RepoName:     s.repoName,
+		RepoPath:     s.repoPath,
+		BranchName:   session.BranchName,
+		WorktreePath: session.WorktreePath,
+		CreatedAt:    session.CreatedAt,
+		ArchivedAt:   archivedAt,
+	})
+}
+
+// RefreshUsage recomputes the token usage of every session in the repository
+// from its Claude Code transcripts and stores the rollups
+func (s *Service) RefreshUsage() error {
+	sessions, err := s.ListSessions("")
+	if err != nil {
+		return err
+	}
+	for _, sess := range sessions {
+		t, err := usage.ForWorktree(sess.WorktreePath)
+		if err != nil {
+			return err
+		}
+		err = s.db.SaveUsage(&database.SessionUsage{
+			SessionID:        sess.ID,
+			InputTokens:      t.InputTokens,
+			OutputTokens:     t.OutputTokens,
+			CacheWriteTokens: t.CacheWriteTokens,
+			CacheReadTokens:  t.CacheReadTokens,
+			CostUSD:          t.CostUSD,
+			UpdatedAt:        time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // UnarchiveSession marks a session as active
 func (s *Service) UnarchiveSession(name string) error {
+	unlock, err := s.lockProject()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	session, err := s.GetSession(name)
 	if err != nil {
 		return err
 	}
+	if _, err := os.Stat(session.WorktreePath); err != nil {
+		return fmt.Errorf("%s's worktree was removed when it was landed; it can only be deleted", name)
+	}
 
 	return s.db.UnarchiveSession(session.ID)
 }
@@ -174,6 +576,17 @@ func (s *Service) ListBranches() ([]string, error) {
 	return worktree.ListBranches(s.repoPath)
 }
 
+// FetchBaseBranch fetches a base branch from its remote, returning the
+// remote-tracking branch to create a session from
+func (s *Service) FetchBaseBranch(branch string) (string, error) {
+	return worktree.FetchBranch(s.repoPath, branch)
+}
+
+// ListRemoteBranches returns the repository's remote-tracking branches
+func (s *Service) ListRemoteBranches() ([]string, error) {
+	return worktree.ListRemoteBranches(s.repoPath)
+}
+
 // GetSessionByBranch returns a session for a given branch name, or nil if none exists
 func (s *Service) GetSessionByBranch(branchName string) (*Session, error) {
 	dbs, err := s.db.GetSessionByBranchName(branchName, s.repoPath)
@@ -186,6 +599,12 @@ func (s *Service) GetSessionByBranch(branchName string) (*Session, error) {
 	return fromDBSession(dbs), nil
 }
 
+// BranchesWithSessions returns the names of the branches the repository's
+// sessions are on
+func (s *Service) BranchesWithSessions() (map[string]bool, error) {
+	return s.db.BranchesWithSessions(s.repoPath)
+}
+
 // TouchSession updates the last accessed time for a session
 func (s *Service) TouchSession(name string) error {
 	sess, err := s.GetSession(name)
@@ -14,6 +14,7 @@ type Session struct {
 	RepoName      string
 	WorktreePath  string
 	BranchName    string
+	BaseBranch    string // what the branch was started from, "" if not known
 	CreatedAt     time.Time
 	LastAccessed  *time.Time
 	ArchivedAt    *time.Time
@@ -29,6 +30,7 @@ func fromDBSession(dbs *database.Session) *Session {
 		RepoName:     dbs.RepoName,
 		WorktreePath: dbs.WorktreePath,
 		BranchName:   dbs.BranchName,
+		BaseBranch:   dbs.BaseBranch,
 		CreatedAt:    dbs.CreatedAt,
 		LastAccessed: dbs.LastAccessed,
 		ArchivedAt:   dbs.ArchivedAt,
@@ -36,6 +38,15 @@ func fromDBSession(dbs *database.Session) *Session {
 	}
 }
 
+// fromDBSessions converts a list of database.Sessions
+func fromDBSessions(dbSessions []*database.Session) []*Session {
+	sessions := make([]*Session, len(dbSessions))
+	for i, dbs := range dbSessions {
+		sessions[i] = fromDBSession(dbs)
+	}
+	return sessions
+}
+
 // toDBSession converts a session.Session to a database.Session
 func (s *Session) toDBSession() *database.Session {
 	return &database.Session{
@@ -45,6 +56,7 @@ func (s *Session) toDBSession() *database.Session {
 		RepoName:     s.RepoName,
 		WorktreePath: s.WorktreePath,
 		BranchName:   s.BranchName,
+		BaseBranch:   s.BaseBranch,
 		CreatedAt:    s.CreatedAt,
 		LastAccessed: s.LastAccessed,
 		ArchivedAt:   s.ArchivedAt,
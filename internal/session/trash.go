@@ -0,0 +1,175 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// TrashedSession is a deleted session whose worktree waits in the trash,
+// uncommitted changes and all, until it is restored or purged
+type TrashedSession struct {
+	*Session
+	TrashPath string
+	TrashedAt time.Time
+}
+
+// TrashSession deletes a session but moves its worktree to the trash, where
+// RestoreSession can bring it back. Teardown commands run as for
+// DeleteSession. With the trash turned off (trash_days: 0) the session is
+// deleted outright.
+// The caller (TUI) is responsible for closing the terminal process first.
+func (s *Service) TrashSession(name string) error {
+	if s.cfg.TrashDays == 0 {
+		return s.DeleteSession(name)
+	}
+
+	unlock, err := s.lockProject()
+	if err != nil {
+		return err
+	}
+
+	session, err := s.GetSession(name)
+	if err != nil {
+		unlock()
+		return err
+	}
+	if _, err := os.Stat(session.WorktreePath); err != nil {
+		// Nothing left to keep
+		unlock()
+		return s.DeleteSession(name)
+	}
+	defer unlock()
+
+	s.teardown(session)
+
+	trashPath := filepath.Join(s.cfg.TrashRoot, s.repoName, name+"."+time.Now().Format("20060102-150405"))
+	if err := worktree.MoveWorktree(session.WorktreePath, trashPath); err != nil {
+		return fmt.Errorf("failed to move the worktree to the trash (set trash_days: 0 to delete sessions right away): %w", err)
+	}
+	if err := s.db.TrashSession(session.ID, trashPath); err != nil {
+		return err
+	}
+	return s.db.DeletePorts(session.ID)
+}
+
+// ListTrash returns the project's trashed sessions, most recently deleted
+// first
+func (s *Service) ListTrash() ([]*TrashedSession, error) {
+	rows, err := s.db.ListTrash(s.repoPath)
+	if err != nil {
+		return nil, err
+	}
+	trashed := make([]*TrashedSession, len(rows))
+	for i, t := range rows {
+		trashed[i] = fromDBTrashed(t)
+	}
+	return trashed, nil
+}
+
+// findTrashed returns the project's first trashed session match accepts, or
+// nil if there is none
+func (s *Service) findTrashed(match func(*TrashedSession) bool) (*TrashedSession, error) {
+	trashed, err := s.ListTrash()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range trashed {
+		if match(t) {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+// RestoreSession brings a trashed session back, with its worktree moved
+// back to where it was. Setup commands don't run again.
+func (s *Service) RestoreSession(id string) (*Session, error) {
+	unlock, err := s.lockProject()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	t, err := s.findTrashed(func(t *TrashedSession) bool { return t.ID == id })
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("session not found in the trash")
+	}
+	if existing, _ := s.db.GetSessionByName(t.Name, s.repoPath); existing != nil {
+		return nil, fmt.Errorf("a session named '%s' already exists", t.Name)
+	}
+	if _, err := os.Stat(t.WorktreePath); err == nil {
+		return nil, fmt.Errorf("%s already exists", t.WorktreePath)
+	}
+	if _, err := os.Stat(t.TrashPath); err != nil {
+		return nil, fmt.Errorf("%s's worktree is gone from the trash", t.Name)
+	}
+
+	if err := worktree.MoveWorktree(t.TrashPath, t.WorktreePath); err != nil {
+		return nil, err
+	}
+	if err := s.db.RestoreSession(t.ID); err != nil {
+		return nil, err
+	}
+	return t.Session, nil
+}
+
+// PurgeTrashed deletes a trashed session of the project for good
+func (s *Service) PurgeTrashed(id string) error {
+	unlock, err := s.lockProject()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	rows, err := s.db.ListTrash(s.repoPath)
+	if err != nil {
+		return err
+	}
+	for _, t := range rows {
+		if t.ID == id {
+			return PurgeTrashed(s.db, t)
+		}
+	}
+	return fmt.Errorf("session not found in the trash")
+}
+
+// PurgeTrashed removes a trashed session's worktree and its records for
+// good, also when its repository is gone (`atc prune`)
+func PurgeTrashed(db *database.DB, t *database.TrashedSession) error {
+	if _, err := os.Stat(t.TrashPath); err == nil {
+		if err := worktree.DeleteWorktree(t.TrashPath); err != nil {
+			// The repository may be gone; remove the directory and let git
+			// forget the worktree if it is still around
+			if err := os.RemoveAll(t.TrashPath); err != nil {
+				return fmt.Errorf("failed to remove worktree: %w", err)
+			}
+			if _, err := os.Stat(t.RepoPath); err == nil {
+				worktree.PruneWorktrees(t.RepoPath)
+			}
+		}
+	}
+	if err := db.DeleteTrashed(t.ID); err != nil {
+		return err
+	}
+	if err := db.DeleteUsage(t.ID); err != nil {
+		return err
+	}
+	return db.DeleteWorkTime(t.ID)
+}
+
+// fromDBTrashed converts a trashed session's database record
+func fromDBTrashed(t *database.TrashedSession) *TrashedSession {
+	return &TrashedSession{
+		Session:   fromDBSession(&t.Session),
+		TrashPath: t.TrashPath,
+		TrashedAt: t.TrashedAt,
+	}
+}
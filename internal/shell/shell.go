@@ -0,0 +1,25 @@
+// Package shell runs command lines with the platform's shell: sh on Unix,
+// cmd.exe on Windows.
+package shell
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Command returns a command that runs a command line with the system shell.
+func Command(command string) *exec.Cmd {
+	args := Args(systemShell(), command)
+	return exec.Command(args[0], args[1:]...)
+}
+
+// Args returns the arguments that run a command line with the given shell.
+// cmd.exe takes /c where every other shell takes -c.
+func Args(shell, command string) []string {
+	name := strings.ToLower(filepath.Base(shell))
+	if name == "cmd" || name == "cmd.exe" {
+		return []string{shell, "/c", command}
+	}
+	return []string{shell, "-c", command}
+}
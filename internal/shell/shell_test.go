@@ -0,0 +1,24 @@
+package shell
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestArgs(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{"/bin/zsh", []string{"/bin/zsh", "-c", "make"}},
+		{"sh", []string{"sh", "-c", "make"}},
+		{"cmd.exe", []string{"cmd.exe", "/c", "make"}},
+		{"CMD", []string{"CMD", "/c", "make"}},
+		{"pwsh", []string{"pwsh", "-c", "make"}},
+	}
+	for _, tt := range tests {
+		if got := Args(tt.shell, "make"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Args(%q) = %v, want %v", tt.shell, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+//go:build !windows
+
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+func systemShell() string {
+	return "sh"
+}
+
+// Default returns the user's interactive shell: $SHELL, or /bin/sh.
+func Default() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
+
+// Quote wraps s in single quotes for safe use in a POSIX shell command.
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Exec replaces the current process with args run in dir, with env as its
+// environment. It only returns on failure.
+func Exec(dir string, args, env []string) error {
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+	return syscall.Exec(path, args, env)
+}
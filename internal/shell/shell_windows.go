@@ -0,0 +1,48 @@
+//go:build windows
+
+package shell
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+func systemShell() string {
+	if comspec := os.Getenv("COMSPEC"); comspec != "" {
+		return comspec
+	}
+	return "cmd.exe"
+}
+
+// Default returns the user's interactive shell: %COMSPEC%, or cmd.exe.
+func Default() string {
+	return systemShell()
+}
+
+// Quote quotes s as a single argument the way Windows programs parse their
+// command lines.
+func Quote(s string) string {
+	return syscall.EscapeArg(s)
+}
+
+// Exec runs args in dir, with env as its environment, and exits with its
+// status once it finishes; Windows can't replace the current process. It
+// only returns if the command can't be started.
+func Exec(dir string, args, env []string) error {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
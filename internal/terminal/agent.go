@@ -0,0 +1,100 @@
+package terminal
+
+import (
+	"strings"
+
+	"github.com/kevinzwang/air-traffic-control/internal/shell"
+)
+
+// Agent describes how to launch the agent process inside a tmux pane.
+type Agent struct {
+	Command string // agent command, e.g. "claude"
+	Shell   string // shell used to run the command ("" = tmux default-shell)
+	Locale  string // LANG/LC_ALL for the agent ("" = inherit)
+	Term    string // TERM for the agent ("" = tmux default-terminal)
+
+	// Env is more environment for the agent, as KEY=value
+	Env []string
+
+	// Args are added to Command, quoted. flags are more, given as shell
+	// words, for a single restart (see withFlags)
+	Args  []string
+	flags string
+
+	// Container is a command the agent's command line is given to as one
+	// more argument, to run it in a container ("" = run it directly)
+	Container string
+
+	// Resume is the conversation a continuing agent resumes when it first
+	// starts, instead of the latest one ("" = the latest, with --continue).
+	// Restarts continue the latest.
+	Resume string
+}
+
+// command builds the agent's command with its arguments, for the platform's
+// shell, wrapped in the container command if there is one.
+func (a Agent) command(continueSession bool) string {
+	cmd := a.Command
+	for _, arg := range a.Args {
+		cmd += " " + shell.Quote(arg)
+	}
+	if a.flags != "" {
+		cmd += " " + a.flags
+	}
+	if continueSession {
+		if a.Resume != "" {
+			cmd += " --resume " + shell.Quote(a.Resume)
+		} else {
+			cmd += " --continue"
+		}
+	}
+	if a.Container != "" {
+		cmd = a.Container + " " + shell.Quote(cmd)
+	}
+	return cmd
+}
+
+// ShellCommand is the command that starts the agent in the user's own
+// terminal, outside ATC, picking up its last conversation if
+// continueSession is set
+func (a Agent) ShellCommand(continueSession bool) string {
+	return a.command(continueSession)
+}
+
+// commandLine builds the shell command tmux runs in the pane. tmux sets
+// TERM from its default-terminal option after applying the session
+// environment, so TERM and locale are forced with env(1) instead of -e.
+func (a Agent) commandLine(continueSession bool) string {
+	cmd := a.command(continueSession)
+	if a.Shell != "" {
+		cmd = a.Shell + " -c " + shellQuote(cmd)
+	}
+
+	var env []string
+	if a.Term != "" {
+		env = append(env, "TERM="+shellQuote(a.Term))
+	}
+	if a.Locale != "" {
+		env = append(env, "LANG="+shellQuote(a.Locale), "LC_ALL="+shellQuote(a.Locale))
+	}
+	for _, kv := range a.Env {
+		key, value, _ := strings.Cut(kv, "=")
+		env = append(env, key+"="+shellQuote(value))
+	}
+	if len(env) > 0 {
+		cmd = "env " + strings.Join(env, " ") + " " + cmd
+	}
+	return cmd
+}
+
+// withFlags returns the agent with extra flags, given as shell words, added
+// to its command after Args, so they take precedence.
+func (a Agent) withFlags(flags string) Agent {
+	a.flags = strings.TrimSpace(flags)
+	return a
+}
+
+// shellQuote wraps s in single quotes for safe use in a POSIX shell command.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
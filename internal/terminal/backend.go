@@ -0,0 +1,48 @@
+package terminal
+
+import (
+	"os/exec"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Backend starts agent terminals and finds the ones still running. socket
+// groups a project's terminals (see SocketName); name is the session name.
+type Backend interface {
+	// New starts the agent in worktreePath in a new terminal.
+	New(name, worktreePath string, agent Agent, width, height int, continueSession bool, p *tea.Program, socket string) (Terminal, error)
+	// Attach resumes a terminal that is still running, resized to fit.
+	Attach(name string, agent Agent, width, height int, p *tea.Program, socket string) (Terminal, error)
+	// Exists reports whether the session's terminal is still running.
+	Exists(socket, name string) bool
+	// AttachCommand returns a command that attaches the user's own terminal
+	// to the session, or nil if the backend can't share its terminals.
+	AttachCommand(socket, name string) *exec.Cmd
+}
+
+// Built-in backends.
+var (
+	// Tmux runs each agent in a tmux session, which outlives ATC.
+	Tmux Backend = tmuxBackend{}
+	// PTY runs agents on pseudo-terminals owned by ATC, rendered with the
+	// in-process screen model. Its sessions end when ATC exits.
+	PTY Backend = ptyBackend{}
+)
+
+// Default is the backend the TUI starts with. It is switched to PTY at
+// startup when tmux isn't installed.
+var Default = Tmux
+
+type tmuxBackend struct{}
+
+// Exists reports whether the tmux session exists.
+func (tmuxBackend) Exists(socket, name string) bool {
+	return SessionExists(socket, name)
+}
+
+type ptyBackend struct{}
+
+// AttachCommand returns nil: only ATC can show a PTY terminal.
+func (ptyBackend) AttachCommand(socket, name string) *exec.Cmd {
+	return nil
+}
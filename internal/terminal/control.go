@@ -0,0 +1,233 @@
+package terminal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kevinzwang/air-traffic-control/internal/logging"
+)
+
+// controlTimeout bounds how long a control-mode command may take before the
+// client is considered broken and the terminal falls back to exec'ing tmux.
+const controlTimeout = 2 * time.Second
+
+// Errors that leave the control client unusable
+var (
+	// errControlClosed is returned by commands sent after the client has exited.
+	errControlClosed = errors.New("tmux control client closed")
+	// errControlTimeout is returned when tmux doesn't answer within controlTimeout.
+	errControlTimeout = errors.New("tmux control client timed out")
+)
+
+// controlClient is a tmux control-mode client (`tmux -C`) attached to one
+// session. It reports pane output as it happens, so the terminal only
+// captures its pane when something changed, and it runs tmux commands over
+// its stdin instead of spawning a process per command.
+type controlClient struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	mu      sync.Mutex // serialises commands: replies arrive in order
+	replies chan controlReply
+
+	output chan struct{} // signalled (without blocking) on %output
+	exited chan struct{} // closed when the client's stdout ends
+	closed chan struct{} // closed by close()
+
+	closeOnce sync.Once
+}
+
+// controlReply is the output of one command, between %begin and %end/%error.
+type controlReply struct {
+	lines []string
+	err   bool
+}
+
+// startControlClient attaches a control-mode client to a session. The client
+// ignores its own size so it never resizes the window ATC renders.
+func startControlClient(socket, name string) (*controlClient, error) {
+	cmd := exec.Command("tmux", "-L", socket, "-C", "attach-session", "-f", "ignore-size", "-t", name)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := logging.Start(cmd); err != nil {
+		return nil, err
+	}
+
+	c := &controlClient{
+		cmd:     cmd,
+		stdin:   stdin,
+		replies: make(chan controlReply, 1),
+		output:  make(chan struct{}, 1),
+		exited:  make(chan struct{}),
+		closed:  make(chan struct{}),
+	}
+	go c.readLoop(stdout)
+
+	// tmux answers the attach itself with an empty reply before anything else
+	if _, err := c.await(); err != nil {
+		c.close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// readLoop parses the control-mode protocol until the client exits.
+func (c *controlClient) readLoop(r io.Reader) {
+	defer close(c.exited)
+	defer c.cmd.Wait()
+
+	br := bufio.NewReader(r)
+	var block *controlReply
+	var blockID string
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		if block != nil {
+			// Guard lines carry the same time and command number as %begin
+			end, isEnd := strings.CutPrefix(line, "%end ")
+			failed, isError := strings.CutPrefix(line, "%error ")
+			switch {
+			case isEnd && guardID(end) == blockID, isError && guardID(failed) == blockID:
+				block.err = isError
+				select {
+				case c.replies <- *block:
+				case <-c.closed:
+					return
+				}
+				block = nil
+			default:
+				block.lines = append(block.lines, line)
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "%begin "):
+			block = &controlReply{}
+			blockID = guardID(strings.TrimPrefix(line, "%begin "))
+		case strings.HasPrefix(line, "%output "), strings.HasPrefix(line, "%extended-output "):
+			select {
+			case c.output <- struct{}{}:
+			default:
+			}
+		case strings.HasPrefix(line, "%exit"):
+			return
+		}
+	}
+}
+
+// guardID returns the "time number" part of a %begin/%end/%error line.
+func guardID(rest string) string {
+	fields := strings.Fields(rest)
+	if len(fields) < 2 {
+		return rest
+	}
+	return fields[0] + " " + fields[1]
+}
+
+// await waits for the next reply.
+func (c *controlClient) await() ([]string, error) {
+	select {
+	case r := <-c.replies:
+		if r.err {
+			return nil, fmt.Errorf("tmux: %s", strings.Join(r.lines, " "))
+		}
+		return r.lines, nil
+	case <-c.exited:
+		return nil, errControlClosed
+	case <-time.After(controlTimeout):
+		// A late reply would be mistaken for the next command's
+		c.close()
+		return nil, errControlTimeout
+	}
+}
+
+// command runs a tmux command line over the control connection and returns
+// its output lines.
+func (c *controlClient) command(line string) ([]string, error) {
+	replies, err := c.commands(line)
+	if err != nil {
+		return nil, err
+	}
+	return replies[0], nil
+}
+
+// commands sends several command lines in one write and returns each one's
+// output lines, in order. It fails if any of them does.
+func (c *controlClient) commands(lines ...string) ([][]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.exited:
+		return nil, errControlClosed
+	default:
+	}
+	if _, err := io.WriteString(c.stdin, strings.Join(lines, "\n")+"\n"); err != nil {
+		return nil, err
+	}
+
+	// Every reply must be read, even after a failure, to stay in step
+	replies := make([][]string, len(lines))
+	var failed error
+	for i := range lines {
+		out, err := c.await()
+		if errors.Is(err, errControlClosed) || errors.Is(err, errControlTimeout) {
+			return nil, err
+		}
+		if err != nil && failed == nil {
+			failed = err
+		}
+		replies[i] = out
+	}
+	if failed != nil {
+		return nil, failed
+	}
+	return replies, nil
+}
+
+// alive reports whether the client is still attached.
+func (c *controlClient) alive() bool {
+	select {
+	case <-c.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// close detaches the client. The session keeps running.
+func (c *controlClient) close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		// An empty line or EOF detaches a control client
+		c.stdin.Close()
+		go func() {
+			select {
+			case <-c.exited:
+			case <-time.After(controlTimeout):
+				c.cmd.Process.Kill()
+			}
+		}()
+	})
+}
+
+// quoteArg quotes s for tmux's command parser.
+func quoteArg(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,93 @@
+package terminal
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestControlReadLoop(t *testing.T) {
+	stream := strings.Join([]string{
+		"%begin 1700000000 10 0",
+		"%end 1700000000 10 0",
+		"%session-changed $0 demo",
+		`%output %0 hello\015\012`,
+		"%begin 1700000000 11 1",
+		"line one",
+		"%end 1 2 3", // not this block's guard
+		"%end 1700000000 11 1",
+		"%begin 1700000000 12 1",
+		"can't find pane: %9",
+		"%error 1700000000 12 1",
+		"%exit",
+		"",
+	}, "\n")
+
+	c := &controlClient{
+		cmd:     exec.Command("true"),
+		replies: make(chan controlReply, 3),
+		output:  make(chan struct{}, 1),
+		exited:  make(chan struct{}),
+		closed:  make(chan struct{}),
+	}
+	c.readLoop(strings.NewReader(stream))
+
+	want := []controlReply{
+		{},
+		{lines: []string{"line one", "%end 1 2 3"}},
+		{lines: []string{"can't find pane: %9"}, err: true},
+	}
+	for i, w := range want {
+		got := <-c.replies
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("reply %d = %+v, want %+v", i, got, w)
+		}
+	}
+	select {
+	case <-c.output:
+	default:
+		t.Error("output notification was not reported")
+	}
+	if c.alive() {
+		t.Error("client still alive after exit notification")
+	}
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestControlCommands(t *testing.T) {
+	var sent bytes.Buffer
+	c := &controlClient{
+		stdin:   nopWriteCloser{&sent},
+		replies: make(chan controlReply, 4),
+		exited:  make(chan struct{}),
+	}
+
+	c.replies <- controlReply{lines: []string{"a", "b"}}
+	c.replies <- controlReply{lines: []string{"0 1"}}
+	got, err := c.commands("capture-pane -p", "display-message -p x")
+	if err != nil {
+		t.Fatalf("commands: %v", err)
+	}
+	if want := [][]string{{"a", "b"}, {"0 1"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replies = %q, want %q", got, want)
+	}
+	if want := "capture-pane -p\ndisplay-message -p x\n"; sent.String() != want {
+		t.Errorf("sent %q, want %q", sent.String(), want)
+	}
+
+	// A failed command still consumes every reply
+	c.replies <- controlReply{lines: []string{"no pane"}, err: true}
+	c.replies <- controlReply{lines: []string{"0 0"}}
+	if _, err := c.commands("capture-pane -p", "display-message -p x"); err == nil {
+		t.Error("commands succeeded despite an error reply")
+	}
+	if len(c.replies) != 0 {
+		t.Errorf("%d replies left unread", len(c.replies))
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package terminal
+
+import "syscall"
+
+// mkfifo creates a FIFO only the user can read and write.
+func mkfifo(path string) error {
+	return syscall.Mkfifo(path, 0600)
+}
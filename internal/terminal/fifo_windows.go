@@ -0,0 +1,10 @@
+//go:build windows
+
+package terminal
+
+import "errors"
+
+// mkfifo fails: Windows has no FIFOs, and no tmux to write to one.
+func mkfifo(path string) error {
+	return errors.New("pipe output mode needs FIFOs, which Windows doesn't have")
+}
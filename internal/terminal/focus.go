@@ -0,0 +1,95 @@
+package terminal
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Terminals the user isn't looking at are refreshed less often, so a dozen
+// background sessions cost little CPU.
+var (
+	// BackgroundPollInterval is how often terminals that aren't on screen
+	// are refreshed.
+	BackgroundPollInterval = 500 * time.Millisecond
+	// BlurredPollInterval is how often every terminal is refreshed while
+	// ATC's window doesn't have focus.
+	BlurredPollInterval = 2 * time.Second
+)
+
+// exitedPollInterval is how often a polled pane whose agent has exited is
+// checked for a respawn.
+const exitedPollInterval = 500 * time.Millisecond
+
+// window tracks whether ATC's window has focus.
+var window = struct {
+	sync.Mutex
+	focused  bool
+	regained chan struct{} // closed (and replaced) when focus comes back
+}{focused: true, regained: make(chan struct{})}
+
+// SetWindowFocused records whether ATC's window has focus. Regaining focus
+// wakes every terminal so it catches up straight away.
+func SetWindowFocused(focused bool) {
+	window.Lock()
+	defer window.Unlock()
+	if focused && !window.focused {
+		close(window.regained)
+		window.regained = make(chan struct{})
+	}
+	window.focused = focused
+}
+
+// windowFocus returns whether the window has focus and a channel that is
+// closed when it next regains focus.
+func windowFocus() (bool, <-chan struct{}) {
+	window.Lock()
+	defer window.Unlock()
+	return window.focused, window.regained
+}
+
+// focusState tracks whether a terminal is on screen. Terminals embed it.
+type focusState struct {
+	focused atomic.Bool
+	wake    chan struct{} // signalled (without blocking) when focused
+}
+
+// initFocus starts the terminal off focused, as terminals are created for
+// the session being shown.
+func (f *focusState) initFocus() {
+	f.wake = make(chan struct{}, 1)
+	f.focused.Store(true)
+}
+
+// SetFocused records whether the terminal is on screen.
+func (f *focusState) SetFocused(focused bool) {
+	if !f.focused.Swap(focused) && focused {
+		f.wakeUp()
+	}
+}
+
+// wakeUp makes the terminal refresh now rather than at its next interval.
+func (f *focusState) wakeUp() {
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
+
+// interval returns how often the terminal should be refreshed.
+func (f *focusState) interval() time.Duration {
+	windowFocused, _ := windowFocus()
+	switch {
+	case !windowFocused:
+		return max(BlurredPollInterval, PollInterval)
+	case !f.focused.Load():
+		return max(BackgroundPollInterval, PollInterval)
+	}
+	return PollInterval
+}
+
+// idleInterval returns how often a terminal that is told about output
+// refreshes without any, to notice the agent going idle or exiting.
+func (f *focusState) idleInterval() time.Duration {
+	return max(idleRefreshInterval, f.interval())
+}
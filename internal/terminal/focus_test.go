@@ -0,0 +1,52 @@
+package terminal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFocusInterval(t *testing.T) {
+	tests := []struct {
+		name          string
+		window, shown bool
+		want          time.Duration
+	}{
+		{"on screen", true, true, PollInterval},
+		{"in the background", true, false, BackgroundPollInterval},
+		{"window unfocused", false, true, BlurredPollInterval},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetWindowFocused(tt.window)
+			defer SetWindowFocused(true)
+
+			var f focusState
+			f.initFocus()
+			f.SetFocused(tt.shown)
+			if got := f.interval(); got != tt.want {
+				t.Errorf("interval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFocusWakes(t *testing.T) {
+	var f focusState
+	f.initFocus()
+	f.SetFocused(false)
+	f.SetFocused(true)
+	select {
+	case <-f.wake:
+	default:
+		t.Error("refocusing didn't wake the terminal")
+	}
+
+	SetWindowFocused(false)
+	_, regained := windowFocus()
+	SetWindowFocused(true)
+	select {
+	case <-regained:
+	default:
+		t.Error("regaining window focus didn't wake terminals")
+	}
+}
@@ -0,0 +1,74 @@
+package terminal
+
+import (
+	"fmt"
+	"os/exec"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// WantsMouse reports whether the program in the shown window turned on mouse
+// reporting with SGR encoding.
+func (t *tmuxTerminal) WantsMouse() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.wantsMouse
+}
+
+// SendMouse writes a mouse event to the shown window as an SGR mouse
+// sequence, the way a terminal reports it to the program.
+func (t *tmuxTerminal) SendMouse(msg tea.MouseMsg, col, row int) {
+	seq := sgrMouse(msg, col, row)
+	if seq == "" {
+		return
+	}
+	t.keysMu.Lock()
+	defer t.keysMu.Unlock()
+	t.flushKeysLocked()
+	exec.Command("tmux", "-L", t.socket, "send-keys", "-t", t.shownTarget(), "-l", seq).Run()
+}
+
+// sgrMouse encodes a mouse event at a 0-based cell as an SGR (mode 1006)
+// sequence, or returns "" for events SGR can't express.
+func sgrMouse(msg tea.MouseMsg, col, row int) string {
+	var b int
+	switch msg.Button {
+	case tea.MouseButtonLeft:
+		b = 0
+	case tea.MouseButtonMiddle:
+		b = 1
+	case tea.MouseButtonRight:
+		b = 2
+	case tea.MouseButtonNone:
+		// Motion with no button held
+		b = 3
+	case tea.MouseButtonWheelUp:
+		b = 64
+	case tea.MouseButtonWheelDown:
+		b = 65
+	case tea.MouseButtonWheelLeft:
+		b = 66
+	case tea.MouseButtonWheelRight:
+		b = 67
+	default:
+		return ""
+	}
+	if msg.Shift {
+		b += 4
+	}
+	if msg.Alt {
+		b += 8
+	}
+	if msg.Ctrl {
+		b += 16
+	}
+
+	final := 'M'
+	switch msg.Action {
+	case tea.MouseActionMotion:
+		b += 32
+	case tea.MouseActionRelease:
+		final = 'm'
+	}
+	return fmt.Sprintf("\x1b[<%d;%d;%d%c", b, col+1, row+1, final)
+}
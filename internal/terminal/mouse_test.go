@@ -0,0 +1,29 @@
+package terminal
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSGRMouse(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  tea.MouseMsg
+		want string
+	}{
+		{"left press", tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}, "\x1b[<0;3;2M"},
+		{"left release", tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionRelease}, "\x1b[<0;3;2m"},
+		{"drag", tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionMotion}, "\x1b[<32;3;2M"},
+		{"ctrl+right", tea.MouseMsg{Button: tea.MouseButtonRight, Action: tea.MouseActionPress, Ctrl: true}, "\x1b[<18;3;2M"},
+		{"wheel down", tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress}, "\x1b[<65;3;2M"},
+		{"back button", tea.MouseMsg{Button: tea.MouseButtonBackward, Action: tea.MouseActionPress}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sgrMouse(tt.msg, 2, 1); got != tt.want {
+				t.Errorf("sgrMouse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package terminal
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/kevinzwang/air-traffic-control/internal/logging"
+)
+
+// Output modes select how a terminal learns about new pane output.
+const (
+	// OutputControl refreshes the pane when a tmux control-mode client
+	// reports output (the default).
+	OutputControl = "control"
+	// OutputPipe streams the pane's output through `tmux pipe-pane` into a
+	// FIFO and renders it with an in-process screen model.
+	OutputPipe = "pipe"
+	// OutputPoll captures the pane every PollInterval.
+	OutputPoll = "poll"
+)
+
+// OutputMode is how terminals watch their panes. It is set from the user
+// config at startup. Modes that can't start fall back to polling.
+var OutputMode = OutputControl
+
+// pipeStream is a pane's output stream: tmux copies everything the pane
+// prints into a FIFO, and the stream feeds it to a screen model.
+type pipeStream struct {
+	fifo string
+	file *os.File
+
+	mu     sync.Mutex // guards screen
+	screen *screen
+
+	output chan struct{} // signalled (without blocking) when bytes arrive
+}
+
+// startPipe seeds a screen model from the pane and starts streaming the
+// pane's output into it.
+func (t *tmuxTerminal) startPipe(width, height int) error {
+	hash := sha256.Sum256([]byte(t.socket + "\x00" + t.name))
+	fifo := filepath.Join(os.TempDir(), fmt.Sprintf("%s-%x.fifo", t.socket, hash[:6]))
+	os.Remove(fifo)
+	if err := mkfifo(fifo); err != nil {
+		return fmt.Errorf("failed to create fifo: %w", err)
+	}
+	// Opening read-write keeps a writer around, so reads never see EOF
+	// between pipe-pane commands and the open doesn't block
+	file, err := os.OpenFile(fifo, os.O_RDWR, 0)
+	if err != nil {
+		os.Remove(fifo)
+		return fmt.Errorf("failed to open fifo: %w", err)
+	}
+
+	p := &pipeStream{
+		fifo:   fifo,
+		file:   file,
+		screen: newScreen(width, height),
+		output: make(chan struct{}, 1),
+	}
+	t.pipe = p
+	t.resyncPipe()
+
+	if err := t.pipePane(); err != nil {
+		t.pipe = nil
+		file.Close()
+		os.Remove(fifo)
+		return err
+	}
+	go p.readLoop()
+	return nil
+}
+
+// pipePane (re)starts copying the pane's output into the FIFO.
+func (t *tmuxTerminal) pipePane() error {
+	cmd := "cat >> '" + strings.ReplaceAll(t.pipe.fifo, "'", `'\''`) + "'"
+	out, err := logging.CombinedOutput(exec.Command("tmux", "-L", t.socket, "pipe-pane", "-O", "-t", t.agentTarget(), cmd))
+	if err != nil {
+		return fmt.Errorf("failed to pipe pane: %w: %s", err, string(out))
+	}
+	return nil
+}
+
+// stopPipe stops tmux copying the pane's output and removes the FIFO.
+func (t *tmuxTerminal) stopPipe() {
+	logging.Run(exec.Command("tmux", "-L", t.socket, "pipe-pane", "-t", t.agentTarget()))
+	t.pipe.file.Close()
+	os.Remove(t.pipe.fifo)
+}
+
+// resyncPipe rebuilds the screen model from the pane's real contents. The
+// model only understands common escape sequences, so it is corrected from
+// tmux whenever the agent goes quiet. It doesn't count as activity.
+func (t *tmuxTerminal) resyncPipe() {
+	agent := t.agentTarget()
+	visible := t.tmuxOutput(agent, "capture-pane", "-p", "-e")
+	history := t.tmuxOutput(agent, "capture-pane", "-p", "-e", "-S", "-", "-E", "-1")
+	cursor := strings.Fields(t.tmuxOutput(agent, "display-message", "-p", "#{cursor_x} #{cursor_y} #{pane_width} #{pane_height}"))
+	if len(cursor) != 4 {
+		return
+	}
+	var x, y, width, height int
+	fmt.Sscanf(strings.Join(cursor, " "), "%d %d %d %d", &x, &y, &width, &height)
+
+	p := t.pipe
+	p.mu.Lock()
+	p.screen.reset(width, height)
+	for i, line := range strings.Split(strings.TrimSuffix(visible, "\n"), "\n") {
+		fmt.Fprintf(p.screen, "\x1b[%dH%s\x1b[0m", i+1, line)
+	}
+	fmt.Fprintf(p.screen, "\x1b[%d;%dH", y+1, x+1)
+	if history != "" {
+		p.screen.history = strings.Split(strings.TrimSuffix(history, "\n"), "\n")
+	}
+	rendered := p.screen.render()
+	p.mu.Unlock()
+
+	if !t.usePipe() {
+		// Another window is shown; the model is kept for when it's back
+		return
+	}
+	t.mu.Lock()
+	changed := rendered != t.lastCapture
+	t.lastCapture = rendered
+	t.mu.Unlock()
+	if changed && t.program != nil {
+		t.program.Send(TerminalOutputMsg{})
+	}
+}
+
+// readLoop feeds the FIFO to the screen model until the file is closed.
+func (p *pipeStream) readLoop() {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := p.file.Read(buf)
+		if n > 0 {
+			p.mu.Lock()
+			p.screen.Write(buf[:n])
+			p.mu.Unlock()
+			select {
+			case p.output <- struct{}{}:
+			default:
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// render returns the visible screen.
+func (p *pipeStream) render() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.screen.render()
+}
+
+// lines returns screen lines in capture-pane numbering.
+func (p *pipeStream) lines(start, end int) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.screen.lines(start, end)
+}
+
+// historySize returns the number of scrollback lines.
+func (p *pipeStream) historySize() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.screen.history)
+}
+
+// resize resizes the screen model.
+func (p *pipeStream) resize(width, height int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.screen.resize(width, height)
+}
@@ -0,0 +1,512 @@
+package terminal
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/shell"
+)
+
+// ptyTerminals holds the PTY terminals that are still running, so a session
+// that was detached (e.g. when its project tab closed) can be attached again.
+var ptyTerminals = struct {
+	sync.Mutex
+	m map[string]*ptyTerminal
+}{m: make(map[string]*ptyTerminal)}
+
+// ptyKey identifies a PTY terminal the way a socket and session name
+// identify a tmux session.
+func ptyKey(socket, name string) string {
+	return socket + "/" + name
+}
+
+// ptyConn is ATC's side of a pseudo-terminal: the primary side of a PTY on
+// Unix, a pseudoconsole's pipes on Windows (see startPTY).
+type ptyConn interface {
+	io.ReadWriteCloser
+	// Resize tells the program its terminal's new size.
+	Resize(width, height int)
+	// ProcessExited is called when the program exits. A pseudoconsole keeps
+	// its output open until it is closed, so Read would never end otherwise.
+	ProcessExited()
+}
+
+// ptyTerminal is a Terminal whose agent runs directly on a PTY.
+type ptyTerminal struct {
+	key     string
+	name    string
+	dir     string
+	agent   Agent
+	program *tea.Program
+
+	mu       sync.Mutex
+	pty      ptyConn
+	proc     *os.Process
+	screen   *screen
+	exited   bool
+	detached bool
+	done     chan struct{} // closed to stop the refresh loop
+	output   chan struct{} // signalled (without blocking) when bytes arrive
+
+	// Rendering and scrollback, as in tmuxTerminal
+	lastCapture string
+	visHeight   int
+	scrollLines int
+
+	// Activity detection
+	lastActivity time.Time
+	state        AgentState
+	workTime     time.Duration
+
+	// Refresh rate, lowered while the terminal isn't on screen
+	focusState
+}
+
+// New starts the agent on a new PTY in worktreePath.
+func (ptyBackend) New(name, worktreePath string, agent Agent, width, height int, continueSession bool, p *tea.Program, socket string) (Terminal, error) {
+	t := &ptyTerminal{
+		key:          ptyKey(socket, name),
+		name:         name,
+		dir:          worktreePath,
+		agent:        agent,
+		program:      p,
+		screen:       newScreen(width, height),
+		done:         make(chan struct{}),
+		output:       make(chan struct{}, 1),
+		visHeight:    height,
+		lastActivity: time.Now(),
+	}
+	t.initFocus()
+	if err := t.spawn(continueSession, "", width, height); err != nil {
+		return nil, err
+	}
+	t.agent.Resume = ""
+
+	ptyTerminals.Lock()
+	ptyTerminals.m[t.key] = t
+	ptyTerminals.Unlock()
+
+	go t.refreshLoop(t.done)
+	return t, nil
+}
+
+// Attach resumes updating the UI for a detached PTY terminal.
+func (ptyBackend) Attach(name string, agent Agent, width, height int, p *tea.Program, socket string) (Terminal, error) {
+	ptyTerminals.Lock()
+	t := ptyTerminals.m[ptyKey(socket, name)]
+	ptyTerminals.Unlock()
+	if t == nil {
+		return nil, os.ErrNotExist
+	}
+
+	t.mu.Lock()
+	t.program = p
+	if t.detached {
+		t.detached = false
+		t.done = make(chan struct{})
+		go t.refreshLoop(t.done)
+	}
+	t.mu.Unlock()
+
+	t.Resize(width, height)
+	return t, nil
+}
+
+// Exists reports whether a PTY terminal is running for the session.
+func (ptyBackend) Exists(socket, name string) bool {
+	ptyTerminals.Lock()
+	defer ptyTerminals.Unlock()
+	_, ok := ptyTerminals.m[ptyKey(socket, name)]
+	return ok
+}
+
+// spawn starts the agent process on a fresh PTY, with any extra flags.
+func (t *ptyTerminal) spawn(continueSession bool, flags string, width, height int) error {
+	sh := t.agent.Shell
+	if sh == "" {
+		sh = shell.Default()
+	}
+	env := os.Environ()
+	if t.agent.Term != "" {
+		env = append(env, "TERM="+t.agent.Term)
+	} else {
+		env = append(env, "TERM=xterm-256color")
+	}
+	if t.agent.Locale != "" {
+		env = append(env, "LANG="+t.agent.Locale, "LC_ALL="+t.agent.Locale)
+	}
+	env = append(env, t.agent.Env...)
+	pty, proc, err := startPTY(shell.Args(sh, t.agent.withFlags(flags).command(continueSession)), t.dir, env, width, height)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.pty, t.proc, t.exited = pty, proc, false
+	t.lastActivity = time.Now()
+	t.mu.Unlock()
+
+	go t.readLoop(pty, proc)
+	return nil
+}
+
+// readLoop feeds the agent's output to the screen until the process exits.
+func (t *ptyTerminal) readLoop(pty ptyConn, proc *os.Process) {
+	waited := make(chan bool, 1)
+	go func() {
+		state, _ := proc.Wait()
+		pty.ProcessExited()
+		waited <- state == nil || !state.Success()
+	}()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := pty.Read(buf)
+		if n > 0 {
+			t.mu.Lock()
+			t.screen.Write(buf[:n])
+			t.mu.Unlock()
+			select {
+			case t.output <- struct{}{}:
+			default:
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	crashed := <-waited
+	pty.Close()
+
+	t.mu.Lock()
+	current := t.proc == proc
+	if current {
+		t.exited = true
+		t.state = StateExited
+	}
+	p, detached := t.program, t.detached
+	t.mu.Unlock()
+
+	if current && !detached && p != nil {
+		p.Send(TerminalExitedMsg{Name: t.name, Crashed: crashed})
+	}
+}
+
+// refreshLoop renders the screen when output arrives, at most once per
+// interval, and tracks the agent's activity state.
+func (t *ptyTerminal) refreshLoop(done <-chan struct{}) {
+	idle := time.NewTimer(t.idleInterval())
+	defer idle.Stop()
+
+	var throttle <-chan time.Time
+	pending := false
+	for {
+		_, regained := windowFocus()
+		select {
+		case <-done:
+			return
+		case <-t.output:
+			if throttle != nil {
+				pending = true
+				continue
+			}
+			t.refresh()
+			throttle = time.After(t.interval())
+		case <-throttle:
+			throttle = nil
+			if pending {
+				pending = false
+				t.refresh()
+				throttle = time.After(t.interval())
+			}
+		case <-t.wake:
+			t.refresh()
+		case <-regained:
+			t.refresh()
+		case <-idle.C:
+			t.refresh()
+			idle.Reset(t.idleInterval())
+		}
+	}
+}
+
+// refresh re-renders the screen and sends Bubble Tea messages for changes.
+func (t *ptyTerminal) refresh() {
+	now := time.Now()
+
+	t.mu.Lock()
+	output := t.screen.render()
+	changed := output != t.lastCapture
+	t.lastCapture = output
+	if changed {
+		t.workTime += workedSince(t.lastActivity, now)
+		t.lastActivity = now
+	}
+	prev := t.state
+	if !t.exited {
+		if now.Sub(t.lastActivity) < IdleThreshold {
+			t.state = StateWorking
+		} else {
+			t.state = StateWaiting
+		}
+	}
+	state := t.state
+	p := t.program
+	t.mu.Unlock()
+
+	if p == nil {
+		return
+	}
+	if changed {
+		p.Send(TerminalOutputMsg{})
+	}
+	if state != prev && state != StateExited {
+		p.Send(TerminalStateMsg{Name: t.name, State: state})
+	}
+}
+
+// TakeWorkTime returns the agent's working time since the last call.
+func (t *ptyTerminal) TakeWorkTime() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	d := t.workTime
+	t.workTime = 0
+	return d
+}
+
+// Name returns the ATC session name.
+func (t *ptyTerminal) Name() string {
+	return t.name
+}
+
+// SendKeys writes the key's bytes to the PTY.
+func (t *ptyTerminal) SendKeys(msg tea.KeyMsg) {
+	b := keyMsgBytes(msg)
+	if b == "" {
+		return
+	}
+	t.mu.Lock()
+	pty := t.pty
+	t.mu.Unlock()
+	io.WriteString(pty, b)
+}
+
+// Paste writes text to the PTY the way a terminal would, with carriage
+// returns for line breaks, wrapped in bracketed-paste markers if the program
+// turned the mode on.
+func (t *ptyTerminal) Paste(text string) {
+	if text == "" {
+		return
+	}
+	text = strings.ReplaceAll(pasteText(text), "\n", "\r")
+	t.mu.Lock()
+	pty := t.pty
+	if t.screen.bracketedPaste {
+		text = "\x1b[200~" + text + "\x1b[201~"
+	}
+	t.mu.Unlock()
+	io.WriteString(pty, text)
+}
+
+// WantsMouse reports whether the program turned on mouse reporting with SGR
+// encoding.
+func (t *ptyTerminal) WantsMouse() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.screen.mouseTracking && t.screen.sgrMouse
+}
+
+// SendMouse writes a mouse event to the PTY as an SGR mouse sequence.
+func (t *ptyTerminal) SendMouse(msg tea.MouseMsg, col, row int) {
+	seq := sgrMouse(msg, col, row)
+	if seq == "" {
+		return
+	}
+	t.mu.Lock()
+	pty := t.pty
+	t.mu.Unlock()
+	io.WriteString(pty, seq)
+}
+
+// Render returns the visible screen, or the scrolled-back view.
+func (t *ptyTerminal) Render() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.scrollLines == 0 {
+		return strings.TrimRight(t.screen.render(), "\n")
+	}
+	return strings.TrimRight(t.screen.lines(-t.scrollLines, -t.scrollLines+t.visHeight-1), "\n")
+}
+
+// Resize changes the PTY and screen size.
+func (t *ptyTerminal) Resize(width, height int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.visHeight = height
+	t.screen.resize(width, height)
+	if !t.exited {
+		t.pty.Resize(width, height)
+	}
+}
+
+// State returns the current agent activity state.
+func (t *ptyTerminal) State() AgentState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state
+}
+
+// IsRunning returns true if the agent process is still alive.
+func (t *ptyTerminal) IsRunning() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !t.exited
+}
+
+// PID returns the agent's process ID.
+func (t *ptyTerminal) PID() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.exited || t.proc == nil {
+		return 0
+	}
+	return t.proc.Pid
+}
+
+// Respawn starts the agent again on a new PTY, keeping the screen.
+func (t *ptyTerminal) Respawn(continueSession bool, flags string) error {
+	t.mu.Lock()
+	proc, exited := t.proc, t.exited
+	width, height := t.screen.width, t.screen.height
+	t.mu.Unlock()
+	if !exited {
+		proc.Kill()
+	}
+	return t.spawn(continueSession, flags, width, height)
+}
+
+// Close kills the agent and forgets the terminal.
+func (t *ptyTerminal) Close() error {
+	t.Detach()
+
+	ptyTerminals.Lock()
+	delete(ptyTerminals.m, t.key)
+	ptyTerminals.Unlock()
+
+	t.mu.Lock()
+	proc, exited := t.proc, t.exited
+	t.mu.Unlock()
+	if !exited {
+		proc.Kill()
+	}
+	return nil
+}
+
+// Detach stops sending UI updates. The agent keeps running until ATC exits.
+func (t *ptyTerminal) Detach() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.detached {
+		t.detached = true
+		close(t.done)
+	}
+}
+
+// Scrollback returns the screen's history and visible lines.
+func (t *ptyTerminal) Scrollback() (string, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	histSize := len(t.screen.history)
+	return t.screen.lines(-histSize, t.screen.height-1), histSize
+}
+
+// ScrollUp scrolls back by the given number of lines.
+func (t *ptyTerminal) ScrollUp(lines int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.scrollLines = min(t.scrollLines+lines, len(t.screen.history))
+}
+
+// ScrollDown scrolls forward by the given number of lines.
+func (t *ptyTerminal) ScrollDown(lines int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.scrollLines = max(t.scrollLines-lines, 0)
+}
+
+// IsScrollMode returns true if the terminal is scrolled back.
+func (t *ptyTerminal) IsScrollMode() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.scrollLines > 0
+}
+
+// ScrollPosition returns the current scroll offset in lines.
+func (t *ptyTerminal) ScrollPosition() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.scrollLines
+}
+
+// ExitScrollMode returns to the live view.
+func (t *ptyTerminal) ExitScrollMode() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.scrollLines = 0
+}
+
+// errNoWindows is returned by OpenWindow: a PTY terminal has only the agent.
+var errNoWindows = errors.New("windows need tmux")
+
+// OpenWindow is unsupported without tmux.
+func (t *ptyTerminal) OpenWindow(name, command string) error {
+	return errNoWindows
+}
+
+// ShowAgent does nothing: the agent is always shown.
+func (t *ptyTerminal) ShowAgent() error {
+	return nil
+}
+
+// Window returns "", as only the agent is ever shown.
+func (t *ptyTerminal) Window() string {
+	return ""
+}
+
+// Windows returns nil: there are no windows to switch between.
+func (t *ptyTerminal) Windows() []string {
+	return nil
+}
+
+// CycleWindow is unsupported without tmux.
+func (t *ptyTerminal) CycleWindow(forward bool) error {
+	return errNoWindows
+}
+
+// keyMsgBytes returns the bytes a terminal sends for a key press.
+func keyMsgBytes(msg tea.KeyMsg) string {
+	var s string
+	switch {
+	case msg.Type == tea.KeyRunes:
+		s = string(msg.Runes)
+	case keyByte(msg.Type) != 0:
+		s = string([]byte{keyByte(msg.Type)})
+	case keySequence(msg.Type) != "":
+		if msg.Alt {
+			return addAltModifier(keySequence(msg.Type))
+		}
+		return keySequence(msg.Type)
+	default:
+		return ""
+	}
+	if msg.Alt {
+		s = "\x1b" + s
+	}
+	return s
+}
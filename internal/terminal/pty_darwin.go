@@ -0,0 +1,51 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a new pseudo-terminal pair. The primary side is left
+// non-blocking so Close interrupts a pending Read.
+func openPTY() (ptmx, tty *os.File, err error) {
+	ptmx, err = os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open pty: %w", err)
+	}
+	conn, err := ptmx.SyscallConn()
+	if err != nil {
+		ptmx.Close()
+		return nil, nil, err
+	}
+	name := make([]byte, 128)
+	var ioctlErr error
+	conn.Control(func(fd uintptr) {
+		for _, req := range []uint{unix.TIOCPTYGRANT, unix.TIOCPTYUNLK} {
+			if _, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, uintptr(req), 0); errno != 0 {
+				ioctlErr = errno
+				return
+			}
+		}
+		if _, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, uintptr(unix.TIOCPTYGNAME), uintptr(unsafe.Pointer(&name[0]))); errno != 0 {
+			ioctlErr = errno
+		}
+	})
+	if ioctlErr != nil {
+		ptmx.Close()
+		return nil, nil, fmt.Errorf("failed to unlock pty: %w", ioctlErr)
+	}
+	if i := bytes.IndexByte(name, 0); i >= 0 {
+		name = name[:i]
+	}
+
+	tty, err = os.OpenFile(string(name), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		ptmx.Close()
+		return nil, nil, fmt.Errorf("failed to open pty: %w", err)
+	}
+	return ptmx, tty, nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !windows
+
+package terminal
+
+import (
+	"errors"
+	"os"
+)
+
+var errPTYUnsupported = errors.New("embedded terminals are not supported on this platform; install tmux")
+
+func startPTY(argv []string, dir string, env []string, width, height int) (ptyConn, *os.Process, error) {
+	return nil, nil, errPTYUnsupported
+}
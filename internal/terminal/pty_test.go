@@ -0,0 +1,54 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKeyMsgBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  tea.KeyMsg
+		want string
+	}{
+		{"runes", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("hé")}, "hé"},
+		{"Alt+rune", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b"), Alt: true}, "\x1bb"},
+		{"Enter", tea.KeyMsg{Type: tea.KeyEnter}, "\r"},
+		{"Ctrl+C", tea.KeyMsg{Type: tea.KeyCtrlC}, "\x03"},
+		{"Alt+Backspace", tea.KeyMsg{Type: tea.KeyBackspace, Alt: true}, "\x1b\x7f"},
+		{"Up", tea.KeyMsg{Type: tea.KeyUp}, "\x1b[A"},
+		{"Alt+Left", tea.KeyMsg{Type: tea.KeyLeft, Alt: true}, "\x1b[1;3D"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keyMsgBytes(tt.msg); got != tt.want {
+				t.Errorf("keyMsgBytes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPTYTerminal(t *testing.T) {
+	term, err := PTY.New("test", t.TempDir(), Agent{Command: "read line; echo got $line"}, 40, 5, false, nil, "atc-test")
+	if err != nil {
+		t.Skipf("pty unavailable: %v", err)
+	}
+	defer term.Close()
+
+	term.SendKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("hi")})
+	term.SendKeys(tea.KeyMsg{Type: tea.KeyEnter})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for term.IsRunning() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if term.IsRunning() {
+		t.Fatal("agent did not exit")
+	}
+	if got := term.Render(); !strings.Contains(got, "got hi") {
+		t.Errorf("Render() = %q, want it to contain %q", got, "got hi")
+	}
+}
@@ -0,0 +1,61 @@
+//go:build linux || darwin
+
+package terminal
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// unixPTY is the primary side of a Unix PTY.
+type unixPTY struct {
+	*os.File
+}
+
+// Resize sets the PTY's window size, which signals the program.
+func (p unixPTY) Resize(width, height int) {
+	setWinsize(p.File, width, height)
+}
+
+// ProcessExited does nothing: reads fail once the program's side is closed.
+func (unixPTY) ProcessExited() {}
+
+// startPTY runs argv in dir on a new PTY of the given size.
+func startPTY(argv []string, dir string, env []string, width, height int) (ptyConn, *os.Process, error) {
+	ptmx, tty, err := openPTY()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tty.Close()
+	setWinsize(ptmx, width, height)
+
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+	setControllingTTY(cmd)
+	if err := cmd.Start(); err != nil {
+		ptmx.Close()
+		return nil, nil, err
+	}
+	return unixPTY{ptmx}, cmd.Process, nil
+}
+
+// setWinsize tells the PTY (and so the agent) its size.
+func setWinsize(ptmx *os.File, width, height int) {
+	ws := &unix.Winsize{Row: uint16(height), Col: uint16(width)}
+	if conn, err := ptmx.SyscallConn(); err == nil {
+		conn.Control(func(fd uintptr) {
+			unix.IoctlSetWinsize(int(fd), unix.TIOCSWINSZ, ws)
+		})
+	}
+}
+
+// setControllingTTY starts cmd in a new session with its stdin as the
+// controlling terminal, so job control and ^C work as in a real terminal.
+func setControllingTTY(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+}
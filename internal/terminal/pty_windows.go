@@ -0,0 +1,156 @@
+//go:build windows
+
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// conPTY is a Windows pseudoconsole (ConPTY) and the pipes to and from it.
+type conPTY struct {
+	console   windows.Handle
+	closeOnce sync.Once
+	in        *os.File // the program's input
+	out       *os.File // the program's output, as VT sequences
+}
+
+func (c *conPTY) Read(p []byte) (int, error) {
+	return c.out.Read(p)
+}
+
+func (c *conPTY) Write(p []byte) (int, error) {
+	return c.in.Write(p)
+}
+
+// Resize changes the pseudoconsole's size.
+func (c *conPTY) Resize(width, height int) {
+	windows.ResizePseudoConsole(c.console, windows.Coord{X: int16(width), Y: int16(height)})
+}
+
+// ProcessExited closes the pseudoconsole, which flushes its last output and
+// then closes the output pipe so Read ends.
+func (c *conPTY) ProcessExited() {
+	c.closeOnce.Do(func() { windows.ClosePseudoConsole(c.console) })
+}
+
+// Close closes the pseudoconsole and the pipes.
+func (c *conPTY) Close() error {
+	c.ProcessExited()
+	c.in.Close()
+	return c.out.Close()
+}
+
+// startPTY runs argv in dir on a new pseudoconsole of the given size. The
+// process has to be created by hand: os/exec can't hand it a pseudoconsole.
+func startPTY(argv []string, dir string, env []string, width, height int) (ptyConn, *os.Process, error) {
+	var inRead, inWrite, outRead, outWrite windows.Handle
+	if err := windows.CreatePipe(&inRead, &inWrite, nil, 0); err != nil {
+		return nil, nil, fmt.Errorf("failed to create pipe: %w", err)
+	}
+	if err := windows.CreatePipe(&outRead, &outWrite, nil, 0); err != nil {
+		windows.CloseHandle(inRead)
+		windows.CloseHandle(inWrite)
+		return nil, nil, fmt.Errorf("failed to create pipe: %w", err)
+	}
+
+	var console windows.Handle
+	err := windows.CreatePseudoConsole(windows.Coord{X: int16(width), Y: int16(height)}, inRead, outWrite, 0, &console)
+	// The pseudoconsole holds its own copies of its ends of the pipes
+	windows.CloseHandle(inRead)
+	windows.CloseHandle(outWrite)
+	if err != nil {
+		windows.CloseHandle(inWrite)
+		windows.CloseHandle(outRead)
+		return nil, nil, fmt.Errorf("failed to create pseudoconsole: %w", err)
+	}
+	pty := &conPTY{
+		console: console,
+		in:      os.NewFile(uintptr(inWrite), "conpty-in"),
+		out:     os.NewFile(uintptr(outRead), "conpty-out"),
+	}
+
+	proc, err := createConsoleProcess(console, argv, dir, env)
+	if err != nil {
+		pty.Close()
+		return nil, nil, err
+	}
+	return pty, proc, nil
+}
+
+// createConsoleProcess starts argv attached to a pseudoconsole.
+func createConsoleProcess(console windows.Handle, argv []string, dir string, env []string) (*os.Process, error) {
+	attrs, err := windows.NewProcThreadAttributeList(1)
+	if err != nil {
+		return nil, err
+	}
+	defer attrs.Delete()
+	// The attribute's value is the handle itself, not a pointer to it
+	if err := attrs.Update(windows.PROC_THREAD_ATTRIBUTE_PSEUDOCONSOLE,
+		*(*unsafe.Pointer)(unsafe.Pointer(&console)), unsafe.Sizeof(console)); err != nil {
+		return nil, fmt.Errorf("failed to attach pseudoconsole: %w", err)
+	}
+
+	si := &windows.StartupInfoEx{ProcThreadAttributeList: attrs.List()}
+	si.Cb = uint32(unsafe.Sizeof(*si))
+	// Without this the program would use ATC's own standard handles
+	si.Flags |= windows.STARTF_USESTDHANDLES
+
+	cmdLine, err := windows.UTF16PtrFromString(windows.ComposeCommandLine(argv))
+	if err != nil {
+		return nil, err
+	}
+	dirPtr, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return nil, err
+	}
+	var pi windows.ProcessInformation
+	err = windows.CreateProcess(nil, cmdLine, nil, nil, false,
+		windows.EXTENDED_STARTUPINFO_PRESENT|windows.CREATE_UNICODE_ENVIRONMENT,
+		envBlock(env), dirPtr, &si.StartupInfo, &pi)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", argv[0], err)
+	}
+	defer windows.CloseHandle(pi.Process)
+	windows.CloseHandle(pi.Thread)
+
+	// Holding pi.Process until this returns keeps the process ID from being
+	// reused before os.FindProcess opens its own handle
+	return os.FindProcess(int(pi.ProcessId))
+}
+
+// envBlock encodes env as a Unicode environment block: NUL-terminated
+// "name=value" strings, ended by another NUL. Like os/exec, a later value
+// for a name replaces an earlier one.
+func envBlock(env []string) *uint16 {
+	last := make(map[string]int, len(env))
+	for i, kv := range env {
+		last[envName(kv)] = i
+	}
+	var block []uint16
+	for i, kv := range env {
+		if last[envName(kv)] != i {
+			continue
+		}
+		block = append(block, utf16.Encode([]rune(kv))...)
+		block = append(block, 0)
+	}
+	block = append(block, 0, 0)
+	return &block[0]
+}
+
+// envName returns the name of a "name=value" entry in the case-insensitive
+// form Windows compares them in. Hidden per-drive entries such as "=C:=C:\"
+// start with '=', which belongs to the name.
+func envName(kv string) string {
+	if i := strings.Index(kv[min(len(kv), 1):], "="); i >= 0 {
+		kv = kv[:i+1]
+	}
+	return strings.ToUpper(kv)
+}
@@ -0,0 +1,650 @@
+package terminal
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mattn/go-runewidth"
+)
+
+// maxScreenHistory caps the scrollback kept by a screen, matching the
+// history-limit ATC sets on its tmux sessions.
+const maxScreenHistory = 50000
+
+// pen is the set of SGR attributes applied to printed characters.
+type pen struct {
+	attrs uint16 // bit n set = SGR n (1 bold … 9 strikethrough)
+	fg    string // SGR color parameters, e.g. "31" or "38;5;208"; "" = default
+	bg    string
+}
+
+// sgr renders the pen as a complete SGR sequence, starting from a reset.
+func (p pen) sgr() string {
+	var b strings.Builder
+	b.WriteString("\x1b[0")
+	for n := 1; n <= 9; n++ {
+		if p.attrs&(1<<n) != 0 {
+			b.WriteString(";" + strconv.Itoa(n))
+		}
+	}
+	if p.fg != "" {
+		b.WriteString(";" + p.fg)
+	}
+	if p.bg != "" {
+		b.WriteString(";" + p.bg)
+	}
+	b.WriteString("m")
+	return b.String()
+}
+
+// cell is one column of the screen. Wide characters occupy two cells; the
+// second has width 0 and no content.
+type cell struct {
+	content string
+	width   int
+	pen     pen
+}
+
+// blank is an empty cell.
+var blank = cell{content: " ", width: 1}
+
+// screen is a small VT100/xterm screen model. It is fed a pane's raw output
+// and renders the visible screen and its scrollback the way capture-pane -e
+// would, so a terminal can be rendered without asking tmux.
+type screen struct {
+	width, height int
+	rows          [][]cell
+	history       []string // rendered lines scrolled off the top of the main screen
+
+	x, y     int
+	wrapNext bool // the last print filled the line; wrap before the next one
+	autowrap bool
+	pen      pen
+	top, bot int // scroll region, inclusive
+	last     rune
+
+	savedX, savedY int
+	savedPen       pen
+
+	// Alternate screen; the main screen's rows are kept in mainRows
+	alt      bool
+	mainRows [][]cell
+
+	// The program asked for pastes to be wrapped in markers (mode 2004)
+	bracketedPaste bool
+
+	// The program turned on mouse reporting (modes 1000, 1002, 1003) and
+	// asked for it in SGR encoding (mode 1006)
+	mouseTracking, sgrMouse bool
+
+	parser *ansi.Parser
+}
+
+// newScreen returns a blank screen of the given size.
+func newScreen(width, height int) *screen {
+	s := &screen{}
+	s.parser = ansi.NewParser()
+	s.parser.SetHandler(ansi.Handler{
+		Print:     s.print,
+		Execute:   s.execute,
+		HandleCsi: s.csi,
+		HandleEsc: s.esc,
+	})
+	s.reset(width, height)
+	return s
+}
+
+// reset clears the screen, scrollback and modes.
+func (s *screen) reset(width, height int) {
+	s.width, s.height = max(width, 1), max(height, 1)
+	s.rows = blankRows(s.width, s.height)
+	s.history = nil
+	s.x, s.y, s.wrapNext = 0, 0, false
+	s.autowrap = true
+	s.pen = pen{}
+	s.top, s.bot = 0, s.height-1
+	s.alt, s.mainRows = false, nil
+	s.parser.Reset()
+}
+
+// Write feeds raw pane output to the screen.
+func (s *screen) Write(p []byte) (int, error) {
+	s.parser.Parse(p)
+	return len(p), nil
+}
+
+// resize changes the screen size, dropping rows from the top into the
+// scrollback when the screen gets shorter. It doesn't reflow wrapped lines.
+func (s *screen) resize(width, height int) {
+	width, height = max(width, 1), max(height, 1)
+	for len(s.rows) > height {
+		if s.y > 0 {
+			s.pushHistory(s.rows[0])
+			s.rows = s.rows[1:]
+			s.y--
+		} else {
+			s.rows = s.rows[:len(s.rows)-1]
+		}
+	}
+	for len(s.rows) < height {
+		s.rows = append(s.rows, blankRow(width))
+	}
+	for i, row := range s.rows {
+		s.rows[i] = resizeRow(row, width)
+	}
+	s.width, s.height = width, height
+	s.top, s.bot = 0, height-1
+	s.x, s.y = min(s.x, width-1), min(s.y, height-1)
+	s.wrapNext = false
+}
+
+// render returns the visible screen, one line per row each ending in a
+// newline, with trailing blanks trimmed.
+func (s *screen) render() string {
+	var b strings.Builder
+	for _, row := range s.rows {
+		b.WriteString(renderRow(row))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// lines returns rendered lines in capture-pane numbering: 0 is the top of
+// the visible screen and negative lines are scrollback.
+func (s *screen) lines(start, end int) string {
+	all := len(s.history) + len(s.rows)
+	from := max(len(s.history)+start, 0)
+	to := min(len(s.history)+end, all-1)
+	var b strings.Builder
+	for i := from; i <= to; i++ {
+		if i < len(s.history) {
+			b.WriteString(s.history[i])
+		} else {
+			b.WriteString(renderRow(s.rows[i-len(s.history)]))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// print writes a character at the cursor.
+func (s *screen) print(r rune) {
+	w := runewidth.RuneWidth(r)
+	if w == 0 {
+		// Combining character: attach it to the previous cell
+		x := s.x - 1
+		if s.wrapNext {
+			x = s.x
+		}
+		if x >= 0 && s.rows[s.y][x].width == 0 && x > 0 {
+			x--
+		}
+		if x >= 0 {
+			s.rows[s.y][x].content += string(r)
+		}
+		return
+	}
+	s.last = r
+
+	if s.wrapNext || s.x+w > s.width {
+		if !s.autowrap {
+			s.x = s.width - w
+		} else {
+			s.x = 0
+			s.lineFeed()
+		}
+		s.wrapNext = false
+	}
+	s.setCell(s.x, cell{content: string(r), width: w, pen: s.pen})
+	if w == 2 && s.x+1 < s.width {
+		s.setCell(s.x+1, cell{width: 0, pen: s.pen})
+	}
+	s.x += w
+	if s.x >= s.width {
+		s.x = s.width - 1
+		s.wrapNext = true
+	}
+}
+
+// setCell replaces a cell on the cursor row, blanking the other half of any
+// wide character it overwrites.
+func (s *screen) setCell(x int, c cell) {
+	row := s.rows[s.y]
+	if row[x].width == 0 && x > 0 && c.width != 0 {
+		row[x-1] = blank
+	}
+	if row[x].width == 2 && x+1 < len(row) && c.width != 2 {
+		row[x+1] = blank
+	}
+	row[x] = c
+}
+
+// execute handles a C0 control character.
+func (s *screen) execute(b byte) {
+	switch b {
+	case '\r':
+		s.x, s.wrapNext = 0, false
+	case '\n', '\v', '\f':
+		s.lineFeed()
+	case '\b':
+		if s.x > 0 {
+			s.x--
+		}
+		s.wrapNext = false
+	case '\t':
+		s.x = min((s.x/8+1)*8, s.width-1)
+	}
+}
+
+// lineFeed moves the cursor down a line, scrolling at the bottom of the
+// scroll region.
+func (s *screen) lineFeed() {
+	s.wrapNext = false
+	switch {
+	case s.y == s.bot:
+		s.scrollUp(1)
+	case s.y < s.height-1:
+		s.y++
+	}
+}
+
+// scrollUp scrolls the scroll region up by n lines. Lines leaving the top of
+// the main screen go to the scrollback.
+func (s *screen) scrollUp(n int) {
+	if s.top == 0 && !s.alt {
+		for i := 0; i < min(n, s.bot+1); i++ {
+			s.pushHistory(s.rows[i])
+		}
+	}
+	s.deleteLines(s.top, n)
+}
+
+// scrollDown scrolls the scroll region down by n lines.
+func (s *screen) scrollDown(n int) {
+	s.insertLines(s.top, n)
+}
+
+// deleteLines removes n lines at y, pulling up the rest of the scroll region.
+func (s *screen) deleteLines(y, n int) {
+	n = min(n, s.bot-y+1)
+	copy(s.rows[y:s.bot+1], s.rows[y+n:s.bot+1])
+	for i := s.bot - n + 1; i <= s.bot; i++ {
+		s.rows[i] = s.blankRow()
+	}
+}
+
+// insertLines inserts n blank lines at y, pushing down the rest of the
+// scroll region.
+func (s *screen) insertLines(y, n int) {
+	n = min(n, s.bot-y+1)
+	copy(s.rows[y+n:s.bot+1], s.rows[y:s.bot+1-n])
+	for i := y; i < y+n; i++ {
+		s.rows[i] = s.blankRow()
+	}
+}
+
+// pushHistory appends a row to the scrollback.
+func (s *screen) pushHistory(row []cell) {
+	s.history = append(s.history, renderRow(row))
+	if len(s.history) > maxScreenHistory {
+		s.history = s.history[len(s.history)-maxScreenHistory:]
+	}
+}
+
+// blankRow returns an empty row in the current background color.
+func (s *screen) blankRow() []cell {
+	row := blankRow(s.width)
+	if s.pen.bg != "" {
+		for i := range row {
+			row[i].pen.bg = s.pen.bg
+		}
+	}
+	return row
+}
+
+// erase blanks cells [from, to) of row y in the current background color.
+func (s *screen) erase(y, from, to int) {
+	row := s.rows[y]
+	for x := max(from, 0); x < min(to, len(row)); x++ {
+		row[x] = cell{content: " ", width: 1, pen: pen{bg: s.pen.bg}}
+	}
+}
+
+// esc handles an ESC sequence.
+func (s *screen) esc(cmd ansi.Cmd) {
+	if cmd.Intermediate() != 0 {
+		return // charset designation and the like
+	}
+	switch cmd.Final() {
+	case '7':
+		s.saveCursor()
+	case '8':
+		s.restoreCursor()
+	case 'D':
+		s.lineFeed()
+	case 'E':
+		s.x = 0
+		s.lineFeed()
+	case 'M':
+		s.wrapNext = false
+		if s.y == s.top {
+			s.scrollDown(1)
+		} else if s.y > 0 {
+			s.y--
+		}
+	case 'c':
+		s.reset(s.width, s.height)
+	}
+}
+
+func (s *screen) saveCursor() {
+	s.savedX, s.savedY, s.savedPen = s.x, s.y, s.pen
+}
+
+func (s *screen) restoreCursor() {
+	s.x, s.y, s.pen = min(s.savedX, s.width-1), min(s.savedY, s.height-1), s.savedPen
+	s.wrapNext = false
+}
+
+// csi handles a CSI sequence.
+func (s *screen) csi(cmd ansi.Cmd, params ansi.Params) {
+	if cmd.Intermediate() != 0 {
+		return
+	}
+	// n is the first parameter, where 0 and missing both mean 1
+	n := max(param(params, 0, 1), 1)
+
+	if cmd.Prefix() == '?' {
+		switch cmd.Final() {
+		case 'h', 'l':
+			s.privateMode(params, cmd.Final() == 'h')
+		}
+		return
+	}
+	if cmd.Prefix() != 0 {
+		return
+	}
+
+	switch cmd.Final() {
+	case 'A':
+		s.moveTo(s.x, s.y-n)
+	case 'B', 'e':
+		s.moveTo(s.x, s.y+n)
+	case 'C', 'a':
+		s.moveTo(s.x+n, s.y)
+	case 'D':
+		s.moveTo(s.x-n, s.y)
+	case 'E':
+		s.moveTo(0, s.y+n)
+	case 'F':
+		s.moveTo(0, s.y-n)
+	case 'G', '`':
+		s.moveTo(n-1, s.y)
+	case 'd':
+		s.moveTo(s.x, n-1)
+	case 'H', 'f':
+		s.moveTo(max(param(params, 1, 1), 1)-1, n-1)
+	case 'J':
+		switch param(params, 0, 0) {
+		case 0:
+			s.erase(s.y, s.x, s.width)
+			for y := s.y + 1; y < s.height; y++ {
+				s.erase(y, 0, s.width)
+			}
+		case 1:
+			for y := 0; y < s.y; y++ {
+				s.erase(y, 0, s.width)
+			}
+			s.erase(s.y, 0, s.x+1)
+		case 2:
+			for y := 0; y < s.height; y++ {
+				s.erase(y, 0, s.width)
+			}
+		case 3:
+			s.history = nil
+		}
+	case 'K':
+		switch param(params, 0, 0) {
+		case 0:
+			s.erase(s.y, s.x, s.width)
+		case 1:
+			s.erase(s.y, 0, s.x+1)
+		case 2:
+			s.erase(s.y, 0, s.width)
+		}
+	case 'L', 'M':
+		if s.y < s.top || s.y > s.bot {
+			return
+		}
+		if cmd.Final() == 'L' {
+			s.insertLines(s.y, n)
+		} else {
+			s.deleteLines(s.y, n)
+		}
+		s.x, s.wrapNext = 0, false
+	case '@':
+		row := s.rows[s.y]
+		n = min(n, s.width-s.x)
+		copy(row[s.x+n:], row[s.x:s.width-n])
+		s.erase(s.y, s.x, s.x+n)
+	case 'P':
+		row := s.rows[s.y]
+		n = min(n, s.width-s.x)
+		copy(row[s.x:], row[s.x+n:])
+		s.erase(s.y, s.width-n, s.width)
+	case 'X':
+		s.erase(s.y, s.x, s.x+n)
+	case 'S':
+		s.scrollUp(n)
+	case 'T':
+		if len(params) <= 1 {
+			s.scrollDown(n)
+		}
+	case 'b':
+		if s.last != 0 {
+			for i := 0; i < min(n, s.width*s.height); i++ {
+				s.print(s.last)
+			}
+		}
+	case 'r':
+		top, bot := max(param(params, 0, 1), 1)-1, param(params, 1, s.height)-1
+		if bot <= 0 || bot >= s.height {
+			bot = s.height - 1
+		}
+		if top < bot {
+			s.top, s.bot = top, bot
+			s.moveTo(0, 0)
+		}
+	case 's':
+		s.saveCursor()
+	case 'u':
+		s.restoreCursor()
+	case 'm':
+		s.sgr(params)
+	}
+}
+
+// privateMode handles DEC private mode set/reset (CSI ? … h/l).
+func (s *screen) privateMode(params ansi.Params, set bool) {
+	for i := range params {
+		switch params[i].Param(0) {
+		case 7:
+			s.autowrap = set
+		case 1000, 1002, 1003:
+			s.mouseTracking = set
+		case 1006:
+			s.sgrMouse = set
+		case 2004:
+			s.bracketedPaste = set
+		case 47, 1047, 1049:
+			if set == s.alt {
+				continue
+			}
+			if set {
+				s.saveCursor()
+				s.mainRows = s.rows
+				s.rows = blankRows(s.width, s.height)
+				s.alt = true
+			} else {
+				s.rows = s.mainRows
+				s.mainRows = nil
+				s.alt = false
+				s.restoreCursor()
+			}
+		}
+	}
+}
+
+// moveTo moves the cursor, clamped to the screen.
+func (s *screen) moveTo(x, y int) {
+	s.x = min(max(x, 0), s.width-1)
+	s.y = min(max(y, 0), s.height-1)
+	s.wrapNext = false
+}
+
+// sgr applies Select Graphic Rendition parameters to the pen.
+func (s *screen) sgr(params ansi.Params) {
+	if len(params) == 0 {
+		s.pen = pen{}
+		return
+	}
+	for i := 0; i < len(params); i++ {
+		p := params[i].Param(0)
+		switch {
+		case p == 0:
+			s.pen = pen{}
+		case p >= 1 && p <= 9:
+			s.pen.attrs |= 1 << p
+		case p == 21:
+			s.pen.attrs |= 1 << 4
+		case p == 22:
+			s.pen.attrs &^= 1<<1 | 1<<2
+		case p >= 23 && p <= 29 && p != 26:
+			s.pen.attrs &^= 1 << (p - 20)
+		case p >= 30 && p <= 37, p >= 90 && p <= 97:
+			s.pen.fg = strconv.Itoa(p)
+		case p == 39:
+			s.pen.fg = ""
+		case p >= 40 && p <= 47, p >= 100 && p <= 107:
+			s.pen.bg = strconv.Itoa(p)
+		case p == 49:
+			s.pen.bg = ""
+		case p == 38, p == 48, p == 58:
+			color, used := extendedColor(params[i:])
+			i += used
+			if color == "" {
+				continue
+			}
+			if p == 38 {
+				s.pen.fg = "38;" + color
+			} else if p == 48 {
+				s.pen.bg = "48;" + color
+			}
+		}
+	}
+}
+
+// extendedColor parses a 256-color or truecolor SGR parameter (38, 48, 58)
+// in either the "38;5;n" or the "38:5:n" form. It returns the color as
+// semicolon-separated parameters after the 38/48, e.g. "5;208" or
+// "2;255;0;0", and how many parameters it consumed after the first.
+func extendedColor(params ansi.Params) (string, int) {
+	var args []int
+	used := 0
+	if params[0].HasMore() {
+		// Colon form: the sub-parameters follow while HasMore is set
+		for i := 1; i < len(params) && params[i-1].HasMore(); i++ {
+			args = append(args, params[i].Param(0))
+			used++
+		}
+		// 38:2:<colorspace>:r:g:b
+		if len(args) == 5 && args[0] == 2 {
+			args = append(args[:1], args[2:]...)
+		}
+	} else {
+		for i := 1; i < len(params) && i <= 4; i++ {
+			args = append(args, params[i].Param(0))
+		}
+		if len(args) > 0 && args[0] == 5 {
+			args, used = args[:min(len(args), 2)], min(len(args), 2)
+		} else if len(args) > 0 && args[0] == 2 {
+			used = len(args)
+		} else {
+			return "", 0
+		}
+	}
+
+	switch {
+	case len(args) == 2 && args[0] == 5:
+		return "5;" + strconv.Itoa(args[1]), used
+	case len(args) == 4 && args[0] == 2:
+		return "2;" + strconv.Itoa(args[1]) + ";" + strconv.Itoa(args[2]) + ";" + strconv.Itoa(args[3]), used
+	}
+	return "", used
+}
+
+// param returns parameter i, or def when it is missing.
+func param(params ansi.Params, i, def int) int {
+	p, _, _ := params.Param(i, def)
+	return p
+}
+
+// blankRow returns an empty row.
+func blankRow(width int) []cell {
+	row := make([]cell, width)
+	for i := range row {
+		row[i] = blank
+	}
+	return row
+}
+
+// blankRows returns an empty screen.
+func blankRows(width, height int) [][]cell {
+	rows := make([][]cell, height)
+	for i := range rows {
+		rows[i] = blankRow(width)
+	}
+	return rows
+}
+
+// resizeRow truncates or pads a row to width, dropping a wide character cut
+// in half.
+func resizeRow(row []cell, width int) []cell {
+	if len(row) >= width {
+		row = row[:width]
+		if width > 0 && row[width-1].width == 2 {
+			row[width-1] = blank
+		}
+		return row
+	}
+	for len(row) < width {
+		row = append(row, blank)
+	}
+	return row
+}
+
+// renderRow renders a row with SGR sequences, trimming trailing blanks.
+func renderRow(row []cell) string {
+	end := len(row)
+	for end > 0 && row[end-1] == blank {
+		end--
+	}
+
+	var b strings.Builder
+	cur := pen{}
+	for _, c := range row[:end] {
+		if c.width == 0 {
+			continue
+		}
+		if c.pen != cur {
+			b.WriteString(c.pen.sgr())
+			cur = c.pen
+		}
+		b.WriteString(c.content)
+	}
+	if cur != (pen{}) {
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScreen(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string // visible lines, trailing blank lines omitted
+	}{
+		{"print and newline", "hello\r\nworld", []string{"hello", "world"}},
+		{"overwrite with CR", "hello\rJ", []string{"Jello"}},
+		{"wrap", "abcdefghijkl", []string{"abcdefghij", "kl"}},
+		{"cursor position", "\x1b[2;3Hx", []string{"", "  x"}},
+		{"erase line", "hello\x1b[3G\x1b[K", []string{"he"}},
+		{"erase screen", "one\r\ntwo\x1b[2J\x1b[Hthree", []string{"three"}},
+		{"cursor up and rewrite", "a\r\nb\r\n\x1b[2AA", []string{"A", "b"}},
+		{"colors", "\x1b[1;31mred\x1b[0m plain", []string{"\x1b[0;1;31mred\x1b[0m plain"}},
+		{"256 color colon form", "\x1b[38:5:208mx", []string{"\x1b[0;38;5;208mx\x1b[0m"}},
+		{"truecolor", "\x1b[48;2;1;2;3mx", []string{"\x1b[0;48;2;1;2;3mx\x1b[0m"}},
+		{"wide characters", "日本\x1b[1G x", []string{" x本"}},
+		{"insert and delete chars", "abcd\x1b[2G\x1b[2@\x1b[4G\x1b[1P", []string{"a  cd"}},
+		{"alt screen restores main", "main\x1b[?1049halt\x1b[?1049l", []string{"main"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newScreen(10, 4)
+			s.Write([]byte(tt.input))
+			got := strings.Split(strings.TrimRight(s.render(), "\n"), "\n")
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("render = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreenScrollback(t *testing.T) {
+	s := newScreen(10, 2)
+	s.Write([]byte("1\r\n2\r\n3\r\n4"))
+	if got, want := s.lines(-2, 1), "1\n2\n3\n4\n"; got != want {
+		t.Errorf("lines(-2, 1) = %q, want %q", got, want)
+	}
+
+	// A scroll region keeps lines out of the scrollback
+	s = newScreen(10, 3)
+	s.Write([]byte("\x1b[2;3r\x1b[2;1Ha\r\nb\r\nc"))
+	if len(s.history) != 0 {
+		t.Errorf("history = %q, want none", s.history)
+	}
+}
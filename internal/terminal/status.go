@@ -0,0 +1,39 @@
+package terminal
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/kevinzwang/air-traffic-control/internal/logging"
+)
+
+// activityState returns whether an agent whose output last changed at last
+// is working or waiting for input.
+func activityState(last, now time.Time) AgentState {
+	if now.Sub(last) < IdleThreshold {
+		return StateWorking
+	}
+	return StateWaiting
+}
+
+// SessionState reads the state of the agent in a tmux session without
+// attaching to it, going by when tmux last saw output in the agent's window.
+// ok is false when the session isn't running.
+func SessionState(socket, name string) (state AgentState, ok bool) {
+	out, err := logging.Output(exec.Command("tmux", "-L", socket, "display-message", "-p", "-t", name+":^",
+		"#{pane_dead} #{window_activity}"))
+	if err != nil {
+		return 0, false
+	}
+	var dead int
+	var activity int64
+	if _, err := fmt.Sscanf(strings.TrimSpace(string(out)), "%d %d", &dead, &activity); err != nil {
+		return 0, false
+	}
+	if dead == 1 {
+		return StateExited, true
+	}
+	return activityState(time.Unix(activity, 0), time.Now()), true
+}
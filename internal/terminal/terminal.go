@@ -1,15 +1,21 @@
 package terminal
 
 import (
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/logging"
 )
 
 // TerminalOutputMsg is sent when new output is available from the terminal.
@@ -18,49 +24,224 @@ type TerminalOutputMsg struct{}
 // TerminalExitedMsg is sent when the child process exits.
 type TerminalExitedMsg struct {
 	Name string
+	// Crashed is set when the process exited with an error status or was
+	// killed by a signal, rather than quitting normally.
+	Crashed bool
+}
+
+// AgentState is the coarse activity state of the agent in a pane.
+type AgentState int
+
+const (
+	// StateWorking means the pane output changed within IdleThreshold.
+	StateWorking AgentState = iota
+	// StateWaiting means the pane has been quiet for IdleThreshold, which
+	// usually means the agent is waiting for input.
+	StateWaiting
+	// StateExited means the agent process has exited.
+	StateExited
+)
+
+// String returns the lowercase name of the state.
+func (s AgentState) String() string {
+	switch s {
+	case StateWorking:
+		return "working"
+	case StateWaiting:
+		return "waiting"
+	case StateExited:
+		return "exited"
+	}
+	return "unknown"
+}
+
+// TerminalStateMsg is sent when a terminal's AgentState changes between
+// working and waiting. Exits are reported by TerminalExitedMsg.
+type TerminalStateMsg struct {
+	Name  string
+	State AgentState
+}
+
+// workedSince is how much of the time between two output changes, at last
+// and now, the agent spent working: all of it if it didn't go idle in
+// between, none of it if it did (or there was no earlier change).
+func workedSince(last, now time.Time) time.Duration {
+	if gap := now.Sub(last); !last.IsZero() && gap > 0 && gap < IdleThreshold {
+		return gap
+	}
+	return 0
+}
+
+// IdleThreshold is how long pane output must stay unchanged before the agent
+// is considered to be waiting for input.
+var IdleThreshold = 3 * time.Second
+
+// PollInterval is how often a terminal captures its pane while the agent is
+// running. It is overridden from the user config at startup.
+var PollInterval = 50 * time.Millisecond
+
+// Terminal is the embedded terminal of one ATC session: the agent process,
+// its screen, and the scrollback position the user is viewing.
+type Terminal interface {
+	// Name returns the ATC session name the terminal belongs to.
+	Name() string
+	// SendKeys forwards a key press to the agent.
+	SendKeys(msg tea.KeyMsg)
+	// Render returns the visible screen (or the scrolled-back view) as ANSI text.
+	Render() string
+	// Resize changes the terminal's size.
+	Resize(width, height int)
+	// State returns the agent's activity state.
+	State() AgentState
+	// TakeWorkTime returns how long the agent has spent working (producing
+	// output) since the last call.
+	TakeWorkTime() time.Duration
+	// IsRunning reports whether the agent process is alive.
+	IsRunning() bool
+	// Respawn restarts the agent process, with any extra flags (shell words)
+	// added to its command for this run.
+	Respawn(continueSession bool, flags string) error
+	// Close ends the agent process.
+	Close() error
+	// Detach stops updating the UI but leaves the agent running.
+	Detach()
+	// Paste sends text as a single paste, marked as one if the program
+	// asked for bracketed paste, so a multi-line prompt isn't submitted a
+	// line at a time.
+	Paste(text string)
+	// WantsMouse reports whether the program shown turned on SGR mouse
+	// reporting, so mouse events can be passed through to it.
+	WantsMouse() bool
+	// SendMouse sends a mouse event at a cell of the screen, counted from 0.
+	SendMouse(msg tea.MouseMsg, col, row int)
+	// PID returns the agent's process ID, or 0 if it isn't running or isn't
+	// known yet.
+	PID() int
+	// SetFocused records whether the terminal is on screen; terminals that
+	// aren't are refreshed less often.
+	SetFocused(focused bool)
+	// OpenWindow shows the session's window called name in place of the
+	// agent, starting command in the worktree if it isn't open yet.
+	OpenWindow(name, command string) error
+	// ShowAgent shows the agent again.
+	ShowAgent() error
+	// CycleWindow shows the next window, or the previous one.
+	CycleWindow(forward bool) error
+	// Window returns the name of the window shown, or "" for the agent's.
+	Window() string
+	// Windows returns the names of the session's windows in order, with ""
+	// for the agent's.
+	Windows() []string
+
+	// Scrollback returns the history and the visible screen, oldest line
+	// first, and how many of the lines are history.
+	Scrollback() (text string, histSize int)
+
+	ScrollUp(lines int)
+	ScrollDown(lines int)
+	IsScrollMode() bool
+	ScrollPosition() int
+	ExitScrollMode()
 }
 
-// Terminal wraps a tmux session for a single Claude session.
-type Terminal struct {
+// tmuxTerminal is a Terminal backed by a tmux session.
+type tmuxTerminal struct {
 	socket  string // tmux socket name (shared across all terminals)
 	name    string // tmux session name (unique per terminal)
+	agent   Agent  // agent launch settings used when (re)spawning the pane
 	program *tea.Program
 	done    chan struct{}
 	mu      sync.Mutex
 	closed  bool
 
+	// control is the control-mode client reporting pane output, and pipe the
+	// pane's output stream; both are nil when the terminal polls instead
+	control *controlClient
+	pipe    *pipeStream
+
 	// Rendering
 	lastCapture string // last captured pane content (for change detection)
 	visHeight   int
+	visWidth    int
 
 	// Scrollback
 	scrollLines    int // lines scrolled back from bottom (0 = live)
 	cachedHistSize int // cached history_size from last poll
 
-	// Exit detection
-	paneDead bool
+	// Exit detection. tmux may see the pane close before it has the exit
+	// status, so the exit is reported once the status is known or
+	// exitStatusWait has passed
+	paneDead     bool
+	deadSince    time.Time
+	exitReported bool
+
+	// Activity detection
+	lastActivity time.Time     // last time the captured output changed
+	state        AgentState    // working/waiting, derived from lastActivity
+	workTime     time.Duration // working time not yet taken by TakeWorkTime
+
+	// Refresh rate, lowered while the terminal isn't on screen
+	focusState
+
+	// Typed text waiting to be sent in one send-keys; keysMu also orders
+	// every send-keys so coalesced text can't overtake other keys
+	keysMu      sync.Mutex
+	keysPending string
+	keysTimer   *time.Timer
+
+	// Extra windows (see windows.go). agentWindow is the ID of the agent's
+	// window; window and windows name the window shown and all of them, with
+	// "" for the agent's
+	agentWindow string
+	window      string
+	windows     []string
+
+	// The shown program turned on SGR mouse reporting (see mouse.go)
+	wantsMouse bool
+
+	// The agent pane's process, for resource usage
+	pid int
 }
 
-// newTerminal creates a Terminal struct and starts its poll loop.
-func newTerminal(name string, width, height int, p *tea.Program, socket string) *Terminal {
-	t := &Terminal{
+// newTerminal creates a tmuxTerminal and starts watching its pane the way
+// OutputMode selects, falling back to polling.
+func newTerminal(name string, agent Agent, width, height int, p *tea.Program, socket string) *tmuxTerminal {
+	t := &tmuxTerminal{
 		socket:    socket,
 		name:      name,
+		agent:     agent,
 		program:   p,
 		done:      make(chan struct{}),
 		visHeight: height,
+		visWidth:  width,
+
+		lastActivity: time.Now(),
+	}
+	t.initFocus()
+	// The agent runs in the session's first window, whichever is shown now
+	out, _ := logging.Output(exec.Command("tmux", "-L", socket, "display-message", "-p", "-t", name+":^", "#{window_id}"))
+	t.agentWindow = strings.TrimSpace(string(out))
+	switch OutputMode {
+	case OutputPipe:
+		if err := t.startPipe(width, height); err == nil {
+			go t.watchLoop(t.pipe.output, nil)
+			return t
+		}
+	case OutputControl:
+		if c, err := startControlClient(socket, name); err == nil {
+			t.control = c
+			go t.watchLoop(c.output, c.exited)
+			return t
+		}
 	}
 	go t.pollLoop()
 	return t
 }
 
-// New creates a tmux session running claude in the given worktree directory.
+// New creates a tmux session running the agent in the given worktree directory.
 // tmuxSocket is the shared socket name (e.g. "atc-<hash>").
-func New(name, worktreePath string, width, height int, continueSession bool, p *tea.Program, tmuxSocket string) (*Terminal, error) {
-	cmd := "claude"
-	if continueSession {
-		cmd = "claude --continue"
-	}
+func (tmuxBackend) New(name, worktreePath string, agent Agent, width, height int, continueSession bool, p *tea.Program, tmuxSocket string) (Terminal, error) {
+	cmd := agent.commandLine(continueSession)
 
 	args := []string{"-L", tmuxSocket, "new-session", "-d",
 		"-s", name,
@@ -70,97 +251,411 @@ func New(name, worktreePath string, width, height int, continueSession bool, p *
 		cmd}
 	createCmd := exec.Command("tmux", args...)
 	createCmd.Dir = worktreePath
-	createCmd.Env = append(os.Environ(), "TERM=xterm-256color")
-	if out, err := createCmd.CombinedOutput(); err != nil {
+	createCmd.Env = os.Environ()
+	if agent.Term != "" {
+		createCmd.Env = append(createCmd.Env, "TERM="+agent.Term)
+	}
+	if out, err := logging.CombinedOutput(createCmd); err != nil {
 		return nil, fmt.Errorf("failed to create tmux session: %w: %s", err, string(out))
 	}
 
 	// Configure: keep pane alive after process exits, set scrollback
-	exec.Command("tmux", "-L", tmuxSocket, "set-option", "-t", name, "remain-on-exit", "on").Run()
-	exec.Command("tmux", "-L", tmuxSocket, "set-option", "-t", name, "history-limit", "50000").Run()
+	logging.Run(exec.Command("tmux", "-L", tmuxSocket, "set-option", "-t", name, "remain-on-exit", "on"))
+	logging.Run(exec.Command("tmux", "-L", tmuxSocket, "set-option", "-t", name, "history-limit", "50000"))
 
-	return newTerminal(name, width, height, p, tmuxSocket), nil
+	agent.Resume = ""
+	return newTerminal(name, agent, width, height, p, tmuxSocket), nil
 }
 
 // pollLoop captures pane content periodically and sends Bubble Tea messages on change.
-func (t *Terminal) pollLoop() {
-	ticker := time.NewTicker(50 * time.Millisecond)
-	defer ticker.Stop()
+// It is used when OutputMode is OutputPoll or the selected mode can't start.
+func (t *tmuxTerminal) pollLoop() {
+	timer := time.NewTimer(t.interval())
+	defer timer.Stop()
+
+	for {
+		_, regained := windowFocus()
+		select {
+		case <-t.done:
+			return
+		case <-t.wake:
+		case <-regained:
+		case <-timer.C:
+		}
+		next := t.interval()
+		if state, _ := t.refresh(); state == StateExited {
+			// Slow down polling since nothing is changing
+			next = max(next, exitedPollInterval)
+		}
+		timer.Reset(next)
+	}
+}
+
+// idleRefreshInterval is how often a terminal that is told about pane output
+// refreshes without any, to notice the agent going idle or exiting.
+const idleRefreshInterval = 500 * time.Millisecond
+
+// watchLoop refreshes the pane when output is signalled (by a control-mode
+// client or the pipe stream), at most once per interval, instead of
+// capturing on a fixed timer. When exited closes, it falls back to polling.
+func (t *tmuxTerminal) watchLoop(output, exited <-chan struct{}) {
+	idle := time.NewTimer(t.idleInterval())
+	defer idle.Stop()
+
+	// Show the current pane straight away rather than at the first output
+	t.refresh()
 
+	var throttle <-chan time.Time
+	pending := false
 	for {
+		_, regained := windowFocus()
 		select {
 		case <-t.done:
 			return
-		case <-ticker.C:
-			output := t.capturePaneVisible()
-			histSize := t.historySize()
-
-			t.mu.Lock()
-			changed := output != t.lastCapture
-			t.lastCapture = output
-			t.cachedHistSize = histSize
-			t.mu.Unlock()
-
-			if changed && t.program != nil {
-				t.program.Send(TerminalOutputMsg{})
+		case <-exited:
+			// The client was detached from outside; keep going by polling
+			t.pollLoop()
+			return
+		case <-output:
+			if throttle != nil {
+				pending = true
+				continue
+			}
+			t.refresh()
+			throttle = time.After(t.interval())
+		case <-throttle:
+			throttle = nil
+			if pending {
+				pending = false
+				t.refresh()
+				throttle = time.After(t.interval())
 			}
+		case <-t.wake:
+			t.refresh()
+		case <-regained:
+			t.refresh()
+		case <-idle.C:
+			t.refresh()
+			idle.Reset(t.idleInterval())
+		}
+	}
+}
+
+// refresh captures the pane, updates the activity state, and sends Bubble Tea
+// messages for changes. It returns the new and previous state.
+func (t *tmuxTerminal) refresh() (state, prev AgentState) {
+	snap := t.snapshot()
 
-			// Check if process exited
-			if t.isPaneDead() {
-				t.mu.Lock()
-				wasDead := t.paneDead
-				t.paneDead = true
-				t.mu.Unlock()
-
-				if !wasDead && t.program != nil {
-					t.program.Send(TerminalExitedMsg{Name: t.name})
-				}
-				// Slow down polling since nothing is changing
-				ticker.Reset(500 * time.Millisecond)
+	now := time.Now()
+
+	t.mu.Lock()
+	changed := snap.content != t.lastCapture
+	switched := snap.window != t.window
+	windowsChanged := !slices.Equal(snap.windows, t.windows)
+	t.lastCapture = snap.content
+	t.cachedHistSize = snap.histSize
+	t.window = snap.window
+	t.windows = snap.windows
+	t.wantsMouse = snap.mouse
+	t.pid = snap.pid
+	if switched {
+		t.scrollLines = 0
+	}
+	switch {
+	case snap.window == "":
+		// Switching windows isn't the agent doing anything
+		if changed && !switched {
+			t.workTime += workedSince(t.lastActivity, now)
+			t.lastActivity = now
+		}
+	case snap.activity.After(t.lastActivity):
+		// The agent's window is hidden, so go by when tmux last saw output
+		t.workTime += workedSince(t.lastActivity, snap.activity)
+		t.lastActivity = snap.activity
+	}
+	t.mu.Unlock()
+
+	if (changed || switched || windowsChanged) && t.program != nil {
+		t.program.Send(TerminalOutputMsg{})
+	}
+
+	// Check if process exited
+	if snap.dead {
+		crashed, known := exitCrashed(snap.exit)
+		t.mu.Lock()
+		if !t.paneDead {
+			t.deadSince = now
+		}
+		report := !t.exitReported && (known || now.Sub(t.deadSince) >= exitStatusWait)
+		prev = t.state
+		t.paneDead = true
+		t.exitReported = t.exitReported || report
+		t.state = StateExited
+		t.mu.Unlock()
+
+		if report && t.program != nil {
+			t.program.Send(TerminalExitedMsg{Name: t.name, Crashed: crashed})
+		}
+		return StateExited, prev
+	}
+
+	t.mu.Lock()
+	prev = t.state
+	t.state = activityState(t.lastActivity, now)
+	state = t.state
+	t.mu.Unlock()
+
+	if state != prev && t.program != nil {
+		t.program.Send(TerminalStateMsg{Name: t.name, State: state})
+	}
+	if t.pipe != nil && state == StateWaiting && prev == StateWorking {
+		t.resyncPipe()
+	}
+	return state, prev
+}
+
+// tmuxBatch runs tmux commands in one round trip, over the control-mode
+// connection when there is one, and returns each command's output. Without
+// one the outputs are told apart by line, so every command after the first
+// must print exactly one line.
+func (t *tmuxTerminal) tmuxBatch(cmds ...[]string) []string {
+	outs := make([]string, len(cmds))
+	if c := t.control; c != nil && c.alive() {
+		lines := make([]string, len(cmds))
+		for i, cmd := range cmds {
+			lines[i] = cmd[0]
+			for _, a := range cmd[1:] {
+				lines[i] += " " + quoteArg(a)
+			}
+		}
+		if replies, err := c.commands(lines...); err == nil {
+			for i, reply := range replies {
+				outs[i] = joinLines(reply)
 			}
+			return outs
 		}
 	}
+
+	// A lone ";" argument separates commands, so one process runs them all
+	args := []string{"-L", t.socket}
+	for i, cmd := range cmds {
+		if i > 0 {
+			args = append(args, ";")
+		}
+		args = append(args, cmd...)
+	}
+	out, _ := logging.Output(exec.Command("tmux", args...))
+	s := string(out)
+	for i := len(cmds) - 1; i > 0; i-- {
+		s = strings.TrimSuffix(s, "\n")
+		j := strings.LastIndexByte(s, '\n')
+		outs[i], s = s[j+1:], s[:j+1]
+	}
+	outs[0] = s
+	return outs
 }
 
-func (t *Terminal) capturePaneVisible() string {
-	out, _ := exec.Command("tmux", "-L", t.socket,
-		"capture-pane", "-t", t.name, "-p", "-e").Output()
-	return string(out)
+// tmuxOutput runs a tmux command against target and returns its output.
+func (t *tmuxTerminal) tmuxOutput(target, command string, args ...string) string {
+	return t.tmuxBatch(append([]string{command, "-t", target}, args...))[0]
 }
 
-func (t *Terminal) capturePaneRange(startLine, endLine int) string {
-	out, _ := exec.Command("tmux", "-L", t.socket,
-		"capture-pane", "-t", t.name, "-p", "-e",
-		"-S", fmt.Sprintf("%d", startLine),
-		"-E", fmt.Sprintf("%d", endLine)).Output()
-	return string(out)
+// Status formats snapshot reads: the shown pane's history size and window
+// (the name last, as it may contain spaces), whether the agent has exited
+// and when its window last had output, and the session's windows.
+const (
+	paneStatusFormat  = "#{history_size} #{mouse_any_flag}#{mouse_sgr_flag} #{window_id} #{window_name}"
+	agentStatusFormat = "#{pane_dead} #{window_activity} #{pane_dead_status}:#{pane_dead_signal} #{pane_pid}"
+	windowsFormat     = "#{W:#{window_id} #{window_name}\t}"
+)
+
+// paneSnapshot is what one refresh learns from tmux.
+type paneSnapshot struct {
+	content  string    // the shown pane
+	histSize int       // the shown pane's scrollback length
+	window   string    // name of the window shown, "" for the agent's
+	mouse    bool      // the shown program wants SGR mouse events
+	windows  []string  // names of the session's windows, "" for the agent's
+	dead     bool      // the agent has exited
+	exit     string    // the agent's exit status and signal, "status:signal"
+	pid      int       // the agent pane's process
+	activity time.Time // when the agent's window last had output
+}
+
+// snapshot captures the shown pane and reads its status and the agent's in
+// one round trip: a single tmux process, or a single write to the
+// control-mode client.
+func (t *tmuxTerminal) snapshot() paneSnapshot {
+	cmds := [][]string{
+		{"display-message", "-p", "-t", t.shownTarget(), paneStatusFormat},
+		{"display-message", "-p", "-t", t.agentTarget(), agentStatusFormat},
+		{"display-message", "-p", "-t", t.shownTarget(), windowsFormat},
+	}
+	if t.pipe == nil {
+		cmds = append([][]string{{"capture-pane", "-p", "-e", "-t", t.shownTarget()}}, cmds...)
+	}
+	out := t.tmuxBatch(cmds...)
+
+	var snap paneSnapshot
+	if t.pipe == nil {
+		snap.content, out = out[0], out[1:]
+	}
+	status := strings.SplitN(strings.TrimSpace(out[0]), " ", 4)
+	if len(status) == 4 {
+		snap.histSize, _ = strconv.Atoi(status[0])
+		snap.mouse = status[1] == "11"
+		snap.window = t.windowName(status[2], status[3])
+	}
+	for _, w := range strings.Split(strings.TrimRight(out[2], "\t\n"), "\t") {
+		if id, name, ok := strings.Cut(w, " "); ok {
+			snap.windows = append(snap.windows, t.windowName(id, name))
+		}
+	}
+	var deadFlag int
+	var activity int64
+	fmt.Sscanf(strings.TrimSpace(out[1]), "%d %d %s %d", &deadFlag, &activity, &snap.exit, &snap.pid)
+	snap.dead = deadFlag == 1
+	if activity > 0 {
+		snap.activity = time.Unix(activity, 0)
+	}
+
+	if t.pipe != nil {
+		// The pipe carries only the agent's pane
+		if snap.window == "" {
+			snap.content, snap.histSize = t.pipe.render(), t.pipe.historySize()
+		} else {
+			snap.content = t.tmuxOutput(t.shownTarget(), "capture-pane", "-p", "-e")
+		}
+	}
+	return snap
+}
+
+// exitStatusWait is how long an exit is held back waiting for tmux to have
+// the process's exit status.
+const exitStatusWait = time.Second
+
+// exitCrashed reads the "status:signal" pair of a dead pane, reporting
+// whether the process failed and whether tmux knew how it ended.
+func exitCrashed(exit string) (crashed, known bool) {
+	status, signal, _ := strings.Cut(exit, ":")
+	switch {
+	case signal != "":
+		return true, true
+	case status != "":
+		return status != "0", true
+	}
+	return false, false
 }
 
-func (t *Terminal) isPaneDead() bool {
-	out, _ := exec.Command("tmux", "-L", t.socket,
-		"display-message", "-t", t.name, "-p", "#{pane_dead}").Output()
-	return strings.TrimSpace(string(out)) == "1"
+// joinLines joins control-mode reply lines back into command output.
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (t *tmuxTerminal) capturePaneRange(startLine, endLine int) string {
+	if t.usePipe() {
+		return t.pipe.lines(startLine, endLine)
+	}
+	return t.tmuxOutput(t.shownTarget(), "capture-pane", "-p", "-e",
+		"-S", fmt.Sprintf("%d", startLine),
+		"-E", fmt.Sprintf("%d", endLine))
 }
 
-func (t *Terminal) historySize() int {
-	out, _ := exec.Command("tmux", "-L", t.socket,
-		"display-message", "-t", t.name, "-p", "#{history_size}").Output()
-	n := 0
-	fmt.Sscanf(strings.TrimSpace(string(out)), "%d", &n)
-	return n
+func (t *tmuxTerminal) isPaneDead() bool {
+	out := t.tmuxOutput(t.agentTarget(), "display-message", "-p", "#{pane_dead}")
+	return strings.TrimSpace(out) == "1"
 }
 
 // SendKeys translates a Bubble Tea KeyMsg and sends it to the tmux session.
-func (t *Terminal) SendKeys(msg tea.KeyMsg) {
+// Literal text is queued for keyCoalesceDelay so fast typing (or a paste
+// delivered as several messages) costs one tmux process instead of one per
+// key; any other key flushes the queue first.
+func (t *tmuxTerminal) SendKeys(msg tea.KeyMsg) {
 	args := t.keyMsgToTmuxArgs(msg)
 	if args == nil {
 		return
 	}
+
+	t.keysMu.Lock()
+	defer t.keysMu.Unlock()
+	if text, ok := literalKeys(msg, args); ok {
+		t.keysPending += text
+		if t.keysTimer == nil {
+			t.keysTimer = time.AfterFunc(keyCoalesceDelay, t.flushKeys)
+		}
+		return
+	}
+	t.flushKeysLocked()
+	// Not logged: the keys are what the user typed
 	exec.Command("tmux", args...).Run()
 }
 
-func (t *Terminal) keyMsgToTmuxArgs(msg tea.KeyMsg) []string {
-	base := []string{"-L", t.socket, "send-keys", "-t", t.name}
+// Paste loads text into a tmux buffer and pastes it into the shown window.
+// paste-buffer -p adds the bracketed-paste markers only when the program has
+// turned the mode on, and turns line feeds into carriage returns.
+func (t *tmuxTerminal) Paste(text string) {
+	if text == "" {
+		return
+	}
+	t.keysMu.Lock()
+	defer t.keysMu.Unlock()
+	t.flushKeysLocked()
+
+	buffer := "atc-paste-" + t.name
+	cmd := exec.Command("tmux", "-L", t.socket, "load-buffer", "-b", buffer, "-", ";",
+		"paste-buffer", "-p", "-d", "-b", buffer, "-t", t.shownTarget())
+	cmd.Stdin = strings.NewReader(pasteText(text))
+	logging.Run(cmd)
+}
+
+// pasteText normalises line endings to line feeds and drops any end-of-paste
+// marker inside the text, which would let the rest of it be read as typed
+// keys.
+func pasteText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	return strings.ReplaceAll(text, "\x1b[201~", "")
+}
+
+// keyCoalesceDelay is how long typed text waits for more before it is sent.
+const keyCoalesceDelay = 5 * time.Millisecond
+
+// literalKeys returns the text a key sends if it can be merged with
+// neighbouring text into one `send-keys -l`.
+func literalKeys(msg tea.KeyMsg, args []string) (string, bool) {
+	if msg.Type == tea.KeySpace && !msg.Alt {
+		return " ", true
+	}
+	if n := len(args); n >= 2 && args[n-2] == "-l" {
+		return args[n-1], true
+	}
+	return "", false
+}
+
+// flushKeys sends any queued text.
+func (t *tmuxTerminal) flushKeys() {
+	t.keysMu.Lock()
+	defer t.keysMu.Unlock()
+	t.flushKeysLocked()
+}
+
+func (t *tmuxTerminal) flushKeysLocked() {
+	if t.keysTimer != nil {
+		t.keysTimer.Stop()
+		t.keysTimer = nil
+	}
+	if t.keysPending == "" {
+		return
+	}
+	exec.Command("tmux", "-L", t.socket, "send-keys", "-t", t.shownTarget(), "-l", t.keysPending).Run()
+	t.keysPending = ""
+}
+
+func (t *tmuxTerminal) keyMsgToTmuxArgs(msg tea.KeyMsg) []string {
+	base := []string{"-L", t.socket, "send-keys", "-t", t.shownTarget()}
 
 	// Alt+Runes: send ESC + rune as a single literal string so both bytes
 	// arrive in one PTY write. If they're split across writes, the process
@@ -627,7 +1122,7 @@ func addAltModifier(seq string) string {
 }
 
 // Render returns the current terminal content as an ANSI string.
-func (t *Terminal) Render() string {
+func (t *tmuxTerminal) Render() string {
 	t.mu.Lock()
 	scrollLines := t.scrollLines
 	lastCapture := t.lastCapture
@@ -648,65 +1143,120 @@ func (t *Terminal) Render() string {
 }
 
 // Resize updates the tmux session dimensions.
-func (t *Terminal) Resize(width, height int) {
+func (t *tmuxTerminal) Resize(width, height int) {
 	t.mu.Lock()
 	t.visHeight = height
+	t.visWidth = width
 	t.mu.Unlock()
 
-	exec.Command("tmux", "-L", t.socket,
-		"resize-window", "-t", t.name,
+	// Only the shown window; the others are resized when they're selected
+	logging.Run(exec.Command("tmux", "-L", t.socket,
+		"resize-window", "-t", t.shownTarget(),
 		"-x", fmt.Sprintf("%d", width),
-		"-y", fmt.Sprintf("%d", height)).Run()
+		"-y", fmt.Sprintf("%d", height)))
+
+	if t.pipe != nil {
+		// tmux reflows the pane; take its result rather than guessing
+		t.pipe.resize(width, height)
+		t.resyncPipe()
+	}
+}
+
+// State returns the current agent activity state.
+func (t *tmuxTerminal) State() AgentState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state
+}
+
+// TakeWorkTime returns the agent's working time since the last call.
+func (t *tmuxTerminal) TakeWorkTime() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	d := t.workTime
+	t.workTime = 0
+	return d
+}
+
+// Name returns the tmux session name, which is the ATC session name.
+func (t *tmuxTerminal) Name() string {
+	return t.name
+}
+
+// PID returns the process running in the agent's pane.
+func (t *tmuxTerminal) PID() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.paneDead {
+		return 0
+	}
+	return t.pid
 }
 
 // IsRunning returns true if the child process is still alive.
-func (t *Terminal) IsRunning() bool {
+func (t *tmuxTerminal) IsRunning() bool {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	return !t.paneDead
 }
 
-// Respawn restarts the claude process in the tmux pane.
-func (t *Terminal) Respawn(continueSession bool) error {
-	cmd := "claude"
-	if continueSession {
-		cmd = "claude --continue"
-	}
-	err := exec.Command("tmux", "-L", t.socket,
-		"respawn-pane", "-t", t.name, "-k", cmd).Run()
+// Respawn restarts the agent process in the tmux pane.
+func (t *tmuxTerminal) Respawn(continueSession bool, flags string) error {
+	cmd := t.agent.withFlags(flags).commandLine(continueSession)
+	err := logging.Run(exec.Command("tmux", "-L", t.socket,
+		"respawn-pane", "-t", t.agentTarget(), "-k", cmd))
 	if err != nil {
 		return err
 	}
+	if t.pipe != nil {
+		t.pipePane()
+	}
 	t.mu.Lock()
 	t.paneDead = false
+	t.exitReported = false
+	t.lastActivity = time.Now()
 	t.mu.Unlock()
 	return nil
 }
 
 // stopPollLoop stops the poll goroutine. Must be called with t.mu held.
 // Returns false if already stopped.
-func (t *Terminal) stopPollLoop() bool {
+func (t *tmuxTerminal) stopPollLoop() bool {
 	if t.closed {
 		return false
 	}
 	t.closed = true
 	close(t.done)
+	if t.control != nil {
+		t.control.close()
+	}
+	if t.pipe != nil {
+		t.stopPipe()
+	}
 	return true
 }
 
 // Close kills the tmux session and stops the poll loop.
-func (t *Terminal) Close() error {
+func (t *tmuxTerminal) Close() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	if !t.stopPollLoop() {
 		return nil
 	}
-	exec.Command("tmux", "-L", t.socket, "kill-session", "-t", t.name).Run()
+	logging.Run(exec.Command("tmux", "-L", t.socket, "kill-session", "-t", t.name))
 	return nil
 }
 
+// Scrollback returns the pane's history and screen.
+func (t *tmuxTerminal) Scrollback() (string, int) {
+	t.mu.Lock()
+	histSize, height := t.cachedHistSize, t.visHeight
+	t.mu.Unlock()
+	return t.capturePaneRange(-histSize, height-1), histSize
+}
+
 // ScrollUp scrolls back by the given number of lines.
-func (t *Terminal) ScrollUp(lines int) {
+func (t *tmuxTerminal) ScrollUp(lines int) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.scrollLines += lines
@@ -716,7 +1266,7 @@ func (t *Terminal) ScrollUp(lines int) {
 }
 
 // ScrollDown scrolls forward by the given number of lines.
-func (t *Terminal) ScrollDown(lines int) {
+func (t *tmuxTerminal) ScrollDown(lines int) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.scrollLines -= lines
@@ -726,45 +1276,153 @@ func (t *Terminal) ScrollDown(lines int) {
 }
 
 // IsScrollMode returns true if the terminal is in scroll mode.
-func (t *Terminal) IsScrollMode() bool {
+func (t *tmuxTerminal) IsScrollMode() bool {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	return t.scrollLines > 0
 }
 
 // ScrollPosition returns the current scroll offset in lines.
-func (t *Terminal) ScrollPosition() int {
+func (t *tmuxTerminal) ScrollPosition() int {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	return t.scrollLines
 }
 
 // ExitScrollMode returns to live view.
-func (t *Terminal) ExitScrollMode() {
+func (t *tmuxTerminal) ExitScrollMode() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.scrollLines = 0
 }
 
+// SocketName returns the tmux socket used for a repository. It is derived from
+// the repo path so tmux sessions persist across ATC restarts.
+func SocketName(repoPath string) string {
+	hash := sha256.Sum256([]byte(repoPath))
+	return fmt.Sprintf("atc-%x", hash[:4])
+}
+
+// KillServer kills the tmux server on the given socket, ending all of its sessions.
+func KillServer(socket string) error {
+	return logging.Run(exec.Command("tmux", "-L", socket, "kill-server"))
+}
+
+// MainSessionName is the tmux session ATC uses for a project's main
+// directory (the control tower), alongside one session per ATC session.
+const MainSessionName = "__main_project__"
+
+// TmuxSession is a tmux session found on an ATC socket.
+type TmuxSession struct {
+	Name string
+	Path string // the directory the session was started in
+}
+
+// ListSessions returns the tmux sessions on a socket. A socket with no
+// running server has no sessions.
+func ListSessions(socket string) ([]TmuxSession, error) {
+	out, err := logging.Output(exec.Command("tmux", "-L", socket, "list-sessions", "-F", "#{session_name}\t#{session_path}"))
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// "no server running" or "error connecting": a stale socket file
+			return nil, nil
+		}
+		return nil, err
+	}
+	var sessions []TmuxSession
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+		name, path, _ := strings.Cut(line, "\t")
+		sessions = append(sessions, TmuxSession{Name: name, Path: path})
+	}
+	return sessions, nil
+}
+
+// TmuxName returns the name tmux gives a session created as name: tmux
+// replaces '.' and ':' with '_'.
+func TmuxName(name string) string {
+	return strings.NewReplacer(".", "_", ":", "_").Replace(name)
+}
+
+// SocketPath returns the path of an ATC socket file.
+func SocketPath(socket string) string {
+	return filepath.Join(socketDir(), socket)
+}
+
+// KillSession kills a single tmux session on the socket.
+func KillSession(socket, name string) error {
+	return logging.Run(exec.Command("tmux", "-L", socket, "kill-session", "-t", name))
+}
+
+// ListSockets returns the names of all ATC tmux sockets (see SocketName)
+// that exist for the current user.
+func ListSockets() ([]string, error) {
+	entries, err := os.ReadDir(socketDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sockets []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "atc-") {
+			sockets = append(sockets, e.Name())
+		}
+	}
+	return sockets, nil
+}
+
+// socketDir returns the directory tmux creates -L sockets in.
+func socketDir() string {
+	dir := os.Getenv("TMUX_TMPDIR")
+	if dir == "" {
+		dir = "/tmp"
+	}
+	return filepath.Join(dir, fmt.Sprintf("tmux-%d", os.Getuid()))
+}
+
 // SessionExists checks whether a tmux session with the given name exists on the socket.
 func SessionExists(socket, name string) bool {
-	err := exec.Command("tmux", "-L", socket, "has-session", "-t", name).Run()
+	err := logging.Run(exec.Command("tmux", "-L", socket, "has-session", "-t", name))
 	return err == nil
 }
 
+// AttachCommand returns a tmux client attached to the session. While it is
+// attached the window follows the client's size; ATC's next Resize takes it
+// back. $TMUX is dropped so it also works when ATC itself runs inside tmux.
+func (tmuxBackend) AttachCommand(socket, name string) *exec.Cmd {
+	cmd := exec.Command("tmux", "-L", socket,
+		"set-option", "-w", "-t", name, "window-size", "latest", ";",
+		"attach-session", "-t", name)
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "TMUX=") {
+			cmd.Env = append(cmd.Env, kv)
+		}
+	}
+	return cmd
+}
+
 // Attach wraps an existing tmux session, resizes it, and starts polling for output.
-func Attach(name string, width, height int, p *tea.Program, tmuxSocket string) (*Terminal, error) {
+func (tmuxBackend) Attach(name string, agent Agent, width, height int, p *tea.Program, tmuxSocket string) (Terminal, error) {
 	// Resize to match current terminal pane
-	exec.Command("tmux", "-L", tmuxSocket,
+	logging.Run(exec.Command("tmux", "-L", tmuxSocket,
 		"resize-window", "-t", name,
 		"-x", fmt.Sprintf("%d", width),
-		"-y", fmt.Sprintf("%d", height)).Run()
+		"-y", fmt.Sprintf("%d", height)))
 
-	t := newTerminal(name, width, height, p, tmuxSocket)
+	t := newTerminal(name, agent, width, height, p, tmuxSocket)
 
 	// Check if the pane process has already exited
 	if t.isPaneDead() {
+		t.mu.Lock()
 		t.paneDead = true
+		t.exitReported = true
+		t.state = StateExited
+		t.mu.Unlock()
 	}
 
 	return t, nil
@@ -772,7 +1430,8 @@ func Attach(name string, width, height int, p *tea.Program, tmuxSocket string) (
 
 // Detach stops the poll loop but does NOT kill the tmux session.
 // The tmux session continues running in the background.
-func (t *Terminal) Detach() {
+func (t *tmuxTerminal) Detach() {
+	t.flushKeys()
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.stopPollLoop()
@@ -1,6 +1,11 @@
 package terminal
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
 
 func TestAddAltModifier(t *testing.T) {
 	tests := []struct {
@@ -76,3 +81,115 @@ func TestAddAltModifier(t *testing.T) {
 		})
 	}
 }
+
+func TestAgentCommandLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		agent Agent
+		cont  bool
+		want  string
+	}{
+		{"bare", Agent{Command: "claude"}, false, "claude"},
+		{"continue", Agent{Command: "claude"}, true, "claude --continue"},
+		{"resume", Agent{Command: "claude", Resume: "4f1c"}, true, "claude --resume '4f1c'"},
+		{"fresh ignores resume", Agent{Command: "claude", Resume: "4f1c"}, false, "claude"},
+		{"term", Agent{Command: "claude", Term: "xterm-256color"}, false, "env TERM='xterm-256color' claude"},
+		{"locale", Agent{Command: "claude", Locale: "en_US.UTF-8"}, false, "env LANG='en_US.UTF-8' LC_ALL='en_US.UTF-8' claude"},
+		{"env", Agent{Command: "claude", Env: []string{"ATC_PORT=3100"}}, false, "env ATC_PORT='3100' claude"},
+		{"shell", Agent{Command: "claude", Shell: "/bin/zsh"}, true, "/bin/zsh -c 'claude --continue'"},
+		{"quoting", Agent{Command: "echo 'hi'", Shell: "bash"}, false, `bash -c 'echo '\''hi'\'''`},
+		{"args", Agent{Command: "claude", Args: []string{"--model", "opus"}}, true, "claude '--model' 'opus' --continue"},
+		{"restart flags", Agent{Command: "claude", Args: []string{"-v"}}.withFlags(" --model opus "), false, "claude '-v' --model opus"},
+		{"container", Agent{Command: "claude", Container: "run-in box"}, true, "run-in box 'claude --continue'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.agent.commandLine(tt.cont)
+			if got != tt.want {
+				t.Errorf("commandLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLiteralKeys(t *testing.T) {
+	term := &tmuxTerminal{socket: "atc-test", name: "s"}
+	tests := []struct {
+		name   string
+		msg    tea.KeyMsg
+		want   string
+		wantOK bool
+	}{
+		{"runes", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ab")}, "ab", true},
+		{"space", tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}, " ", true},
+		{"Alt+rune", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b"), Alt: true}, "\x1bb", true},
+		{"Enter", tea.KeyMsg{Type: tea.KeyEnter}, "", false},
+		{"Escape", tea.KeyMsg{Type: tea.KeyEscape}, "", false},
+		{"Up", tea.KeyMsg{Type: tea.KeyUp}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := literalKeys(tt.msg, term.keyMsgToTmuxArgs(tt.msg))
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("literalKeys() = %q, %v; want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestPasteText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"plain", "hello", "hello"},
+		{"CRLF line endings", "one\r\ntwo\rthree", "one\ntwo\nthree"},
+		{"end marker dropped", "a\x1b[201~b", "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pasteText(tt.text); got != tt.want {
+				t.Errorf("pasteText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitCrashed(t *testing.T) {
+	tests := []struct {
+		exit           string
+		crashed, known bool
+	}{
+		{"0:", false, true},
+		{"1:", true, true},
+		{":9", true, true},
+		{":", false, false},
+		{"", false, false},
+	}
+	for _, tt := range tests {
+		if crashed, known := exitCrashed(tt.exit); crashed != tt.crashed || known != tt.known {
+			t.Errorf("exitCrashed(%q) = %v, %v; want %v, %v", tt.exit, crashed, known, tt.crashed, tt.known)
+		}
+	}
+}
+
+func TestWorkedSince(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		last time.Time
+		want time.Duration
+	}{
+		{"output kept changing", now.Add(-time.Second), time.Second},
+		{"agent went idle in between", now.Add(-IdleThreshold), 0},
+		{"no earlier output", time.Time{}, 0},
+		{"clock went backwards", now.Add(time.Second), 0},
+	}
+	for _, tt := range tests {
+		if got := workedSince(tt.last, now); got != tt.want {
+			t.Errorf("%s: workedSince = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
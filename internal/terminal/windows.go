@@ -0,0 +1,130 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kevinzwang/air-traffic-control/internal/logging"
+)
+
+// A session's tmux session can hold windows besides the agent's, such as a
+// git UI. ATC's pane shows whichever one is selected; the agent keeps running
+// in its own window meanwhile.
+
+// agentTarget returns the tmux target of the agent's pane.
+func (t *tmuxTerminal) agentTarget() string {
+	if t.agentWindow == "" {
+		return t.shownTarget()
+	}
+	return t.agentWindow
+}
+
+// shownTarget returns the tmux target of the pane shown. A bare session name
+// would be matched against window names first.
+func (t *tmuxTerminal) shownTarget() string {
+	return t.name + ":"
+}
+
+// Window returns the name of the window shown, or "" for the agent's.
+func (t *tmuxTerminal) Window() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.window
+}
+
+// Windows returns the names of the session's windows in order, with "" for
+// the agent's.
+func (t *tmuxTerminal) Windows() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.windows)
+}
+
+// windowName returns the name ATC uses for a window: "" for the agent's.
+func (t *tmuxTerminal) windowName(id, name string) string {
+	if t.agentWindow == "" || id == t.agentWindow {
+		return ""
+	}
+	return name
+}
+
+// usePipe reports whether the pipe stream has what's shown. It only carries
+// the agent's pane.
+func (t *tmuxTerminal) usePipe() bool {
+	return t.pipe != nil && t.Window() == ""
+}
+
+// idleInterval is focusState's, except that other windows are polled when
+// the pipe is in use, as their output doesn't go through it.
+func (t *tmuxTerminal) idleInterval() time.Duration {
+	if t.pipe != nil && t.Window() != "" {
+		return t.interval()
+	}
+	return t.focusState.idleInterval()
+}
+
+// OpenWindow shows the window called name, first starting command in a new
+// window in the worktree if there isn't one. The window closes when command
+// exits.
+func (t *tmuxTerminal) OpenWindow(name, command string) error {
+	if t.agentWindow == "" {
+		return errors.New("can't find the agent's tmux window")
+	}
+	if logging.Run(exec.Command("tmux", "-L", t.socket, "select-window", "-t", t.name+":="+name)) != nil {
+		if fields := strings.Fields(command); len(fields) > 0 {
+			if _, err := exec.LookPath(fields[0]); err != nil {
+				return fmt.Errorf("%s not found", fields[0])
+			}
+		}
+		out, err := logging.CombinedOutput(exec.Command("tmux", "-L", t.socket, "new-window",
+			"-n", name, "-t", t.shownTarget(), "-c", "#{session_path}", command))
+		if err != nil {
+			return fmt.Errorf("failed to open %s window: %w: %s", name, err, string(out))
+		}
+	}
+	t.showWindow()
+	return nil
+}
+
+// ShowAgent shows the agent's window again.
+func (t *tmuxTerminal) ShowAgent() error {
+	if t.agentWindow == "" {
+		return nil
+	}
+	if err := logging.Run(exec.Command("tmux", "-L", t.socket, "select-window", "-t", t.agentWindow)); err != nil {
+		return err
+	}
+	t.showWindow()
+	return nil
+}
+
+// CycleWindow shows the next window, or the previous one, wrapping around.
+func (t *tmuxTerminal) CycleWindow(forward bool) error {
+	command := "previous-window"
+	if forward {
+		command = "next-window"
+	}
+	if err := logging.Run(exec.Command("tmux", "-L", t.socket, command, "-t", t.name)); err != nil {
+		return err
+	}
+	t.showWindow()
+	return nil
+}
+
+// showWindow fits the newly selected window to the pane, which may have
+// been resized while it was hidden, and refreshes straight away.
+func (t *tmuxTerminal) showWindow() {
+	t.mu.Lock()
+	width, height := t.visWidth, t.visHeight
+	t.mu.Unlock()
+
+	logging.Run(exec.Command("tmux", "-L", t.socket,
+		"resize-window", "-t", t.shownTarget(),
+		"-x", fmt.Sprintf("%d", width),
+		"-y", fmt.Sprintf("%d", height)))
+	t.wakeUp()
+}
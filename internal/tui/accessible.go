@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kevinzwang/air-traffic-control/internal/events"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+)
+
+// Accessible mode is for screen readers. ATC runs in the normal screen
+// rather than the alternate one and without the mouse, so the state changes,
+// messages and errors it prints as plain lines above its view are read out.
+// Panes are shown one at a time, the sidebar as a plain list with each
+// session's state in words, the selection is marked with ">" rather than
+// color alone, and dialogs drop their box-drawing borders
+
+// accessible is whether accessible mode is on. Change it with SetAccessible.
+var accessible bool
+
+// SetAccessible turns accessible mode on or off and rebuilds all styles
+func SetAccessible(on bool) {
+	accessible = on
+	buildStyles()
+}
+
+// Accessible reports whether accessible mode is on
+func Accessible() bool {
+	return accessible
+}
+
+// accessibleStyles drops borders and marks the selected list item in text
+func accessibleStyles() {
+	sidebarFocusedStyle = lipgloss.NewStyle()
+	sidebarUnfocusedStyle = lipgloss.NewStyle()
+	dialogBoxStyle = lipgloss.NewStyle().Padding(1, 2)
+	selectedItemStyle = selectedItemStyle.SetString(">")
+	normalItemStyle = normalItemStyle.SetString(" ")
+}
+
+// announce queues a line to print above the view in accessible mode.
+// Agents starting work aren't announced; finishing it is.
+func (m *Model) announce(level logLevel, text string) {
+	if !accessible {
+		return
+	}
+	switch level {
+	case logError:
+		text = "Error: " + text
+	case logEvent:
+		if strings.HasSuffix(text, " "+events.KindWorking) {
+			return
+		}
+	}
+	m.announcements = append(m.announcements, text)
+}
+
+// printAnnouncements prints the queued announcements
+func (m *Model) printAnnouncements() tea.Cmd {
+	if len(m.announcements) == 0 {
+		return nil
+	}
+	lines := strings.Join(m.announcements, "\n")
+	m.announcements = nil
+	if asciiOnly {
+		lines = toASCII(lines)
+	}
+	return tea.Println(lines)
+}
+
+// restoreMouse turns mouse tracking back on after a command that took over
+// the terminal reset it, except in accessible mode, which runs without it
+func (m *Model) restoreMouse() tea.Cmd {
+	if accessible {
+		return nil
+	}
+	return tea.EnableMouseCellMotion
+}
+
+// sessionState describes a session's agent in words
+func (m *Model) sessionState(s *session.Session) string {
+	t, ok := m.terminals[s.Name]
+	switch {
+	case m.settingUpSessions[s.Name]:
+		return "setting up"
+	case m.crashLooped(s.Name):
+		return "crashed"
+	case !ok:
+		return "not started"
+	case !t.IsRunning():
+		return "exited"
+	}
+	return t.State().String()
+}
+
+// viewAccessibleSidebar lists the project's sessions as plain lines, one
+// per session: its number, name, state and what it was last doing
+func (m *Model) viewAccessibleSidebar() string {
+	var b strings.Builder
+	repoName := m.repoName
+	if repoName == "" {
+		repoName = "No project"
+	}
+	b.WriteString(titleStyle.Render("Project "+repoName) + "\n")
+	if m.isProjectHeaderSelected() {
+		b.WriteString("> " + repoName + " (main worktree)\n")
+	}
+
+	sessions := m.activeSessions()
+	if len(sessions) == 0 {
+		b.WriteString("No sessions\n")
+	}
+	for i, s := range sessions {
+		marker := "  "
+		if m.cursor == i {
+			marker = "> "
+		}
+		line := fmt.Sprintf("%s%d. %s, %s", marker, i+1, s.Name, m.sessionState(s))
+		if m.pinnedSession != nil && m.pinnedSession.Name == s.Name {
+			line += ", pinned"
+		}
+		if m.isDirty(s) {
+			line += ", uncommitted changes"
+		}
+		if summary := m.sessionSummary(s); summary != "" {
+			line += ": " + summary
+		}
+		b.WriteString(truncate(line, max(m.windowWidth, 10)) + "\n")
+	}
+	if n := m.archivedCount(); n > 0 {
+		marker := "  "
+		if m.cursor == len(sessions) {
+			marker = "> "
+		}
+		b.WriteString(fmt.Sprintf("%s%d archived\n", marker, n))
+	}
+	for i, s := range m.inlineArchived() {
+		marker := "  "
+		if m.cursor == len(sessions)+1+i {
+			marker = "> "
+		}
+		b.WriteString(truncate(fmt.Sprintf("%s%s, archived", marker, s.Name), max(m.windowWidth, 10)) + "\n")
+	}
+	if note := m.updateNote(); note != "" {
+		b.WriteString(note + "\n")
+	}
+	b.WriteString(fmt.Sprintf("Press %s for help", m.keys.Help))
+
+	return lipgloss.NewStyle().
+		Width(m.windowWidth).
+		Height(m.layoutHeight()).
+		MaxHeight(m.layoutHeight()).
+		Render(b.String())
+}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+)
+
+const (
+	// activityLimit is the number of events loaded into the activity overlay
+	activityLimit = 200
+	// activityVisible is the number of events shown at once
+	activityVisible = 15
+)
+
+// activityLoadedMsg carries the events for the activity overlay
+type activityLoadedMsg struct {
+	events []*database.SessionEvent
+}
+
+// openActivityOverlay shows the activity log of the selected session, or of
+// the whole project when the project header is selected
+func (m *Model) openActivityOverlay() (tea.Model, tea.Cmd) {
+	if m.service == nil || m.db == nil {
+		return m, nil
+	}
+	m.activitySession = ""
+	if !m.isProjectHeaderSelected() {
+		active := m.activeSessions()
+		if m.cursor < 0 || m.cursor >= len(active) {
+			return m, nil
+		}
+		m.activitySession = active[m.cursor].Name
+	}
+	m.activityEvents = nil
+	m.activityScrollOffset = 0
+	m.overlay = overlayActivity
+
+	repoPath, name := m.service.RepoPath(), m.activitySession
+	return m, func() tea.Msg {
+		events, err := m.db.ListEvents(repoPath, name, activityLimit)
+		if err != nil {
+			return errMsg{err}
+		}
+		return activityLoadedMsg{events}
+	}
+}
+
+func (m *Model) handleActivityKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", m.keys.Activity, m.keys.Quit:
+		m.overlay = overlayNone
+		return m, nil
+	case "up", "k":
+		if m.activityScrollOffset > 0 {
+			m.activityScrollOffset--
+		}
+		return m, nil
+	case "down", "j":
+		if m.activityScrollOffset < len(m.activityEvents)-activityVisible {
+			m.activityScrollOffset++
+		}
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *Model) viewActivity() string {
+	var b strings.Builder
+	title := "Activity: " + m.repoName
+	if m.activitySession != "" {
+		title = "Activity: " + m.activitySession
+	}
+	b.WriteString(titleStyle.Render(truncate(title, 60)))
+	b.WriteString("\n\n")
+
+	if len(m.activityEvents) == 0 {
+		b.WriteString(metadataStyle.Render("No events recorded") + "\n")
+	} else {
+		endIdx := m.activityScrollOffset + activityVisible
+		if endIdx > len(m.activityEvents) {
+			endIdx = len(m.activityEvents)
+		}
+		if m.activityScrollOffset > 0 {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↑ %d newer", m.activityScrollOffset)) + "\n")
+		}
+		for _, e := range m.activityEvents[m.activityScrollOffset:endIdx] {
+			line := e.Kind
+			if m.activitySession == "" {
+				line = e.SessionName + " " + line
+			}
+			if e.Detail != "" {
+				line += " (" + e.Detail + ")"
+			}
+			b.WriteString(metadataStyle.Render(e.CreatedAt.Local().Format("Jan 02 15:04:05")) + "  ")
+			b.WriteString(normalItemStyle.Render(truncate(line, 56)) + "\n")
+		}
+		if endIdx < len(m.activityEvents) {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↓ %d older", len(m.activityEvents)-endIdx)) + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("[↑/↓] Scroll  [Esc] Close"))
+	return dialogBoxStyle.Render(b.String())
+}
@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/events"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// Adopting a worktree created by hand or by another tool, or left by a
+// detached session, makes it a session named after its branch, with the
+// agent started in it (see also `atc adopt`)
+
+// adoptableLoadedMsg carries the project's worktrees no session has
+type adoptableLoadedMsg struct {
+	worktrees []worktree.Worktree
+	err       error
+}
+
+// sessionAdoptedMsg reports a worktree adopted as a session
+type sessionAdoptedMsg struct {
+	session *session.Session
+}
+
+// openAdopt lists the project's worktrees that can be adopted
+func (m *Model) openAdopt() (tea.Model, tea.Cmd) {
+	if m.service == nil {
+		return m, nil
+	}
+	m.adoptList = nil
+	m.adoptCursor = 0
+	m.adoptLoading = true
+	m.overlay = overlayAdopt
+	m.err = nil
+	svc := m.service
+	return m, func() tea.Msg {
+		worktrees, err := svc.AdoptableWorktrees()
+		return adoptableLoadedMsg{worktrees: worktrees, err: err}
+	}
+}
+
+// showAdoptable fills in the adopt overlay once the worktrees are listed
+func (m *Model) showAdoptable(msg adoptableLoadedMsg) {
+	if m.overlay != overlayAdopt {
+		return
+	}
+	m.adoptLoading = false
+	if msg.err != nil {
+		m.err = msg.err
+		return
+	}
+	m.adoptList = msg.worktrees
+}
+
+// handleAdoptKeys adopts the selected worktree
+func (m *Model) handleAdoptKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		return m.dismissOverlay()
+	case "up", "k":
+		if m.adoptCursor > 0 {
+			m.adoptCursor--
+		}
+	case "down", "j":
+		if m.adoptCursor < len(m.adoptList)-1 {
+			m.adoptCursor++
+		}
+	case "enter":
+		if m.adoptCursor >= len(m.adoptList) {
+			return m, nil
+		}
+		path := m.adoptList[m.adoptCursor].Path
+		svc := m.service
+		m.err = nil
+		return m, func() tea.Msg {
+			sess, err := svc.AdoptWorktree(path)
+			if err != nil {
+				return errMsg{err}
+			}
+			return sessionAdoptedMsg{sess}
+		}
+	}
+	return m, nil
+}
+
+// finishAdopt selects the adopted session and starts its agent
+func (m *Model) finishAdopt(msg sessionAdoptedMsg) tea.Cmd {
+	sess := msg.session
+	m.logEvent(sess.Name, events.KindAdopted, sess.WorktreePath)
+	m.message = fmt.Sprintf("Adopted %s as session '%s'", sess.WorktreePath, sess.Name)
+	m.overlay = overlayNone
+	m.adoptList = nil
+	m.selectAfterLoad = sess.Name
+	m.activatingSession = sess.Name
+	return tea.Batch(m.loadSessions(), m.activateSession(sess, true))
+}
+
+// viewAdopt lists the project's worktrees that no session has
+func (m *Model) viewAdopt() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Adopt a Worktree"))
+	b.WriteString("\n")
+	b.WriteString(subtitleStyle.Render("Worktrees of this project that no session has"))
+	b.WriteString("\n\n")
+
+	helpText := "[↑/↓] Navigate  [Enter] Adopt as a session  [Esc] Cancel"
+	switch {
+	case m.adoptLoading:
+		b.WriteString(m.spinner.View() + " Listing worktrees...")
+		b.WriteString("\n")
+	case len(m.adoptList) == 0:
+		b.WriteString(metadataStyle.Render("  Every worktree already has a session") + "\n")
+	default:
+		maxVisible := 10
+		startIdx := 0
+		if m.adoptCursor >= maxVisible {
+			startIdx = m.adoptCursor - maxVisible + 1
+		}
+		endIdx := min(startIdx+maxVisible, len(m.adoptList))
+		itemWidth := len(helpText)
+		if startIdx > 0 {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↑ %d more", startIdx)) + "\n")
+		}
+		for i := startIdx; i < endIdx; i++ {
+			wt := m.adoptList[i]
+			branch := wt.Branch
+			if branch == "" {
+				branch = "(not on a branch)"
+			}
+			label := truncate(branch, 30) + "  " + truncate(wt.Path, itemWidth-34)
+			if i == m.adoptCursor {
+				b.WriteString(selectedItemStyle.Width(itemWidth).Render(label) + "\n")
+			} else {
+				b.WriteString(normalItemStyle.Width(itemWidth).Render(label) + "\n")
+			}
+		}
+		if endIdx < len(m.adoptList) {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↓ %d more", len(m.adoptList)-endIdx)) + "\n")
+		}
+	}
+
+	if m.err != nil {
+		b.WriteString("\n" + errorStyle.Render(truncate(m.err.Error(), 70)) + "\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(helpText))
+	return dialogBoxStyle.Render(b.String())
+}
@@ -2,13 +2,15 @@ package tui
 
 import (
 	"bytes"
-	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 	"unicode"
@@ -18,9 +20,15 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/kevinzwang/air-traffic-control/internal/config"
 	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/events"
+	"github.com/kevinzwang/air-traffic-control/internal/github"
 	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/shell"
 	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+	"github.com/kevinzwang/air-traffic-control/internal/watch"
 	"github.com/kevinzwang/air-traffic-control/internal/worktree"
 )
 
@@ -35,6 +43,7 @@ type focus int
 const (
 	focusSidebar focus = iota
 	focusTerminal
+	focusPinned // pinned session in split view
 )
 
 // Overlay state
@@ -52,6 +61,28 @@ const (
 	overlayCreating
 	overlayArchivedSessions
 	overlaySelectProject
+	overlaySetupTrust
+	overlaySetupPreview
+	overlayUsage
+	overlayActivity
+	overlayQuitConfirm
+	overlaySelectPullRequest
+	overlayBaseSync
+	overlayLand
+	overlayChangedFiles
+	overlayVerifyTrust
+	overlayVerifyLog
+	overlayPromptQueue
+	overlayHandoffs
+	overlayTrash
+	overlayHistory
+	overlayResume
+	overlayFork
+	overlayDetachConfirm
+	overlayAdopt
+	overlayLog
+	overlaySwitcher
+	overlayExitTo
 )
 
 // Selection mode for multi-click
@@ -66,16 +97,19 @@ const (
 const multiClickThreshold = 500 * time.Millisecond
 
 // mainProjectTerminalKey is the key used in m.terminals for the main project directory session.
-const mainProjectTerminalKey = "__main_project__"
+const mainProjectTerminalKey = terminal.MainSessionName
 
 // Custom messages
 type sessionsLoadedMsg struct {
-	sessions []*session.Session
+	sessions []*session.Session // the active ones
+	archived int
 }
 
 type sessionCreatedMsg struct {
 	session       *session.Session
 	setupCommands []string
+	setupTrusted  bool  // false if the worktree's setup commands need confirming
+	copyErr       error // copying a forked session's uncommitted changes failed
 }
 
 type setupCompleteMsg struct {
@@ -88,7 +122,8 @@ type sessionDeletedMsg struct {
 }
 
 type sessionArchivedMsg struct {
-	name string
+	name      string
+	uploadErr error
 }
 
 type sessionUnarchivedMsg struct {
@@ -100,7 +135,11 @@ type errMsg struct {
 }
 
 type branchesLoadedMsg struct {
+	repoPath             string
+	refs                 time.Time // the repository's refs change time when listed
+	sessionsVersion      int64
 	branches             []string
+	remoteBranches       []string
 	branchesWithSessions map[string]bool
 }
 
@@ -112,6 +151,12 @@ type spawnTerminalFinishedMsg struct {
 	err error
 }
 
+// nativeAttachFinishedMsg is sent when the user detaches a tmux client
+// started with the attach key
+type nativeAttachFinishedMsg struct {
+	err error
+}
+
 type projectSwitchedMsg struct {
 	service       *session.Service
 	repoName      string
@@ -123,24 +168,49 @@ type Model struct {
 	focus         focus
 	overlay       overlay
 	db            *database.DB
+	cfg           *config.GlobalConfig
+	keys          keyMap
 	service       *session.Service
 	repoName      string
 	sessions      []*session.Session
 	cursor        int
 	scrollOffset  int
-	activeSession *session.Session // Currently viewed session
+	activeSession *session.Session  // Currently viewed session
+	pinnedSession *session.Session  // Shown beside activeSession in split view
+	summaries     map[string]string // latest conversation summary per session name
+
+	// Agents auto-respawn is restarting after crashes (see respawn.go)
+	crashes map[string]*crashLoop
+
+	// CPU and memory of running agents (see resources.go)
+	resources map[string]*sessionResources
+
+	// State of each worktree's container, when agents run in containers
+	// (see container.go)
+	containers map[string]string
+
+	// Ports handed out to sessions, by session ID, and the first one each is
+	// listening on, by worktree path (see ports.go)
+	ports      map[string][]int
+	devServers map[string]int
 
 	// Terminal instances (session name -> Terminal)
-	terminals  map[string]*terminal.Terminal
+	terminals  map[string]terminal.Terminal
+	backend    terminal.Backend // starts and reattaches terminals
 	program    *tea.Program
 	tmuxSocket string
 
+	// Shared status-change log (read by `atc tail`)
+	eventLog *events.Log
+
 	// Project selection state
 	projects            []*database.Project
 	filteredProjects    []*database.Project
 	projectCursor       int
 	projectScrollOffset int
 	projectInput        textinput.Model
+	projectPathMode     bool              // entering a new project's path (see projects.go)
+	forgettingProject   *database.Project // confirming its removal (see projects.go)
 	noProjectMode       bool
 
 	// Window dimensions
@@ -153,18 +223,44 @@ type Model struct {
 	archivedList         []*session.Session
 	deleteFromArchived   bool
 
+	// How many sessions the current project has archived. Only the active
+	// ones are in sessions; archivedList is loaded a page at a time (see
+	// archived.go)
+	archivedTotal   int
+	archivedLoading bool
+
+	// Whether the loaded archived sessions are listed in the sidebar,
+	// greyed out beneath the active ones
+	archivedInline bool
+
 	// Spinner for creating state
-	spinner           spinner.Model
-	err               error
-	message           string
-	settingUpSessions map[string]bool
+	spinner             spinner.Model
+	err                 error
+	message             string
+	settingUpSessions   map[string]bool
+	setupFailedSessions map[string]bool
 
 	// Session creation fields
 	createInput        textinput.Model
 	pendingSessionName string
+	pendingSessionBase string // recorded as the new session's base instead of the branch it starts from
 	selectAfterLoad    string // session name to select after next sessionsLoadedMsg
+	restoreFocus       bool   // focus the terminal after next sessionsLoadedMsg (see uistate.go)
 	activatingSession  string // session name currently being activated (to prevent double-create)
 
+	// Each project's last branch listing, by repository path (see
+	// branchcache.go)
+	branchCaches map[string]*branchCache
+
+	// Notices branch and session changes made outside this instance (see
+	// watch.go); nil if file watching isn't available
+	watcher *watch.Watcher
+
+	// Sessions change counter last read from the database, to notice other
+	// ATC instances' changes (see sync.go)
+	sessionsVersion     int64
+	sessionsVersionSeen bool
+
 	// Branch selection fields
 	branches             []string
 	filteredBranches     []string
@@ -172,9 +268,117 @@ type Model struct {
 	branchCursor         int
 	branchScrollOffset   int
 	branchesWithSessions map[string]bool
+	remoteBranches       []string
+	showRemoteBranches   bool // list remoteBranches in the branch pickers too
 	currentBranch        string
 	selectedBranchName   string
 	newSessionInput      textinput.Model
+	defaultBaseBranch    string // preselected and marked in the base-branch picker
+	fetchFirst           bool   // fetch the base branch before creating the session
+	fetching             bool   // the session being created is fetching its base first
+
+	// Review sessions from pull requests (see prs.go)
+	pullRequests        []github.PullRequest
+	pullRequestCursor   int
+	pullRequestsLoading bool
+	pendingPrompts      map[string]string // typed into a new session's agent once it waits for input
+
+	// Sessions' pull request and CI status by worktree path (see prs.go)
+	prStatuses map[string]github.PullRequestStatus
+
+	// Sessions that would conflict with their base, by worktree path (see
+	// conflicts.go)
+	conflicts map[string]baseConflict
+
+	// Sessions' changes against their base, by worktree path (see
+	// diffstat.go)
+	diffStats map[string]diffStat
+
+	// Sessions with uncommitted changes, by worktree path, and whether a
+	// check is running (see dirty.go)
+	dirty         map[string]bool
+	dirtyChecking bool
+
+	// How long each session's agent has worked in total, by session ID (see
+	// worktime.go)
+	workTimes map[string]time.Duration
+
+	// Tags plugins put on sessions, sessions whose agent is working, and the
+	// keyword lines on each agent's screen at the last scan, by session name
+	// (see plugins.go)
+	sessionTags   map[string][]string
+	pluginWorking map[string]bool
+	pluginMatches map[string]map[string]bool
+
+	// Sync of a session with its base branch (see basesync.go)
+	baseSync *baseSync
+
+	// Session being landed on its base (see land.go)
+	landing *landing
+
+	// Changed-files browser and diff viewer (see changes.go)
+	changedFiles *changedFiles
+
+	// Read-only view of a session's conversation (see history.go)
+	history *conversationHistory
+
+	// Conversation picker shown before starting an agent whose worktree has
+	// more than one (see conversations.go)
+	resumePicker *resumePicker
+
+	// Fork being named, and the session whose uncommitted changes the
+	// session being created gets (see fork.go)
+	forking         *forking
+	pendingForkFrom *session.Session
+
+	// Verify command runs, by worktree path, and the session whose trust
+	// prompt or log is open (see verify.go)
+	verifyRuns    map[string]*verifyRun
+	verifySession *session.Session
+	verifyScroll  int
+
+	// Prompts queued for sessions, by session name, and the open queue (see
+	// queue.go)
+	promptQueues map[string][]string
+	queueSent    map[string]bool // sent a prompt the agent hasn't started on yet
+	queueSession string
+	queueCursor  int
+	queueInput   textinput.Model
+
+	// Prompts handed to other sessions once a session verifies, by source
+	// session name, and the open hand-off overlay (see pipeline.go)
+	handoffs      map[string][]handoff
+	handoffEditor *handoffEditor
+
+	// Scheduled runs (see schedule.go): the session names already created
+	// or skipped, and the created sessions whose agent isn't done yet
+	scheduleRuns      map[string]bool
+	scheduledSessions map[string]bool
+
+	// Trash overlay (see trash.go), and the trashed session waiting for a
+	// second D to be deleted for good
+	trashList    []*session.TrashedSession
+	trashCursor  int
+	trashPurging string
+
+	// Adopt overlay: the project's worktrees no session has (see adopt.go)
+	adoptList    []worktree.Worktree
+	adoptCursor  int
+	adoptLoading bool
+
+	// Usage limits agents ran into, by session name, and the last limit
+	// message seen on each session's screen (see ratelimit.go)
+	rateLimits   map[string]rateLimit
+	screenLimits map[string]string
+
+	// Setup trust prompt (see setup_trust.go) and the setup preview before a
+	// project's first session (see setup_preview.go)
+	pendingBaseBranch  string
+	pendingUseExisting bool
+	skipSetup          bool             // create the pending session without running setup
+	untrustedSession   *session.Session // created session whose setup awaits trust
+	untrustedSetup     []string
+	setupPreviewed     bool
 
 	// Delete confirmation
 	selectedSession *session.Session
@@ -195,32 +399,111 @@ type Model struct {
 
 	// Drag extension mode
 	selMode selectionMode
+
+	// Scrollback search (see search.go)
+	search scrollbackSearch
+
+	// Keyboard copy mode (see copymode.go)
+	copy copyMode
+
+	// Menu for restarting an exited agent (see restart.go)
+	restart restartMenu
+
+	// The mouse selects text even over programs that use it, instead of
+	// passing its events through (see mouse.go)
+	mouseSelect bool
+
+	// Sidebar layout (persisted per user)
+	sidebarWidth     int
+	sidebarCollapsed bool
+	resizingSidebar  bool // dragging the sidebar border
+
+	// Guided walkthrough (`atc tutorial`)
+	tutorial tutorialStep
+
+	// Token usage overlay
+	usageRows    []*database.SessionUsage
+	usageLoading bool
+
+	// Activity log overlay
+	activitySession      string // "" for the whole project
+	activityEvents       []*database.SessionEvent
+	activityScrollOffset int
+
+	// Errors, messages and session events since ATC started, the last error
+	// and message logged, and the log overlay (see log.go)
+	log           logRing
+	loggedErr     string
+	loggedMessage string
+	logCursor     int
+	logErrorsOnly bool
+
+	// The host terminal's title as last set (see title.go)
+	shownTitle string
+
+	// Quick switcher (see switcher.go), and the session it opens once the
+	// project it switched to has loaded
+	switcher *switcher
+	switchTo string
+
+	// What to run in ATC's place once it quits to a session's worktree (see
+	// exitto.go)
+	exitCommand *ExitCommand
+
+	// The latest release, once looked up (see version.go)
+	latestVersion string
+
+	// Lines waiting to be printed above the view in accessible mode (see
+	// accessible.go)
+	announcements []string
+
+	// Open projects. The current tab's state lives in the fields above;
+	// tabs[tabIndex] is only up to date after captureTab.
+	tabs     []*projectTab
+	tabIndex int
 }
 
-func NewModel(db *database.DB, service *session.Service, repoName string, invokingBranch string) *Model {
+func NewModel(db *database.DB, cfg *config.GlobalConfig, service *session.Service, repoName string, invokingBranch string) *Model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
+	if asciiOnly {
+		s.Spinner = spinner.Line
+	}
+	if reducedMotion {
+		s.Spinner = stillSpinner
+	}
 
 	var tmuxSocket string
 	if service != nil {
 		// Stable socket name based on repo path so tmux sessions persist across ATC restarts
-		hash := sha256.Sum256([]byte(service.RepoPath()))
-		tmuxSocket = fmt.Sprintf("atc-%x", hash[:4])
+		tmuxSocket = terminal.SocketName(service.RepoPath())
+	}
+
+	m := &Model{
+		focus:               focusSidebar,
+		overlay:             overlayNone,
+		db:                  db,
+		cfg:                 cfg,
+		keys:                newKeyMap(cfg.Keybindings),
+		service:             service,
+		repoName:            repoName,
+		spinner:             s,
+		currentBranch:       invokingBranch,
+		terminals:           make(map[string]terminal.Terminal),
+		backend:             terminal.Default,
+		tmuxSocket:          tmuxSocket,
+		settingUpSessions:   make(map[string]bool),
+		setupFailedSessions: make(map[string]bool),
+		noProjectMode:       service == nil,
+		pendingPrompts:      make(map[string]string),
 	}
-
-	return &Model{
-		focus:             focusSidebar,
-		overlay:           overlayNone,
-		db:                db,
-		service:           service,
-		repoName:          repoName,
-		spinner:           s,
-		currentBranch:     invokingBranch,
-		terminals:         make(map[string]*terminal.Terminal),
-		tmuxSocket:        tmuxSocket,
-		settingUpSessions: make(map[string]bool),
-		noProjectMode:     service == nil,
+	if service != nil {
+		m.tabs = []*projectTab{{}}
+		m.captureTab()
 	}
+	m.loadSidebarSettings()
+	m.restoreUIState()
+	return m
 }
 
 // SetProgram sets the Bubble Tea program reference, needed for terminal async messages.
@@ -228,16 +511,91 @@ func (m *Model) SetProgram(p *tea.Program) {
 	m.program = p
 }
 
+// SetEventLog sets the log that session status changes are appended to.
+func (m *Model) SetEventLog(l *events.Log) {
+	m.eventLog = l
+}
+
+// logEvent records a session status change in the shared event log.
+// Lifecycle events (everything but working/waiting) are also kept in the
+// project's activity log in the database.
+func (m *Model) logEvent(sessionName, kind, detail string) {
+	repo, svc := m.projectFor(sessionName)
+	if sessionName == mainProjectTerminalKey {
+		sessionName = "(main)"
+	}
+	m.eventLog.Append(events.Event{
+		Repo:    repo,
+		Session: sessionName,
+		Kind:    kind,
+		Detail:  detail,
+	})
+	entry := sessionName + " " + kind
+	if detail != "" {
+		entry += " (" + detail + ")"
+	}
+	m.addLog(logEvent, entry)
+	if m.db != nil && svc != nil && kind != events.KindWorking && kind != events.KindWaiting {
+		err := m.db.InsertEvent(&database.SessionEvent{
+			RepoPath:    svc.RepoPath(),
+			SessionName: sessionName,
+			Kind:        kind,
+			Detail:      detail,
+			CreatedAt:   time.Now(),
+		})
+		if err != nil {
+			m.addLog(logError, fmt.Sprintf("failed to record %s %s in the activity log: %v", sessionName, kind, err))
+			slog.Debug("failed to record event", "session", sessionName, "kind", kind, "err", err)
+		}
+	}
+}
+
 func (m *Model) Init() tea.Cmd {
 	if m.noProjectMode {
+		open := m.openLastProject()
+		if open == nil {
+			open = m.loadProjects()
+		}
 		return tea.Batch(
-			m.loadProjects(),
+			open,
 			m.spinner.Tick,
+			summaryTick(),
+			resourceTick(),
+			m.checkSessionsVersion(),
+			syncTick(),
+			prStatusTick(pollInterval(5*time.Second)),
+			conflictTick(),
+			diffStatTick(),
+			dirtyTick(),
+			workTimeTick(),
+			m.saveWorkTime(),
+			scheduleTick(),
+			m.scanOrphans(),
+			m.expireArchived(),
+			pluginScanTick(),
+			m.checkForUpdate(),
+			m.waitForChange(),
 		)
 	}
 	return tea.Batch(
 		m.loadSessions(),
 		m.spinner.Tick,
+		summaryTick(),
+		resourceTick(),
+		m.checkSessionsVersion(),
+		syncTick(),
+		prStatusTick(pollInterval(5*time.Second)),
+		conflictTick(),
+		diffStatTick(),
+		dirtyTick(),
+		workTimeTick(),
+		m.saveWorkTime(),
+		scheduleTick(),
+		m.scanOrphans(),
+		m.expireArchived(),
+		pluginScanTick(),
+		m.checkForUpdate(),
+		m.waitForChange(),
 	)
 }
 
@@ -250,6 +608,31 @@ func (m *Model) detachTerminal(name string) {
 	}
 }
 
+// detachDeletedTerminals lets go of the terminals of sessions another ATC
+// instance deleted or archived. A load can be read before a session was
+// created and arrive after, so the sessions it's missing are only taken as
+// gone once the database says so too
+func (m *Model) detachDeletedTerminals() {
+	var missing []string
+	for name := range m.terminals {
+		if name != mainProjectTerminalKey && !m.hasSession(name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 || m.service == nil {
+		return
+	}
+	current, err := m.service.ListActiveSessions()
+	if err != nil {
+		return
+	}
+	for _, name := range missing {
+		if !slices.ContainsFunc(current, func(s *session.Session) bool { return s.Name == name }) {
+			m.detachTerminal(name)
+		}
+	}
+}
+
 // mainProjectSession returns an in-memory Session (not persisted to DB)
 // that targets the main project directory (repo root).
 func (m *Model) mainProjectSession() *session.Session {
@@ -273,34 +656,67 @@ func (m *Model) loadSessions() tea.Cmd {
 		if m.service == nil {
 			return sessionsLoadedMsg{sessions: nil}
 		}
-		sessions, err := m.service.ListSessions("")
+		sessions, err := m.service.ListActiveSessions()
 		if err != nil {
 			return errMsg{err}
 		}
-		return sessionsLoadedMsg{sessions}
+		archived, err := m.service.ArchivedCount()
+		if err != nil {
+			return errMsg{err}
+		}
+		return sessionsLoadedMsg{sessions: sessions, archived: archived}
 	}
 }
 
+// loadBranches shows the project's cached branches, if any, and lists them
+// again in the background unless nothing has changed (see branchcache.go)
 func (m *Model) loadBranches() tea.Cmd {
+	if m.service == nil {
+		return func() tea.Msg { return errMsg{fmt.Errorf("no project selected")} }
+	}
+	m.showCachedBranches()
+	return m.refreshBranches()
+}
+
+// refreshBranches lists the project's branches in the background, unless
+// nothing has changed since they were cached
+func (m *Model) refreshBranches() tea.Cmd {
+	service, db := m.service, m.db
+	cache := m.branchCaches[service.RepoPath()]
 	return func() tea.Msg {
-		if m.service == nil {
-			return errMsg{fmt.Errorf("no project selected")}
+		repoPath := service.RepoPath()
+		refs, refsOK := worktree.RefsModTime(repoPath)
+		var sessionsVersion int64
+		if db != nil {
+			var err error
+			if sessionsVersion, err = db.SessionsVersion(); err != nil {
+				refsOK = false
+			}
 		}
-		branches, err := m.service.ListBranches()
+		if cache.current(refs, refsOK, sessionsVersion) {
+			return nil
+		}
+
+		branches, err := service.ListBranches()
+		if err != nil {
+			return errMsg{err}
+		}
+		remoteBranches, err := service.ListRemoteBranches()
 		if err != nil {
 			return errMsg{err}
 		}
 
-		branchesWithSessions := make(map[string]bool)
-		for _, branch := range branches {
-			sess, _ := m.service.GetSessionByBranch(branch)
-			if sess != nil {
-				branchesWithSessions[branch] = true
-			}
+		branchesWithSessions, err := service.BranchesWithSessions()
+		if err != nil {
+			return errMsg{err}
 		}
 
 		return branchesLoadedMsg{
+			repoPath:             repoPath,
+			refs:                 refs,
+			sessionsVersion:      sessionsVersion,
 			branches:             branches,
+			remoteBranches:       remoteBranches,
 			branchesWithSessions: branchesWithSessions,
 		}
 	}
@@ -318,10 +734,13 @@ func (m *Model) loadProjects() tea.Cmd {
 
 func (m *Model) switchProject(project *database.Project) tea.Cmd {
 	return func() tea.Msg {
-		svc, err := session.NewService(m.db, project.RepoPath)
+		svc, err := session.NewService(m.db, project.RepoPath, m.cfg)
 		if err != nil {
 			return errMsg{err}
 		}
+		if err := m.db.TouchProject(project.RepoPath); err != nil {
+			return errMsg{err}
+		}
 		branch, err := worktree.GetCurrentBranch(project.RepoPath)
 		if err != nil {
 			branch = "HEAD"
@@ -332,28 +751,55 @@ func (m *Model) switchProject(project *database.Project) tea.Cmd {
 
 // --- Update ---
 
+// Update handles msg, then retitles the host terminal if what's shown changed
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if reducedMotion && isAnimationFrame(msg) {
+		return m, nil
+	}
+	model, cmd := m.update(msg)
+	if title := m.updateWindowTitle(); title != nil {
+		cmd = tea.Batch(cmd, title)
+	}
+	if lines := m.printAnnouncements(); lines != nil {
+		cmd = tea.Batch(cmd, lines)
+	}
+	return model, cmd
+}
+
+func (m *Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	defer m.syncTerminalFocus()
+	defer m.captureLog()
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.windowWidth = msg.Width
 		m.windowHeight = msg.Height
-		// Resize active terminal
-		if m.activeSession != nil {
-			if t, ok := m.terminals[m.activeSession.Name]; ok {
-				tw, th := m.terminalPaneDimensions()
-				t.Resize(tw, th)
-			}
-		}
+		// Resize active (and pinned) terminals
+		m.resizeTerminalIfNeeded()
+		return m, nil
+
+	case tea.FocusMsg:
+		terminal.SetWindowFocused(true)
+		return m, nil
+
+	case tea.BlurMsg:
+		terminal.SetWindowFocused(false)
 		return m, nil
 
 	case tea.KeyMsg:
+		// Input means the window has focus, even if the outer terminal
+		// doesn't report focus changes
+		terminal.SetWindowFocused(true)
 		return m.handleKeyMsg(msg)
 
 	case tea.MouseMsg:
+		terminal.SetWindowFocused(true)
 		return m.handleMouseMsg(msg)
 
 	case sessionsLoadedMsg:
 		m.sessions = msg.sessions
+		m.archivedTotal = msg.archived
+		m.detachDeletedTerminals()
 		active := m.activeSessions()
 		// If we need to select a specific session (e.g. just created), move cursor to it
 		if m.selectAfterLoad != "" {
@@ -366,47 +812,227 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.selectAfterLoad = ""
 		}
 		// Clamp cursor to valid range
-		maxIdx := len(active) - 1
-		if m.archivedCount() > 0 {
-			maxIdx++
-		}
-		if maxIdx < 0 {
-			maxIdx = 0
-		}
-		if m.cursor > maxIdx {
-			m.cursor = maxIdx
+		if m.cursor > m.maxSidebarCursor() {
+			m.cursor = m.maxSidebarCursor()
 		}
 		cmd := m.switchViewToCurrentSession()
-		// Refresh archived overlay if open
-		if m.overlay == overlayArchivedSessions {
-			m.archivedList = m.archivedSessionsList()
-			if len(m.archivedList) == 0 {
-				m.overlay = overlayNone
-			} else if m.archivedCursor >= len(m.archivedList) {
-				m.archivedCursor = len(m.archivedList) - 1
+		if m.restoreFocus {
+			m.restoreFocus = false
+			if m.activeSession != nil {
+				m.focus = focusTerminal
 			}
 		}
-		return m, cmd
+		// Refresh archived overlay if open, or the archived sessions in the
+		// sidebar if shown
+		if m.overlay == overlayArchivedSessions && m.archivedTotal == 0 {
+			m.overlay = overlayNone
+		}
+		if m.archivedTotal > 0 && (m.overlay == overlayArchivedSessions || m.archivedInline) {
+			cmd = tea.Batch(cmd, m.reloadArchived())
+		}
+		return m, tea.Batch(cmd, m.loadSummaries())
+
+	case summaryTickMsg:
+		return m, tea.Batch(m.loadSummaries(), m.loadRateLimits(), summaryTick())
+
+	case rateLimitsMsg:
+		return m, m.updateRateLimits(msg)
+
+	case rateLimitResetMsg:
+		m.expireRateLimits(time.Now(), len(m.rateLimits) > 0)
+		return m, nil
+
+	case prStatusTickMsg:
+		return m, tea.Batch(m.loadPRStatuses(), prStatusTick(pollInterval(prStatusInterval)))
+
+	case conflictTickMsg:
+		return m, tea.Batch(m.checkConflicts(), conflictTick())
+
+	case baseSyncDoneMsg:
+		m.finishBaseSync(msg)
+		return m, nil
+
+	case landMergedMsg:
+		return m, m.removeLanded(msg)
+
+	case landDoneMsg:
+		return m, m.finishLanding(msg)
+
+	case conversationsLoadedMsg:
+		m.showConversations(msg)
+		return m, nil
+
+	case historyLoadedMsg:
+		m.showHistory(msg)
+		return m, nil
+
+	case changedFilesMsg:
+		m.showChangedFiles(msg)
+		return m, nil
+
+	case fileDiffMsg:
+		m.showFileDiff(msg)
+		return m, nil
+
+	case verifyDoneMsg:
+		m.finishVerify(msg)
+		return m, nil
+
+	case conflictsCheckedMsg:
+		m.updateConflicts(msg)
+		return m, nil
+
+	case diffStatTickMsg:
+		return m, tea.Batch(m.loadDiffStats(), diffStatTick())
+
+	case scheduleTickMsg:
+		return m, tea.Batch(m.runSchedules(time.Now()), scheduleTick())
+
+	case diffStatsMsg:
+		m.updateDiffStats(msg)
+		return m, nil
+
+	case dirtyTickMsg:
+		return m, tea.Batch(m.checkDirty(), dirtyTick())
+
+	case dirtyCheckedMsg:
+		m.updateDirty(msg)
+		return m, nil
+
+	case workTimeTickMsg:
+		return m, tea.Batch(m.saveWorkTime(), workTimeTick())
+
+	case workTimesLoadedMsg:
+		m.workTimes = msg.times
+		return m, nil
+
+	case pluginScanTickMsg:
+		return m, tea.Batch(m.scanPluginKeywords(), pluginScanTick())
+
+	case pluginActionsMsg:
+		return m, m.handlePluginActions(msg)
+
+	case prStatusesMsg:
+		m.updatePRStatuses(msg)
+		return m, nil
+
+	case syncTickMsg:
+		return m, tea.Batch(m.checkSessionsVersion(), syncTick())
+
+	case sessionsVersionMsg:
+		return m, m.updateSessionsVersion(msg)
+
+	case watchChangeMsg:
+		return m, tea.Batch(m.handleWatchChange(msg), m.waitForChange())
+
+	case summariesLoadedMsg:
+		m.summaries = msg.summaries
+		return m, nil
+
+	case resourceTickMsg:
+		return m, tea.Batch(m.sampleResources(), m.sampleContainers(), m.sampleDevServers(), resourceTick())
+
+	case containersSampledMsg:
+		m.containers = msg.states
+		return m, nil
+
+	case windowToggleMsg:
+		m.showWindow(msg)
+		return m, nil
+
+	case windowCycleMsg:
+		m.showNextWindow(msg)
+		return m, nil
+
+	case copyModeMsg:
+		if t, ok := m.terminals[msg.session]; ok {
+			m.startCopyMode(t)
+		}
+		return m, nil
+
+	case pasteMsg:
+		m.pasteInto(msg)
+		return m, nil
+
+	case devServersSampledMsg:
+		m.devServers = msg.listening
+		return m, nil
+
+	case resourcesSampledMsg:
+		return m, m.updateResources(msg)
+
+	case orphansFoundMsg:
+		if m.message == "" {
+			m.message = orphansMessage(msg)
+		}
+		return m, nil
+
+	case archivedExpiredMsg:
+		if msg.err != nil {
+			m.err = fmt.Errorf("failed to delete expired archived sessions: %w", msg.err)
+		}
+		if len(msg.expired) == 0 {
+			return m, nil
+		}
+		m.message = expiredMessage(msg.expired, m.cfg.ArchiveRetention.Days)
+		return m, m.loadSessions()
+
+	case usageLoadedMsg:
+		m.usageRows = msg.rows
+		m.usageLoading = false
+		return m, nil
+
+	case activityLoadedMsg:
+		m.activityEvents = msg.events
+		return m, nil
+
+	case archivedLoadedMsg:
+		m.showArchived(msg)
+		return m, nil
 
 	case branchesLoadedMsg:
-		m.branches = msg.branches
-		m.branchesWithSessions = msg.branchesWithSessions
-		m.filterBranches()
+		refresh := m.cacheBranches(msg)
+		if m.service != nil && msg.repoPath == m.service.RepoPath() {
+			m.showBranches(msg, refresh)
+		}
 		return m, nil
 
 	case sessionCreatedMsg:
+		m.logEvent(msg.session.Name, events.KindCreated, "")
+		m.advanceTutorial(tutorialCreate)
 		m.overlay = overlayNone
 		m.pendingSessionName = ""
-		m.selectAfterLoad = msg.session.Name
-		m.activatingSession = msg.session.Name
-		cmds := []tea.Cmd{m.loadSessions(), m.activateSession(msg.session, true)}
+		m.pendingSessionBase = ""
+		if msg.copyErr != nil {
+			m.err = msg.copyErr
+		}
+		cmds := []tea.Cmd{m.loadSessions()}
+		if m.scheduledSessions[msg.session.Name] {
+			m.message = fmt.Sprintf("Started scheduled session %s", msg.session.Name)
+			cmds = append(cmds, m.startScheduledTerminal(msg.session))
+		} else {
+			m.selectAfterLoad = msg.session.Name
+			m.activatingSession = msg.session.Name
+			cmds = append(cmds, m.activateSession(msg.session, true))
+		}
 		if len(msg.setupCommands) > 0 {
+			if !msg.setupTrusted {
+				m.confirmCreatedSetup(msg.session, msg.setupCommands)
+				return m, tea.Batch(cmds...)
+			}
 			m.settingUpSessions[msg.session.Name] = true
-			cmds = append(cmds, m.runSetupInBackground(msg.session.Name, msg.session.WorktreePath, msg.setupCommands))
+			cmds = append(cmds, m.runSetupInBackground(msg.session, msg.setupCommands))
 		}
 		return m, tea.Batch(cmds...)
 
 	case sessionDeletedMsg:
+		m.logEvent(msg.name, events.KindDeleted, "")
+		m.forgetPromptQueue(msg.name)
+		m.forgetPluginState(msg.name)
+		m.forgetHandoffs(msg.name)
+		m.forgetScheduledSession(msg.name)
+		m.unpinIfNamed(msg.name)
+		m.advanceTutorial(tutorialDelete)
 		m.message = fmt.Sprintf("Session '%s' deleted", msg.name)
 		m.selectedSession = nil
 		if m.activeSession != nil && m.activeSession.Name == msg.name {
@@ -421,7 +1047,18 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, m.loadSessions()
 
 	case sessionArchivedMsg:
+		m.logEvent(msg.name, events.KindArchived, "")
+		m.forgetPromptQueue(msg.name)
+		m.forgetPluginState(msg.name)
+		m.forgetHandoffs(msg.name)
+		m.forgetScheduledSession(msg.name)
+		m.advanceTutorial(tutorialArchive)
 		m.message = fmt.Sprintf("Session '%s' archived", msg.name)
+		if msg.uploadErr != nil {
+			m.err = fmt.Errorf("archive upload failed: %w", msg.uploadErr)
+		}
+		delete(m.setupFailedSessions, msg.name)
+		m.unpinIfNamed(msg.name)
 		if t, ok := m.terminals[msg.name]; ok {
 			t.Close()
 			delete(m.terminals, msg.name)
@@ -432,27 +1069,62 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, m.loadSessions()
 
 	case sessionUnarchivedMsg:
+		m.logEvent(msg.name, events.KindUnarchived, "")
 		m.message = fmt.Sprintf("Session '%s' unarchived", msg.name)
 		return m, m.loadSessions()
 
+	case trashLoadedMsg:
+		m.showTrash(msg)
+		return m, nil
+
+	case sessionRestoredMsg:
+		return m, m.restoredSession(msg)
+
+	case sessionDetachedMsg:
+		return m, m.finishDetach(msg)
+
+	case adoptableLoadedMsg:
+		m.showAdoptable(msg)
+		return m, nil
+
+	case sessionAdoptedMsg:
+		return m, m.finishAdopt(msg)
+
+	case trashPurgedMsg:
+		m.message = fmt.Sprintf("Session '%s' deleted for good", msg.name)
+		return m, m.loadTrash()
+
 	case spawnTerminalFinishedMsg:
 		if msg.err != nil {
 			m.err = msg.err
 		}
 		// Re-enable mouse tracking after the external shell resets terminal modes
-		return m, tea.EnableMouseCellMotion
+		return m, m.restoreMouse()
+
+	case nativeAttachFinishedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+		}
+		// The windows followed the tmux client's size, including those of
+		// sessions switched to from it; take them all back
+		m.resizeAllTerminals()
+		return m, m.restoreMouse()
 
 	case setupCompleteMsg:
-		if !m.settingUpSessions[msg.sessionName] {
+		settingUp, setupFailed := m.setupStateFor(msg.sessionName)
+		if settingUp == nil {
 			return m, nil
 		}
-		delete(m.settingUpSessions, msg.sessionName)
+		delete(settingUp, msg.sessionName)
 		if msg.err != nil {
 			m.err = fmt.Errorf("setup failed for '%s': %w", msg.sessionName, msg.err)
-		} else {
-			m.message = fmt.Sprintf("Setup complete for '%s'", msg.sessionName)
+			setupFailed[msg.sessionName] = true
+			m.logEvent(msg.sessionName, events.KindSetupFailed, msg.err.Error())
+			return m, m.notify("Setup failed", msg.sessionName)
 		}
-		return m, nil
+		m.message = fmt.Sprintf("Setup complete for '%s'", msg.sessionName)
+		m.logEvent(msg.sessionName, events.KindSetupComplete, "")
+		return m, m.notify("Setup complete", msg.sessionName)
 
 	case projectsLoadedMsg:
 		m.projects = msg.projects
@@ -466,41 +1138,48 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case projectSwitchedMsg:
-		m.detachTerminal(mainProjectTerminalKey)
-		m.service = msg.service
-		m.repoName = msg.repoName
-		// Recompute tmux socket for the new project
-		hash := sha256.Sum256([]byte(msg.service.RepoPath()))
-		m.tmuxSocket = fmt.Sprintf("atc-%x", hash[:4])
-		m.activeSession = nil
-		m.cursor = 0
-		m.scrollOffset = 0
-		m.noProjectMode = false
-		m.overlay = overlayNone
-		// Reset branch state
-		m.currentBranch = msg.currentBranch
-		m.branches = nil
-		m.filteredBranches = nil
-		m.branchesWithSessions = make(map[string]bool)
-		m.branchCursor = 0
-		m.branchScrollOffset = 0
-		m.selectedBranchName = ""
-		// Reset session creation state
-		m.pendingSessionName = ""
-		m.selectAfterLoad = ""
-		m.activatingSession = ""
-		m.settingUpSessions = make(map[string]bool)
-		// Reset misc state
-		m.selectedSession = nil
-		m.err = nil
-		m.message = ""
-		m.hasSelection = false
-		m.selecting = false
-		return m, m.loadSessions()
+		cmd := m.openProjectTab(msg)
+		if m.switchTo != "" {
+			m.selectAfterLoad = m.switchTo
+			m.restoreFocus = true
+			m.switchTo = ""
+		}
+		return m, cmd
+
+	case switcherLoadedMsg:
+		m.showSwitcherSessions(msg)
+		return m, nil
+
+	case versionCheckedMsg:
+		m.latestVersion = msg.latest
+		return m, nil
+
+	case projectPinnedMsg:
+		m.projects = msg.projects
+		m.filterProjects()
+		m.selectProject(msg.repoPath)
+		return m, nil
+
+	case pullRequestsLoadedMsg:
+		m.pullRequests = msg.prs
+		m.pullRequestsLoading = false
+		return m, nil
+
+	case pullRequestFetchedMsg:
+		m.pendingSessionBase = msg.base
+		return m, m.doCreateSession(msg.ref, false)
+
+	case projectForgottenMsg:
+		m.message = fmt.Sprintf("Removed %s from the project list", msg.name)
+		return m, m.loadProjects()
 
 	case errMsg:
 		m.err = msg.err
 		if m.overlay == overlayCreating {
+			m.pendingSessionBase = ""
+			m.forgetScheduledSession(m.pendingSessionName)
+		}
+		if m.overlay == overlayCreating || m.overlay == overlayUsage {
 			m.overlay = overlayNone
 		}
 		return m, nil
@@ -514,15 +1193,57 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Terminal output arrived, just re-render
 		return m, nil
 
+	case terminal.TerminalStateMsg:
+		pluginCmd := m.pluginStateChanged(msg.Name, msg.State)
+		switch msg.State {
+		case terminal.StateWorking:
+			m.logEvent(msg.Name, events.KindWorking, "")
+			delete(m.queueSent, msg.Name)
+		case terminal.StateWaiting:
+			m.logEvent(msg.Name, events.KindWaiting, "")
+			delete(m.queueSent, msg.Name)
+			// Before anything is sent to an agent that just ran into a limit
+			limitCmd := m.checkRateLimit(msg.Name)
+			m.pastePendingPrompt(msg.Name)
+			m.sendQueuedPrompt(msg.Name)
+			return m, tea.Batch(limitCmd, m.autoVerify(msg.Name), pluginCmd)
+		}
+		return m, pluginCmd
+
 	case terminal.TerminalExitedMsg:
-		// Terminal process exited - no action needed, View() will show last state
-		return m, nil
+		// Terminal process exited - View() will show last state
+		detail := ""
+		if msg.Crashed {
+			detail = "crashed"
+		}
+		m.logEvent(msg.Name, events.KindExited, detail)
+		delete(m.queueSent, msg.Name)
+		pluginCmd := m.pluginExited(msg.Name)
+		if cmd := m.autoRespawn(msg); cmd != nil {
+			return m, tea.Batch(cmd, pluginCmd)
+		}
+		if m.crashes[msg.Name] != nil && m.crashes[msg.Name].gaveUp {
+			return m, tea.Batch(m.notify("Agent keeps crashing", msg.Name), pluginCmd)
+		}
+		return m, tea.Batch(m.notify("Agent exited", msg.Name), pluginCmd)
+
+	case respawnMsg:
+		return m.handleRespawn(msg)
 	}
 
 	return m, nil
 }
 
 func (m *Model) activateSession(sess *session.Session, switchFocus bool) tea.Cmd {
+	return m.activateSessionResuming(sess, switchFocus, resumeLatest)
+}
+
+// activateSessionResuming activates a session, starting its agent on the
+// given conversation if it has no terminal yet (see conversations.go)
+func (m *Model) activateSessionResuming(sess *session.Session, switchFocus bool, resume string) tea.Cmd {
+	// Read here rather than in the background: it may hand out the session's
+	// ports
+	agent := m.sessionAgent(sess)
 	return func() tea.Msg {
 		m.activeSession = sess
 		if switchFocus {
@@ -533,9 +1254,11 @@ func (m *Model) activateSession(sess *session.Session, switchFocus bool) tea.Cmd
 
 		tw, th := m.terminalPaneDimensions()
 
-		if err := m.ensureTerminal(sess, tw, th); err != nil {
+		if err := m.ensureTerminalResuming(sess, agent, tw, th, resume); err != nil {
 			return errMsg{err}
 		}
+		// The pinned pane may have just become visible beside this one
+		m.resizeTerminalIfNeeded()
 
 		if m.service != nil && sess.Name != mainProjectTerminalKey {
 			m.service.TouchSession(sess.Name)
@@ -546,9 +1269,15 @@ func (m *Model) activateSession(sess *session.Session, switchFocus bool) tea.Cmd
 }
 
 // ensureTerminal guarantees a running terminal wrapper exists for the session.
-// It reuses an existing wrapper, reattaches to a persisted tmux session,
-// or creates a new tmux session as needed.
+// It reuses an existing wrapper, reattaches to a terminal the backend still
+// has running (e.g. a persisted tmux session), or starts a new one as needed.
 func (m *Model) ensureTerminal(sess *session.Session, width, height int) error {
+	return m.ensureTerminalResuming(sess, m.sessionAgent(sess), width, height, resumeLatest)
+}
+
+// ensureTerminalResuming is ensureTerminal with the session's agent settings
+// (see sessionAgent) and the conversation a new terminal's agent picks up
+func (m *Model) ensureTerminalResuming(sess *session.Session, agent terminal.Agent, width, height int, resume string) error {
 	if m.tmuxSocket == "" {
 		return fmt.Errorf("no project selected")
 	}
@@ -563,31 +1292,70 @@ func (m *Model) ensureTerminal(sess *session.Session, width, height int) error {
 	m.detachTerminal(sess.Name)
 
 	// If tmux session already exists on the socket, reattach
-	if terminal.SessionExists(m.tmuxSocket, sess.Name) {
-		t, err := terminal.Attach(sess.Name, width, height, m.program, m.tmuxSocket)
+	if m.backend.Exists(m.tmuxSocket, sess.Name) {
+		t, err := m.backend.Attach(sess.Name, agent, width, height, m.program, m.tmuxSocket)
 		if err != nil {
 			return err
 		}
 		m.terminals[sess.Name] = t
+		m.logEvent(sess.Name, events.KindAttached, "existing tmux session")
 		// If the pane process died while ATC was away, respawn with --continue
 		if !t.IsRunning() {
-			if err := t.Respawn(true); err != nil {
+			if err := t.Respawn(true, ""); err != nil {
 				return err
 			}
+			m.logEvent(sess.Name, events.KindRespawned, "agent had exited")
 		}
 		return nil
 	}
 
 	// No tmux session exists, create a new one
-	continueSession := worktree.HasExistingConversation(sess.WorktreePath)
-	t, err := terminal.New(sess.Name, sess.WorktreePath, width, height, continueSession, m.program, m.tmuxSocket)
+	continueSession := resume != resumeNone && worktree.HasExistingConversation(sess.WorktreePath)
+	if resume != resumeNone {
+		agent.Resume = resume
+	}
+	t, err := m.backend.New(sess.Name, sess.WorktreePath, agent, width, height, continueSession, m.program, m.tmuxSocket)
 	if err != nil {
 		return err
 	}
 	m.terminals[sess.Name] = t
+	detail := "new tmux session"
+	switch {
+	case continueSession && agent.Resume != "":
+		detail = "new tmux session, resuming conversation " + agent.Resume
+	case continueSession:
+		detail = "new tmux session, continuing conversation"
+	}
+	m.logEvent(sess.Name, events.KindAttached, detail)
 	return nil
 }
 
+// agentName returns the name of the configured agent's program, e.g.
+// "claude", for messages that mention it
+func (m *Model) agentName() string {
+	fields := strings.Fields(m.agent().Command)
+	if len(fields) == 0 {
+		return "agent"
+	}
+	return filepath.Base(fields[0])
+}
+
+// agent returns the agent launch settings configured for the current project.
+// The repository's own agent settings are only used once it's trusted.
+func (m *Model) agent() terminal.Agent {
+	cfg := m.cfg
+	if m.service != nil {
+		cfg = &m.service.TrustedConfig().GlobalConfig
+	}
+	return terminal.Agent{
+		Command: cfg.Agent,
+		Shell:   cfg.Shell,
+		Locale:  cfg.Locale,
+		Term:    cfg.Term,
+		Args:    cfg.AgentFlags.Args(),
+	}
+}
+
 // --- Key handling ---
 
 func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -601,12 +1369,36 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 
 	// Ctrl+C from terminal switches back to sidebar
-	if msg.String() == "ctrl+c" && m.focus == focusTerminal {
+	if msg.String() == "ctrl+c" && m.focus != focusSidebar {
 		m.focus = focusSidebar
 		m.resizeTerminalIfNeeded()
 		return m, nil
 	}
 
+	// Alt+1..9 jumps to a session from either pane, the next/previous
+	// session keys step through them, and the quick switcher finds one in
+	// any project
+	if n, ok := jumpNumber(msg); ok {
+		return m.jumpToSession(n)
+	}
+	switch msg.String() {
+	case m.keys.NextSession:
+		return m.cycleSession(1)
+	case m.keys.PrevSession:
+		return m.cycleSession(-1)
+	case m.keys.Switcher:
+		return m.openSwitcher()
+	}
+
+	// Move focus between the panes of a split view
+	if msg.String() == m.keys.SwitchPane && m.focus != focusSidebar && m.splitActive() {
+		m.switchPane()
+		return m, nil
+	}
+
+	if m.focus == focusPinned {
+		return m.handlePinnedKeys(msg)
+	}
 	if m.focus == focusTerminal {
 		return m.handleTerminalKeys(msg)
 	}
@@ -619,15 +1411,56 @@ func (m *Model) handleMouseMsg(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 		return m.handleOverlayMouse(msg)
 	}
 
+	// Dragging the sidebar border resizes it
+	if m.resizingSidebar {
+		switch msg.Action {
+		case tea.MouseActionMotion:
+			m.resizeSidebar(msg.X + 1)
+			return m, nil
+		case tea.MouseActionRelease:
+			m.resizingSidebar = false
+			m.resizeSidebar(msg.X + 1)
+			return m, m.saveSidebarSettings()
+		}
+	}
+	if !m.sidebarCollapsed && m.windowWidth >= smallScreenThreshold &&
+		msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress && m.onSidebarBorder(msg.X) {
+		m.resizingSidebar = true
+		return m, nil
+	}
+
 	var termStartX int
 	if m.sidebarVisible() {
-		termStartX = sidebarWidth + 1 // sidebar visual width (includes border) + spacer
+		termStartX = m.sidebarWidth + 1 // sidebar visual width (includes border) + spacer
 	} else {
 		termStartX = 0
 	}
 
+	// Pinned pane (split view): clicks focus it, the wheel scrolls it.
+	// Text selection is only supported in the active pane.
+	if pw := m.pinnedPaneWidth(); pw > 0 {
+		pinnedStartX := termStartX
+		termStartX += pw + 1 // pinned pane + divider
+		if msg.X >= pinnedStartX && msg.X < termStartX && !m.selecting {
+			t := m.terminals[m.pinnedSession.Name]
+			switch {
+			case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+				m.message = ""
+				m.err = nil
+				m.hasSelection = false
+				m.focus = focusPinned
+				m.resizeTerminalIfNeeded()
+			case msg.Button == tea.MouseButtonWheelUp && t.IsRunning():
+				t.ScrollUp(3)
+			case msg.Button == tea.MouseButtonWheelDown && t.IsRunning():
+				t.ScrollDown(3)
+			}
+			return m, nil
+		}
+	}
+
 	// Sidebar mouse events (click or wheel in sidebar area)
-	if m.sidebarVisible() && msg.X < sidebarWidth {
+	if m.sidebarVisible() && msg.X < m.sidebarWidth {
 		switch {
 		case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
 			return m.handleSidebarMouse(msg)
@@ -639,6 +1472,10 @@ func (m *Model) handleMouseMsg(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if t := m.mousePassthroughTerminal(msg, termStartX); t != nil {
+		return m.passMouseThrough(t, msg, termStartX)
+	}
+
 	// Terminal pane mouse events
 	switch {
 	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
@@ -661,7 +1498,7 @@ func (m *Model) handleMouseMsg(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 			m.lastClickRow = row
 
 			// Click on terminal switches focus
-			if m.focus == focusSidebar {
+			if m.focus != focusTerminal {
 				m.message = ""
 				m.err = nil
 				m.focus = focusTerminal
@@ -798,6 +1635,7 @@ func (m *Model) handleMouseMsg(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 		}
 		m.hasSelection = false
 		t.ScrollUp(3)
+		m.advanceTutorial(tutorialScroll)
 		return m, nil
 
 	case msg.Button == tea.MouseButtonWheelDown:
@@ -834,6 +1672,10 @@ func (m *Model) handleSidebarMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 		m.cursor = idx
 		m.adjustScroll()
 		return m, m.switchViewToCurrentSession()
+	case "archived_session":
+		m.cursor = idx
+		m.adjustScroll()
+		return m, m.moreArchivedBelow(idx)
 	case "archived":
 		return m.openArchivedOverlay()
 	case "scroll_up":
@@ -846,20 +1688,7 @@ func (m *Model) handleSidebarMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 	case "scroll_down":
-		active := m.activeSessions()
-		maxVisible := m.maxVisibleSessions()
-		maxOffset := len(active) - maxVisible
-		if maxOffset < 0 {
-			maxOffset = 0
-		}
-		if m.scrollOffset < maxOffset {
-			m.scrollOffset++
-			// Keep cursor in visible range
-			if m.cursor < m.scrollOffset {
-				m.cursor = m.scrollOffset
-			}
-		}
-		return m, nil
+		return m.handleSidebarWheelDown()
 	}
 	return m, nil
 }
@@ -879,9 +1708,8 @@ func (m *Model) handleSidebarWheelUp() (tea.Model, tea.Cmd) {
 
 // handleSidebarWheelDown scrolls the sidebar session list down.
 func (m *Model) handleSidebarWheelDown() (tea.Model, tea.Cmd) {
-	active := m.activeSessions()
 	maxVisible := m.maxVisibleSessions()
-	maxOffset := len(active) - maxVisible
+	maxOffset := m.sidebarListLen() - maxVisible
 	if maxOffset < 0 {
 		maxOffset = 0
 	}
@@ -892,7 +1720,7 @@ func (m *Model) handleSidebarWheelDown() (tea.Model, tea.Cmd) {
 			m.cursor = m.scrollOffset
 		}
 	}
-	return m, nil
+	return m, m.moreArchivedBelow(m.scrollOffset + maxVisible - 1)
 }
 
 // handleOverlayMouse handles mouse events when an overlay is active.
@@ -972,6 +1800,7 @@ func (m *Model) handleOverlayMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 					m.archivedScrollOffset = m.archivedCursor - maxVisible + 1
 				}
 			}
+			return m, m.moreArchived(m.archivedCursor)
 		case overlaySelectProject:
 			if m.projectCursor < len(m.filteredProjects)-1 {
 				m.projectCursor++
@@ -1142,12 +1971,8 @@ func (m *Model) handleArchivedOverlayClick(msg tea.MouseMsg) (tea.Model, tea.Cmd
 
 func (m *Model) handleSidebarKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "q", "ctrl+c":
-		// Detach all terminals (stop polling) but leave tmux sessions running
-		for _, t := range m.terminals {
-			t.Detach()
-		}
-		return m, tea.Quit
+	case m.keys.Quit, "ctrl+c":
+		return m.handleQuit()
 
 	case "up", "k":
 		if m.cursor > 0 {
@@ -1167,45 +1992,144 @@ func (m *Model) handleSidebarKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.adjustScroll()
 			return m, m.switchViewToCurrentSession()
 		}
-		active := m.activeSessions()
-		maxIdx := len(active) - 1
-		if m.archivedCount() > 0 {
-			maxIdx++
-		}
-		if m.cursor < maxIdx {
+		if m.cursor < m.maxSidebarCursor() {
 			m.cursor++
 			m.adjustScroll()
-			return m, m.switchViewToCurrentSession()
+			return m, tea.Batch(m.switchViewToCurrentSession(), m.moreArchivedBelow(m.cursor))
 		}
 		return m, nil
 
 	case "enter":
 		return m.handleEnter()
 
-	case "n":
+	case m.keys.New:
 		if m.service == nil {
 			return m, nil
 		}
 		return m.openCreateOverlay()
 
-	case "d":
+	case m.keys.Delete:
 		return m.openDeleteOverlay()
 
-	case "a":
+	case m.keys.Archive:
 		return m.handleArchive()
 
-	case "p":
+	case m.keys.Project:
 		m.initProjectInput()
 		m.overlay = overlaySelectProject
 		return m, m.loadProjects()
 
-	case "s":
+	case m.keys.Shell:
 		return m.handleSpawnTerminal()
 
-	case "?":
+	case m.keys.Attach:
+		return m.handleNativeAttach()
+
+	case m.keys.External:
+		return m.handleExternalTerminal()
+
+	case m.keys.Editor:
+		return m.handleOpenEditor()
+
+	case m.keys.ExitTo:
+		return m.openExitTo()
+
+	case m.keys.Browser:
+		return m.handleOpenBrowser()
+
+	case m.keys.Sync:
+		return m.handleSyncWithBase()
+
+	case m.keys.Land:
+		return m.handleLand()
+
+	case m.keys.Files:
+		return m.openChangedFiles()
+
+	case m.keys.Verify:
+		return m.handleVerify()
+
+	case m.keys.Queue:
+		return m.openPromptQueue()
+
+	case m.keys.Handoff:
+		return m.openHandoffs()
+
+	case m.keys.Fork:
+		return m.openFork()
+
+	case m.keys.Detach:
+		return m.openDetach()
+
+	case m.keys.Adopt:
+		return m.openAdopt()
+
+	case m.keys.Trash:
+		return m.openTrash()
+
+	case m.keys.Git:
+		return m.handleGitWindow()
+
+	case m.keys.ShellWindow:
+		return m.handleShellWindow()
+
+	case m.keys.CopyMode:
+		return m.handleCopyMode()
+
+	case m.keys.Paste:
+		return m.handlePaste()
+
+	case m.keys.MouseMode:
+		return m.toggleMouseMode()
+
+	case m.keys.NextWindow:
+		return m.cycleWindow(true)
+
+	case m.keys.PrevWindow:
+		return m.cycleWindow(false)
+
+	case m.keys.Help:
 		m.overlay = overlayHelp
 		return m, nil
 
+	case m.keys.SidebarWider:
+		m.resizeSidebar(m.sidebarWidth + sidebarWidthStep)
+		return m, m.saveSidebarSettings()
+
+	case m.keys.SidebarNarrower:
+		m.resizeSidebar(m.sidebarWidth - sidebarWidthStep)
+		return m, m.saveSidebarSettings()
+
+	case m.keys.SidebarToggle:
+		return m, m.toggleSidebar()
+
+	case m.keys.SidebarArchived:
+		return m, m.toggleArchivedInline()
+
+	case m.keys.Pin:
+		return m, m.togglePin()
+
+	case m.keys.Usage:
+		return m.openUsageOverlay()
+
+	case m.keys.Activity:
+		return m.openActivityOverlay()
+
+	case m.keys.History:
+		return m.openHistory()
+
+	case m.keys.Log:
+		return m.openLog()
+
+	case m.keys.NextProject:
+		return m, m.cycleTab(1)
+
+	case m.keys.PrevProject:
+		return m, m.cycleTab(-1)
+
+	case m.keys.CloseProject:
+		return m, m.closeTab()
+
 	case "esc":
 		if m.activeSession != nil {
 			m.message = ""
@@ -1217,6 +2141,11 @@ func (m *Model) handleSidebarKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	default:
+		for _, w := range m.cfg.Windows {
+			if msg.String() == w.Key {
+				return m.toggleWindow(w.Name, w.Command)
+			}
+		}
 		return m, nil
 	}
 }
@@ -1230,23 +2159,26 @@ func (m *Model) handleTerminalKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if !ok {
 		return m, nil
 	}
+	return m.forwardKeys(t, msg)
+}
 
-	// Check if session ended - Enter restarts
+// forwardKeys handles a key press for a focused terminal pane: scrolling,
+// restarting an exited agent, or sending the key to tmux.
+func (m *Model) forwardKeys(t terminal.Terminal, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Check if session ended - the restart menu takes the keys
 	if !t.IsRunning() {
-		if msg.Type == tea.KeyEnter {
-			if err := t.Respawn(true); err != nil {
-				m.err = err
-				return m, nil
-			}
-			return m, nil
-		}
-		return m, nil
+		return m.handleRestartKeys(t, msg)
+	}
+
+	if m.copy.term == t {
+		return m.handleCopyKeys(t, msg)
 	}
 
 	// Page Up/Down for scrolling
 	if msg.Type == tea.KeyPgUp {
 		_, th := m.terminalPaneDimensions()
 		t.ScrollUp(th / 2)
+		m.advanceTutorial(tutorialScroll)
 		return m, nil
 	}
 	if msg.Type == tea.KeyPgDown {
@@ -1255,6 +2187,26 @@ func (m *Model) handleTerminalKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if m.search.term == t {
+		return m.handleSearchKeys(t, msg)
+	}
+	if t.IsScrollMode() && msg.String() == "/" {
+		m.startSearch(t)
+		return m, nil
+	}
+	if t.IsScrollMode() && msg.String() == "v" {
+		m.startCopyMode(t)
+		return m, nil
+	}
+
+	// A paste arrives as one message; send it as one so its line breaks
+	// don't submit it a line at a time
+	if msg.Paste {
+		t.ExitScrollMode()
+		t.Paste(string(msg.Runes))
+		return m, nil
+	}
+
 	// Any other key exits scroll mode (don't forward to tmux —
 	// prevents partially-parsed mouse escape sequences from leaking through)
 	if t.IsScrollMode() {
@@ -1264,6 +2216,9 @@ func (m *Model) handleTerminalKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	// Send key to tmux session
 	t.SendKeys(msg)
+	if msg.Type == tea.KeyEnter {
+		m.advanceTutorial(tutorialPrompt)
+	}
 	return m, nil
 }
 
@@ -1276,36 +2231,85 @@ func (m *Model) handleEnter() (tea.Model, tea.Cmd) {
 	if m.archivedCount() > 0 && m.cursor == len(active) {
 		return m.openArchivedOverlay()
 	}
+	if m.cursorArchived() != nil {
+		m.message = fmt.Sprintf("Archived — press %s to unarchive", m.keys.Archive)
+		return m, nil
+	}
 	if m.cursor >= len(active) {
 		return m, nil
 	}
+	if m.needsConversationChoice(active[m.cursor]) {
+		return m.openConversationPicker(active[m.cursor])
+	}
 	return m, m.activateSession(active[m.cursor], true)
 }
 
-func (m *Model) handleSpawnTerminal() (tea.Model, tea.Cmd) {
-	var sess *session.Session
+// cursorSession returns the session under the sidebar cursor (the main
+// project session on the project header), or nil on the archived line and
+// archived sessions
+func (m *Model) cursorSession() *session.Session {
 	if m.isProjectHeaderSelected() {
-		sess = m.mainProjectSession()
-	} else {
-		active := m.activeSessions()
-		if m.cursor >= len(active) {
-			return m, nil
-		}
-		sess = active[m.cursor]
+		return m.mainProjectSession()
+	}
+	active := m.activeSessions()
+	if m.cursor < 0 || m.cursor >= len(active) {
+		return nil
 	}
+	return active[m.cursor]
+}
 
-	shell := os.Getenv("SHELL")
-	if shell == "" {
-		shell = "/bin/sh"
+func (m *Model) handleSpawnTerminal() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil {
+		return m, nil
 	}
 
-	c := exec.Command(shell)
+	agent := m.agent()
+	c := exec.Command(m.userShell())
 	c.Dir = sess.WorktreePath
+	if agent.Locale != "" {
+		c.Env = append(os.Environ(), "LANG="+agent.Locale, "LC_ALL="+agent.Locale)
+	}
 	return m, tea.Exec(&altScreenExec{cmd: c}, func(err error) tea.Msg {
 		return spawnTerminalFinishedMsg{err: err}
 	})
 }
 
+// userShell returns the shell sessions' shells run: the configured one, or
+// $SHELL
+func (m *Model) userShell() string {
+	if sh := m.agent().Shell; sh != "" {
+		return sh
+	}
+	return shell.Default()
+}
+
+// handleNativeAttach suspends ATC and attaches a real tmux client to the
+// selected session, for heavy interactive use; detaching returns to ATC
+func (m *Model) handleNativeAttach() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil || m.tmuxSocket == "" {
+		return m, nil
+	}
+	if m.settingUpSessions[sess.Name] {
+		return m, nil
+	}
+	c := m.backend.AttachCommand(m.tmuxSocket, sess.Name)
+	if c == nil {
+		m.message = "Full-screen attach needs tmux"
+		return m, nil
+	}
+	// Start (or reattach) the agent first so there is a session to attach to
+	tw, th := m.terminalPaneDimensions()
+	if err := m.ensureTerminal(sess, tw, th); err != nil {
+		m.err = err
+		return m, nil
+	}
+	return m, tea.Exec(&altScreenExec{cmd: c}, func(err error) tea.Msg {
+		return nativeAttachFinishedMsg{err: err}
+	})
+}
+
 // altScreenExec wraps an exec.Cmd to run inside the terminal's alternate
 // screen buffer, giving the child process a clean display and preserving the
 // parent's scrollback.
@@ -1337,6 +2341,11 @@ func (m *Model) openCreateOverlay() (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) openDeleteOverlay() (tea.Model, tea.Cmd) {
+	if archived := m.cursorArchived(); archived != nil {
+		m.selectedSession = archived
+		m.overlay = overlayDeleteConfirm
+		return m, nil
+	}
 	active := m.activeSessions()
 	if m.cursor < 0 || len(active) == 0 || m.cursor >= len(active) {
 		return m, nil
@@ -1347,6 +2356,9 @@ func (m *Model) openDeleteOverlay() (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) handleArchive() (tea.Model, tea.Cmd) {
+	if m.cursorArchived() != nil {
+		return m, m.unarchiveAtCursor()
+	}
 	active := m.activeSessions()
 	if m.cursor < 0 || len(active) == 0 || m.cursor >= len(active) || m.service == nil {
 		return m, nil
@@ -1356,7 +2368,7 @@ func (m *Model) handleArchive() (tea.Model, tea.Cmd) {
 		if err := m.service.ArchiveSession(selected.Name); err != nil {
 			return errMsg{err}
 		}
-		return sessionArchivedMsg{selected.Name}
+		return sessionArchivedMsg{selected.Name, m.service.UploadArchive(selected.Name)}
 	}
 }
 
@@ -1384,6 +2396,50 @@ func (m *Model) handleOverlayKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleArchivedOverlayKeys(msg)
 	case overlaySelectProject:
 		return m.handleSelectProjectKeys(msg)
+	case overlaySetupTrust:
+		return m.handleSetupTrustKeys(msg)
+	case overlaySetupPreview:
+		return m.handleSetupPreviewKeys(msg)
+	case overlayUsage:
+		return m.handleUsageKeys(msg)
+	case overlayActivity:
+		return m.handleActivityKeys(msg)
+	case overlayQuitConfirm:
+		return m.handleQuitConfirmKeys(msg)
+	case overlaySelectPullRequest:
+		return m.handlePullRequestKeys(msg)
+	case overlayBaseSync:
+		return m.handleBaseSyncKeys(msg)
+	case overlayLand:
+		return m.handleLandKeys(msg)
+	case overlayChangedFiles:
+		return m.handleChangedFilesKeys(msg)
+	case overlayVerifyTrust:
+		return m.handleVerifyTrustKeys(msg)
+	case overlayVerifyLog:
+		return m.handleVerifyLogKeys(msg)
+	case overlayPromptQueue:
+		return m.handlePromptQueueKeys(msg)
+	case overlayHandoffs:
+		return m.handleHandoffKeys(msg)
+	case overlayTrash:
+		return m.handleTrashKeys(msg)
+	case overlayHistory:
+		return m.handleHistoryKeys(msg)
+	case overlayResume:
+		return m.handleResumeKeys(msg)
+	case overlayFork:
+		return m.handleForkKeys(msg)
+	case overlayDetachConfirm:
+		return m.handleDetachKeys(msg)
+	case overlayAdopt:
+		return m.handleAdoptKeys(msg)
+	case overlayLog:
+		return m.handleLogKeys(msg)
+	case overlaySwitcher:
+		return m.handleSwitcherKeys(msg)
+	case overlayExitTo:
+		return m.handleExitToKeys(msg)
 	}
 	return m, nil
 }
@@ -1391,7 +2447,7 @@ func (m *Model) handleOverlayKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // dismissOverlay mirrors the Esc key behavior for each overlay type.
 func (m *Model) dismissOverlay() (tea.Model, tea.Cmd) {
 	switch m.overlay {
-	case overlayHelp:
+	case overlayHelp, overlayUsage, overlayActivity, overlayLog, overlayQuitConfirm:
 		m.overlay = overlayNone
 	case overlayCreateSession:
 		m.overlay = overlayNone
@@ -1403,8 +2459,9 @@ func (m *Model) dismissOverlay() (tea.Model, tea.Cmd) {
 		m.overlay = overlayCreateSession
 		m.createInput.Focus()
 		return m, textinput.Blink
-	case overlaySelectExistingBranch:
+	case overlaySelectExistingBranch, overlaySelectPullRequest:
 		m.overlay = overlayCreateSession
+		m.err = nil
 		m.createInput.Focus()
 		return m, textinput.Blink
 	case overlayConfirmBranchWithSession:
@@ -1418,17 +2475,81 @@ func (m *Model) dismissOverlay() (tea.Model, tea.Cmd) {
 			m.overlay = overlayNone
 		}
 		m.selectedSession = nil
-	case overlayArchivedSessions:
+	case overlayArchivedSessions:
+		m.overlay = overlayNone
+	case overlaySelectProject:
+		if m.noProjectMode {
+			// Can't dismiss project picker when launched outside a git repo
+			return m, tea.Quit
+		}
+		m.overlay = overlayNone
+	case overlaySetupTrust:
+		if m.untrustedSession != nil {
+			m.skipUntrustedSetup()
+			return m, nil
+		}
+		m.overlay = overlayNone
+		m.pendingSessionName = ""
+		m.pendingSessionBase = ""
+		m.pendingForkFrom = nil
+	case overlaySetupPreview:
+		m.overlay = overlayNone
+		m.pendingSessionName = ""
+		m.pendingSessionBase = ""
+		m.pendingForkFrom = nil
+	case overlayCreating:
+		// Cannot dismiss while creating
+		return m, nil
+	case overlayBaseSync:
+		m.leaveBaseSync()
+	case overlayLand:
+		if m.landing != nil && m.landing.running {
+			// Cannot dismiss while landing
+			return m, nil
+		}
+		m.landing = nil
+		m.overlay = overlayNone
+	case overlayChangedFiles:
+		m.closeChangedFiles()
+	case overlayVerifyTrust, overlayVerifyLog:
+		m.verifySession = nil
+		m.overlay = overlayNone
+	case overlayPromptQueue:
+		m.queueSession = ""
+		m.overlay = overlayNone
+	case overlayHandoffs:
+		m.handoffEditor = nil
+		m.overlay = overlayNone
+		m.err = nil
+	case overlayTrash:
+		m.trashList = nil
+		m.trashPurging = ""
+		m.overlay = overlayNone
+		m.err = nil
+	case overlayHistory:
+		m.history = nil
+		m.overlay = overlayNone
+	case overlayResume:
+		m.resumePicker = nil
+		m.overlay = overlayNone
+	case overlayFork:
+		m.forking = nil
+		m.overlay = overlayNone
+		m.err = nil
+	case overlayDetachConfirm:
+		m.selectedSession = nil
 		m.overlay = overlayNone
-	case overlaySelectProject:
-		if m.noProjectMode {
-			// Can't dismiss project picker when launched outside a git repo
-			return m, tea.Quit
-		}
+	case overlayAdopt:
+		m.adoptList = nil
 		m.overlay = overlayNone
-	case overlayCreating:
-		// Cannot dismiss while creating
-		return m, nil
+		m.err = nil
+	case overlaySwitcher:
+		m.switcher = nil
+		m.overlay = overlayNone
+	case overlayExitTo:
+		m.selectedSession = nil
+		m.overlay = overlayNone
+		m.err = nil
 	}
 	return m, nil
 }
@@ -1445,6 +2566,8 @@ func (m *Model) handleCreateOverlayKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.overlay = overlaySelectExistingBranch
 		m.initBranchInput()
 		return m, m.loadBranches()
+	case "ctrl+p":
+		return m, m.openPullRequests()
 	case "enter":
 		name := strings.TrimSpace(m.createInput.Value())
 		if name == "" {
@@ -1457,6 +2580,8 @@ func (m *Model) handleCreateOverlayKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		m.pendingSessionName = name
 		m.overlay = overlaySelectBaseBranch
+		m.defaultBaseBranch = m.service.DefaultBaseBranch()
+		m.fetchFirst = m.service.Config().FetchBeforeCreate
 		m.initBranchInput()
 		return m, m.loadBranches()
 	default:
@@ -1514,6 +2639,35 @@ func (m *Model) handleSelectBaseBranchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		}
 		return m, m.doCreateSession(baseBranch, false)
 
+	case "ctrl+r":
+		m.toggleRemoteBranches()
+		return m, nil
+
+	case "ctrl+f":
+		m.fetchFirst = !m.fetchFirst
+		return m, nil
+
+	case "ctrl+s":
+		if totalItems == 0 {
+			return m, nil
+		}
+		// Choosing HEAD clears the default
+		branch := ""
+		if !showHead || m.branchCursor != 0 {
+			branch = m.getSelectedBaseBranch(showHead)
+		}
+		if err := m.service.SetDefaultBaseBranch(branch); err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.defaultBaseBranch = m.service.DefaultBaseBranch()
+		if m.defaultBaseBranch == "" {
+			m.message = "New sessions now start from HEAD by default"
+		} else {
+			m.message = fmt.Sprintf("New sessions now start from %s by default", m.defaultBaseBranch)
+		}
+		return m, nil
+
 	default:
 		var cmd tea.Cmd
 		m.branchInput, cmd = m.branchInput.Update(msg)
@@ -1551,6 +2705,11 @@ func (m *Model) handleSelectExistingBranchKeys(msg tea.KeyMsg) (tea.Model, tea.C
 			return m, nil
 		}
 		selectedBranch := m.filteredBranches[m.branchCursor]
+		if slices.Contains(m.remoteBranches, selectedBranch) {
+			// Check it out as a local branch tracking the remote one
+			m.pendingSessionName = worktree.LocalBranchName(selectedBranch)
+			return m, m.doCreateSession(selectedBranch, true)
+		}
 		if m.branchesWithSessions[selectedBranch] {
 			m.selectedBranchName = selectedBranch
 			m.overlay = overlayConfirmBranchWithSession
@@ -1559,6 +2718,10 @@ func (m *Model) handleSelectExistingBranchKeys(msg tea.KeyMsg) (tea.Model, tea.C
 		m.pendingSessionName = selectedBranch
 		return m, m.doCreateSession(selectedBranch, true)
 
+	case "ctrl+r":
+		m.toggleRemoteBranches()
+		return m, nil
+
 	default:
 		var cmd tea.Cmd
 		m.branchInput, cmd = m.branchInput.Update(msg)
@@ -1626,6 +2789,7 @@ func (m *Model) handleDeleteConfirmKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "y", "Y":
 		name := m.selectedSession.Name
 		delete(m.settingUpSessions, name)
+		delete(m.setupFailedSessions, name)
 		// Close terminal if running
 		if t, ok := m.terminals[name]; ok {
 			t.Close()
@@ -1635,7 +2799,7 @@ func (m *Model) handleDeleteConfirmKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if m.service == nil {
 				return errMsg{fmt.Errorf("no project selected")}
 			}
-			if err := m.service.DeleteSession(name); err != nil {
+			if err := m.service.TrashSession(name); err != nil {
 				return errMsg{err}
 			}
 			return sessionDeletedMsg{name}
@@ -1657,7 +2821,7 @@ func (m *Model) handleDeleteConfirmKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m *Model) handleHelpKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "esc", "?", "q":
+	case "esc", m.keys.Help, m.keys.Quit:
 		m.overlay = overlayNone
 		return m, nil
 	case "ctrl+c":
@@ -1667,59 +2831,81 @@ func (m *Model) handleHelpKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) doCreateSession(baseBranch string, useExisting bool) tea.Cmd {
+	if m.shouldConfirmSetup() {
+		m.confirmSetup(baseBranch, useExisting)
+		return nil
+	}
+	if m.shouldPreviewSetup() {
+		m.previewSetup(baseBranch, useExisting)
+		return nil
+	}
 	name := m.pendingSessionName
+	recordedBase := m.pendingSessionBase
+	forkFrom := m.pendingForkFrom
+	m.pendingForkFrom = nil
+	skipSetup := m.skipSetup
+	m.skipSetup = false
+	fetch := m.fetchFirst && !useExisting
+	m.fetchFirst = false
+	m.fetching = fetch
 	m.overlay = overlayCreating
 
 	return func() tea.Msg {
 		if m.service == nil {
 			return errMsg{fmt.Errorf("no project selected")}
 		}
+		if fetch {
+			fetched, err := m.service.FetchBaseBranch(baseBranch)
+			if err != nil {
+				return errMsg{err}
+			}
+			baseBranch = fetched
+		}
 		sess, setupCmds, err := m.service.CreateSession(name, baseBranch, useExisting)
 		if err != nil {
 			return errMsg{err}
 		}
-		return sessionCreatedMsg{session: sess, setupCommands: setupCmds}
+		if recordedBase != "" {
+			if err := m.service.SetSessionBase(sess, recordedBase); err != nil {
+				return errMsg{err}
+			}
+		}
+		var copyErr error
+		if forkFrom != nil {
+			if err := worktree.CopyChanges(forkFrom.WorktreePath, sess.WorktreePath); err != nil {
+				copyErr = fmt.Errorf("couldn't copy %s's uncommitted changes: %w", forkFrom.Name, err)
+			}
+		}
+		if skipSetup {
+			setupCmds = nil
+		}
+		trusted := true
+		if len(setupCmds) > 0 {
+			trusted, _ = m.service.CommandsTrusted()
+		}
+		return sessionCreatedMsg{session: sess, setupCommands: setupCmds, setupTrusted: trusted, copyErr: copyErr}
 	}
 }
 
-func (m *Model) runSetupInBackground(sessionName, worktreePath string, commands []string) tea.Cmd {
+func (m *Model) runSetupInBackground(sess *session.Session, commands []string) tea.Cmd {
+	env := m.portEnv(sess)
 	return func() tea.Msg {
 		var buf bytes.Buffer
-		err := worktree.RunSetupCommands(worktreePath, commands, &buf)
-		return setupCompleteMsg{sessionName: sessionName, err: err}
+		err := worktree.RunSetupCommands(sess.WorktreePath, commands, env, &buf)
+		return setupCompleteMsg{sessionName: sess.Name, err: err}
 	}
 }
 
 // --- Helper methods ---
 
+// activeSessions is the current project's sessions in the sidebar. Archived
+// sessions aren't loaded with them
 func (m *Model) activeSessions() []*session.Session {
-	var active []*session.Session
-	for _, s := range m.sessions {
-		if s.Status != "archived" {
-			active = append(active, s)
-		}
-	}
-	return active
+	return m.sessions
 }
 
 func (m *Model) archivedCount() int {
-	count := 0
-	for _, s := range m.sessions {
-		if s.Status == "archived" {
-			count++
-		}
-	}
-	return count
-}
-
-func (m *Model) archivedSessionsList() []*session.Session {
-	var archived []*session.Session
-	for _, s := range m.sessions {
-		if s.Status == "archived" {
-			archived = append(archived, s)
-		}
-	}
-	return archived
+	return m.archivedTotal
 }
 
 func (m *Model) showHeadOption() bool {
@@ -1741,6 +2927,24 @@ func (m *Model) getSelectedBaseBranch(showHead bool) string {
 	return ""
 }
 
+// preselectDefaultBaseBranch moves the base-branch cursor to the project's
+// default base branch, if it exists locally.
+func (m *Model) preselectDefaultBaseBranch() {
+	if m.defaultBaseBranch == "" {
+		return
+	}
+	offset := 0
+	if m.showHeadOption() {
+		offset = 1
+	}
+	for i, branch := range m.filteredBranches {
+		if branch == m.defaultBaseBranch {
+			m.branchCursor = i + offset
+			return
+		}
+	}
+}
+
 func (m *Model) clampBranchCursor(total int) {
 	if m.branchCursor >= total {
 		m.branchCursor = total - 1
@@ -1753,11 +2957,15 @@ func (m *Model) clampBranchCursor(total int) {
 func (m *Model) filterBranches() {
 	query := strings.ToLower(strings.TrimSpace(m.branchInput.Value()))
 
+	branches := m.branches
+	if m.showRemoteBranches {
+		branches = append(slices.Clip(m.branches), m.pickableRemoteBranches()...)
+	}
 	if query == "" {
-		m.filteredBranches = m.branches
+		m.filteredBranches = branches
 	} else {
 		m.filteredBranches = nil
-		for _, branch := range m.branches {
+		for _, branch := range branches {
 			if strings.Contains(strings.ToLower(branch), query) {
 				m.filteredBranches = append(m.filteredBranches, branch)
 			}
@@ -1783,11 +2991,15 @@ func (m *Model) adjustScroll() {
 
 func (m *Model) maxVisibleSessions() int {
 	// tower+blank+topborder(8) + [archived line(1)] + bottom border(1) = 10
-	available := m.windowHeight - 10
+	available := m.layoutHeight() - 10
+	if callout := m.viewTutorialCallout(m.sidebarWidth - 2); callout != "" {
+		available -= lipgloss.Height(callout)
+	}
+	available /= sessionRowHeight
 	if available < 1 {
 		return 1
 	}
-	return available // 1 line per session now
+	return available
 }
 
 // sidebarHitTest maps a mouse Y coordinate to the sidebar element at that position.
@@ -1819,20 +3031,29 @@ func (m *Model) sidebarHitTest(y int) (kind string, index int) {
 		lineIdx++
 	}
 
-	// Session rows
+	// Session rows, and with archived sessions shown, the archived line and
+	// archived sessions
+	listLen := m.sidebarListLen()
 	endIdx := m.scrollOffset + maxVisible
-	if endIdx > len(active) {
-		endIdx = len(active)
+	if endIdx > listLen {
+		endIdx = listLen
 	}
-	visibleCount := endIdx - m.scrollOffset
-	if row >= lineIdx && row < lineIdx+visibleCount {
-		sessionIdx := m.scrollOffset + (row - lineIdx)
-		return "session", sessionIdx
+	visibleRows := (endIdx - m.scrollOffset) * sessionRowHeight
+	if row >= lineIdx && row < lineIdx+visibleRows {
+		sessionIdx := m.scrollOffset + (row-lineIdx)/sessionRowHeight
+		switch {
+		case sessionIdx < len(active):
+			return "session", sessionIdx
+		case sessionIdx == len(active):
+			return "archived", 0
+		default:
+			return "archived_session", sessionIdx
+		}
 	}
-	lineIdx += visibleCount
+	lineIdx += visibleRows
 
 	// "↓ N more" indicator
-	hasScrollDown := endIdx < len(active)
+	hasScrollDown := endIdx < listLen || (listLen > len(active) && endIdx < len(active)+1+m.archivedTotal)
 	if hasScrollDown {
 		if row == lineIdx {
 			return "scroll_down", 0
@@ -1840,8 +3061,8 @@ func (m *Model) sidebarHitTest(y int) (kind string, index int) {
 		lineIdx++
 	}
 
-	// Archived sessions indicator
-	if m.archivedCount() > 0 {
+	// Archived sessions indicator, unless it's in the list
+	if m.archivedCount() > 0 && !m.archivedInline {
 		if row == lineIdx {
 			return "archived", 0
 		}
@@ -1852,15 +3073,17 @@ func (m *Model) sidebarHitTest(y int) (kind string, index int) {
 }
 
 // sidebarVisible returns whether the sidebar should be rendered.
-// On narrow screens (< smallScreenThreshold), the sidebar is only shown when focused.
+// On narrow screens (< smallScreenThreshold) or when collapsed, the sidebar is
+// only shown when focused.
 func (m *Model) sidebarVisible() bool {
-	if m.windowWidth >= smallScreenThreshold {
+	if m.windowWidth >= smallScreenThreshold && !m.sidebarCollapsed && !accessible {
 		return true
 	}
 	return m.focus == focusSidebar
 }
 
-// resizeTerminalIfNeeded resizes the active terminal to match current pane dimensions.
+// resizeTerminalIfNeeded resizes the active (and pinned) terminal to match
+// current pane dimensions.
 func (m *Model) resizeTerminalIfNeeded() {
 	if m.activeSession != nil {
 		if t, ok := m.terminals[m.activeSession.Name]; ok {
@@ -1868,21 +3091,69 @@ func (m *Model) resizeTerminalIfNeeded() {
 			t.Resize(tw, th)
 		}
 	}
+	if m.splitActive() {
+		_, th := m.terminalPaneDimensions()
+		m.terminals[m.pinnedSession.Name].Resize(m.pinnedPaneWidth(), th)
+	}
+}
+
+// resizeAllTerminals gives every running terminal the terminal pane's size,
+// or the pinned pane's for the pinned session
+func (m *Model) resizeAllTerminals() {
+	tw, th := m.terminalPaneDimensions()
+	split := m.splitActive()
+	for name, t := range m.terminals {
+		if !t.IsRunning() {
+			continue
+		}
+		if split && name == m.pinnedSession.Name {
+			t.Resize(m.pinnedPaneWidth(), th)
+		} else {
+			t.Resize(tw, th)
+		}
+	}
+}
+
+// syncTerminalFocus tells each terminal whether it is on screen, so the ones
+// that aren't are refreshed less often
+func (m *Model) syncTerminalFocus() {
+	shown := func(s *session.Session, name string) bool {
+		return s != nil && s.Name == name
+	}
+	split := m.splitActive()
+	for name, t := range m.terminals {
+		t.SetFocused(shown(m.activeSession, name) || split && shown(m.pinnedSession, name))
+	}
+	for i, tab := range m.tabs {
+		if i == m.tabIndex || tab == nil {
+			continue
+		}
+		for _, t := range tab.terminals {
+			t.SetFocused(false)
+		}
+	}
+}
+
+// terminalAreaWidth returns the width available to terminal panes (all of
+// the window not used by the sidebar)
+func (m *Model) terminalAreaWidth() int {
+	if m.sidebarVisible() {
+		// m.sidebarWidth already includes border chars, plus 1 for spacer
+		return m.windowWidth - m.sidebarWidth - 1
+	}
+	return m.windowWidth
 }
 
 // terminalPaneDimensions returns the inner width/height for the terminal pane.
 func (m *Model) terminalPaneDimensions() (int, int) {
-	var termWidth int
-	if m.sidebarVisible() {
-		// sidebarWidth already includes border chars, plus 1 for spacer
-		termWidth = m.windowWidth - sidebarWidth - 1
-	} else {
-		termWidth = m.windowWidth
+	termWidth := m.terminalAreaWidth()
+	if m.splitActive() {
+		_, termWidth = splitPaneWidths(termWidth)
 	}
 	if termWidth < 10 {
 		termWidth = 10
 	}
-	termHeight := m.windowHeight // no terminal border
+	termHeight := m.layoutHeight() - m.tabBarHeight() // no terminal border
 	if termHeight < 5 {
 		termHeight = 5
 	}
@@ -1900,7 +3171,7 @@ func (m *Model) mouseToTermCoords(mouseX, mouseY, termStartX int) (col, row int)
 	if col >= tw {
 		col = tw - 1
 	}
-	row = mouseY + 1 // Bubble Tea mouse Y is 1 above rendered row
+	row = mouseY + 1 - m.tabBarHeight() // Bubble Tea mouse Y is 1 above rendered row
 	if row < 0 {
 		row = 0
 	}
@@ -1913,20 +3184,31 @@ func (m *Model) mouseToTermCoords(mouseX, mouseY, termStartX int) (col, row int)
 // --- View ---
 
 func (m *Model) View() string {
+	if asciiOnly {
+		return toASCII(m.view())
+	}
+	return m.view()
+}
+
+func (m *Model) view() string {
 	if m.windowWidth == 0 || m.windowHeight == 0 {
 		return "Loading..."
 	}
 
 	var layout string
-	if !m.sidebarVisible() {
+	if accessible && m.sidebarVisible() {
+		// One pane at a time, the sidebar as plain lines
+		layout = m.viewAccessibleSidebar()
+	} else if !m.sidebarVisible() {
 		// Narrow screen + terminal focused: terminal only
-		layout = m.viewTerminal()
+		layout = m.viewTerminalArea()
 	} else {
 		// Sidebar visible: both panes side by side
 		sidebar := m.viewSidebar()
-		termPane := m.viewTerminal()
+		termPane := m.viewTerminalArea()
 		layout = lipgloss.JoinHorizontal(lipgloss.Top, sidebar, " ", termPane)
 	}
+	layout += "\n" + m.viewStatusBar()
 
 	// Render overlay on top if active
 	if m.overlay != overlayNone {
@@ -1940,7 +3222,7 @@ func (m *Model) View() string {
 }
 
 func (m *Model) viewSidebar() string {
-	innerWidth := sidebarWidth - 2
+	innerWidth := m.sidebarWidth - 2
 	if innerWidth < 1 {
 		innerWidth = 1
 	}
@@ -1950,30 +3232,24 @@ func (m *Model) viewSidebar() string {
 	if m.focus == focusSidebar {
 		towerStyle = lipgloss.NewStyle().Foreground(primary)
 		atcStyle = lipgloss.NewStyle().Foreground(textNormal).Bold(true)
-		versionStyle = lipgloss.NewStyle().Foreground(textMuted)
+		versionStyle = textStyle(textMuted)
 		repoStyle = lipgloss.NewStyle().Foreground(primary)
 		helpKeyStyle = lipgloss.NewStyle().Foreground(textNormal)
-		helpDescStyle = lipgloss.NewStyle().Foreground(textMuted)
+		helpDescStyle = textStyle(textMuted)
 	} else {
-		towerStyle = lipgloss.NewStyle().Foreground(textMuted)
-		atcStyle = lipgloss.NewStyle().Foreground(textMuted)
-		versionStyle = lipgloss.NewStyle().Foreground(textDim)
-		repoStyle = lipgloss.NewStyle().Foreground(textMuted)
-		helpKeyStyle = lipgloss.NewStyle().Foreground(textMuted)
-		helpDescStyle = lipgloss.NewStyle().Foreground(textDim)
+		towerStyle = textStyle(textMuted)
+		atcStyle = textStyle(textMuted)
+		versionStyle = textStyle(textDim)
+		repoStyle = textStyle(textMuted)
+		helpKeyStyle = textStyle(textMuted)
+		helpDescStyle = textStyle(textDim)
 	}
 	// Highlight the repo name when the project header is selected
 	if m.isProjectHeaderSelected() {
 		if m.focus == focusSidebar {
-			repoStyle = lipgloss.NewStyle().
-				Background(primary).
-				Foreground(lipgloss.Color("#000000")).
-				Bold(true)
+			repoStyle = barStyle(primary)
 		} else {
-			repoStyle = lipgloss.NewStyle().
-				Background(textDim).
-				Foreground(lipgloss.Color("#000000")).
-				Bold(true)
+			repoStyle = barStyle(textDim)
 		}
 	}
 
@@ -1986,10 +3262,18 @@ func (m *Model) viewSidebar() string {
 	pad := "   "
 	tower.WriteString("\n")
 	tower.WriteString("  " + towerStyle.Render("__\\-----/__") + pad + helpItem("^C", "back to sidebar") + "\n")
-	tower.WriteString("  " + towerStyle.Render("\\         /") + pad + helpItem("n", " new session") + "\n")
-	tower.WriteString("  " + towerStyle.Render(" \\  ") + atcStyle.Render("ATC") + towerStyle.Render("  /") + pad + " " + helpItem("a", " archive") + "\n")
-	tower.WriteString("  " + towerStyle.Render("  \\  _  /") + pad + "  " + helpItem("?", " help") + "\n")
-	tower.WriteString("  " + towerStyle.Render("   |   |") + pad + "   " + versionStyle.Render(Version) + "\n")
+	tower.WriteString("  " + towerStyle.Render("\\         /") + pad + helpItem(m.keys.New, " new session") + "\n")
+	tower.WriteString("  " + towerStyle.Render(" \\  ") + atcStyle.Render("ATC") + towerStyle.Render("  /") + pad + " " + helpItem(m.keys.Archive, " archive") + "\n")
+	tower.WriteString("  " + towerStyle.Render("  \\  _  /") + pad + "  " + helpItem(m.keys.Help, " help") + "\n")
+	// Live traffic summary in place of the version once sessions are active
+	status := versionStyle.Render(Version)
+	if health := m.sessionHealth(); !health.empty() {
+		status = renderHealth(health, m.focus == focusSidebar)
+	}
+	tower.WriteString("  " + towerStyle.Render("   |   |") + pad + "   " + status + "\n")
+	if note := m.updateNote(); note != "" {
+		tower.WriteString("  " + versionStyle.Render(truncate(note, max(innerWidth-2, 1))))
+	}
 	tower.WriteString("\n")
 
 	// Top border with embedded repo name
@@ -2020,10 +3304,20 @@ func (m *Model) viewSidebar() string {
 
 	if len(filtered) == 0 && m.archivedCount() == 0 {
 		b.WriteString(metadataStyle.Render("No sessions") + "\n")
+		if m.tutorial == tutorialOff {
+			b.WriteString("\n" + placeholderStyle.Render("New here? Run `atc tutorial`") + "\n")
+		}
 	} else {
+		// With archived sessions shown, the archived line and the archived
+		// sessions scroll with the active ones
+		listLen := m.sidebarListLen()
+		total := listLen
+		if listLen > len(filtered) {
+			total = max(listLen, len(filtered)+1+m.archivedTotal)
+		}
 		endIdx := m.scrollOffset + maxVisible
-		if endIdx > len(filtered) {
-			endIdx = len(filtered)
+		if endIdx > listLen {
+			endIdx = listLen
 		}
 
 		if m.scrollOffset > 0 {
@@ -2031,48 +3325,46 @@ func (m *Model) viewSidebar() string {
 		}
 
 		for i := m.scrollOffset; i < endIdx; i++ {
-			s := filtered[i]
-			m.renderSidebarSession(&b, s, i, innerWidth)
+			switch {
+			case i < len(filtered):
+				m.renderSidebarSession(&b, filtered[i], i, innerWidth)
+			case i == len(filtered):
+				b.WriteString("\n")
+				m.renderArchivedLine(&b, innerWidth)
+			default:
+				m.renderSidebarArchived(&b, m.archivedList[i-len(filtered)-1], i, innerWidth)
+			}
 		}
 
-		if endIdx < len(filtered) {
-			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↓ %d more", len(filtered)-endIdx)) + "\n")
+		if endIdx < total {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↓ %d more", total-endIdx)) + "\n")
 		}
 	}
 
-	// Archived sessions indicator
-	if archivedN := m.archivedCount(); archivedN > 0 {
-		label := fmt.Sprintf("(%d archived)", archivedN)
-		isOnArchived := m.cursor == len(filtered)
-		if isOnArchived {
-			b.WriteString(lipgloss.NewStyle().
-				Background(textMuted).
-				Foreground(lipgloss.Color("#000000")).
-				Bold(true).
-				Width(innerWidth).
-				Render(" "+label) + "\n")
-		} else if m.focus == focusSidebar {
-			b.WriteString(lipgloss.NewStyle().Foreground(textMuted).Render(" "+label) + "\n")
-		} else {
-			b.WriteString(lipgloss.NewStyle().Foreground(textDim).Render(" "+label) + "\n")
-		}
+	// Archived sessions indicator, unless it's in the list above
+	if m.archivedCount() > 0 && !m.archivedInline {
+		m.renderArchivedLine(&b, innerWidth)
 	}
 
 	// Fill remaining space
-	towerHeight := 8                                  // 6 tower lines + 1 blank line + 1 custom top border
-	sidebarHeight := m.windowHeight - towerHeight - 1 // minus tower, minus bottom border only
+	towerHeight := 8                                    // 6 tower lines + 1 blank line + 1 custom top border
+	sidebarHeight := m.layoutHeight() - towerHeight - 1 // minus tower, minus bottom border only
 	if sidebarHeight < 1 {
 		sidebarHeight = 1
 	}
 
-	// Reserve lines for status bar if needed (divider + message = 2 lines)
-	statusLines := 0
-	if m.err != nil || m.message != "" {
-		statusLines = 2
+	// Usage limit banner and tutorial callout sit at the bottom of the sidebar
+	callout := m.viewTutorialCallout(innerWidth)
+	if banner := m.viewRateLimitBanner(innerWidth); banner != "" {
+		callout = strings.TrimPrefix(callout+"\n"+banner, "\n")
+	}
+	calloutLines := 0
+	if callout != "" {
+		calloutLines = lipgloss.Height(callout)
 	}
 
 	contentLines := strings.Count(b.String(), "\n")
-	targetLines := sidebarHeight - statusLines
+	targetLines := sidebarHeight - calloutLines
 	if targetLines < contentLines {
 		targetLines = contentLines
 	}
@@ -2081,13 +3373,8 @@ func (m *Model) viewSidebar() string {
 		contentLines++
 	}
 
-	// Status bar (errors/messages only)
-	if m.err != nil {
-		b.WriteString(dividerStyle.Render(strings.Repeat("─", innerWidth)) + "\n")
-		b.WriteString(errorStyle.Render(truncate(m.err.Error(), innerWidth)) + "\n")
-	} else if m.message != "" {
-		b.WriteString(dividerStyle.Render(strings.Repeat("─", innerWidth)) + "\n")
-		b.WriteString(successStyle.Render(truncate(m.message, innerWidth)) + "\n")
+	if callout != "" {
+		b.WriteString(callout + "\n")
 	}
 
 	style := sidebarUnfocusedStyle.BorderTop(false)
@@ -2096,11 +3383,39 @@ func (m *Model) viewSidebar() string {
 	}
 
 	bordered := style.
-		Width(sidebarWidth - 2).
+		Width(m.sidebarWidth - 2).
 		Height(sidebarHeight).
 		Render(b.String())
 
-	return tower.String() + bordered
+	return clipLines(tower.String(), m.sidebarWidth) + bordered
+}
+
+// renderArchivedLine renders the "(N archived)" line that opens the archived
+// sessions overlay
+func (m *Model) renderArchivedLine(b *strings.Builder, maxWidth int) {
+	label := fmt.Sprintf(" (%d archived)", m.archivedCount())
+	if m.cursor == len(m.activeSessions()) {
+		b.WriteString(barStyle(textMuted).Width(maxWidth).Render(label) + "\n")
+	} else if m.focus == focusSidebar {
+		b.WriteString(textStyle(textMuted).Render(label) + "\n")
+	} else {
+		b.WriteString(textStyle(textDim).Render(label) + "\n")
+	}
+}
+
+// renderSidebarArchived renders an archived session shown beneath the active
+// ones, greyed out, with when it was archived under its name
+func (m *Model) renderSidebarArchived(b *strings.Builder, s *session.Session, idx int, maxWidth int) {
+	style := textStyle(textDim)
+	if m.cursor == idx {
+		style = barStyle(textMuted).Width(maxWidth)
+	}
+	b.WriteString(style.Render("   "+truncate(s.Name, maxWidth-4)) + "\n")
+	detail := "archived"
+	if s.ArchivedAt != nil {
+		detail += " " + s.ArchivedAt.Local().Format("Jan 02 15:04")
+	}
+	b.WriteString(style.Render("   "+truncate(detail, maxWidth-4)) + "\n")
 }
 
 func (m *Model) renderSidebarSession(b *strings.Builder, s *session.Session, idx int, maxWidth int) {
@@ -2108,26 +3423,70 @@ func (m *Model) renderSidebarSession(b *strings.Builder, s *session.Session, idx
 	isSettingUp := m.settingUpSessions[s.Name]
 
 	prefix := " "
+	crashLooped := m.crashLooped(s.Name)
 	if isSettingUp {
 		prefix = " " + m.spinner.View() + " "
+	} else if crashLooped {
+		prefix = " ✗ "
+	} else if m.pinnedSession != nil && m.pinnedSession.Name == s.Name {
+		prefix = " ◧ "
+	}
+	prefix = jumpLabel(idx) + prefix
+	suffix := ""
+	if m.isDirty(s) {
+		suffix = " " + dirtyMarker
 	}
-	name := truncate(s.Name, maxWidth-lipgloss.Width(prefix)-1)
+	name := truncate(s.Name, maxWidth-lipgloss.Width(prefix)-lipgloss.Width(suffix)-1) + suffix
 
-	var style lipgloss.Style
+	var style, summaryStyle lipgloss.Style
 	if m.focus == focusSidebar {
 		if isSelected {
 			style = sidebarSessionSelectedStyle.Width(maxWidth)
+			summaryStyle = style.Bold(false)
 		} else {
 			style = sidebarSessionStyle
+			summaryStyle = textStyle(textMuted)
 		}
 	} else {
 		if isSelected {
 			style = sidebarSessionDimSelectedStyle.Width(maxWidth)
+			summaryStyle = style.Bold(false)
 		} else {
 			style = sidebarSessionDimStyle
+			summaryStyle = textStyle(textDim)
 		}
 	}
 	b.WriteString(style.Render(prefix+name) + "\n")
+
+	// Second line: what the agent was last doing, indented under the name
+	summary := truncate(m.sessionSummary(s), maxWidth-4)
+	b.WriteString(summaryStyle.Render("   "+summary) + "\n")
+}
+
+// sessionSummary is what a session's agent was last doing, or what is
+// holding it up
+func (m *Model) sessionSummary(s *session.Session) string {
+	summary := m.tagSummary(s.Name, m.verifySummary(s, m.scheduleSummary(s.Name, m.handoffSummary(s.Name, m.queueSummary(s.Name, m.conflictSummary(s, m.prSummary(s, m.diffStatSummary(s, m.devServerSummary(s, m.summaries[s.Name])))))))))
+	if state := m.containerState(s); state != "" {
+		summary = "container " + state
+	}
+	if m.crashLooped(s.Name) {
+		summary = fmt.Sprintf("crashed %d times in a row", m.crashes[s.Name].count+1)
+	}
+	return summary
+}
+
+// viewTerminalArea renders everything right of the sidebar: the project tab
+// bar, the pinned pane in split view, and the active session's terminal
+func (m *Model) viewTerminalArea() string {
+	pane := m.viewTerminal()
+	if m.splitActive() {
+		pane = lipgloss.JoinHorizontal(lipgloss.Top, m.viewPinned(), pane)
+	}
+	if m.tabBarHeight() > 0 {
+		pane = m.viewTabBar(m.terminalAreaWidth()) + "\n" + pane
+	}
+	return pane
 }
 
 func (m *Model) viewTerminal() string {
@@ -2137,40 +3496,7 @@ func (m *Model) viewTerminal() string {
 
 	if m.activeSession != nil {
 		if t, ok := m.terminals[m.activeSession.Name]; ok {
-			var rendered string
-			if !t.IsRunning() {
-				rendered = t.Render() + "\n\n  Session ended. Press Enter to restart."
-			} else {
-				rendered = t.Render()
-			}
-
-			// Overlay scroll indicator when in scroll mode
-			scrollPos := t.ScrollPosition()
-			if scrollPos > 0 {
-				indicator := scrollIndicatorStyle.Render(fmt.Sprintf(" SCROLL -%d ", scrollPos))
-				lines := strings.Split(rendered, "\n")
-				if len(lines) > 0 {
-					indicatorW := lipgloss.Width(indicator)
-					padLen := tw - indicatorW
-					if padLen < 0 {
-						padLen = 0
-					}
-					lines[0] = strings.Repeat(" ", padLen) + indicator
-				}
-				rendered = strings.Join(lines, "\n")
-			}
-
-			// Apply selection highlight
-			if m.hasSelection || m.selecting {
-				rendered = m.applySelectionHighlight(rendered)
-			}
-
-			// Dim terminal content when sidebar is focused
-			if m.focus == focusSidebar {
-				rendered = dimANSIColors(rendered, 0.75)
-			}
-
-			return rendered
+			return m.renderTerminal(t, tw, m.hasSelection || m.selecting, m.focus != focusTerminal)
 		}
 	}
 
@@ -2195,6 +3521,78 @@ func (m *Model) viewTerminal() string {
 		Render(content)
 }
 
+// overlayRight draws badge over the right end of a rendered line tw wide,
+// keeping what fits of the line to its left
+func overlayRight(line, badge string, tw int) string {
+	width := lipgloss.Width(badge)
+	if width > tw {
+		return badge
+	}
+	line = ansi.Truncate(line, tw-width, "")
+	return line + "\x1b[0m" + strings.Repeat(" ", tw-width-ansi.StringWidth(line)) + badge
+}
+
+// renderTerminal renders a terminal's screen at width tw with the scroll
+// indicator, optionally the mouse selection, and dimming when unfocused.
+func (m *Model) renderTerminal(t terminal.Terminal, tw int, highlight, dim bool) string {
+	var rendered string
+	if !t.IsRunning() {
+		_, th := m.terminalPaneDimensions()
+		rendered = m.withRestartMenu(t, t.Render(), th)
+	} else {
+		rendered = t.Render()
+	}
+	if m.search.term == t {
+		rendered = m.highlightSearch(rendered)
+	}
+	rendered = renderWindowTabs(t, rendered, tw)
+
+	copying := m.copy.term == t
+	if copying {
+		_, th := m.terminalPaneDimensions()
+		rendered = m.renderCopyMode(t, rendered, tw, th)
+	}
+
+	// Overlay scroll indicator when in scroll mode
+	scrollPos := t.ScrollPosition()
+	if scrollPos > 0 || copying {
+		label := fmt.Sprintf(" SCROLL -%d ", scrollPos)
+		if copying {
+			label = m.copyModeLabel(scrollPos)
+		}
+		indicator := scrollIndicatorStyle.Render(label)
+		lines := strings.Split(rendered, "\n")
+		if copying {
+			// Keep the rest of the line, which the cursor may be on
+			lines[0] = overlayRight(lines[0], indicator, tw)
+		} else if len(lines) > 0 {
+			indicatorW := lipgloss.Width(indicator)
+			padLen := tw - indicatorW
+			if padLen < 0 {
+				padLen = 0
+			}
+			lines[0] = strings.Repeat(" ", padLen) + indicator
+		}
+		rendered = strings.Join(lines, "\n")
+	}
+	if m.search.term == t {
+		_, th := m.terminalPaneDimensions()
+		rendered = m.searchStatusLine(rendered, tw, th)
+	}
+
+	// Apply selection highlight
+	if highlight {
+		rendered = m.applySelectionHighlight(rendered)
+	}
+
+	// Dim terminal content when another pane is focused
+	if dim {
+		rendered = dimANSIColors(rendered, 0.75)
+	}
+
+	return rendered
+}
+
 func (m *Model) viewOverlay() string {
 	switch m.overlay {
 	case overlayCreateSession:
@@ -2217,6 +3615,50 @@ func (m *Model) viewOverlay() string {
 		return m.viewArchivedOverlay()
 	case overlaySelectProject:
 		return m.viewSelectProject()
+	case overlaySetupTrust:
+		return m.viewSetupTrust()
+	case overlaySetupPreview:
+		return m.viewSetupPreview()
+	case overlayUsage:
+		return m.viewUsage()
+	case overlayActivity:
+		return m.viewActivity()
+	case overlayQuitConfirm:
+		return m.viewQuitConfirm()
+	case overlaySelectPullRequest:
+		return m.viewPullRequests()
+	case overlayBaseSync:
+		return m.viewBaseSync()
+	case overlayLand:
+		return m.viewLand()
+	case overlayChangedFiles:
+		return m.viewChangedFiles()
+	case overlayVerifyTrust:
+		return m.viewVerifyTrust()
+	case overlayVerifyLog:
+		return m.viewVerifyLog()
+	case overlayPromptQueue:
+		return m.viewPromptQueue()
+	case overlayHandoffs:
+		return m.viewHandoffs()
+	case overlayTrash:
+		return m.viewTrash()
+	case overlayHistory:
+		return m.viewHistory()
+	case overlayResume:
+		return m.viewResumePicker()
+	case overlayFork:
+		return m.viewFork()
+	case overlayDetachConfirm:
+		return m.viewDetach()
+	case overlayAdopt:
+		return m.viewAdopt()
+	case overlayLog:
+		return m.viewLog()
+	case overlaySwitcher:
+		return m.viewSwitcher()
+	case overlayExitTo:
+		return m.viewExitTo()
 	}
 	return ""
 }
@@ -2233,7 +3675,7 @@ func (m *Model) viewCreateOverlay() string {
 		b.WriteString("\n" + errorStyle.Render(m.err.Error()))
 	}
 	b.WriteString("\n\n")
-	b.WriteString(helpStyle.Render("[Enter] Next  [^B] From branch  [Esc] Cancel"))
+	b.WriteString(helpStyle.Render("[Enter] Next  [^B] From branch  [^P] From PR  [Esc] Cancel"))
 	return dialogBoxStyle.Render(b.String())
 }
 
@@ -2266,8 +3708,15 @@ func (m *Model) viewSelectBaseBranch() string {
 		endIdx = len(m.filteredBranches)
 	}
 
+	label := func(branch string) string {
+		if branch == m.defaultBaseBranch {
+			return branch + " (default)"
+		}
+		return branch
+	}
+
 	// Compute max item width for full-width highlight (match widest dialog element)
-	helpText := "[↑/↓] Navigate  [Enter] Select  [Esc] Back"
+	helpText := "[↑/↓] Navigate  [Enter] Select  [Ctrl+S] Set default  " + m.remoteBranchesHelp() + "  [Esc] Back"
 	itemWidth := len(helpText)
 	if showHead {
 		w := len(fmt.Sprintf("HEAD (%s)", m.currentBranch))
@@ -2276,8 +3725,8 @@ func (m *Model) viewSelectBaseBranch() string {
 		}
 	}
 	for i := startIdx; i < endIdx; i++ {
-		if len(m.filteredBranches[i]) > itemWidth {
-			itemWidth = len(m.filteredBranches[i])
+		if w := len(label(m.filteredBranches[i])); w > itemWidth {
+			itemWidth = w
 		}
 	}
 
@@ -2294,7 +3743,7 @@ func (m *Model) viewSelectBaseBranch() string {
 		b.WriteString(metadataStyle.Render("  ↑ "+fmt.Sprintf("%d more", startIdx)) + "\n")
 	}
 	for i := startIdx; i < endIdx; i++ {
-		branch := m.filteredBranches[i]
+		branch := label(m.filteredBranches[i])
 		pos := i + cursorOffset
 		if m.branchCursor == pos {
 			b.WriteString(selectedItemStyle.Width(itemWidth).Render(branch) + "\n")
@@ -2306,6 +3755,12 @@ func (m *Model) viewSelectBaseBranch() string {
 		b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↓ %d more", len(m.filteredBranches)-endIdx)) + "\n")
 	}
 
+	b.WriteString("\n")
+	if m.fetchFirst {
+		b.WriteString(metadataStyle.Render("[Ctrl+F] Fetch from origin first: on"))
+	} else {
+		b.WriteString(metadataStyle.Render("[Ctrl+F] Fetch from origin first: off"))
+	}
 	b.WriteString("\n")
 	b.WriteString(helpStyle.Render(helpText))
 	return dialogBoxStyle.Render(b.String())
@@ -2332,7 +3787,7 @@ func (m *Model) viewSelectExistingBranch() string {
 		b.WriteString(metadataStyle.Render("  No branches match filter") + "\n")
 	} else {
 		// Compute max item width for full-width highlight (match widest dialog element)
-		helpText := "[↑/↓] Navigate  [Enter] Select  [Esc] Back  + has session"
+		helpText := "[↑/↓] Navigate  [Enter] Select  " + m.remoteBranchesHelp() + "  [Esc] Back  + has session"
 		itemWidth := len(helpText)
 		for i := startIdx; i < endIdx; i++ {
 			// Reserve space for " +" suffix on branches with sessions
@@ -2370,7 +3825,7 @@ func (m *Model) viewSelectExistingBranch() string {
 	}
 
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("[↑/↓] Navigate  [Enter] Select  [Esc] Back  + has session"))
+	b.WriteString(helpStyle.Render("[↑/↓] Navigate  [Enter] Select  " + m.remoteBranchesHelp() + "  [Esc] Back  + has session"))
 	return dialogBoxStyle.Render(b.String())
 }
 
@@ -2414,12 +3869,19 @@ func (m *Model) viewDeleteOverlay() string {
 	b.WriteString("\n")
 	b.WriteString(dialogTextStyle.Render("  - Kill the Claude process (if running)"))
 	b.WriteString("\n")
-	b.WriteString(dialogTextStyle.Render("  - Remove the git worktree"))
-	b.WriteString("\n")
-	b.WriteString(dialogTextStyle.Render("  - Delete all local changes"))
-	b.WriteString("\n\n")
-	b.WriteString(warningStyle.Render("This cannot be undone."))
-	b.WriteString("\n\n")
+	if days := m.cfg.TrashDays; days > 0 {
+		b.WriteString(dialogTextStyle.Render(fmt.Sprintf("  - Move the git worktree to the trash for %d %s", days, plural(days, "day", "days"))))
+		b.WriteString("\n\n")
+		b.WriteString(metadataStyle.Render(fmt.Sprintf("Press %s to restore it until then.", m.keys.Trash)))
+		b.WriteString("\n\n")
+	} else {
+		b.WriteString(dialogTextStyle.Render("  - Remove the git worktree"))
+		b.WriteString("\n")
+		b.WriteString(dialogTextStyle.Render("  - Delete all local changes"))
+		b.WriteString("\n\n")
+		b.WriteString(warningStyle.Render("This cannot be undone."))
+		b.WriteString("\n\n")
+	}
 	b.WriteString(dialogTextStyle.Render("[Y] Yes, delete    [N] Cancel"))
 	return dialogBoxStyle.Render(b.String())
 }
@@ -2434,17 +3896,81 @@ func (m *Model) viewHelpOverlay() string {
 	b.WriteString("\n")
 	b.WriteString(dialogTextStyle.Render("  Enter        Start/resume session"))
 	b.WriteString("\n")
-	b.WriteString(dialogTextStyle.Render("  n            New session"))
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.New, "New session")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Delete, "Delete session")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Trash, "Restore deleted sessions")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Fork, "Fork session onto a new branch")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Detach, "Detach from ATC, keeping worktree and agent")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Adopt, "Adopt an existing worktree as a session")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Archive, "Archive session")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Project, "Switch project")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Shell, "Open shell in worktree")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Attach, "Attach with tmux, full screen (detach to return)")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.External, "Open session in a new terminal window")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Editor, "Open worktree in editor")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.ExitTo, "Quit into a shell or the agent in the worktree")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Browser, "Open dev server in browser")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Sync, "Sync branch with its base (rebase or merge)")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Land, "Squash-land on its base, push and archive")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Files, "Browse files changed since the base")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Verify, "Run the repo's verify command (log if failed)")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Queue, "Queue prompts to send one by one")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Handoff, "Hand a prompt to another session once verified")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Git, "Toggle git UI in terminal pane")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.ShellWindow, "Toggle shell in terminal pane")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.PrevWindow+" "+m.keys.NextWindow, "Previous/next window in terminal pane")))
 	b.WriteString("\n")
-	b.WriteString(dialogTextStyle.Render("  d            Delete session"))
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.CopyMode, "Copy text from terminal with the keyboard")))
 	b.WriteString("\n")
-	b.WriteString(dialogTextStyle.Render("  a            Archive session"))
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Paste, "Paste clipboard into terminal")))
 	b.WriteString("\n")
-	b.WriteString(dialogTextStyle.Render("  p            Switch project"))
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.MouseMode, "Mouse: select text or pass to app")))
 	b.WriteString("\n")
-	b.WriteString(dialogTextStyle.Render("  s            Open shell in worktree"))
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.SidebarNarrower+" "+m.keys.SidebarWider, "Narrow/widen sidebar (or drag its border)")))
 	b.WriteString("\n")
-	b.WriteString(dialogTextStyle.Render("  q            Quit ATC"))
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.SidebarToggle, "Collapse/expand sidebar")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.SidebarArchived, "Show/hide archived sessions in sidebar")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Pin, "Pin session for split view")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Usage, "Token usage and cost")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Activity, "Activity log")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.History, "Conversation history")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Log, "Errors, messages and events since ATC started")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.NextProject, "Next open project")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.PrevProject, "Previous open project")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.CloseProject, "Close project tab")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Quit, "Quit ATC")))
 	b.WriteString("\n\n")
 	b.WriteString(dialogTextStyle.Render("Terminal:"))
 	b.WriteString("\n")
@@ -2454,36 +3980,65 @@ func (m *Model) viewHelpOverlay() string {
 	b.WriteString("\n")
 	b.WriteString(dialogTextStyle.Render("  Scroll/PgDn  Scroll down (any key exits)"))
 	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("  /            Search scrollback (while scrolled; n/N older/newer)"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("  v            Copy mode (while scrolled; hjkl, v select, y yank)"))
+	b.WriteString("\n")
 	b.WriteString(dialogTextStyle.Render("  Click+drag   Select text (copies to clipboard)"))
 	b.WriteString("\n")
 	b.WriteString(dialogTextStyle.Render("  Double-click Select word"))
 	b.WriteString("\n")
 	b.WriteString(dialogTextStyle.Render("  Triple-click Select line"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.SwitchPane, "Switch pane in split view")))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render("Tower:"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("  ● working  ◌ waiting  ✗ failed/exited  ⚙ setting up"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("  " + dirtyMarker + " after a session's name: uncommitted changes"))
 	b.WriteString("\n\n")
 	b.WriteString(dialogTextStyle.Render("Global:"))
 	b.WriteString("\n")
 	b.WriteString(dialogTextStyle.Render("  Ctrl+C       Back to sidebar (from terminal)"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("  Alt+1..9     Jump to the numbered session"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.NextSession, "Next session")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.PrevSession, "Previous session")))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(helpLine(m.keys.Switcher, "Find a session in any project")))
 	b.WriteString("\n\n")
-	b.WriteString(helpStyle.Render("Press Esc or ? to close"))
+	b.WriteString(helpStyle.Render(fmt.Sprintf("Press Esc or %s to close", m.keys.Help)))
 	return dialogBoxStyle.Render(b.String())
 }
 
+// helpLine formats a key and its description as an aligned help overlay row.
+func helpLine(key, desc string) string {
+	return fmt.Sprintf("  %-12s %s", key, desc)
+}
+
 func (m *Model) viewCreatingOverlay() string {
 	var b strings.Builder
 	b.WriteString(titleStyle.Render("Creating Session"))
 	b.WriteString("\n\n")
-	b.WriteString(m.spinner.View() + " Creating \"" + m.pendingSessionName + "\"...")
+	if m.fetching {
+		b.WriteString(m.spinner.View() + " Fetching and creating \"" + m.pendingSessionName + "\"...")
+	} else {
+		b.WriteString(m.spinner.View() + " Creating \"" + m.pendingSessionName + "\"...")
+	}
 	return dialogBoxStyle.Render(b.String())
 }
 
 // --- Archived sessions overlay ---
 
 func (m *Model) openArchivedOverlay() (tea.Model, tea.Cmd) {
-	m.archivedList = m.archivedSessionsList()
+	m.archivedList = nil
 	m.archivedCursor = 0
 	m.archivedScrollOffset = 0
 	m.overlay = overlayArchivedSessions
-	return m, nil
+	return m, m.loadArchived(0, archivedPageSize)
 }
 
 func (m *Model) handleArchivedOverlayKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -2507,7 +4062,7 @@ func (m *Model) handleArchivedOverlayKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.archivedScrollOffset = m.archivedCursor - maxVisible + 1
 			}
 		}
-		return m, nil
+		return m, m.moreArchived(m.archivedCursor)
 
 	case "u":
 		if len(m.archivedList) == 0 || m.archivedCursor >= len(m.archivedList) || m.service == nil {
@@ -2521,7 +4076,7 @@ func (m *Model) handleArchivedOverlayKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return sessionUnarchivedMsg{selected.Name}
 		}
 
-	case "d":
+	case m.keys.Delete:
 		if len(m.archivedList) == 0 || m.archivedCursor >= len(m.archivedList) {
 			return m, nil
 		}
@@ -2545,7 +4100,9 @@ func (m *Model) viewArchivedOverlay() string {
 	b.WriteString(titleStyle.Render("Archived Sessions"))
 	b.WriteString("\n\n")
 
-	if len(m.archivedList) == 0 {
+	if len(m.archivedList) == 0 && m.archivedLoading {
+		b.WriteString(m.spinner.View() + " Loading archived sessions...\n")
+	} else if len(m.archivedList) == 0 {
 		b.WriteString(metadataStyle.Render("No archived sessions") + "\n")
 	} else {
 		maxVisible := 10
@@ -2559,7 +4116,7 @@ func (m *Model) viewArchivedOverlay() string {
 		}
 
 		// Compute max item width for full-width highlight (match widest dialog element)
-		helpText := "[↑/↓] Navigate  [u] Unarchive  [d] Delete  [Esc] Close"
+		helpText := fmt.Sprintf("[↑/↓] Navigate  [u] Unarchive  [%s] Delete  [Esc] Close", m.keys.Delete)
 		itemWidth := len(helpText)
 		for i := m.archivedScrollOffset; i < endIdx; i++ {
 			if len(m.archivedList[i].Name) > itemWidth {
@@ -2576,13 +4133,13 @@ func (m *Model) viewArchivedOverlay() string {
 			}
 		}
 
-		if endIdx < len(m.archivedList) {
-			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↓ %d more", len(m.archivedList)-endIdx)) + "\n")
+		if more := max(m.archivedTotal, len(m.archivedList)) - endIdx; more > 0 {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↓ %d more", more)) + "\n")
 		}
 	}
 
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("[↑/↓] Navigate  [u] Unarchive  [d] Delete  [Esc] Close"))
+	b.WriteString(helpStyle.Render(fmt.Sprintf("[↑/↓] Navigate  [u] Unarchive  [%s] Delete  [Esc] Close", m.keys.Delete)))
 	return dialogBoxStyle.Render(b.String())
 }
 
@@ -2608,13 +4165,13 @@ func (m *Model) switchViewToCurrentSession() tea.Cmd {
 		if t, ok := m.terminals[sess.Name]; ok {
 			// Terminal exists (running or stopped) — just resize if running
 			if t.IsRunning() {
-				tw, th := m.terminalPaneDimensions()
-				t.Resize(tw, th)
+				m.resizeTerminalIfNeeded()
 			}
 			return nil
 		}
-		// No terminal exists — auto-activate unless activation is already in flight
-		if m.activatingSession == sess.Name {
+		// No terminal exists — auto-activate unless activation is already in
+		// flight, or Enter should first ask which conversation to resume
+		if m.activatingSession == sess.Name || m.needsConversationChoice(sess) {
 			return nil
 		}
 		return m.activateSession(sess, false)
@@ -2777,6 +4334,18 @@ func truncateAnsi(s string, maxWidth int) string {
 	return result.String()
 }
 
+// clipLines truncates each line of an ANSI-styled block to maxWidth columns,
+// resetting styles on lines that were cut
+func clipLines(s string, maxWidth int) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if lipgloss.Width(line) > maxWidth {
+			lines[i] = truncateAnsi(line, maxWidth) + "\x1b[0m"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // skipAnsi skips past the first skip visible characters in s and returns
 // the remainder, including any ANSI sequences that appear after the skip point.
 func skipAnsi(s string, skip int) string {
@@ -2915,7 +4484,12 @@ func (m *Model) getSelectedText() string {
 	content := t.Render()
 	lines := strings.Split(content, "\n")
 	startRow, startCol, endRow, endCol := m.normalizedSelection()
+	return extractText(lines, startRow, startCol, endRow, endCol)
+}
 
+// extractText returns the plain text of lines from (startRow, startCol) to
+// (endRow, endCol), inclusive.
+func extractText(lines []string, startRow, startCol, endRow, endCol int) string {
 	var sb strings.Builder
 	for i := startRow; i <= endRow && i < len(lines); i++ {
 		if i < 0 {
@@ -2953,7 +4527,7 @@ func (m *Model) getSelectedText() string {
 
 // handleProjectOverlayClick handles clicks inside the project selection overlay.
 func (m *Model) handleProjectOverlayClick(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	if len(m.filteredProjects) == 0 {
+	if m.projectPathMode || m.forgettingProject != nil || len(m.filteredProjects) == 0 {
 		return m, nil
 	}
 
@@ -2997,6 +4571,8 @@ func (m *Model) handleProjectOverlayClick(msg tea.MouseMsg) (tea.Model, tea.Cmd)
 // --- Project selection overlay ---
 
 func (m *Model) initProjectInput() {
+	m.projectPathMode = false
+	m.forgettingProject = nil
 	m.projectInput = textinput.New()
 	m.projectInput.Placeholder = "Filter projects..."
 	m.projectInput.Focus()
@@ -3021,6 +4597,13 @@ func (m *Model) filterProjects() {
 }
 
 func (m *Model) handleSelectProjectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.projectPathMode {
+		return m.handleProjectPathKeys(msg)
+	}
+	if m.forgettingProject != nil {
+		return m.handleForgetProjectKeys(msg)
+	}
+	m.err = nil
 	totalItems := len(m.filteredProjects)
 
 	switch msg.String() {
@@ -3029,6 +4612,20 @@ func (m *Model) handleSelectProjectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "esc":
 		return m.dismissOverlay()
 
+	case "ctrl+n":
+		m.startProjectPathInput()
+		return m, textinput.Blink
+
+	case "ctrl+d":
+		m.confirmForgetProject()
+		return m, nil
+
+	case "ctrl+p":
+		if m.projectCursor < totalItems {
+			return m, m.toggleProjectPin(m.filteredProjects[m.projectCursor])
+		}
+		return m, nil
+
 	case "up", "k":
 		if m.projectCursor > 0 {
 			m.projectCursor--
@@ -3082,6 +4679,21 @@ func (m *Model) handleSelectProjectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m *Model) viewSelectProject() string {
 	var b strings.Builder
+	if m.forgettingProject != nil {
+		return m.viewForgetProject()
+	}
+	if m.projectPathMode {
+		b.WriteString(titleStyle.Render("Add Project"))
+		b.WriteString("\n\n")
+		b.WriteString(m.projectInput.View())
+		b.WriteString("\n\n")
+		if m.err != nil {
+			b.WriteString(errorStyle.Render(truncate(m.err.Error(), 60)))
+			b.WriteString("\n\n")
+		}
+		b.WriteString(helpStyle.Render("[Enter] Add and open  [Esc] Back"))
+		return dialogBoxStyle.Render(b.String())
+	}
 	b.WriteString(titleStyle.Render("Switch Project"))
 	b.WriteString("\n\n")
 	b.WriteString(m.projectInput.View())
@@ -3102,11 +4714,7 @@ func (m *Model) viewSelectProject() string {
 		}
 
 		// Compute max item width
-		helpText := "[↑/↓] Navigate  [Enter] Select  [Esc] Cancel"
-		if m.noProjectMode {
-			helpText = "[↑/↓] Navigate  [Enter] Select  [Esc] Quit"
-		}
-		itemWidth := len(helpText)
+		itemWidth := len(m.projectPickerHelp())
 
 		// Check for duplicate repo names to decide if we need path disambiguation
 		nameCount := make(map[string]int)
@@ -3123,6 +4731,9 @@ func (m *Model) viewSelectProject() string {
 			if m.service != nil && m.service.RepoPath() == p.RepoPath {
 				label += " (current)"
 			}
+			if p.Pinned {
+				label = "★ " + label
+			}
 			if len(label) > itemWidth {
 				itemWidth = len(label)
 			}
@@ -3140,6 +4751,9 @@ func (m *Model) viewSelectProject() string {
 			if m.service != nil && m.service.RepoPath() == p.RepoPath {
 				label += " (current)"
 			}
+			if p.Pinned {
+				label = "★ " + label
+			}
 			if m.projectCursor == i {
 				b.WriteString(selectedItemStyle.Width(itemWidth).Render(label) + "\n")
 			} else {
@@ -3152,14 +4766,22 @@ func (m *Model) viewSelectProject() string {
 	}
 
 	b.WriteString("\n")
-	if m.noProjectMode {
-		b.WriteString(helpStyle.Render("[↑/↓] Navigate  [Enter] Select  [Esc] Quit"))
-	} else {
-		b.WriteString(helpStyle.Render("[↑/↓] Navigate  [Enter] Select  [Esc] Cancel"))
+	if m.err != nil {
+		b.WriteString(errorStyle.Render(truncate(m.err.Error(), 60)))
+		b.WriteString("\n\n")
 	}
+	b.WriteString(helpStyle.Render(m.projectPickerHelp()))
 	return dialogBoxStyle.Render(b.String())
 }
 
+// projectPickerHelp is the key hint line under the project list
+func (m *Model) projectPickerHelp() string {
+	if m.noProjectMode {
+		return "[↑/↓] Navigate  [Enter] Select  [Ctrl+P] Pin  [Ctrl+N] Add path  [Ctrl+D] Remove  [Esc] Quit"
+	}
+	return "[↑/↓] Navigate  [Enter] Select  [Ctrl+P] Pin  [Ctrl+N] Add path  [Ctrl+D] Remove  [Esc] Cancel"
+}
+
 // truncatePath shortens a path for display, keeping the last components
 func truncatePath(path string, maxLen int) string {
 	if len(path) <= maxLen {
@@ -3170,7 +4792,11 @@ func truncatePath(path string, maxLen int) string {
 
 // copySelectionToClipboard copies the selected text to the system clipboard.
 func (m *Model) copySelectionToClipboard() {
-	text := m.getSelectedText()
+	copyToClipboard(m.getSelectedText())
+}
+
+// copyToClipboard sets the system clipboard.
+func copyToClipboard(text string) {
 	if text == "" {
 		return
 	}
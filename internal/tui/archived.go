@@ -0,0 +1,231 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/reconcile"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+)
+
+// Projects can pile up hundreds of archived sessions, so the sidebar only
+// holds the active ones and a count of the rest. The archived overlay, and
+// the sidebar when archived sessions are shown in it, load them a page at a
+// time, fetching the next page as the cursor nears the end of what's loaded
+
+// archivedPageSize is how many archived sessions are loaded at a time
+const archivedPageSize = 100
+
+// archivedPrefetch is how close the cursor gets to the end of the loaded
+// archived sessions before the next page is fetched
+const archivedPrefetch = 20
+
+// archivedLoadedMsg carries a page of archived sessions starting at offset
+type archivedLoadedMsg struct {
+	repoPath string
+	offset   int
+	sessions []*session.Session
+}
+
+// loadArchived loads up to limit archived sessions starting at offset
+func (m *Model) loadArchived(offset, limit int) tea.Cmd {
+	if m.service == nil {
+		return nil
+	}
+	m.archivedLoading = true
+	service := m.service
+	return func() tea.Msg {
+		sessions, err := service.ListArchivedSessions(limit, offset)
+		if err != nil {
+			return errMsg{err}
+		}
+		return archivedLoadedMsg{repoPath: service.RepoPath(), offset: offset, sessions: sessions}
+	}
+}
+
+// reloadArchived loads the archived sessions again, as many as were loaded
+func (m *Model) reloadArchived() tea.Cmd {
+	return m.loadArchived(0, max(len(m.archivedList), archivedPageSize))
+}
+
+// moreArchived fetches the next page of archived sessions if the cursor,
+// at index i of the loaded ones, is near their end and more are left
+func (m *Model) moreArchived(i int) tea.Cmd {
+	loaded := len(m.archivedList)
+	if m.archivedLoading || loaded >= m.archivedTotal || i < loaded-archivedPrefetch {
+		return nil
+	}
+	return m.loadArchived(loaded, archivedPageSize)
+}
+
+// moreArchivedBelow fetches the next page of archived sessions if the
+// sidebar shows them and its row row is near the end of the loaded ones
+func (m *Model) moreArchivedBelow(row int) tea.Cmd {
+	if !m.archivedInline {
+		return nil
+	}
+	i := row - len(m.activeSessions()) - 1
+	if i < 0 {
+		return nil
+	}
+	return m.moreArchived(i)
+}
+
+// showArchived puts a page of archived sessions in the overlay and sidebar
+func (m *Model) showArchived(msg archivedLoadedMsg) {
+	m.archivedLoading = false
+	if m.service == nil || msg.repoPath != m.service.RepoPath() {
+		return
+	}
+	if msg.offset == 0 {
+		m.archivedList = msg.sessions
+	} else if msg.offset == len(m.archivedList) {
+		m.archivedList = append(m.archivedList, msg.sessions...)
+	}
+	if m.cursor > m.maxSidebarCursor() {
+		m.cursor = m.maxSidebarCursor()
+		m.adjustScroll()
+	}
+	if m.overlay != overlayArchivedSessions {
+		return
+	}
+	if len(m.archivedList) == 0 {
+		m.overlay = overlayNone
+		return
+	}
+	if m.archivedCursor >= len(m.archivedList) {
+		m.archivedCursor = len(m.archivedList) - 1
+	}
+}
+
+// sessionNameTaken reports whether the current project has a session named
+// name, active or archived
+func (m *Model) sessionNameTaken(name string) bool {
+	if slices.ContainsFunc(m.sessions, func(s *session.Session) bool { return s.Name == name }) {
+		return true
+	}
+	if m.archivedTotal == 0 || m.service == nil {
+		return false
+	}
+	existing, _ := m.service.GetSession(name)
+	return existing != nil
+}
+
+// inlineArchived returns the archived sessions listed in the sidebar beneath
+// the archived line, nil unless they're shown there
+func (m *Model) inlineArchived() []*session.Session {
+	if !m.archivedInline || m.archivedTotal == 0 {
+		return nil
+	}
+	return m.archivedList
+}
+
+// sidebarListLen is how many rows the sidebar's scrolling list has: the
+// active sessions, and with archived sessions shown, the archived line and
+// the loaded archived sessions
+func (m *Model) sidebarListLen() int {
+	n := len(m.activeSessions())
+	if m.archivedInline && m.archivedTotal > 0 {
+		n += 1 + len(m.archivedList)
+	}
+	return n
+}
+
+// maxSidebarCursor is the last row the sidebar cursor can be on
+func (m *Model) maxSidebarCursor() int {
+	n := len(m.activeSessions()) - 1
+	if m.archivedTotal > 0 {
+		n += 1 + len(m.inlineArchived())
+	}
+	return max(n, 0)
+}
+
+// cursorArchived returns the archived session under the sidebar cursor, or
+// nil if it isn't on one
+func (m *Model) cursorArchived() *session.Session {
+	inline := m.inlineArchived()
+	i := m.cursor - len(m.activeSessions()) - 1
+	if i < 0 || i >= len(inline) {
+		return nil
+	}
+	return inline[i]
+}
+
+// toggleArchivedInline shows or hides archived sessions in the sidebar,
+// beneath the active ones
+func (m *Model) toggleArchivedInline() tea.Cmd {
+	m.archivedInline = !m.archivedInline
+	cmds := []tea.Cmd{m.saveSidebarSettings()}
+	if m.archivedInline {
+		m.message = "Showing archived sessions"
+		if m.archivedTotal > 0 {
+			cmds = append(cmds, m.reloadArchived())
+		}
+	} else {
+		m.message = "Hiding archived sessions"
+		m.cursor = min(m.cursor, m.maxSidebarCursor())
+		m.scrollOffset = min(m.scrollOffset, max(m.sidebarListLen()-m.maxVisibleSessions(), 0))
+		m.adjustScroll()
+	}
+	return tea.Batch(cmds...)
+}
+
+// unarchiveAtCursor unarchives the archived session under the sidebar cursor
+func (m *Model) unarchiveAtCursor() tea.Cmd {
+	selected := m.cursorArchived()
+	if selected == nil || m.service == nil {
+		return nil
+	}
+	service := m.service
+	return func() tea.Msg {
+		if err := service.UnarchiveSession(selected.Name); err != nil {
+			return errMsg{err}
+		}
+		return sessionUnarchivedMsg{selected.Name}
+	}
+}
+
+// archivedExpiredMsg reports the archived sessions the retention policy
+// deleted on startup
+type archivedExpiredMsg struct {
+	expired []*reconcile.Expired
+	err     error
+}
+
+// expireArchived deletes sessions archived longer ago than archive_retention
+// allows, in every project, in the background
+func (m *Model) expireArchived() tea.Cmd {
+	if m.db == nil || m.cfg == nil || m.cfg.ArchiveRetention.Days <= 0 {
+		return nil
+	}
+	db, cfg := m.db, m.cfg
+	return func() tea.Msg {
+		expired, err := reconcile.Expire(db, cfg)
+		if len(expired) == 0 && err == nil {
+			return nil
+		}
+		return archivedExpiredMsg{expired, err}
+	}
+}
+
+// expiredMessage is the status line summing up what expireArchived deleted
+func expiredMessage(expired []*reconcile.Expired, days int) string {
+	var names []string
+	branches := 0
+	for _, e := range expired {
+		names = append(names, fmt.Sprintf("%s (%s)", e.Name, filepath.Base(e.RepoPath)))
+		if e.Branch != "" {
+			branches++
+		}
+	}
+	n := len(expired)
+	text := fmt.Sprintf("Deleted %d %s archived over %d %s ago", n, plural(n, "session", "sessions"), days, plural(days, "day", "days"))
+	if branches > 0 {
+		text += fmt.Sprintf(" and %d %s", branches, plural(branches, "branch", "branches"))
+	}
+	return text + ": " + strings.Join(names, ", ")
+}
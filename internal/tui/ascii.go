@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// ASCII-only mode is for terminals and fonts without Unicode. Every frame is
+// passed through toASCII, which swaps the borders, arrows and symbols ATC
+// and the agents draw for ASCII characters of the same width, so layouts
+// line up as they did
+
+// asciiOnly is whether ASCII-only mode is on. Change it with SetASCII.
+var asciiOnly bool
+
+// SetASCII turns ASCII-only mode on or off
+func SetASCII(on bool) {
+	asciiOnly = on
+}
+
+// asciiRunes maps the symbols ATC draws to ASCII look-alikes
+var asciiRunes = map[rune]rune{
+	'↑':      '^',
+	'↓':      'v',
+	'→':      '>',
+	'←':      '<',
+	'—':      '-',
+	'–':      '-',
+	'−':      '-',
+	'·':      '.',
+	'∙':      '.',
+	'•':      '*',
+	'…':      '.',
+	'✓':      '+',
+	'✗':      'x',
+	'●':      '*',
+	'○':      'o',
+	'◌':      'o',
+	'◧':      '#',
+	'★':      '*',
+	'⚙':      '*',
+	'⚠':      '!',
+	'⎿':      'L',
+	'❯':      '>',
+	'›':      '>',
+	'‹':      '<',
+	'“':      '"',
+	'”':      '"',
+	'‘':      '\'',
+	'’':      '\'',
+	'\u00a0': ' ', // no-break space
+}
+
+// asciiBoxRune turns a box-drawing or block character into -, | or +
+func asciiBoxRune(r rune) (rune, bool) {
+	switch {
+	case r >= 0x2500 && r <= 0x257f:
+		switch r {
+		case '─', '━', '═', '╌', '╍', '┄', '┅', '┈', '┉', '╴', '╶', '╸', '╺':
+			return '-', true
+		case '│', '┃', '║', '╎', '╏', '┆', '┇', '┊', '┋', '╵', '╷', '╹', '╻':
+			return '|', true
+		}
+		return '+', true
+	case r >= 0x2580 && r <= 0x259f:
+		return '#', true
+	}
+	return 0, false
+}
+
+// toASCII replaces every non-ASCII character in a rendered frame with an
+// ASCII one of the same width: a look-alike where there is one, otherwise ?
+// for each column. ANSI escape sequences are ASCII and pass through.
+func toASCII(s string) string {
+	i := 0
+	for i < len(s) && s[i] < utf8.RuneSelf {
+		i++
+	}
+	if i == len(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	b.WriteString(s[:i])
+	for _, r := range s[i:] {
+		if r < utf8.RuneSelf {
+			b.WriteRune(r)
+			continue
+		}
+		if a, ok := asciiRunes[r]; ok {
+			b.WriteRune(a)
+			continue
+		}
+		if a, ok := asciiBoxRune(r); ok {
+			b.WriteRune(a)
+			continue
+		}
+		b.WriteString(strings.Repeat("?", runewidth.RuneWidth(r)))
+	}
+	return b.String()
+}
@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// baseSync is a running or conflicted sync of a session's branch with its
+// base
+type baseSync struct {
+	session   *session.Session
+	base      string
+	strategy  string // rebase or merge
+	running   bool
+	conflicts []string
+}
+
+// baseSyncDoneMsg reports how a sync with the base branch ended
+type baseSyncDoneMsg struct {
+	conflicts []string
+	err       error
+}
+
+// handleSyncWithBase rebases the selected session's branch onto its base, or
+// merges the base in, as sync_strategy says
+func (m *Model) handleSyncWithBase() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil || m.isProjectHeaderSelected() {
+		return m, nil
+	}
+	base := m.service.SessionBase(sess)
+	if base == "" {
+		m.err = fmt.Errorf("%s has no base branch to sync with", sess.Name)
+		return m, nil
+	}
+	if t, ok := m.terminals[sess.Name]; ok && t.IsRunning() && t.State() == terminal.StateWorking {
+		m.err = fmt.Errorf("%s's agent is working; sync once it's waiting", sess.Name)
+		return m, nil
+	}
+
+	strategy := m.service.Config().SyncStrategy
+	m.baseSync = &baseSync{session: sess, base: base, strategy: strategy, running: true}
+	m.overlay = overlayBaseSync
+	m.err = nil
+	return m, func() tea.Msg {
+		conflicts, err := worktree.Sync(sess.WorktreePath, base, strategy)
+		return baseSyncDoneMsg{conflicts: conflicts, err: err}
+	}
+}
+
+// finishBaseSync shows how a sync ended: done, failed, or stopped on
+// conflicts, which stay in the overlay to be dealt with
+func (m *Model) finishBaseSync(msg baseSyncDoneMsg) {
+	sync := m.baseSync
+	if sync == nil {
+		return
+	}
+	sync.running = false
+	if msg.err != nil {
+		m.baseSync = nil
+		m.overlay = overlayNone
+		m.err = msg.err
+		return
+	}
+	if len(msg.conflicts) > 0 {
+		sync.conflicts = msg.conflicts
+		return
+	}
+	m.baseSync = nil
+	m.overlay = overlayNone
+	delete(m.conflicts, sync.session.WorktreePath)
+	if sync.strategy == "merge" {
+		m.message = fmt.Sprintf("Merged %s into %s", sync.base, sync.session.Name)
+	} else {
+		m.message = fmt.Sprintf("Rebased %s onto %s", sync.session.Name, sync.base)
+	}
+}
+
+// handleBaseSyncKeys handles the sync overlay: nothing while it runs, then
+// what to do about conflicts
+func (m *Model) handleBaseSyncKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	sync := m.baseSync
+	if sync == nil || sync.running {
+		return m, nil
+	}
+	switch msg.String() {
+	case "a", "A":
+		m.baseSync = nil
+		m.overlay = overlayNone
+		if err := worktree.AbortSync(sync.session.WorktreePath, sync.strategy); err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.message = fmt.Sprintf("Aborted the %s of %s", sync.strategy, sync.session.Name)
+	case "h", "H":
+		t, ok := m.terminals[sync.session.Name]
+		if !ok || !t.IsRunning() {
+			m.err = fmt.Errorf("start %s's agent first", sync.session.Name)
+			return m, nil
+		}
+		t.Paste(conflictPrompt(sync))
+		t.SendKeys(tea.KeyMsg{Type: tea.KeyEnter})
+		m.baseSync = nil
+		m.overlay = overlayNone
+		m.err = nil
+		m.message = fmt.Sprintf("Asked %s's agent to resolve the conflicts", sync.session.Name)
+	case "l", "L", "esc":
+		return m.dismissOverlay()
+	}
+	return m, nil
+}
+
+// leaveBaseSync closes the conflict overlay, leaving the rebase or merge for
+// the user to finish in the worktree
+func (m *Model) leaveBaseSync() {
+	if m.baseSync == nil || m.baseSync.running {
+		return
+	}
+	m.message = fmt.Sprintf("Resolve the conflicts in %s's worktree to finish the %s", m.baseSync.session.Name, m.baseSync.strategy)
+	m.baseSync = nil
+	m.overlay = overlayNone
+}
+
+// conflictPrompt asks the agent to resolve a sync's conflicts and finish it
+func conflictPrompt(sync *baseSync) string {
+	files := strings.Join(sync.conflicts, ", ")
+	if sync.strategy == "merge" {
+		return fmt.Sprintf("Merging %s into this branch stopped with conflicts in %s. Resolve them, keeping the intent of both sides, then `git add` the files and run `git commit --no-edit` to finish the merge.", sync.base, files)
+	}
+	return fmt.Sprintf("Rebasing this branch onto %s stopped with conflicts in %s. Resolve them, keeping the intent of both sides, then `git add` the files and run `git rebase --continue`; repeat for any later conflicts until the rebase is done.", sync.base, files)
+}
+
+// viewBaseSync shows a sync in progress, or the files it conflicted on
+func (m *Model) viewBaseSync() string {
+	sync := m.baseSync
+	if sync == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(dialogTitleStyle.Render("Sync with " + sync.base))
+	b.WriteString("\n\n")
+	if sync.running {
+		verb := "Rebasing " + sync.session.Name + " onto " + sync.base
+		if sync.strategy == "merge" {
+			verb = "Merging " + sync.base + " into " + sync.session.Name
+		}
+		b.WriteString(m.spinner.View() + " " + verb + "...")
+		return dialogBoxStyle.Render(b.String())
+	}
+
+	n := len(sync.conflicts)
+	b.WriteString(dialogTextStyle.Render(fmt.Sprintf("The %s stopped on conflicts in %d %s:", sync.strategy, n, plural(n, "file", "files"))))
+	b.WriteString("\n\n")
+	const maxFiles = 10
+	for i, file := range sync.conflicts {
+		if i == maxFiles {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  … %d more", n-maxFiles)) + "\n")
+			break
+		}
+		b.WriteString(errorStyle.Render("  "+truncate(file, 60)) + "\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("[H] Hand the conflicts to the agent"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(fmt.Sprintf("[A] Abort the %s", sync.strategy)))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("[L] Leave it to resolve yourself"))
+	return dialogBoxStyle.Render(b.String())
+}
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"slices"
+	"time"
+)
+
+// The branch pickers open with the branches last listed for the project,
+// then refresh them in the background. The refresh skips git and the
+// database when neither the repository's refs nor the sessions have changed
+// since
+
+// branchCache is a project's branch list as of a refs change time and
+// sessions version
+type branchCache struct {
+	refs            time.Time
+	sessionsVersion int64
+	branches        branchesLoadedMsg
+}
+
+// current reports whether the cache still matches the repository and the
+// database
+func (c *branchCache) current(refs time.Time, refsOK bool, sessionsVersion int64) bool {
+	return c != nil && refsOK && refs.Equal(c.refs) && sessionsVersion == c.sessionsVersion
+}
+
+// showCachedBranches fills the branch picker from the current project's
+// cache, or empties it until the first listing arrives
+func (m *Model) showCachedBranches() {
+	var cached branchesLoadedMsg
+	if c := m.branchCaches[m.service.RepoPath()]; c != nil {
+		cached = c.branches
+	}
+	m.showBranches(cached, false)
+}
+
+// cacheBranches remembers a branch listing for its project, returning
+// whether the project already had one shown
+func (m *Model) cacheBranches(msg branchesLoadedMsg) bool {
+	if m.branchCaches == nil {
+		m.branchCaches = make(map[string]*branchCache)
+	}
+	_, cached := m.branchCaches[msg.repoPath]
+	m.branchCaches[msg.repoPath] = &branchCache{refs: msg.refs, sessionsVersion: msg.sessionsVersion, branches: msg}
+	return cached
+}
+
+// showBranches puts a branch listing in the picker. A refresh keeps the
+// cursor on the branch it was on; a first listing preselects the default
+// base branch
+func (m *Model) showBranches(msg branchesLoadedMsg, refresh bool) {
+	offset := 0
+	if m.overlay == overlaySelectBaseBranch && m.showHeadOption() {
+		offset = 1
+	}
+	var selected string
+	if i := m.branchCursor - offset; refresh && i >= 0 && i < len(m.filteredBranches) {
+		selected = m.filteredBranches[i]
+	}
+
+	m.branches = msg.branches
+	m.remoteBranches = msg.remoteBranches
+	m.branchesWithSessions = msg.branchesWithSessions
+	m.filterBranches()
+
+	switch {
+	case !refresh:
+		if m.overlay == overlaySelectBaseBranch {
+			m.preselectDefaultBaseBranch()
+		}
+	case selected != "":
+		if i := slices.Index(m.filteredBranches, selected); i >= 0 {
+			m.branchCursor = i + offset
+		}
+	}
+	m.clampBranchCursor(len(m.filteredBranches) + offset)
+}
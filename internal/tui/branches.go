@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"slices"
+
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// toggleRemoteBranches shows or hides remote-tracking branches in the
+// branch pickers
+func (m *Model) toggleRemoteBranches() {
+	m.showRemoteBranches = !m.showRemoteBranches
+	m.filterBranches()
+	total := len(m.filteredBranches)
+	if m.overlay == overlaySelectBaseBranch && m.showHeadOption() {
+		total++
+	}
+	m.clampBranchCursor(total)
+}
+
+// pickableRemoteBranches returns the remote branches to list after the local
+// ones. Checking out an existing branch skips remote branches that already
+// have a local branch, which is listed instead
+func (m *Model) pickableRemoteBranches() []string {
+	if m.overlay != overlaySelectExistingBranch {
+		return m.remoteBranches
+	}
+	var branches []string
+	for _, branch := range m.remoteBranches {
+		if !slices.Contains(m.branches, worktree.LocalBranchName(branch)) {
+			branches = append(branches, branch)
+		}
+	}
+	return branches
+}
+
+// remoteBranchesHelp is the branch pickers' key hint for the remote toggle
+func (m *Model) remoteBranchesHelp() string {
+	if m.showRemoteBranches {
+		return "[Ctrl+R] Hide remote"
+	}
+	return "[Ctrl+R] Show remote"
+}
@@ -0,0 +1,285 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// changedFiles is the changed-files browser of a session: the files its
+// branch changed since its base, and the diff of the one being viewed
+type changedFiles struct {
+	session *session.Session
+	base    string
+	files   []worktree.ChangedFile
+	loading bool
+	cursor  int
+
+	diff       []string // lines of the open diff; nil in the file list
+	diffScroll int
+}
+
+// changedFilesMsg carries the files a session's branch changed
+type changedFilesMsg struct {
+	files []worktree.ChangedFile
+	err   error
+}
+
+// fileDiffMsg carries the diff of one changed file
+type fileDiffMsg struct {
+	diff string
+	err  error
+}
+
+// openChangedFiles lists the files the selected session's branch changed
+// since its base
+func (m *Model) openChangedFiles() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil || m.isProjectHeaderSelected() {
+		return m, nil
+	}
+	base := m.service.SessionBase(sess)
+	if base == "" {
+		m.err = fmt.Errorf("%s has no base branch to compare with", sess.Name)
+		return m, nil
+	}
+	m.changedFiles = &changedFiles{session: sess, base: base, loading: true}
+	m.overlay = overlayChangedFiles
+	m.err = nil
+	return m, func() tea.Msg {
+		files, err := worktree.ChangedFiles(sess.WorktreePath, base)
+		return changedFilesMsg{files: files, err: err}
+	}
+}
+
+// handleChangedFilesKeys handles the file list and the diff viewer
+func (m *Model) handleChangedFilesKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := m.changedFiles
+	if c == nil {
+		return m, nil
+	}
+	if msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+	if c.diff != nil {
+		return m.handleFileDiffKeys(msg)
+	}
+	switch msg.String() {
+	case "esc", "q":
+		return m.dismissOverlay()
+	case "up", "k":
+		if c.cursor > 0 {
+			c.cursor--
+		}
+	case "down", "j":
+		if c.cursor < len(c.files)-1 {
+			c.cursor++
+		}
+	case "enter":
+		if c.cursor >= len(c.files) {
+			return m, nil
+		}
+		file := c.files[c.cursor]
+		return m, func() tea.Msg {
+			diff, err := worktree.FileDiff(c.session.WorktreePath, c.base, file)
+			return fileDiffMsg{diff: diff, err: err}
+		}
+	case "e", "E":
+		return m, m.editChangedFile()
+	}
+	return m, nil
+}
+
+// handleFileDiffKeys scrolls the open diff
+func (m *Model) handleFileDiffKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := m.changedFiles
+	maxScroll := max(len(c.diff)-m.diffVisibleLines(), 0)
+	switch msg.String() {
+	case "esc", "q":
+		return m.dismissOverlay()
+	case "up", "k":
+		c.diffScroll = max(c.diffScroll-1, 0)
+	case "down", "j":
+		c.diffScroll = min(c.diffScroll+1, maxScroll)
+	case "pgup", "ctrl+u":
+		c.diffScroll = max(c.diffScroll-m.diffVisibleLines(), 0)
+	case "pgdown", "ctrl+d", " ":
+		c.diffScroll = min(c.diffScroll+m.diffVisibleLines(), maxScroll)
+	case "home", "g":
+		c.diffScroll = 0
+	case "end", "G":
+		c.diffScroll = maxScroll
+	case "e", "E":
+		return m, m.editChangedFile()
+	}
+	return m, nil
+}
+
+// editChangedFile opens the selected changed file in the user's editor
+func (m *Model) editChangedFile() tea.Cmd {
+	c := m.changedFiles
+	if c.cursor >= len(c.files) {
+		return nil
+	}
+	file := c.files[c.cursor]
+	if file.Status == 'D' {
+		m.message = file.Path + " was deleted"
+		return nil
+	}
+	return m.openInEditor(c.session, filepath.Join(c.session.WorktreePath, file.Path))
+}
+
+// showChangedFiles fills in the file list once it is loaded
+func (m *Model) showChangedFiles(msg changedFilesMsg) {
+	c := m.changedFiles
+	if c == nil {
+		return
+	}
+	c.loading = false
+	if msg.err != nil {
+		m.changedFiles = nil
+		m.overlay = overlayNone
+		m.err = msg.err
+		return
+	}
+	c.files = msg.files
+}
+
+// showFileDiff opens a loaded diff in the diff viewer
+func (m *Model) showFileDiff(msg fileDiffMsg) {
+	c := m.changedFiles
+	if c == nil {
+		return
+	}
+	if msg.err != nil {
+		m.err = msg.err
+		return
+	}
+	m.err = nil
+	c.diff = strings.Split(strings.TrimSuffix(msg.diff, "\n"), "\n")
+	c.diffScroll = 0
+}
+
+// closeChangedFiles goes back from the diff viewer to the file list, or
+// closes the file list
+func (m *Model) closeChangedFiles() {
+	if c := m.changedFiles; c != nil && c.diff != nil {
+		c.diff = nil
+		return
+	}
+	m.changedFiles = nil
+	m.overlay = overlayNone
+}
+
+// diffVisibleLines is how many diff lines fit in the diff viewer
+func (m *Model) diffVisibleLines() int {
+	return max(m.windowHeight-12, 5)
+}
+
+// viewChangedFiles is the file list, or the diff viewer with a file open
+func (m *Model) viewChangedFiles() string {
+	c := m.changedFiles
+	if c == nil {
+		return ""
+	}
+	if c.diff != nil {
+		return m.viewFileDiff()
+	}
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(truncate(fmt.Sprintf("Changes in %s since %s", c.session.Name, c.base), 70)))
+	b.WriteString("\n\n")
+
+	helpText := "[↑/↓] Navigate  [Enter] Diff  [E] Edit  [Esc] Close"
+	switch {
+	case c.loading:
+		b.WriteString(m.spinner.View() + " Loading changes...")
+		b.WriteString("\n")
+	case len(c.files) == 0:
+		b.WriteString(metadataStyle.Render("  No changes") + "\n")
+	default:
+		maxVisible := 15
+		startIdx := 0
+		if c.cursor >= maxVisible {
+			startIdx = c.cursor - maxVisible + 1
+		}
+		endIdx := min(startIdx+maxVisible, len(c.files))
+
+		labels := make([]string, len(c.files))
+		itemWidth := len(helpText)
+		for i, f := range c.files {
+			label := string(f.Status) + "  " + truncate(f.Path, 60)
+			if f.OldPath != "" {
+				label = string(f.Status) + "  " + truncate(f.OldPath+" → "+f.Path, 60)
+			}
+			labels[i] = label
+			if i >= startIdx && i < endIdx && len(label) > itemWidth {
+				itemWidth = len(label)
+			}
+		}
+
+		if startIdx > 0 {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↑ %d more", startIdx)) + "\n")
+		}
+		for i := startIdx; i < endIdx; i++ {
+			if i == c.cursor {
+				b.WriteString(selectedItemStyle.Width(itemWidth).Render(labels[i]) + "\n")
+			} else {
+				b.WriteString(normalItemStyle.Width(itemWidth).Render(labels[i]) + "\n")
+			}
+		}
+		if endIdx < len(c.files) {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↓ %d more", len(c.files)-endIdx)) + "\n")
+		}
+	}
+	if m.err != nil {
+		b.WriteString("\n" + errorStyle.Render(truncate(m.err.Error(), 70)) + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(helpText))
+	return dialogBoxStyle.Render(b.String())
+}
+
+// viewFileDiff is the diff viewer: the open diff, colored by line
+func (m *Model) viewFileDiff() string {
+	c := m.changedFiles
+	file := c.files[c.cursor]
+	width := max(m.windowWidth-10, 40)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(truncate(file.Path, width)))
+	b.WriteString("\n\n")
+
+	visible := m.diffVisibleLines()
+	end := min(c.diffScroll+visible, len(c.diff))
+	for _, line := range c.diff[c.diffScroll:end] {
+		line = truncate(strings.ReplaceAll(line, "\t", "    "), width)
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"),
+			strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "):
+			b.WriteString(metadataStyle.Render(line))
+		case strings.HasPrefix(line, "@@"):
+			b.WriteString(titleStyle.Render(line))
+		case strings.HasPrefix(line, "+"):
+			b.WriteString(successStyle.Render(line))
+		case strings.HasPrefix(line, "-"):
+			b.WriteString(warningStyle.Render(line))
+		default:
+			b.WriteString(dialogTextStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+	for i := end - c.diffScroll; i < visible; i++ {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	position := fmt.Sprintf("lines %d-%d of %d", c.diffScroll+1, end, len(c.diff))
+	b.WriteString(helpStyle.Render("[↑/↓/PgUp/PgDn] Scroll  [E] Edit  [Esc] Back  " + position))
+	return dialogBoxStyle.Render(b.String())
+}
@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handlePaste pastes the system clipboard into the selected session's
+// terminal and focuses it
+func (m *Model) handlePaste() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil || m.settingUpSessions[sess.Name] {
+		return m, nil
+	}
+	name := sess.Name
+	return m, tea.Sequence(m.activateSession(sess, true), func() tea.Msg {
+		text, err := readClipboard()
+		if err != nil {
+			return errMsg{err}
+		}
+		return pasteMsg{session: name, text: text}
+	})
+}
+
+// pasteMsg carries the clipboard's text to paste into a session's terminal
+// (see handlePaste)
+type pasteMsg struct {
+	session, text string
+}
+
+// pasteInto pastes the clipboard's text into the session's terminal, if its
+// agent is running
+func (m *Model) pasteInto(msg pasteMsg) {
+	t, ok := m.terminals[msg.session]
+	if !ok || !t.IsRunning() {
+		return
+	}
+	t.ExitScrollMode()
+	t.Paste(msg.text)
+}
+
+// readClipboard returns the system clipboard's text. Unlike copying, which
+// uses OSC 52, this needs a clipboard tool on the machine ATC runs on
+func readClipboard() (string, error) {
+	var tools [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		tools = [][]string{{"pbpaste"}}
+	case "windows":
+		tools = [][]string{{"powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"}}
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			tools = append(tools, []string{"wl-paste", "--no-newline"})
+		}
+		tools = append(tools,
+			[]string{"xclip", "-selection", "clipboard", "-out"},
+			[]string{"xsel", "--clipboard", "--output"})
+	}
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool[0]); err != nil {
+			continue
+		}
+		out, err := exec.Command(tool[0], tool[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("reading the clipboard with %s failed: %w", tool[0], err)
+		}
+		return string(out), nil
+	}
+	return "", fmt.Errorf("no clipboard tool found (install wl-clipboard, xclip or xsel)")
+}
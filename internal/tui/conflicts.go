@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// conflictCheckInterval is how often sessions are checked for conflicts with
+// their base branch
+const conflictCheckInterval = 30 * time.Second
+
+// baseConflict records that a session's branch would conflict with its base
+type baseConflict struct {
+	base  string
+	files []string
+}
+
+// conflictTickMsg triggers a conflict check
+type conflictTickMsg struct{}
+
+// conflictsCheckedMsg carries the conflicts found for each checked session's
+// worktree
+type conflictsCheckedMsg struct {
+	checked   []string
+	conflicts map[string]baseConflict
+}
+
+// conflictTick schedules the next conflict check
+func conflictTick() tea.Cmd {
+	return tea.Tick(pollInterval(conflictCheckInterval), func(time.Time) tea.Msg {
+		return conflictTickMsg{}
+	})
+}
+
+// checkConflicts test-merges each of the current project's sessions with its
+// base branch in the background
+func (m *Model) checkConflicts() tea.Cmd {
+	if m.service == nil {
+		return nil
+	}
+	sessions := m.activeSessions()
+	if len(sessions) == 0 {
+		return nil
+	}
+	svc := m.service
+	return func() tea.Msg {
+		msg := conflictsCheckedMsg{conflicts: make(map[string]baseConflict)}
+		for _, s := range sessions {
+			msg.checked = append(msg.checked, s.WorktreePath)
+			base := svc.SessionBase(s)
+			if base == "" {
+				continue
+			}
+			files, err := worktree.Conflicts(s.WorktreePath, base)
+			if err == nil && len(files) > 0 {
+				msg.conflicts[s.WorktreePath] = baseConflict{base: base, files: files}
+			}
+		}
+		return msg
+	}
+}
+
+// updateConflicts records checked sessions' conflicts, keeping other
+// projects' until they are checked again
+func (m *Model) updateConflicts(msg conflictsCheckedMsg) {
+	if m.conflicts == nil {
+		m.conflicts = make(map[string]baseConflict)
+	}
+	for _, path := range msg.checked {
+		if c, ok := msg.conflicts[path]; ok {
+			m.conflicts[path] = c
+		} else {
+			delete(m.conflicts, path)
+		}
+	}
+}
+
+// conflictSummary puts a warning that the session would conflict with its
+// base in front of its sidebar summary
+func (m *Model) conflictSummary(s *session.Session, summary string) string {
+	c, ok := m.conflicts[s.WorktreePath]
+	if !ok {
+		return summary
+	}
+	label := fmt.Sprintf("⚠ conflicts with %s (%d %s)", c.base, len(c.files), plural(len(c.files), "file", "files"))
+	if summary == "" {
+		return label
+	}
+	return label + " · " + summary
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/config"
+	"github.com/kevinzwang/air-traffic-control/internal/container"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+// containersSampledMsg carries the state of each worktree's container
+type containersSampledMsg struct {
+	states map[string]string
+}
+
+// containerConfig returns the current project's container settings
+func (m *Model) containerConfig() config.ContainerConfig {
+	if m.service == nil {
+		return config.ContainerConfig{}
+	}
+	return m.service.Config().Container
+}
+
+// runContainerConfig returns the container settings to start agents and
+// shells with. They come from the repository, so they're only used once the
+// user has trusted it
+func (m *Model) runContainerConfig() config.ContainerConfig {
+	if m.service == nil {
+		return config.ContainerConfig{}
+	}
+	return m.service.TrustedConfig().Container
+}
+
+// sessionAgent returns the agent launch settings for a session, with its
+// ports, run in the session's container when the project uses them
+func (m *Model) sessionAgent(sess *session.Session) terminal.Agent {
+	agent := m.agent()
+	agent.Env = m.portEnv(sess)
+	if cfg := m.runContainerConfig(); cfg.Enabled() {
+		agent.Container = container.AgentWrapper(cfg, sess.WorktreePath, envNames(agent.Env))
+	}
+	return agent
+}
+
+// sampleContainers reads the state of the project's containers in the
+// background, alongside the resource sample
+func (m *Model) sampleContainers() tea.Cmd {
+	if !m.containerConfig().Enabled() {
+		return nil
+	}
+	return func() tea.Msg {
+		states, err := container.States()
+		if err != nil {
+			return nil
+		}
+		return containersSampledMsg{states}
+	}
+}
+
+// containerState returns the state of a session's container when it's worth
+// showing: the agent was started but its container isn't running
+func (m *Model) containerState(s *session.Session) string {
+	if _, ok := m.terminals[s.Name]; !ok || !m.containerConfig().Enabled() {
+		return ""
+	}
+	if state := m.containers[s.WorktreePath]; state != "running" {
+		return state
+	}
+	return ""
+}
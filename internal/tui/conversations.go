@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// When a session's agent starts with more than one conversation in its
+// worktree, Enter first asks which to resume (claude --resume), rather than
+// continuing whichever was active last
+
+// How a new terminal's agent picks up the session's conversation: the latest
+// one (--continue), none, or else the one with the given ID
+const (
+	resumeLatest = ""
+	resumeNone   = "-"
+)
+
+// resumePicker lists a session's conversations to pick the one its agent
+// resumes
+type resumePicker struct {
+	session       *session.Session
+	conversations []worktree.Conversation
+	loading       bool
+	cursor        int
+}
+
+// conversationsLoadedMsg carries a worktree's conversations
+type conversationsLoadedMsg struct {
+	conversations []worktree.Conversation
+}
+
+// needsConversationChoice reports whether starting the session's agent
+// should ask which conversation to resume: it isn't running and its worktree
+// has more than one
+func (m *Model) needsConversationChoice(sess *session.Session) bool {
+	if _, ok := m.terminals[sess.Name]; ok {
+		return false
+	}
+	if len(worktree.ConversationFiles(sess.WorktreePath)) < 2 {
+		return false
+	}
+	return m.tmuxSocket != "" && !m.backend.Exists(m.tmuxSocket, sess.Name)
+}
+
+// openConversationPicker lists the session's conversations to resume one
+func (m *Model) openConversationPicker(sess *session.Session) (tea.Model, tea.Cmd) {
+	m.resumePicker = &resumePicker{session: sess, loading: true}
+	m.overlay = overlayResume
+	m.err = nil
+	return m, func() tea.Msg {
+		return conversationsLoadedMsg{worktree.ListConversations(sess.WorktreePath)}
+	}
+}
+
+// showConversations fills in the picker once the conversations are read
+func (m *Model) showConversations(msg conversationsLoadedMsg) {
+	if p := m.resumePicker; p != nil {
+		p.loading = false
+		p.conversations = msg.conversations
+	}
+}
+
+// handleResumeKeys picks the conversation the agent resumes, or a new one
+func (m *Model) handleResumeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.resumePicker
+	if p == nil {
+		return m, nil
+	}
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		return m.dismissOverlay()
+	case "up", "k":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down", "j":
+		if p.cursor < len(p.conversations)-1 {
+			p.cursor++
+		}
+	case "enter":
+		if p.cursor >= len(p.conversations) {
+			return m, nil
+		}
+		return m, m.startResumed(p.conversations[p.cursor].ID)
+	case "n", "N":
+		return m, m.startResumed(resumeNone)
+	}
+	return m, nil
+}
+
+// startResumed closes the picker and starts the session's agent on the
+// chosen conversation
+func (m *Model) startResumed(resume string) tea.Cmd {
+	sess := m.resumePicker.session
+	m.resumePicker = nil
+	m.overlay = overlayNone
+	m.activatingSession = sess.Name
+	return m.activateSessionResuming(sess, true, resume)
+}
+
+// viewResumePicker lists the session's conversations, most recent first
+func (m *Model) viewResumePicker() string {
+	p := m.resumePicker
+	if p == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(truncate("Resume a Conversation: "+p.session.Name, 70)))
+	b.WriteString("\n")
+	b.WriteString(subtitleStyle.Render("The agent picks up the chosen conversation"))
+	b.WriteString("\n\n")
+
+	helpText := "[↑/↓] Navigate  [Enter] Resume  [n] New conversation  [Esc] Cancel"
+	switch {
+	case p.loading:
+		b.WriteString(m.spinner.View() + " Reading conversations...")
+		b.WriteString("\n")
+	case len(p.conversations) == 0:
+		b.WriteString(metadataStyle.Render("  No conversations") + "\n")
+	default:
+		maxVisible := 10
+		startIdx := 0
+		if p.cursor >= maxVisible {
+			startIdx = p.cursor - maxVisible + 1
+		}
+		endIdx := min(startIdx+maxVisible, len(p.conversations))
+		itemWidth := len(helpText)
+		if startIdx > 0 {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↑ %d more", startIdx)) + "\n")
+		}
+		for i := startIdx; i < endIdx; i++ {
+			c := p.conversations[i]
+			summary := strings.Join(strings.Fields(c.Summary), " ")
+			if summary == "" {
+				summary = "(no prompts)"
+			}
+			label := c.Modified.Local().Format("Jan 02 15:04") + "  " + truncate(summary, itemWidth-16)
+			if i == p.cursor {
+				b.WriteString(selectedItemStyle.Width(itemWidth).Render(label) + "\n")
+			} else {
+				b.WriteString(normalItemStyle.Width(itemWidth).Render(label) + "\n")
+			}
+		}
+		if endIdx < len(p.conversations) {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↓ %d more", len(p.conversations)-endIdx)) + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(helpText))
+	return dialogBoxStyle.Render(b.String())
+}
@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+// copyMode is keyboard text selection over a terminal's screen and
+// scrollback, for when dragging with the mouse isn't reliable (e.g. over
+// SSH). Positions are tmux line numbers (0 is the top of the screen, history
+// is negative) and columns
+type copyMode struct {
+	term     terminal.Terminal // the terminal being copied from, nil when off
+	histSize int               // scrollback length when copy mode started
+
+	row, col int // the cursor
+
+	selecting            bool // v was pressed; the selection runs from the anchor to the cursor
+	anchorRow, anchorCol int
+}
+
+// startCopyMode puts a terminal in copy mode with the cursor at the bottom
+// left of the view
+func (m *Model) startCopyMode(t terminal.Terminal) {
+	_, histSize := t.Scrollback()
+	_, th := m.terminalPaneDimensions()
+	m.search.term = nil
+	m.copy = copyMode{term: t, histSize: histSize, row: th - 1 - t.ScrollPosition()}
+	m.message = ""
+}
+
+// handleCopyMode shows the selected session and puts its terminal in copy
+// mode
+func (m *Model) handleCopyMode() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil || m.settingUpSessions[sess.Name] {
+		return m, nil
+	}
+	start := copyModeMsg{session: sess.Name}
+	return m, tea.Sequence(m.activateSession(sess, true), func() tea.Msg { return start })
+}
+
+// copyModeMsg asks for copy mode once its session's terminal is up (see
+// handleCopyMode)
+type copyModeMsg struct {
+	session string
+}
+
+// endCopyMode leaves copy mode and returns the terminal to its live view
+func (m *Model) endCopyMode() {
+	if m.copy.term != nil {
+		m.copy.term.ExitScrollMode()
+	}
+	m.copy = copyMode{}
+}
+
+// handleCopyKeys moves the copy mode cursor, selects and yanks
+func (m *Model) handleCopyKeys(t terminal.Terminal, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tw, th := m.terminalPaneDimensions()
+	c := &m.copy
+	switch msg.String() {
+	case "h", "left":
+		c.col--
+	case "l", "right":
+		c.col++
+	case "k", "up":
+		c.row--
+	case "j", "down":
+		c.row++
+	case "pgup", "ctrl+b":
+		c.row -= th / 2
+	case "pgdown", "ctrl+f":
+		c.row += th / 2
+	case "0", "home":
+		c.col = 0
+	case "$", "end":
+		c.col = tw - 1
+	case "g":
+		c.row = -c.histSize
+	case "G":
+		c.row = th - 1
+	case "v", " ":
+		c.selecting = !c.selecting
+		c.anchorRow, c.anchorCol = c.row, c.col
+	case "y", "enter":
+		if c.selecting {
+			text, histSize := t.Scrollback()
+			lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+			// Line numbers are relative to the screen, which the history is
+			// above, so they're unaffected by the history growing
+			sr, sc, er, ec := orderedRange(c.anchorRow, c.anchorCol, c.row, c.col)
+			copyToClipboard(extractText(lines, sr+histSize, sc, er+histSize, ec))
+			m.message = "Copied to clipboard"
+		}
+		m.endCopyMode()
+		return m, nil
+	case "esc", "q":
+		m.endCopyMode()
+		return m, nil
+	}
+
+	c.col = min(max(c.col, 0), tw-1)
+	c.row = min(max(c.row, -c.histSize), th-1)
+
+	// Scroll just enough to keep the cursor in view
+	scroll := t.ScrollPosition()
+	switch {
+	case c.row < -scroll:
+		scroll = -c.row
+	case c.row > th-1-scroll:
+		scroll = th - 1 - c.row
+	}
+	if scroll != t.ScrollPosition() {
+		t.ExitScrollMode()
+		t.ScrollUp(scroll)
+	}
+	return m, nil
+}
+
+// orderedRange returns two positions with the earlier one first
+func orderedRange(row1, col1, row2, col2 int) (startRow, startCol, endRow, endCol int) {
+	if row1 > row2 || (row1 == row2 && col1 > col2) {
+		return row2, col2, row1, col1
+	}
+	return row1, col1, row2, col2
+}
+
+// renderCopyMode draws the copy mode selection and cursor over a rendered
+// terminal th lines high
+func (m *Model) renderCopyMode(t terminal.Terminal, rendered string, tw, th int) string {
+	lines := strings.Split(rendered, "\n")
+	for len(lines) < th {
+		lines = append(lines, "")
+	}
+	scroll := t.ScrollPosition()
+	c := m.copy
+
+	if c.selecting {
+		sr, sc, er, ec := orderedRange(c.anchorRow, c.anchorCol, c.row, c.col)
+		for row := max(sr+scroll, 0); row <= er+scroll && row < th; row++ {
+			from, to := 0, tw-1
+			if row == sr+scroll {
+				from = sc
+			}
+			if row == er+scroll {
+				to = ec
+			}
+			lines[row] = applyHighlightToLine(lines[row], from, to, selectionLightenFactor)
+		}
+	}
+	if row := c.row + scroll; row >= 0 && row < th {
+		lines[row] = applyHighlightToLine(lines[row], c.col, c.col, copyCursorLightenFactor)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// copyCursorLightenFactor makes the copy mode cursor stand out from the
+// selection around it
+const copyCursorLightenFactor = 0.7
+
+// copyModeLabel is the indicator shown at the top of a terminal in copy mode
+func (m *Model) copyModeLabel(scrollPos int) string {
+	hints := "v select  y yank  esc exit"
+	if m.copy.selecting {
+		hints = "y yank  v cancel  esc exit"
+	}
+	if scrollPos > 0 {
+		return fmt.Sprintf(" COPY -%d  %s ", scrollPos, hints)
+	}
+	return " COPY  " + hints + " "
+}
@@ -0,0 +1,26 @@
+package tui
+
+import "testing"
+
+func TestCopyModeExtract(t *testing.T) {
+	// Two lines of history above a two line screen
+	lines := []string{"old one", "old two", "\x1b[32mscreen\x1b[0m top", "bottom"}
+	tests := []struct {
+		name                           string
+		anchorRow, anchorCol, row, col int
+		want                           string
+	}{
+		{"within a line", 0, 0, 0, 5, "screen"},
+		{"backwards", 0, 5, 0, 0, "screen"},
+		{"into history", -1, 4, 1, 2, "two\nscreen top\nbot"},
+		{"past the end of a line", -2, 4, -2, 40, "one"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr, sc, er, ec := orderedRange(tt.anchorRow, tt.anchorCol, tt.row, tt.col)
+			if got := extractText(lines, sr+2, sc, er+2, ec); got != tt.want {
+				t.Errorf("extractText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/events"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+// Detaching a session drops it from ATC but leaves its branch, worktree and
+// tmux session as they are, for the user to take over by hand
+
+// sessionDetachedMsg reports a session ATC no longer tracks
+type sessionDetachedMsg struct {
+	name         string
+	worktreePath string
+}
+
+// openDetach asks to confirm detaching the selected session
+func (m *Model) openDetach() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil || m.isProjectHeaderSelected() || m.service == nil {
+		return m, nil
+	}
+	m.selectedSession = sess
+	m.overlay = overlayDetachConfirm
+	m.err = nil
+	return m, nil
+}
+
+// handleDetachKeys detaches the session once confirmed
+func (m *Model) handleDetachKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		sess := m.selectedSession
+		if sess == nil {
+			return m.dismissOverlay()
+		}
+		// Stop following the agent without ending it
+		m.detachTerminal(sess.Name)
+		svc := m.service
+		return m, func() tea.Msg {
+			if err := svc.DetachSession(sess.Name); err != nil {
+				return errMsg{err}
+			}
+			return sessionDetachedMsg{name: sess.Name, worktreePath: sess.WorktreePath}
+		}
+	case "n", "N", "esc":
+		return m.dismissOverlay()
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// finishDetach forgets everything ATC kept about a detached session and
+// tells where to find it
+func (m *Model) finishDetach(msg sessionDetachedMsg) tea.Cmd {
+	m.logEvent(msg.name, events.KindDetached, "")
+	m.forgetPromptQueue(msg.name)
+	m.forgetPluginState(msg.name)
+	m.forgetHandoffs(msg.name)
+	m.forgetScheduledSession(msg.name)
+	m.unpinIfNamed(msg.name)
+	delete(m.settingUpSessions, msg.name)
+	delete(m.setupFailedSessions, msg.name)
+	m.selectedSession = nil
+	m.overlay = overlayNone
+	if m.activeSession != nil && m.activeSession.Name == msg.name {
+		m.activeSession = nil
+	}
+	m.message = fmt.Sprintf("Detached %s; its worktree is %s", msg.name, msg.worktreePath)
+	if m.tmuxSocket != "" && m.backend.Exists(m.tmuxSocket, msg.name) {
+		m.message += fmt.Sprintf(" (tmux -L %s attach -t %s)", m.tmuxSocket, terminal.TmuxName(msg.name))
+	}
+	return m.loadSessions()
+}
+
+// viewDetach asks to confirm detaching a session
+func (m *Model) viewDetach() string {
+	sess := m.selectedSession
+	if sess == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(dialogTitleStyle.Render("Detach Session"))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render(fmt.Sprintf("Detach \"%s\" from ATC?", sess.Name)))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render("ATC forgets the session, but keeps:"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("  - The branch " + sess.BranchName))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("  - The worktree, with its local changes"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("  - The Claude process and tmux session (if running)"))
+	b.WriteString("\n\n")
+	b.WriteString(metadataStyle.Render(truncate(sess.WorktreePath, 70)))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render("[Y] Yes, detach    [N] Cancel"))
+	return dialogBoxStyle.Render(b.String())
+}
@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// diffStatInterval is how often sessions are checked for new commits whose
+// diff stat against the base needs recomputing
+const diffStatInterval = 5 * time.Second
+
+// diffStat is a session's changes against its base, as of the branch and
+// base commits it was computed at
+type diffStat struct {
+	head    string
+	base    string
+	changes worktree.LineChanges
+}
+
+// diffStatTickMsg triggers a diff stat refresh
+type diffStatTickMsg struct{}
+
+// diffStatsMsg carries the diff stats of each checked session's worktree
+type diffStatsMsg struct {
+	checked []string
+	stats   map[string]diffStat
+}
+
+// diffStatTick schedules the next diff stat refresh
+func diffStatTick() tea.Cmd {
+	return tea.Tick(pollInterval(diffStatInterval), func(time.Time) tea.Msg {
+		return diffStatTickMsg{}
+	})
+}
+
+// loadDiffStats diffs each of the current project's sessions against its
+// base in the background, reusing the last stat of those whose branch and
+// base haven't moved
+func (m *Model) loadDiffStats() tea.Cmd {
+	if m.service == nil {
+		return nil
+	}
+	sessions := m.activeSessions()
+	if len(sessions) == 0 {
+		return nil
+	}
+	svc := m.service
+	cached := make(map[string]diffStat, len(sessions))
+	for _, s := range sessions {
+		if d, ok := m.diffStats[s.WorktreePath]; ok {
+			cached[s.WorktreePath] = d
+		}
+	}
+	return func() tea.Msg {
+		msg := diffStatsMsg{stats: make(map[string]diffStat)}
+		for _, s := range sessions {
+			msg.checked = append(msg.checked, s.WorktreePath)
+			base := svc.SessionBase(s)
+			if base == "" {
+				continue
+			}
+			revs, err := worktree.ResolveRevisions(s.WorktreePath, "HEAD", base)
+			if err != nil || len(revs) != 2 {
+				continue
+			}
+			if d, ok := cached[s.WorktreePath]; ok && d.head == revs[0] && d.base == revs[1] {
+				msg.stats[s.WorktreePath] = d
+				continue
+			}
+			changes, err := worktree.DiffStat(s.WorktreePath, base)
+			if err != nil {
+				continue
+			}
+			msg.stats[s.WorktreePath] = diffStat{head: revs[0], base: revs[1], changes: changes}
+		}
+		return msg
+	}
+}
+
+// updateDiffStats records checked sessions' diff stats, keeping other
+// projects' until they are checked again
+func (m *Model) updateDiffStats(msg diffStatsMsg) {
+	if m.diffStats == nil {
+		m.diffStats = make(map[string]diffStat)
+	}
+	for _, path := range msg.checked {
+		if d, ok := msg.stats[path]; ok {
+			m.diffStats[path] = d
+		} else {
+			delete(m.diffStats, path)
+		}
+	}
+}
+
+// diffStatSummary puts the size of the session's changes against its base,
+// e.g. "+412 −87 in 9 files", in front of its sidebar summary
+func (m *Model) diffStatSummary(s *session.Session, summary string) string {
+	d, ok := m.diffStats[s.WorktreePath]
+	if !ok || d.changes.Files == 0 {
+		return summary
+	}
+	c := d.changes
+	label := fmt.Sprintf("+%d −%d in %d %s", c.Added, c.Deleted, c.Files, plural(c.Files, "file", "files"))
+	if summary == "" {
+		return label
+	}
+	return label + " · " + summary
+}
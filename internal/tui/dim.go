@@ -1,21 +1,26 @@
 package tui
 
 import (
+	"regexp"
 	"strconv"
 	"strings"
 )
 
-// dimANSIColors walks an ANSI-colored string and reduces the brightness of
-// every color by the given factor (0.0–1.0). Non-color SGR attributes (bold,
+// dimANSIColors walks an ANSI-colored string and fades every color toward the
+// theme background by the given factor (0.0–1.0), so dimming works on both
+// dark and light terminals. Non-color SGR attributes (bold,
 // italic, underline, …) and non-SGR escape sequences (cursor movement, etc.)
 // are passed through unchanged.
 func dimANSIColors(s string, factor float64) string {
 	if len(s) == 0 {
 		return s
 	}
+	if monochrome {
+		return faintANSI(s)
+	}
 
-	// Dim default foreground: rgb(137,150,163)
-	const dimDefault = "\x1b[38;2;137;150;163m"
+	// Dim default foreground from the theme, e.g. rgb(137,150,163) for dark
+	dimDefault := "\x1b[" + dimDefaultSGR() + "m"
 
 	var out strings.Builder
 	out.Grow(len(s) + 64)
@@ -129,7 +134,7 @@ func dimANSIColors(s string, factor float64) string {
 func transformSGR(params string, factor float64) string {
 	if params == "" {
 		// ESC[m is equivalent to ESC[0m (reset).
-		return "0;38;2;137;150;163"
+		return "0;" + dimDefaultSGR()
 	}
 
 	parts := strings.Split(params, ";")
@@ -148,12 +153,12 @@ func transformSGR(params string, factor float64) string {
 		switch {
 		case code == 0:
 			// Reset — emit reset + re-apply dim default foreground.
-			out = append(out, "0", "38", "2", "137", "150", "163")
+			out = append(out, "0", dimDefaultSGR())
 			i++
 
 		case code == 39:
 			// Default foreground — replace with dim default.
-			out = append(out, "38", "2", "137", "150", "163")
+			out = append(out, dimDefaultSGR())
 			i++
 
 		case code == 49:
@@ -228,10 +233,20 @@ func transformSGR(params string, factor float64) string {
 	return strings.Join(out, ";")
 }
 
+// dimDefaultSGR returns the SGR parameters for the theme's dim default foreground.
+func dimDefaultSGR() string {
+	fg := theme.DimForeground
+	return "38;2;" + strconv.Itoa(fg[0]) + ";" + strconv.Itoa(fg[1]) + ";" + strconv.Itoa(fg[2])
+}
+
+// dimRGB fades a color toward the theme background by the given factor
+// (1.0 keeps the color, 0.0 is the background). With a black background this
+// is plain brightness scaling.
 func dimRGB(r, g, b int, factor float64) (int, int, int) {
-	return int(float64(r) * factor),
-		int(float64(g) * factor),
-		int(float64(b) * factor)
+	bg := theme.Background
+	return bg[0] + int(float64(r-bg[0])*factor),
+		bg[1] + int(float64(g-bg[1])*factor),
+		bg[2] + int(float64(b-bg[2])*factor)
 }
 
 // color256ToRGB converts a 256-color index to RGB.
@@ -281,3 +296,15 @@ var ansi16Colors = [16][3]int{
 	{0, 255, 255},   // 14: Bright Cyan
 	{255, 255, 255}, // 15: Bright White
 }
+
+// sgrPattern matches an SGR escape sequence
+var sgrPattern = regexp.MustCompile(`\x1b\[[0-9;:]*m`)
+
+// faintANSI dims a string without color, for monochrome mode: faint is
+// turned on at the start of every line and again after every SGR sequence,
+// which may have reset it
+func faintANSI(s string) string {
+	const faint = "\x1b[2m"
+	s = sgrPattern.ReplaceAllString(s, "${0}"+faint)
+	return faint + strings.ReplaceAll(s, "\n", "\n"+faint)
+}
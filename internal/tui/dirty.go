@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// dirtyCheckInterval is how often sessions' worktrees are checked for
+// uncommitted changes
+const dirtyCheckInterval = 10 * time.Second
+
+// dirtyMarker follows the names of sessions with uncommitted changes
+const dirtyMarker = "●"
+
+// dirtyTickMsg triggers a check for uncommitted changes
+type dirtyTickMsg struct{}
+
+// dirtyCheckedMsg carries which of the checked sessions' worktrees have
+// uncommitted changes
+type dirtyCheckedMsg struct {
+	checked []string
+	dirty   map[string]bool
+}
+
+// dirtyTick schedules the next check
+func dirtyTick() tea.Cmd {
+	return tea.Tick(pollInterval(dirtyCheckInterval), func(time.Time) tea.Msg {
+		return dirtyTickMsg{}
+	})
+}
+
+// checkDirty runs git status in each of the current project's sessions'
+// worktrees in the background, one at a time. A check still running when
+// the next is due makes that one skip
+func (m *Model) checkDirty() tea.Cmd {
+	if m.dirtyChecking {
+		return nil
+	}
+	sessions := m.activeSessions()
+	if len(sessions) == 0 {
+		return nil
+	}
+	m.dirtyChecking = true
+	return func() tea.Msg {
+		msg := dirtyCheckedMsg{dirty: make(map[string]bool)}
+		for _, s := range sessions {
+			st, err := worktree.GetStatus(s.WorktreePath, "")
+			if err != nil {
+				continue
+			}
+			msg.checked = append(msg.checked, s.WorktreePath)
+			if st.Dirty > 0 {
+				msg.dirty[s.WorktreePath] = true
+			}
+		}
+		return msg
+	}
+}
+
+// updateDirty records which checked sessions have uncommitted changes,
+// keeping other projects' until they are checked again
+func (m *Model) updateDirty(msg dirtyCheckedMsg) {
+	m.dirtyChecking = false
+	if m.dirty == nil {
+		m.dirty = make(map[string]bool)
+	}
+	for _, path := range msg.checked {
+		if msg.dirty[path] {
+			m.dirty[path] = true
+		} else {
+			delete(m.dirty, path)
+		}
+	}
+}
+
+// isDirty reports whether the session's worktree had uncommitted changes
+// when last checked
+func (m *Model) isDirty(s *session.Session) bool {
+	return m.dirty[s.WorktreePath]
+}
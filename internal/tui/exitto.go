@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/shell"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// Exiting to a session quits ATC and runs a shell, or the agent itself, in
+// the session's worktree in the terminal ATC was running in
+
+// ExitCommand is a command to run in ATC's place once it has quit
+type ExitCommand struct {
+	Dir  string
+	Args []string
+	Env  []string
+}
+
+// ExitCommand returns what the user chose to run once ATC quits, or nil to
+// just exit
+func (m *Model) ExitCommand() *ExitCommand {
+	return m.exitCommand
+}
+
+// openExitTo asks whether to exit to a shell or the agent in the selected
+// session's worktree
+func (m *Model) openExitTo() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil || m.settingUpSessions[sess.Name] {
+		return m, nil
+	}
+	m.selectedSession = sess
+	m.overlay = overlayExitTo
+	m.err = nil
+	return m, nil
+}
+
+// handleExitToKeys quits ATC into the chosen command
+func (m *Model) handleExitToKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	sess := m.selectedSession
+	if sess == nil {
+		return m.dismissOverlay()
+	}
+	switch msg.String() {
+	case "s", "S", "enter":
+		m.exitCommand = m.exitToCommand(sess, false)
+		return m.quit(false)
+	case "c", "C":
+		// End the agent ATC was running so it doesn't write to the same
+		// conversation as the one about to be started
+		if t, ok := m.terminals[sess.Name]; ok {
+			t.Close()
+			delete(m.terminals, sess.Name)
+		}
+		m.exitCommand = m.exitToCommand(sess, true)
+		return m.quit(false)
+	case "n", "N", "esc", m.keys.ExitTo:
+		return m.dismissOverlay()
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// exitToCommand builds the command run in a session's worktree once ATC
+// quits: the user's shell, or the agent continuing its last conversation if
+// it has one
+func (m *Model) exitToCommand(sess *session.Session, runAgent bool) *ExitCommand {
+	agent := m.sessionAgent(sess)
+	env := os.Environ()
+	if agent.Locale != "" {
+		env = append(env, "LANG="+agent.Locale, "LC_ALL="+agent.Locale)
+	}
+	env = append(env, agent.Env...)
+
+	args := []string{m.userShell()}
+	if runAgent {
+		continueSession := worktree.HasExistingConversation(sess.WorktreePath)
+		args = shell.Args(m.userShell(), agent.ShellCommand(continueSession))
+	}
+	return &ExitCommand{Dir: sess.WorktreePath, Args: args, Env: env}
+}
+
+// viewExitTo offers a shell or the agent in the selected session's worktree
+func (m *Model) viewExitTo() string {
+	sess := m.selectedSession
+	if sess == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(dialogTitleStyle.Render("Exit to Session"))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render("Quit ATC and run in " + sess.Name + "'s worktree:"))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render("[S] A shell"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("[C] The agent, ending it in ATC and continuing its conversation"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("[N] Cancel"))
+	b.WriteString("\n\n")
+	b.WriteString(metadataStyle.Render(truncate(sess.WorktreePath, 70)))
+	if m.backend == terminal.PTY && m.runningAgents() > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(dialogTextStyle.Render("Without tmux, agents stop when ATC exits."))
+	}
+	return dialogBoxStyle.Render(b.String())
+}
@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/shell"
+)
+
+// defaultExternalTerminal opens $ATC_ATTACH_COMMAND in a new window of the
+// platform's usual terminal when external_terminal isn't configured. The
+// command is given to AppleScript as an argument rather than spliced into the
+// script, so its quotes and backslashes don't need escaping
+func defaultExternalTerminal() string {
+	if runtime.GOOS == "darwin" {
+		return `osascript -e 'on run argv' -e 'tell application "Terminal" to do script (item 1 of argv)' -e 'tell application "Terminal" to activate' -e 'end run' "$ATC_ATTACH_COMMAND"`
+	}
+	return `"${TERMINAL:-x-terminal-emulator}" -e sh -c "$ATC_ATTACH_COMMAND"`
+}
+
+// handleExternalTerminal opens the selected session in a new OS terminal
+// window attached to the same tmux session
+func (m *Model) handleExternalTerminal() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil || m.tmuxSocket == "" || m.settingUpSessions[sess.Name] {
+		return m, nil
+	}
+	attach := m.backend.AttachCommand(m.tmuxSocket, sess.Name)
+	if attach == nil {
+		m.message = "Opening a session in another window needs tmux"
+		return m, nil
+	}
+	tw, th := m.terminalPaneDimensions()
+	if err := m.ensureTerminal(sess, tw, th); err != nil {
+		m.err = err
+		return m, nil
+	}
+
+	command := m.cfg.ExternalTerminal
+	if command == "" {
+		command = defaultExternalTerminal()
+	}
+	// The new window must not look nested in whatever tmux ATC runs in, so
+	// TMUX is left out of the terminal's environment and, for terminals that
+	// start from their own environment, unset again by env(1). Windows has
+	// no env(1)
+	line := shellJoin(attach.Args)
+	if runtime.GOOS != "windows" {
+		line = "env -u TMUX " + line
+	}
+	var env []string
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "TMUX=") {
+			env = append(env, kv)
+		}
+	}
+	return m, func() tea.Msg {
+		cmd := shell.Command(command)
+		cmd.Dir = sess.WorktreePath
+		cmd.Env = append(env,
+			"ATC_ATTACH_COMMAND="+line,
+			"ATC_SESSION="+sess.Name,
+			"ATC_WORKTREE="+sess.WorktreePath,
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return errMsg{fmt.Errorf("failed to open terminal window: %w: %s", err, strings.TrimSpace(string(out)))}
+		}
+		return nil
+	}
+}
+
+// shellJoin quotes args into a command line for the platform's shell
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = shell.Quote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// handleOpenEditor opens the selected session's worktree in the user's
+// editor. A configured editor runs alongside ATC; $VISUAL or $EDITOR takes
+// over the screen like the shell does
+func (m *Model) handleOpenEditor() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil {
+		return m, nil
+	}
+	return m, m.openInEditor(sess, sess.WorktreePath)
+}
+
+// openInEditor opens path, the session's worktree or a file in it, in the
+// user's editor
+func (m *Model) openInEditor(sess *session.Session, path string) tea.Cmd {
+	editor, inTerminal := m.cfg.Editor, false
+	if editor == "" {
+		editor, inTerminal = os.Getenv("VISUAL"), true
+	}
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		m.message = "Set editor in ~/.atc/config.yaml, or $EDITOR"
+		return nil
+	}
+
+	// Through the shell, so editor can carry its own arguments
+	cmd := shell.Command(editor + " " + shell.Quote(path))
+	cmd.Dir = sess.WorktreePath
+	if inTerminal {
+		return tea.Exec(&altScreenExec{cmd: cmd}, func(err error) tea.Msg {
+			return spawnTerminalFinishedMsg{err: err}
+		})
+	}
+	return func() tea.Msg {
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return errMsg{fmt.Errorf("failed to open editor: %w: %s", err, strings.TrimSpace(string(out)))}
+		}
+		return nil
+	}
+}
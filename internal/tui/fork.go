@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// Forking a session starts a new one on a branch off the session's branch,
+// with the same base, optionally bringing along its uncommitted changes, so
+// another agent can try a different direction from where it got to
+
+// forking is a session being forked
+type forking struct {
+	source      *session.Session
+	dirty       int // uncommitted changes in the source's worktree
+	copyChanges bool
+	input       textinput.Model
+}
+
+// openFork asks for the name of a fork of the selected session
+func (m *Model) openFork() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil || m.isProjectHeaderSelected() || m.service == nil {
+		return m, nil
+	}
+	st, err := worktree.GetStatus(sess.WorktreePath, "")
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+
+	input := textinput.New()
+	input.SetValue(m.forkName(sess.Name))
+	input.Focus()
+	input.CharLimit = 100
+	input.Width = 40
+	m.forking = &forking{source: sess, dirty: st.Dirty, copyChanges: st.Dirty > 0, input: input}
+	m.overlay = overlayFork
+	m.err = nil
+	return m, textinput.Blink
+}
+
+// forkName is the first of name-fork, name-fork-2, ... no session has
+func (m *Model) forkName(name string) string {
+	fork := name + "-fork"
+	for i := 2; m.sessionNameTaken(fork); i++ {
+		fork = fmt.Sprintf("%s-fork-%d", name, i)
+	}
+	return fork
+}
+
+// handleForkKeys edits the fork's name, toggles copying the uncommitted
+// changes and creates the fork
+func (m *Model) handleForkKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := m.forking
+	if f == nil {
+		return m, nil
+	}
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		return m.dismissOverlay()
+	case "tab":
+		if f.dirty > 0 {
+			f.copyChanges = !f.copyChanges
+		}
+		return m, nil
+	case "enter":
+		name := strings.TrimSpace(f.input.Value())
+		if name == "" {
+			m.err = fmt.Errorf("session name cannot be empty")
+			return m, nil
+		}
+		if err := worktree.ValidateBranchName(name); err != nil {
+			m.err = fmt.Errorf("invalid session name: %w", err)
+			return m, nil
+		}
+		m.forking = nil
+		m.pendingSessionName = name
+		m.pendingSessionBase = m.service.SessionBase(f.source)
+		if f.copyChanges {
+			m.pendingForkFrom = f.source
+		}
+		return m, m.doCreateSession(f.source.BranchName, false)
+	}
+	var cmd tea.Cmd
+	f.input, cmd = f.input.Update(msg)
+	m.err = nil
+	return m, cmd
+}
+
+// viewFork asks for the fork's name and whether it gets the uncommitted
+// changes
+func (m *Model) viewFork() string {
+	f := m.forking
+	if f == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(truncate("Fork Session: "+f.source.Name, 70)))
+	b.WriteString("\n")
+	b.WriteString(subtitleStyle.Render(truncate("A new session on a branch off "+f.source.BranchName, 70)))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render("Name:"))
+	b.WriteString("\n")
+	b.WriteString(f.input.View())
+	b.WriteString("\n\n")
+
+	helpText := "[Enter] Fork  [Esc] Cancel"
+	if f.dirty == 0 {
+		b.WriteString(metadataStyle.Render("No uncommitted changes to copy"))
+	} else {
+		check := "[ ]"
+		if f.copyChanges {
+			check = "[x]"
+		}
+		b.WriteString(dialogTextStyle.Render(fmt.Sprintf("%s Copy its %d uncommitted %s", check, f.dirty, plural(f.dirty, "change", "changes"))))
+		helpText = "[Tab] Toggle copying changes  " + helpText
+	}
+	b.WriteString("\n")
+	if m.err != nil {
+		b.WriteString("\n" + errorStyle.Render(truncate(m.err.Error(), 70)) + "\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(helpText))
+	return dialogBoxStyle.Render(b.String())
+}
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+// healthCounts aggregates session states for the tower header
+type healthCounts struct {
+	working   int
+	waiting   int
+	failed    int // setup failed or agent exited
+	settingUp int
+}
+
+// sessionHealth counts the states of this project's sessions. Working and
+// waiting come from attached terminals, so sessions that haven't been opened
+// since ATC started are not counted, and neither is the main project terminal.
+func (m *Model) sessionHealth() healthCounts {
+	var c healthCounts
+	failed := make(map[string]bool)
+	for name := range m.setupFailedSessions {
+		failed[name] = true
+	}
+	for name, t := range m.terminals {
+		if name == mainProjectTerminalKey {
+			continue
+		}
+		switch t.State() {
+		case terminal.StateWorking:
+			c.working++
+		case terminal.StateWaiting:
+			c.waiting++
+		case terminal.StateExited:
+			failed[name] = true
+		}
+	}
+	c.failed = len(failed)
+	c.settingUp = len(m.settingUpSessions)
+	return c
+}
+
+// empty reports whether there is nothing to show
+func (c healthCounts) empty() bool {
+	return c == healthCounts{}
+}
+
+// renderHealth formats the counts as a compact colored summary, e.g.
+// "● 2 ◌ 1 ✗ 0 ⚙ 1" (working, waiting, failed, setting up)
+func renderHealth(c healthCounts, focused bool) string {
+	colors := [4]lipgloss.Color{success, primary, danger, textMuted}
+	if !focused {
+		colors = [4]lipgloss.Color{textMuted, textMuted, textMuted, textDim}
+	}
+	items := []struct {
+		symbol string
+		n      int
+	}{
+		{"●", c.working},
+		{"◌", c.waiting},
+		{"✗", c.failed},
+		{"⚙", c.settingUp},
+	}
+
+	var out string
+	for i, item := range items {
+		if i > 0 {
+			out += " "
+		}
+		style := lipgloss.NewStyle().Foreground(colors[i])
+		if item.n == 0 {
+			style = textStyle(textDim)
+		}
+		out += style.Render(fmt.Sprintf("%s %d", item.symbol, item.n))
+	}
+	return out
+}
@@ -6,12 +6,14 @@ import (
 	"unicode/utf8"
 )
 
-// lightenRGB blends a color toward white by the given factor (0.0–1.0).
+// lightenRGB blends a color toward the theme's highlight target (white for
+// dark themes, black for light ones) by the given factor (0.0–1.0).
 // Factor 0.35 makes colors noticeably lighter while staying distinguishable.
 func lightenRGB(r, g, b int, factor float64) (int, int, int) {
-	return r + int(float64(255-r)*factor),
-		g + int(float64(255-g)*factor),
-		b + int(float64(255-b)*factor)
+	t := theme.HighlightTarget
+	return r + int(float64(t[0]-r)*factor),
+		g + int(float64(t[1]-g)*factor),
+		b + int(float64(t[2]-b)*factor)
 }
 
 // ansiColorState tracks the current foreground and background RGB colors
@@ -127,13 +129,17 @@ func updateColorState(state *ansiColorState, paramStr string) {
 }
 
 // emitHighlightSGR emits an SGR sequence that sets both fg and bg to lightened
-// versions of the current colors. Defaults: fg=229,229,229 bg=0,0,0.
+// versions of the current colors. Defaults come from the theme
+// (fg=229,229,229 bg=0,0,0 for dark). Without color it reverses the text.
 func emitHighlightSGR(state *ansiColorState, factor float64) string {
-	fgR, fgG, fgB := 229, 229, 229 // default white fg
+	if monochrome {
+		return "\x1b[7m"
+	}
+	fgR, fgG, fgB := theme.Foreground[0], theme.Foreground[1], theme.Foreground[2]
 	if state.fgSet {
 		fgR, fgG, fgB = state.fgR, state.fgG, state.fgB
 	}
-	bgR, bgG, bgB := 0, 0, 0 // default black bg
+	bgR, bgG, bgB := theme.Background[0], theme.Background[1], theme.Background[2]
 	if state.bgSet {
 		bgR, bgG, bgB = state.bgR, state.bgG, state.bgB
 	}
@@ -148,6 +154,9 @@ func emitHighlightSGR(state *ansiColorState, factor float64) string {
 // emitRestoreSGR restores the original (non-lightened) colors after exiting
 // the selection region.
 func emitRestoreSGR(state *ansiColorState) string {
+	if monochrome {
+		return "\x1b[27m"
+	}
 	var b strings.Builder
 	if state.fgSet {
 		b.WriteString("\x1b[38;2;" + strconv.Itoa(state.fgR) + ";" + strconv.Itoa(state.fgG) + ";" + strconv.Itoa(state.fgB) + "m")
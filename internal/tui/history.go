@@ -0,0 +1,297 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// conversationHistory is the read-only view of a session's conversation with
+// its agent, read from its latest Claude Code transcript
+type conversationHistory struct {
+	session  *session.Session
+	messages []worktree.ConversationMessage
+	loading  bool
+
+	// The messages wrapped to the overlay's width
+	lines  []historyLine
+	width  int
+	scroll int
+
+	// Search: typing is true while the query is entered, matches are the
+	// lines containing it and match the current one
+	typing  bool
+	input   textinput.Model
+	query   string
+	matches []int
+	match   int
+}
+
+// historyLine is a line of the conversation view
+type historyLine struct {
+	text  string
+	role  string
+	label bool // names who speaks next
+}
+
+// historyLoadedMsg carries the messages of a session's conversation
+type historyLoadedMsg struct {
+	messages []worktree.ConversationMessage
+	err      error
+}
+
+// openHistory shows the selected session's conversation with its agent
+func (m *Model) openHistory() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil || m.isProjectHeaderSelected() {
+		return m, nil
+	}
+	m.history = &conversationHistory{session: sess, loading: true}
+	m.overlay = overlayHistory
+	m.err = nil
+	return m, func() tea.Msg {
+		messages, err := worktree.LatestConversation(sess.WorktreePath)
+		return historyLoadedMsg{messages: messages, err: err}
+	}
+}
+
+// showHistory fills in the conversation once it is loaded, scrolled to the
+// latest messages
+func (m *Model) showHistory(msg historyLoadedMsg) {
+	h := m.history
+	if h == nil {
+		return
+	}
+	if msg.err != nil {
+		m.history = nil
+		m.overlay = overlayNone
+		m.err = msg.err
+		return
+	}
+	h.loading = false
+	h.messages = msg.messages
+	h.width = 0
+	h.scroll = m.maxHistoryScroll()
+}
+
+// historyWidth is the width the conversation is wrapped to
+func (m *Model) historyWidth() int {
+	return max(m.windowWidth-10, 40)
+}
+
+// historyLines returns the conversation's lines, wrapping its messages again
+// if the window was resized
+func (m *Model) historyLines() []historyLine {
+	h := m.history
+	width := m.historyWidth()
+	if h.width == width {
+		return h.lines
+	}
+	h.width = width
+	h.lines = nil
+	prevRole := ""
+	for _, msg := range h.messages {
+		// The agent's replies and the tools it used make up one turn
+		role := msg.Role
+		if role == worktree.RoleTool {
+			role = worktree.RoleAssistant
+		}
+		if role != prevRole {
+			if prevRole != "" {
+				h.lines = append(h.lines, historyLine{})
+			}
+			label := "You"
+			if role == worktree.RoleAssistant {
+				label = "Claude"
+			}
+			if !msg.Time.IsZero() {
+				label += "  " + msg.Time.Local().Format("Jan 02 15:04")
+			}
+			h.lines = append(h.lines, historyLine{text: label, role: role, label: true})
+			prevRole = role
+		}
+		if msg.Role == worktree.RoleTool {
+			h.lines = append(h.lines, historyLine{text: ansi.Truncate("⚙ "+msg.Text, width, "…"), role: msg.Role})
+			continue
+		}
+		text := strings.ReplaceAll(strings.TrimSpace(msg.Text), "\t", "    ")
+		for _, line := range strings.Split(ansi.Wrap(text, width, ""), "\n") {
+			h.lines = append(h.lines, historyLine{text: line, role: msg.Role})
+		}
+	}
+	h.findMatches()
+	return h.lines
+}
+
+// maxHistoryScroll scrolls the conversation to its end
+func (m *Model) maxHistoryScroll() int {
+	return max(len(m.historyLines())-m.diffVisibleLines(), 0)
+}
+
+// findMatches finds the lines containing the search query, keeping the
+// current match on the first one from the top of the view on
+func (h *conversationHistory) findMatches() {
+	h.matches = nil
+	h.match = 0
+	if h.query == "" {
+		return
+	}
+	query := strings.ToLower(h.query)
+	for i, line := range h.lines {
+		if strings.Contains(strings.ToLower(line.text), query) {
+			h.matches = append(h.matches, i)
+		}
+	}
+	for i, line := range h.matches {
+		if line >= h.scroll {
+			h.match = i
+			return
+		}
+	}
+}
+
+// showMatch scrolls the current match into the middle of the view
+func (m *Model) showMatch() {
+	h := m.history
+	if len(h.matches) == 0 {
+		return
+	}
+	h.scroll = max(min(h.matches[h.match]-m.diffVisibleLines()/2, m.maxHistoryScroll()), 0)
+}
+
+// handleHistoryKeys scrolls and searches the conversation
+func (m *Model) handleHistoryKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	h := m.history
+	if h == nil {
+		return m, nil
+	}
+	if msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+	if h.typing {
+		switch msg.Type {
+		case tea.KeyEnter:
+			h.typing = false
+			h.query = strings.TrimSpace(h.input.Value())
+			m.historyLines()
+			h.findMatches()
+			m.showMatch()
+			return m, nil
+		case tea.KeyEscape:
+			h.typing = false
+			return m, nil
+		}
+		var cmd tea.Cmd
+		h.input, cmd = h.input.Update(msg)
+		return m, cmd
+	}
+
+	maxScroll := m.maxHistoryScroll()
+	switch msg.String() {
+	case "esc", "q", m.keys.History:
+		return m.dismissOverlay()
+	case "up", "k":
+		h.scroll = max(h.scroll-1, 0)
+	case "down", "j":
+		h.scroll = min(h.scroll+1, maxScroll)
+	case "pgup", "ctrl+u":
+		h.scroll = max(h.scroll-m.diffVisibleLines(), 0)
+	case "pgdown", "ctrl+d", " ":
+		h.scroll = min(h.scroll+m.diffVisibleLines(), maxScroll)
+	case "home", "g":
+		h.scroll = 0
+	case "end", "G":
+		h.scroll = maxScroll
+	case "/":
+		h.input = textinput.New()
+		h.input.Prompt = "/"
+		h.input.CharLimit = 200
+		h.input.SetValue(h.query)
+		h.input.Focus()
+		h.typing = true
+		return m, textinput.Blink
+	case "n", "N":
+		if len(h.matches) == 0 {
+			return m, nil
+		}
+		step := 1
+		if msg.String() == "N" {
+			step = len(h.matches) - 1
+		}
+		h.match = (h.match + step) % len(h.matches)
+		m.showMatch()
+	}
+	return m, nil
+}
+
+// viewHistory is the conversation, scrolled, with the search prompt or the
+// current match at the bottom
+func (m *Model) viewHistory() string {
+	h := m.history
+	if h == nil {
+		return ""
+	}
+	width := m.historyWidth()
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(truncate("Conversation: "+h.session.Name, width)))
+	b.WriteString("\n\n")
+
+	visible := m.diffVisibleLines()
+	if h.loading {
+		b.WriteString(m.spinner.View() + " Reading the conversation...")
+		b.WriteString("\n")
+		return dialogBoxStyle.Render(b.String())
+	}
+	lines := m.historyLines()
+	if len(lines) == 0 {
+		b.WriteString(metadataStyle.Render("  No conversation yet") + "\n")
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("[Esc] Close"))
+		return dialogBoxStyle.Render(b.String())
+	}
+
+	current := -1
+	if len(h.matches) > 0 {
+		current = h.matches[h.match]
+	}
+	end := min(h.scroll+visible, len(lines))
+	for i := h.scroll; i < end; i++ {
+		line := lines[i]
+		switch {
+		case i == current:
+			b.WriteString(selectedItemStyle.Render(line.text))
+		case line.label:
+			b.WriteString(titleStyle.Render(line.text))
+		case line.role == worktree.RoleTool:
+			b.WriteString(metadataStyle.Render(line.text))
+		case line.role == worktree.RoleUser:
+			b.WriteString(successStyle.Render(line.text))
+		default:
+			b.WriteString(dialogTextStyle.Render(line.text))
+		}
+		b.WriteString("\n")
+	}
+	for i := end - h.scroll; i < visible; i++ {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	position := fmt.Sprintf("lines %d-%d of %d", h.scroll+1, end, len(lines))
+	switch {
+	case h.typing:
+		b.WriteString(h.input.View())
+	case h.query != "" && len(h.matches) == 0:
+		b.WriteString(warningStyle.Render(fmt.Sprintf("No matches for %q", h.query)) + "  " + helpStyle.Render(position))
+	case h.query != "":
+		b.WriteString(helpStyle.Render(fmt.Sprintf("[n/N] Next/previous match (%d of %d)  [/] Search  [Esc] Close  %s", h.match+1, len(h.matches), position)))
+	default:
+		b.WriteString(helpStyle.Render("[↑/↓/PgUp/PgDn] Scroll  [/] Search  [Esc] Close  " + position))
+	}
+	return dialogBoxStyle.Render(b.String())
+}
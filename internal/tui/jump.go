@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+)
+
+// The first nine sessions in the sidebar are numbered, and Alt+1..9 jumps
+// straight to one of them from either pane. Alt+j/Alt+k step through the
+// sessions the same way
+
+// jumpSessions is the number of sessions Alt+digit reaches
+const jumpSessions = 9
+
+// jumpNumber returns the session number of an Alt+1..9 key
+func jumpNumber(msg tea.KeyMsg) (int, bool) {
+	if !msg.Alt || msg.Type != tea.KeyRunes || len(msg.Runes) != 1 {
+		return 0, false
+	}
+	r := msg.Runes[0]
+	if r < '1' || r > '0'+jumpSessions {
+		return 0, false
+	}
+	return int(r - '0'), true
+}
+
+// jumpLabel is the number shown before a sidebar row, or a space past the
+// ninth session
+func jumpLabel(idx int) string {
+	if idx >= jumpSessions {
+		return " "
+	}
+	return string(rune('1' + idx))
+}
+
+// jumpToSession activates the n-th session in the sidebar, keeping the
+// terminal focused if it was
+func (m *Model) jumpToSession(n int) (tea.Model, tea.Cmd) {
+	active := m.activeSessions()
+	if n < 1 || n > len(active) {
+		return m, nil
+	}
+	sess := active[n-1]
+	m.cursor = n - 1
+	m.adjustScroll()
+	if m.needsConversationChoice(sess) {
+		return m.openConversationPicker(sess)
+	}
+	return m, m.activateSession(sess, m.focus != focusSidebar)
+}
+
+// cycleSession activates the session delta rows from the one shown (or the
+// one under the cursor), wrapping around the list
+func (m *Model) cycleSession(delta int) (tea.Model, tea.Cmd) {
+	active := m.activeSessions()
+	if len(active) == 0 {
+		return m, nil
+	}
+	idx := -1
+	if m.activeSession != nil {
+		idx = slices.IndexFunc(active, func(s *session.Session) bool { return s.Name == m.activeSession.Name })
+	}
+	if idx < 0 && m.cursor < len(active) {
+		idx = m.cursor
+	}
+	next := 0
+	switch {
+	case idx >= 0:
+		next = (idx + delta + len(active)) % len(active)
+	case delta < 0:
+		next = len(active) - 1
+	}
+	return m.jumpToSession(next + 1)
+}
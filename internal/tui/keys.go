@@ -0,0 +1,239 @@
+package tui
+
+// keyMap holds the rebindable sidebar shortcuts. Values are Bubble Tea key
+// strings as returned by tea.KeyMsg.String() (e.g. "n", "ctrl+n").
+type keyMap struct {
+	New     string
+	Delete  string
+	Trash   string // deleted sessions, to restore them
+	Fork    string // new session off the selected one's branch
+	Detach  string // stop tracking, leaving the worktree and agent running
+	Adopt   string // existing worktree as a session
+	Archive string
+	Project string
+	Shell   string
+	Help    string
+	Quit    string
+
+	// Viewing a session outside ATC's pane
+	Attach   string // full-screen tmux client
+	External string // new terminal window
+	ExitTo   string // quit ATC into a shell or the agent in the worktree
+	Editor   string // worktree in the user's editor
+	Browser  string // session's dev server in the browser
+	Files    string // files the branch changed, and their diffs
+	Verify   string // repo's test/lint command, or the log of a failed run
+
+	// Bringing a session's branch up to date with its base, and landing it
+	// there once it's done
+	Sync string
+	Land string
+
+	// Windows shown in the terminal pane in place of the agent
+	Git         string
+	ShellWindow string
+	NextWindow  string
+	PrevWindow  string
+
+	// Clipboard and the terminal pane
+	CopyMode  string
+	Paste     string
+	MouseMode string // select text vs. pass the mouse through to the app
+
+	// Prompts sent to the agent one by one as it finishes each, and to
+	// other sessions once it verifies
+	Queue   string
+	Handoff string
+
+	// Info overlays
+	Usage    string
+	Activity string
+	History  string // the agent's conversation, read-only
+	Log      string // errors, messages and events since ATC started
+
+	// Sidebar layout
+	SidebarWider    string
+	SidebarNarrower string
+	SidebarToggle   string
+	SidebarArchived string // archived sessions beneath the active ones
+
+	// Split view
+	Pin        string
+	SwitchPane string // also works while a terminal pane has focus
+
+	// Stepping through sessions, and finding one in any project, also while
+	// a terminal pane has focus
+	NextSession string
+	PrevSession string
+	Switcher    string
+
+	// Project tabs
+	NextProject  string
+	PrevProject  string
+	CloseProject string
+}
+
+// defaultKeyMap returns the built-in sidebar bindings
+func defaultKeyMap() keyMap {
+	return keyMap{
+		New:     "n",
+		Delete:  "d",
+		Trash:   "D",
+		Fork:    "F",
+		Detach:  "X",
+		Adopt:   "A",
+		Archive: "a",
+		Project: "p",
+		Shell:   "s",
+		Help:    "?",
+		Quit:    "q",
+
+		Attach:   "t",
+		External: "T",
+		ExitTo:   "x",
+		Editor:   "o",
+		Browser:  "b",
+		Files:    "f",
+		Verify:   "V",
+
+		Sync: "r",
+		Land: "L",
+
+		Git:         "g",
+		ShellWindow: "S",
+		NextWindow:  ">",
+		PrevWindow:  "<",
+
+		CopyMode:  "c",
+		Paste:     "P",
+		MouseMode: "m",
+
+		Queue:   "Q",
+		Handoff: "H",
+
+		Usage:    "u",
+		Activity: "l",
+		History:  "h",
+		Log:      "E",
+
+		SidebarWider:    "]",
+		SidebarNarrower: "[",
+		SidebarToggle:   "\\",
+		SidebarArchived: ".",
+
+		Pin:        "v",
+		SwitchPane: "ctrl+]",
+
+		NextSession: "alt+j",
+		PrevSession: "alt+k",
+		Switcher:    "ctrl+k",
+
+		NextProject:  "tab",
+		PrevProject:  "shift+tab",
+		CloseProject: "w",
+	}
+}
+
+// newKeyMap applies user overrides (action name -> key) on top of the defaults.
+// Unknown action names are ignored.
+func newKeyMap(overrides map[string]string) keyMap {
+	km := defaultKeyMap()
+	for action, key := range overrides {
+		if key == "" {
+			continue
+		}
+		switch action {
+		case "new":
+			km.New = key
+		case "delete":
+			km.Delete = key
+		case "trash":
+			km.Trash = key
+		case "fork":
+			km.Fork = key
+		case "detach":
+			km.Detach = key
+		case "adopt":
+			km.Adopt = key
+		case "archive":
+			km.Archive = key
+		case "project":
+			km.Project = key
+		case "shell":
+			km.Shell = key
+		case "attach":
+			km.Attach = key
+		case "external":
+			km.External = key
+		case "exit_to":
+			km.ExitTo = key
+		case "editor":
+			km.Editor = key
+		case "browser":
+			km.Browser = key
+		case "files":
+			km.Files = key
+		case "verify":
+			km.Verify = key
+		case "sync":
+			km.Sync = key
+		case "land":
+			km.Land = key
+		case "git":
+			km.Git = key
+		case "shell_window":
+			km.ShellWindow = key
+		case "next_window":
+			km.NextWindow = key
+		case "prev_window":
+			km.PrevWindow = key
+		case "copy_mode":
+			km.CopyMode = key
+		case "paste":
+			km.Paste = key
+		case "mouse_mode":
+			km.MouseMode = key
+		case "help":
+			km.Help = key
+		case "quit":
+			km.Quit = key
+		case "queue":
+			km.Queue = key
+		case "handoff":
+			km.Handoff = key
+		case "usage":
+			km.Usage = key
+		case "activity":
+			km.Activity = key
+		case "history":
+			km.History = key
+		case "log":
+			km.Log = key
+		case "sidebar_wider":
+			km.SidebarWider = key
+		case "sidebar_narrower":
+			km.SidebarNarrower = key
+		case "sidebar_toggle":
+			km.SidebarToggle = key
+		case "sidebar_archived":
+			km.SidebarArchived = key
+		case "pin":
+			km.Pin = key
+		case "switch_pane":
+			km.SwitchPane = key
+		case "next_session":
+			km.NextSession = key
+		case "prev_session":
+			km.PrevSession = key
+		case "switcher":
+			km.Switcher = key
+		case "next_project":
+			km.NextProject = key
+		case "prev_project":
+			km.PrevProject = key
+		case "close_project":
+			km.CloseProject = key
+		}
+	}
+	return km
+}
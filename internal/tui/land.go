@@ -0,0 +1,206 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/events"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// landing is a session being squash-landed on its base and archived
+type landing struct {
+	session *session.Session
+	target  string // local branch the squash commit goes on
+	ahead   int    // commits being squashed
+	agent   string // the agent program, killed once the branch has landed
+	input   textinput.Model
+	running bool
+}
+
+// landMergedMsg reports the squash commit made and pushed, after which the
+// session's agent is stopped and its worktree removed
+type landMergedMsg struct {
+	name   string
+	result *session.LandResult
+	err    error
+}
+
+// landDoneMsg reports how landing a session ended
+type landDoneMsg struct {
+	name      string
+	result    *session.LandResult
+	uploadErr error
+	err       error
+}
+
+// handleLand asks to confirm landing the selected session: squash-merging
+// its branch into its base, pushing, and cleaning up the session
+func (m *Model) handleLand() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil || m.isProjectHeaderSelected() {
+		return m, nil
+	}
+	if t, ok := m.terminals[sess.Name]; ok && t.IsRunning() && t.State() == terminal.StateWorking {
+		m.err = fmt.Errorf("%s's agent is working; land it once it's waiting", sess.Name)
+		return m, nil
+	}
+	target, err := m.service.LandTarget(sess)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	st, err := worktree.GetStatus(sess.WorktreePath, target)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	if st.Dirty > 0 {
+		m.err = fmt.Errorf("%s has uncommitted changes; commit or discard them first", sess.Name)
+		return m, nil
+	}
+	if st.Ahead == 0 {
+		m.err = fmt.Errorf("%s has no commits to land on %s", sess.Name, target)
+		return m, nil
+	}
+
+	input := textinput.New()
+	input.SetValue(m.service.LandSubject(sess, target))
+	input.Focus()
+	input.CharLimit = 200
+	input.Width = 60
+	m.landing = &landing{session: sess, target: target, ahead: st.Ahead, agent: m.agentName(), input: input}
+	m.overlay = overlayLand
+	m.err = nil
+	return m, textinput.Blink
+}
+
+// handleLandKeys edits the squash commit's subject and confirms landing
+func (m *Model) handleLandKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	l := m.landing
+	if l == nil || l.running {
+		return m, nil
+	}
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		return m.dismissOverlay()
+	case "enter":
+		subject := strings.TrimSpace(l.input.Value())
+		if subject == "" {
+			return m, nil
+		}
+		// The agent keeps running until the branch has landed, so a
+		// conflict or a failed push leaves the session as it was
+		name, svc := l.session.Name, m.service
+		l.running = true
+		m.err = nil
+		return m, func() tea.Msg {
+			result, err := svc.LandSession(name, subject)
+			return landMergedMsg{name: name, result: result, err: err}
+		}
+	}
+	var cmd tea.Cmd
+	l.input, cmd = l.input.Update(msg)
+	return m, cmd
+}
+
+// removeLanded stops a landed session's agent and removes the session in the
+// background
+func (m *Model) removeLanded(msg landMergedMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.finishLanding(landDoneMsg{name: msg.name, err: msg.err})
+	}
+	if t, ok := m.terminals[msg.name]; ok {
+		t.Close()
+		delete(m.terminals, msg.name)
+	}
+	svc := m.service
+	return func() tea.Msg {
+		if err := svc.RemoveLanded(msg.name); err != nil {
+			return landDoneMsg{name: msg.name, err: err}
+		}
+		return landDoneMsg{name: msg.name, result: msg.result, uploadErr: svc.UploadArchive(msg.name)}
+	}
+}
+
+// finishLanding shows how landing a session ended and drops it from the
+// sidebar once it is archived
+func (m *Model) finishLanding(msg landDoneMsg) tea.Cmd {
+	l := m.landing
+	m.landing = nil
+	m.overlay = overlayNone
+	if msg.err != nil {
+		m.err = msg.err
+		return m.loadSessions()
+	}
+
+	res := msg.result
+	m.logEvent(msg.name, events.KindLanded, res.Target)
+	m.forgetPromptQueue(msg.name)
+	m.forgetPluginState(msg.name)
+	m.forgetHandoffs(msg.name)
+	m.forgetScheduledSession(msg.name)
+	switch {
+	case res.Commit == "":
+		m.message = fmt.Sprintf("%s already had %s's changes; archived it", res.Target, msg.name)
+	case res.Upstream == "":
+		m.message = fmt.Sprintf("Landed %s on %s (not pushed: %s tracks no remote branch)", msg.name, res.Target, res.Target)
+	default:
+		m.message = fmt.Sprintf("Landed %s on %s and pushed to %s", msg.name, res.Target, res.Upstream)
+	}
+	if msg.uploadErr != nil {
+		m.err = fmt.Errorf("archive upload failed: %w", msg.uploadErr)
+	}
+	delete(m.setupFailedSessions, msg.name)
+	m.unpinIfNamed(msg.name)
+	if l != nil {
+		delete(m.conflicts, l.session.WorktreePath)
+	}
+	if m.activeSession != nil && m.activeSession.Name == msg.name {
+		m.activeSession = nil
+	}
+	return m.loadSessions()
+}
+
+// viewLand confirms landing a session, or shows it landing
+func (m *Model) viewLand() string {
+	l := m.landing
+	if l == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(dialogTitleStyle.Render("Land Session"))
+	b.WriteString("\n\n")
+	if l.running {
+		b.WriteString(m.spinner.View() + " Landing " + l.session.Name + " on " + l.target + "...")
+		return dialogBoxStyle.Render(b.String())
+	}
+
+	b.WriteString(dialogTextStyle.Render(fmt.Sprintf("Land \"%s\" on %s?", l.session.Name, l.target)))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render("This will:"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(fmt.Sprintf("  - Squash its %d %s into one commit on %s", l.ahead, plural(l.ahead, "commit", "commits"), l.target)))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("  - Push " + l.target + " (if it tracks a remote branch)"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(fmt.Sprintf("  - Kill the %s process (if running)", l.agent)))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("  - Remove the git worktree and delete " + l.session.BranchName))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("  - Archive the session"))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render("Commit message:"))
+	b.WriteString("\n")
+	b.WriteString(l.input.View())
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("[Enter] Land  [Esc] Cancel"))
+	return dialogBoxStyle.Render(b.String())
+}
@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// The log keeps the errors and messages shown in the status bar and the
+// events logged for sessions since ATC started, so a failure isn't lost as
+// soon as the next one replaces it
+
+const (
+	// logLimit is the number of entries kept, dropping the oldest
+	logLimit = 500
+	// logVisible is the number of entries shown at once
+	logVisible = 15
+)
+
+// logLevel is what kind of entry a log entry is
+type logLevel int
+
+const (
+	logError logLevel = iota
+	logInfo
+	logEvent
+)
+
+// logEntry is a line of the log
+type logEntry struct {
+	at    time.Time
+	level logLevel
+	text  string
+}
+
+// logRing holds the latest logLimit entries
+type logRing struct {
+	entries []logEntry
+	start   int // the oldest entry, once the ring is full
+}
+
+// add appends an entry, overwriting the oldest once the ring is full
+func (r *logRing) add(e logEntry) {
+	if len(r.entries) < logLimit {
+		r.entries = append(r.entries, e)
+		return
+	}
+	r.entries[r.start] = e
+	r.start = (r.start + 1) % logLimit
+}
+
+// newest returns the entries newest first, only the errors if errorsOnly
+func (r *logRing) newest(errorsOnly bool) []logEntry {
+	var out []logEntry
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		e := r.entries[(r.start+i)%len(r.entries)]
+		if errorsOnly && e.level != logError {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// addLog appends a line to the log
+func (m *Model) addLog(level logLevel, text string) {
+	m.log.add(logEntry{at: time.Now(), level: level, text: text})
+	m.announce(level, text)
+}
+
+// captureLog logs the error or message shown in the status bar, once
+func (m *Model) captureLog() {
+	if m.err == nil {
+		m.loggedErr = ""
+	} else if text := m.err.Error(); text != m.loggedErr {
+		m.loggedErr = text
+		m.addLog(logError, text)
+	}
+	if m.message != m.loggedMessage {
+		m.loggedMessage = m.message
+		if m.message != "" {
+			m.addLog(logInfo, m.message)
+		}
+	}
+}
+
+// openLog shows the log, newest entries first
+func (m *Model) openLog() (tea.Model, tea.Cmd) {
+	m.logCursor = 0
+	m.logErrorsOnly = false
+	m.overlay = overlayLog
+	return m, nil
+}
+
+// handleLogKeys moves through the log and filters it to errors
+func (m *Model) handleLogKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", m.keys.Log, m.keys.Quit:
+		return m.dismissOverlay()
+	case "up", "k":
+		if m.logCursor > 0 {
+			m.logCursor--
+		}
+	case "down", "j":
+		if m.logCursor < len(m.log.newest(m.logErrorsOnly))-1 {
+			m.logCursor++
+		}
+	case "e":
+		m.logErrorsOnly = !m.logErrorsOnly
+		m.logCursor = 0
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// viewLog lists the log's entries with when they happened; the selected one
+// is shown in full
+func (m *Model) viewLog() string {
+	var b strings.Builder
+	title := "Log"
+	if m.logErrorsOnly {
+		title = "Log: errors"
+	}
+	b.WriteString(titleStyle.Render(title))
+	b.WriteString("\n\n")
+
+	helpText := "[↑/↓] Navigate  [e] Errors only  [Esc] Close"
+	if m.logErrorsOnly {
+		helpText = "[↑/↓] Navigate  [e] Everything  [Esc] Close"
+	}
+	entries := m.log.newest(m.logErrorsOnly)
+	width := max(min(m.windowWidth-30, 100), 40)
+	if len(entries) == 0 {
+		b.WriteString(metadataStyle.Render("Nothing logged yet") + "\n")
+	} else {
+		startIdx := 0
+		if m.logCursor >= logVisible {
+			startIdx = m.logCursor - logVisible + 1
+		}
+		endIdx := min(startIdx+logVisible, len(entries))
+		if startIdx > 0 {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↑ %d newer", startIdx)) + "\n")
+		}
+		for i := startIdx; i < endIdx; i++ {
+			e := entries[i]
+			style := successStyle
+			switch e.level {
+			case logError:
+				style = errorStyle
+			case logEvent:
+				style = metadataStyle
+			}
+			marker := "  "
+			text := truncate(e.text, width)
+			if i == m.logCursor {
+				marker = "> "
+				text = ansi.Wrap(e.text, width, "")
+				text = strings.ReplaceAll(text, "\n", "\n"+strings.Repeat(" ", 19))
+			}
+			b.WriteString(marker + metadataStyle.Render(e.at.Local().Format("Jan 02 15:04:05")) + "  ")
+			b.WriteString(style.Render(text) + "\n")
+		}
+		if endIdx < len(entries) {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↓ %d older", len(entries)-endIdx)) + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(helpText))
+	return dialogBoxStyle.Render(b.String())
+}
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+// Reduced-motion mode is for users with motion sensitivity and for slow SSH
+// links. Nothing on screen animates: the spinner is a still glyph and
+// cursors don't blink. Anything animated added later should check
+// reducedMotion too. Background polling also runs less often, so fewer
+// frames are redrawn and sent
+
+// reducedMotion is whether reduced-motion mode is on. Change it with
+// SetReducedMotion.
+var reducedMotion bool
+
+// reducedMotionPollFactor is how many times longer background polls wait in
+// reduced-motion mode
+const reducedMotionPollFactor = 4
+
+// stillSpinner stands in for the spinner in reduced-motion mode
+var stillSpinner = spinner.Spinner{Frames: []string{"• "}, FPS: time.Second}
+
+// The terminal package's off-screen refresh rates, before reduced motion
+// slows them
+var backgroundPollInterval, blurredPollInterval = terminal.BackgroundPollInterval, terminal.BlurredPollInterval
+
+// SetReducedMotion turns reduced-motion mode on or off
+func SetReducedMotion(on bool) {
+	reducedMotion = on
+	terminal.BackgroundPollInterval = pollInterval(backgroundPollInterval)
+	terminal.BlurredPollInterval = pollInterval(blurredPollInterval)
+}
+
+// pollInterval is how long a background poll waits between runs of every d
+func pollInterval(d time.Duration) time.Duration {
+	if reducedMotion {
+		return d * reducedMotionPollFactor
+	}
+	return d
+}
+
+// isAnimationFrame reports whether msg only advances an animation, which
+// reduced-motion mode drops so the animation never moves
+func isAnimationFrame(msg tea.Msg) bool {
+	switch msg.(type) {
+	case spinner.TickMsg, cursor.BlinkMsg:
+		return true
+	}
+	return false
+}
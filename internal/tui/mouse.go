@@ -0,0 +1,52 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+// mousePassthroughTerminal returns the active terminal if a mouse event over
+// it should go to the program inside rather than select or scroll: the
+// program turned on mouse reporting, the terminal shows the live screen, no
+// selection is being dragged, and the user hasn't switched to selecting
+func (m *Model) mousePassthroughTerminal(msg tea.MouseMsg, termStartX int) terminal.Terminal {
+	if m.mouseSelect || m.selecting || m.activeSession == nil || msg.X < termStartX {
+		return nil
+	}
+	t, ok := m.terminals[m.activeSession.Name]
+	if !ok || !t.IsRunning() || t.IsScrollMode() || !t.WantsMouse() {
+		return nil
+	}
+	return t
+}
+
+// passMouseThrough sends a mouse event to the program in a terminal. A click
+// also focuses the terminal, like clicking it to select does
+func (m *Model) passMouseThrough(t terminal.Terminal, msg tea.MouseMsg, termStartX int) (tea.Model, tea.Cmd) {
+	if msg.Action == tea.MouseActionPress && !tea.MouseEvent(msg).IsWheel() {
+		m.hasSelection = false
+		if m.focus != focusTerminal {
+			m.message = ""
+			m.err = nil
+			m.focus = focusTerminal
+			m.resizeTerminalIfNeeded()
+		}
+	}
+	col, row := m.mouseToTermCoords(msg.X, msg.Y, termStartX)
+	t.SendMouse(msg, col, row)
+	return m, nil
+}
+
+// toggleMouseMode switches the mouse between passing its events to programs
+// that use it and always selecting text
+func (m *Model) toggleMouseMode() (tea.Model, tea.Cmd) {
+	m.mouseSelect = !m.mouseSelect
+	m.err = nil
+	if m.mouseSelect {
+		m.message = "Mouse selects text"
+	} else {
+		m.message = "Mouse goes to apps that use it"
+	}
+	return m, nil
+}
@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/shell"
+)
+
+// notify alerts the user according to the notification settings: a terminal
+// bell and/or a user-provided command that receives the event via environment
+// variables (ATC_NOTIFY_TITLE, ATC_NOTIFY_BODY).
+func (m *Model) notify(title, body string) tea.Cmd {
+	if m.cfg == nil {
+		return nil
+	}
+	settings := m.cfg.Notifications
+	if !settings.Bell && settings.Command == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		if settings.Bell {
+			// Like OSC 52, write to stderr so the bell reaches the host
+			// terminal without disturbing Bubble Tea's renderer.
+			fmt.Fprint(os.Stderr, "\a")
+		}
+		if settings.Command != "" {
+			cmd := shell.Command(settings.Command)
+			cmd.Env = append(os.Environ(),
+				"ATC_NOTIFY_TITLE="+title,
+				"ATC_NOTIFY_BODY="+body,
+			)
+			cmd.Run()
+		}
+		return nil
+	}
+}
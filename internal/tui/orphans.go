@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kevinzwang/air-traffic-control/internal/reconcile"
+)
+
+// orphansFoundMsg reports what the startup scan found and cleaned up
+type orphansFoundMsg struct {
+	count  int      // orphans left for `atc gc`
+	killed []string // tmux sessions of deleted sessions that were ended
+}
+
+// scanOrphans ends the tmux sessions of this project's deleted sessions, then
+// looks for worktrees, sessions and tmux sessions that are out of sync in the
+// background, so the user can be pointed at `atc gc`
+func (m *Model) scanOrphans() tea.Cmd {
+	if m.db == nil || m.cfg == nil {
+		return nil
+	}
+	db, root := m.db, m.cfg.WorktreeRoot
+	var repoPath string
+	if m.service != nil {
+		repoPath = m.service.RepoPath()
+	}
+	return func() tea.Msg {
+		var msg orphansFoundMsg
+		if repoPath != "" {
+			// A session deleted while ATC wasn't running leaves its agent
+			// running with nothing to show or stop it
+			msg.killed, _ = reconcile.CleanTmuxSessions(db, repoPath)
+		}
+		if orphans, err := reconcile.Scan(db, root); err == nil {
+			msg.count = len(orphans)
+		}
+		if msg.count == 0 && len(msg.killed) == 0 {
+			return nil
+		}
+		return msg
+	}
+}
+
+// orphansMessage is the status line shown when the startup scan cleans up
+// tmux sessions or finds orphans
+func orphansMessage(msg orphansFoundMsg) string {
+	var parts []string
+	if n := len(msg.killed); n > 0 {
+		parts = append(parts, fmt.Sprintf("Ended %d leftover tmux %s of deleted sessions (%s)",
+			n, plural(n, "session", "sessions"), strings.Join(msg.killed, ", ")))
+	}
+	if msg.count > 0 {
+		parts = append(parts, fmt.Sprintf("Found %d out-of-sync %s (worktrees, sessions, tmux) — run `atc gc` to review",
+			msg.count, plural(msg.count, "item", "items")))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// plural returns one when n is 1 and many otherwise
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,259 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+)
+
+// Hand-offs chain sessions into a pipeline, e.g. implement → review → docs:
+// a prompt for one session that is queued once another session's verify
+// command passes. A session with hand-offs runs its verify command by itself
+// each time its agent goes back to waiting for input. Like prompt queues,
+// hand-offs live in this ATC instance only.
+
+// handoff is a prompt queued for target once its source session verifies
+type handoff struct {
+	target string
+	prompt string
+}
+
+// handoffEditor is the open hand-off overlay of a source session
+type handoffEditor struct {
+	source  *session.Session
+	targets []*session.Session // the project's other sessions
+	target  int
+	cursor  int // selected existing hand-off
+	input   textinput.Model
+}
+
+// openHandoffs shows the selected session's hand-offs, to add or remove them
+func (m *Model) openHandoffs() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil || m.isProjectHeaderSelected() {
+		return m, nil
+	}
+	if m.service.Config().Verify == "" {
+		m.message = "Hand-offs wait for the verify command: set verify in .atc.yaml first"
+		return m, nil
+	}
+	var targets []*session.Session
+	for _, s := range m.activeSessions() {
+		if s.Name != sess.Name {
+			targets = append(targets, s)
+		}
+	}
+	if len(targets) == 0 {
+		m.message = "Hand-offs need another session to hand off to"
+		return m, nil
+	}
+
+	input := textinput.New()
+	input.Placeholder = "Prompt to send once " + sess.Name + " verifies..."
+	input.CharLimit = 2000
+	input.Width = 60
+	input.Focus()
+	m.handoffEditor = &handoffEditor{source: sess, targets: targets, input: input}
+	m.overlay = overlayHandoffs
+	m.err = nil
+	return m, textinput.Blink
+}
+
+// handleHandoffKeys picks the target session, adds hand-offs and removes them
+func (m *Model) handleHandoffKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	e := m.handoffEditor
+	if e == nil {
+		return m, nil
+	}
+	source := e.source.Name
+	existing := m.handoffs[source]
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		return m.dismissOverlay()
+	case "tab":
+		e.target = (e.target + 1) % len(e.targets)
+		return m, nil
+	case "shift+tab":
+		e.target = (e.target + len(e.targets) - 1) % len(e.targets)
+		return m, nil
+	case "up":
+		if e.cursor > 0 {
+			e.cursor--
+		}
+		return m, nil
+	case "down":
+		if e.cursor < len(existing)-1 {
+			e.cursor++
+		}
+		return m, nil
+	case "ctrl+x":
+		if e.cursor < len(existing) {
+			m.handoffs[source] = append(existing[:e.cursor:e.cursor], existing[e.cursor+1:]...)
+			e.cursor = max(min(e.cursor, len(existing)-2), 0)
+		}
+		return m, nil
+	case "enter":
+		prompt := strings.TrimSpace(e.input.Value())
+		if prompt == "" {
+			return m, nil
+		}
+		if trusted, err := m.service.CommandsTrusted(); err != nil || !trusted {
+			m.err = fmt.Errorf("run %s's verify command once with %s to trust it first", source, m.keys.Verify)
+			return m, nil
+		}
+		if m.handoffs == nil {
+			m.handoffs = make(map[string][]handoff)
+		}
+		m.handoffs[source] = append(existing, handoff{target: e.targets[e.target].Name, prompt: prompt})
+		e.cursor = len(m.handoffs[source]) - 1
+		e.input.SetValue("")
+		m.err = nil
+		return m, nil
+	}
+	var cmd tea.Cmd
+	e.input, cmd = e.input.Update(msg)
+	return m, cmd
+}
+
+// autoVerify runs the verify command of a session with hand-offs, or of a
+// scheduled session, once its agent is waiting again with no queued prompts
+// left and isn't held up by a usage limit, so the next stage starts when it
+// passes
+func (m *Model) autoVerify(name string) tea.Cmd {
+	if (len(m.handoffs[name]) == 0 && !m.scheduledSessions[name]) || m.service == nil {
+		return nil
+	}
+	if m.queueSent[name] || len(m.promptQueues[name]) > 0 || m.rateLimited(name) {
+		return nil
+	}
+	if m.service.Config().Verify == "" {
+		m.finishScheduledRun(name, nil)
+		return nil
+	}
+	var sess *session.Session
+	for _, s := range m.activeSessions() {
+		if s.Name == name {
+			sess = s
+		}
+	}
+	// Only for the current project, whose verify command m.service runs
+	if sess == nil || sess.RepoPath != m.service.RepoPath() {
+		return nil
+	}
+	if run, ok := m.verifyRuns[sess.WorktreePath]; ok && run.running {
+		return nil
+	}
+	if trusted, err := m.service.CommandsTrusted(); err != nil || !trusted {
+		return nil
+	}
+	return m.startVerify(sess)
+}
+
+// runHandoffs queues a verified session's hand-off prompts for their targets
+// and drops them
+func (m *Model) runHandoffs(source string) {
+	pending := m.handoffs[source]
+	if len(pending) == 0 {
+		return
+	}
+	delete(m.handoffs, source)
+	if m.promptQueues == nil {
+		m.promptQueues = make(map[string][]string)
+	}
+	var targets []string
+	for _, h := range pending {
+		m.promptQueues[h.target] = append(m.promptQueues[h.target], h.prompt)
+		m.sendQueuedPrompt(h.target)
+		if !slices.Contains(targets, h.target) {
+			targets = append(targets, h.target)
+		}
+	}
+	m.message = fmt.Sprintf("%s verified; handed off to %s", source, strings.Join(targets, ", "))
+}
+
+// forgetHandoffs drops the hand-offs from and to a deleted or archived
+// session
+func (m *Model) forgetHandoffs(name string) {
+	delete(m.handoffs, name)
+	for source, hs := range m.handoffs {
+		kept := hs[:0:0]
+		for _, h := range hs {
+			if h.target != name {
+				kept = append(kept, h)
+			}
+		}
+		if len(kept) == 0 {
+			delete(m.handoffs, source)
+		} else {
+			m.handoffs[source] = kept
+		}
+	}
+}
+
+// handoffSummary puts where the session hands off to in front of its sidebar
+// summary
+func (m *Model) handoffSummary(name, summary string) string {
+	hs := m.handoffs[name]
+	if len(hs) == 0 {
+		return summary
+	}
+	var targets []string
+	for _, h := range hs {
+		targets = append(targets, h.target)
+	}
+	label := "→ " + strings.Join(targets, ", ")
+	if summary == "" {
+		return label
+	}
+	return label + " · " + summary
+}
+
+// viewHandoffs is the hand-off overlay of a source session
+func (m *Model) viewHandoffs() string {
+	e := m.handoffEditor
+	if e == nil {
+		return ""
+	}
+	source := e.source.Name
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(truncate("Hand-offs: "+source, 70)))
+	b.WriteString("\n")
+	b.WriteString(subtitleStyle.Render(fmt.Sprintf("Queued for another session once %s's verify command passes", source)))
+	b.WriteString("\n\n")
+
+	helpText := "[Tab] Session  [Enter] Add  [↑/↓] Select  [^X] Remove  [Esc] Close"
+	existing := m.handoffs[source]
+	if len(existing) == 0 {
+		b.WriteString(metadataStyle.Render("  No hand-offs") + "\n")
+	} else {
+		itemWidth := len(helpText)
+		for i, h := range existing {
+			label := fmt.Sprintf("→ %s: %s", h.target, truncate(strings.ReplaceAll(h.prompt, "\n", " "), 50))
+			if i == e.cursor {
+				b.WriteString(selectedItemStyle.Width(itemWidth).Render(label) + "\n")
+			} else {
+				b.WriteString(normalItemStyle.Width(itemWidth).Render(label) + "\n")
+			}
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("Send to: ") + selectedItemStyle.Render(e.targets[e.target].Name))
+	b.WriteString("\n")
+	b.WriteString(e.input.View())
+	b.WriteString("\n")
+	if m.err != nil {
+		b.WriteString("\n" + errorStyle.Render(truncate(m.err.Error(), 70)) + "\n")
+	}
+	b.WriteString("\n" + metadataStyle.Render(fmt.Sprintf("%s verifies each time its agent goes back to waiting, or press %s on it", source, m.keys.Verify)) + "\n")
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(helpText))
+	return dialogBoxStyle.Render(b.String())
+}
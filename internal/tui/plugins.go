@@ -0,0 +1,224 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/kevinzwang/air-traffic-control/internal/plugin"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+// Plugins (see the plugin package) are run in the background on the current
+// project's sessions' events, and the actions they print are done here. Tags
+// are kept by this ATC only, like prompt queues
+
+// pluginScanInterval is how often the agents' screens are checked for lines
+// with the configured keywords
+const pluginScanInterval = 2 * time.Second
+
+// pluginScanTickMsg triggers a keyword scan
+type pluginScanTickMsg struct{}
+
+// pluginActionsMsg carries what the plugins asked for about an event of a
+// project
+type pluginActionsMsg struct {
+	repoPath string
+	actions  []plugin.Action
+	errs     []error
+}
+
+// pluginScanTick schedules the next keyword scan
+func pluginScanTick() tea.Cmd {
+	return tea.Tick(pollInterval(pluginScanInterval), func(time.Time) tea.Msg {
+		return pluginScanTickMsg{}
+	})
+}
+
+// pluginEvent is an event of the given kind about the current project's
+// session
+func (m *Model) pluginEvent(kind, name string) plugin.Event {
+	ev := plugin.Event{Event: kind, Time: time.Now(), Session: name}
+	if m.service != nil {
+		ev.Repo = m.service.RepoPath()
+	}
+	for _, s := range m.sessions {
+		if s.Name == name {
+			ev.Branch, ev.Worktree = s.BranchName, s.WorktreePath
+			break
+		}
+	}
+	return ev
+}
+
+// runPlugins runs the plugins on ev in the background. Only the current
+// project's sessions are told about: the main project terminal isn't one,
+// and other tabs' terminals would be sent as this project's
+func (m *Model) runPlugins(ev plugin.Event) tea.Cmd {
+	if m.cfg == nil || m.cfg.Plugins.Dir == "" || !m.hasSession(ev.Session) {
+		return nil
+	}
+	dir, timeout := m.cfg.Plugins.Dir, m.cfg.Plugins.Timeout
+	return func() tea.Msg {
+		actions, errs := plugin.Dispatch(dir, ev, timeout)
+		if len(actions) == 0 && len(errs) == 0 {
+			return nil
+		}
+		return pluginActionsMsg{repoPath: ev.Repo, actions: actions, errs: errs}
+	}
+}
+
+// pluginStateChanged tells the plugins a session's agent changed state, and
+// that it's idle if it just finished working
+func (m *Model) pluginStateChanged(name string, state terminal.AgentState) tea.Cmd {
+	ev := m.pluginEvent(plugin.StateChanged, name)
+	ev.State = state.String()
+	cmds := []tea.Cmd{m.runPlugins(ev)}
+	switch state {
+	case terminal.StateWorking:
+		if m.pluginWorking == nil {
+			m.pluginWorking = make(map[string]bool)
+		}
+		m.pluginWorking[name] = true
+	case terminal.StateWaiting:
+		// An agent found waiting when its terminal starts hasn't finished
+		// anything
+		if m.pluginWorking[name] {
+			delete(m.pluginWorking, name)
+			cmds = append(cmds, m.runPlugins(m.pluginEvent(plugin.AgentIdle, name)))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// pluginExited tells the plugins a session's agent exited
+func (m *Model) pluginExited(name string) tea.Cmd {
+	delete(m.pluginWorking, name)
+	ev := m.pluginEvent(plugin.StateChanged, name)
+	ev.State = "exited"
+	return m.runPlugins(ev)
+}
+
+// scanPluginKeywords tells the plugins about the lines with a keyword that
+// appeared on the running agents' screens since the last scan
+func (m *Model) scanPluginKeywords() tea.Cmd {
+	if m.cfg == nil || len(m.cfg.Plugins.Keywords) == 0 {
+		return nil
+	}
+	if m.pluginMatches == nil {
+		m.pluginMatches = make(map[string]map[string]bool)
+	}
+	var cmds []tea.Cmd
+	for _, s := range m.sessions {
+		t, ok := m.terminals[s.Name]
+		if !ok || !t.IsRunning() || t.Window() != "" {
+			continue
+		}
+		matched := make(map[string]bool)
+		for _, line := range strings.Split(ansi.Strip(t.Render()), "\n") {
+			line = strings.TrimSpace(line)
+			for _, keyword := range m.cfg.Plugins.Keywords {
+				if keyword == "" || !strings.Contains(line, keyword) {
+					continue
+				}
+				matched[line] = true
+				// Still on screen from the last scan
+				if !m.pluginMatches[s.Name][line] {
+					ev := m.pluginEvent(plugin.OutputMatched, s.Name)
+					ev.Keyword, ev.Line = keyword, line
+					cmds = append(cmds, m.runPlugins(ev))
+				}
+				break
+			}
+		}
+		m.pluginMatches[s.Name] = matched
+	}
+	return tea.Batch(cmds...)
+}
+
+// handlePluginActions logs the plugins' failures and does what they asked,
+// if it's about the project still open
+func (m *Model) handlePluginActions(msg pluginActionsMsg) tea.Cmd {
+	for _, err := range msg.errs {
+		m.addLog(logError, err.Error())
+	}
+	if m.service == nil || msg.repoPath != m.service.RepoPath() {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, a := range msg.actions {
+		if a.Action != plugin.Notify && !m.hasSession(a.Session) {
+			m.addLog(logError, fmt.Sprintf("plugin %s: no session %q", a.Plugin, a.Session))
+			continue
+		}
+		switch a.Action {
+		case plugin.Notify:
+			text := a.Title
+			if a.Body != "" {
+				if text != "" {
+					text += ": "
+				}
+				text += a.Body
+			}
+			m.message = text
+			cmds = append(cmds, m.notify(a.Title, a.Body))
+		case plugin.Enqueue:
+			if strings.TrimSpace(a.Prompt) == "" {
+				continue
+			}
+			if m.promptQueues == nil {
+				m.promptQueues = make(map[string][]string)
+			}
+			m.promptQueues[a.Session] = append(m.promptQueues[a.Session], a.Prompt)
+			m.addLog(logInfo, fmt.Sprintf("plugin %s queued a prompt for %s", a.Plugin, a.Session))
+			m.sendQueuedPrompt(a.Session)
+		case plugin.Tag:
+			if a.Tag == "" || slices.Contains(m.sessionTags[a.Session], a.Tag) {
+				continue
+			}
+			if m.sessionTags == nil {
+				m.sessionTags = make(map[string][]string)
+			}
+			m.sessionTags[a.Session] = append(m.sessionTags[a.Session], a.Tag)
+		case plugin.Untag:
+			tags := slices.DeleteFunc(m.sessionTags[a.Session], func(t string) bool { return t == a.Tag })
+			if len(tags) == 0 {
+				delete(m.sessionTags, a.Session)
+			} else {
+				m.sessionTags[a.Session] = tags
+			}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// hasSession reports whether the current project has a session by that name
+func (m *Model) hasSession(name string) bool {
+	return slices.ContainsFunc(m.sessions, func(s *session.Session) bool { return s.Name == name })
+}
+
+// forgetPluginState drops what plugins and their events left about a session
+// that is gone
+func (m *Model) forgetPluginState(name string) {
+	delete(m.sessionTags, name)
+	delete(m.pluginWorking, name)
+	delete(m.pluginMatches, name)
+}
+
+// tagSummary puts the session's plugin tags in front of its sidebar summary
+func (m *Model) tagSummary(name, summary string) string {
+	tags := m.sessionTags[name]
+	if len(tags) == 0 {
+		return summary
+	}
+	label := "#" + strings.Join(tags, " #")
+	if summary == "" {
+		return label
+	}
+	return label + " · " + summary
+}
@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+)
+
+// devServerDialTimeout bounds each check for a listening port
+const devServerDialTimeout = 100 * time.Millisecond
+
+// devServersSampledMsg carries the first listening port of each session,
+// keyed by worktree path
+type devServersSampledMsg struct {
+	listening map[string]int
+}
+
+// sessionPorts returns the ports handed out to a session, reading (or
+// allocating) them the first time. It fills m.ports, so it's only called from
+// Update, never from a tea.Cmd: what a Cmd needs is read before it's returned.
+// The main project terminal isn't a stored session and gets none
+func (m *Model) sessionPorts(sess *session.Session) []int {
+	if ports, ok := m.ports[sess.ID]; ok {
+		return ports
+	}
+	if m.service == nil || sess.ID == "" {
+		return nil
+	}
+	ports, err := m.service.Ports(sess)
+	if err != nil {
+		return nil
+	}
+	if m.ports == nil {
+		m.ports = make(map[string][]int)
+	}
+	m.ports[sess.ID] = ports
+	return ports
+}
+
+// portEnv returns the environment that gives a session's commands its ports
+func (m *Model) portEnv(sess *session.Session) []string {
+	return session.PortEnv(m.sessionPorts(sess))
+}
+
+// envNames returns the names of KEY=value environment entries
+func envNames(env []string) []string {
+	names := make([]string, len(env))
+	for i, kv := range env {
+		names[i], _, _ = strings.Cut(kv, "=")
+	}
+	return names
+}
+
+// sampleDevServers checks in the background which of their ports the
+// current project's running sessions are listening on
+func (m *Model) sampleDevServers() tea.Cmd {
+	ports := make(map[string][]int)
+	for _, s := range m.sessions {
+		if _, ok := m.terminals[s.Name]; ok {
+			ports[s.WorktreePath] = m.sessionPorts(s)
+		}
+	}
+	if len(ports) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		listening := make(map[string]int)
+		for wt, list := range ports {
+			for _, port := range list {
+				conn, err := net.DialTimeout("tcp", net.JoinHostPort("localhost", strconv.Itoa(port)), devServerDialTimeout)
+				if err == nil {
+					conn.Close()
+					listening[wt] = port
+					break
+				}
+			}
+		}
+		return devServersSampledMsg{listening}
+	}
+}
+
+// devServerSummary adds the session's dev server, if one is listening, to
+// its sidebar summary
+func (m *Model) devServerSummary(s *session.Session, summary string) string {
+	port := m.devServers[s.WorktreePath]
+	if port == 0 {
+		return summary
+	}
+	if summary == "" {
+		return fmt.Sprintf("dev server on :%d", port)
+	}
+	return fmt.Sprintf(":%d · %s", port, summary)
+}
+
+// handleOpenBrowser opens the selected session's dev server in the browser
+func (m *Model) handleOpenBrowser() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil {
+		return m, nil
+	}
+	port := m.devServers[sess.WorktreePath]
+	if port == 0 {
+		if ports := m.sessionPorts(sess); len(ports) > 0 {
+			m.message = fmt.Sprintf("No dev server listening on :%d-%d", ports[0], ports[len(ports)-1])
+		}
+		return m, nil
+	}
+	url := fmt.Sprintf("http://localhost:%d", port)
+	return m, func() tea.Msg {
+		if out, err := browserCommand(url).CombinedOutput(); err != nil {
+			return errMsg{fmt.Errorf("failed to open %s: %w: %s", url, err, strings.TrimSpace(string(out)))}
+		}
+		return nil
+	}
+}
+
+// browserCommand returns the command that opens url in the default browser
+func browserCommand(url string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url)
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		return exec.Command("xdg-open", url)
+	}
+}
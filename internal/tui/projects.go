@@ -0,0 +1,236 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// startProjectPathInput switches the project picker's input from filtering
+// known projects to entering the path of a new one
+func (m *Model) startProjectPathInput() {
+	m.projectPathMode = true
+	m.err = nil
+	m.projectInput = textinput.New()
+	m.projectInput.Placeholder = "Path to a git repository..."
+	m.projectInput.Focus()
+	m.projectInput.CharLimit = 500
+	m.projectInput.Width = 50
+}
+
+// handleProjectPathKeys handles keys while a new project's path is entered
+func (m *Model) handleProjectPathKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.projectPathMode = false
+		m.err = nil
+		m.initProjectInput()
+		m.filterProjects()
+		return m, nil
+	case "enter":
+		path := strings.TrimSpace(m.projectInput.Value())
+		if path == "" {
+			return m, nil
+		}
+		m.err = nil
+		return m, m.addProject(path)
+	}
+	var cmd tea.Cmd
+	m.projectInput, cmd = m.projectInput.Update(msg)
+	return m, cmd
+}
+
+// addProject records the git repository at path (or containing it) as a
+// project and switches to it
+func (m *Model) addProject(path string) tea.Cmd {
+	return func() tea.Msg {
+		if path == "~" || strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+			}
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return errMsg{err}
+		}
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			return errMsg{fmt.Errorf("%s is not a directory", abs)}
+		}
+		repoPath, err := worktree.RepoRoot(abs)
+		if err != nil {
+			return errMsg{fmt.Errorf("%s is not in a git repository", abs)}
+		}
+		project := &database.Project{RepoPath: repoPath, RepoName: filepath.Base(repoPath)}
+		if err := m.db.AddProject(project.RepoPath, project.RepoName); err != nil {
+			return errMsg{err}
+		}
+		return m.switchProject(project)()
+	}
+}
+
+// What happens to a forgotten project's sessions
+const (
+	forgetKeepSessions = iota
+	forgetArchiveSessions
+	forgetDeleteSessions
+)
+
+// projectForgottenMsg reports that a project was removed from the picker
+type projectForgottenMsg struct {
+	name string
+}
+
+// projectOpen reports whether the project at repoPath is open in a tab
+func (m *Model) projectOpen(repoPath string) bool {
+	if m.service != nil && m.service.RepoPath() == repoPath {
+		return true
+	}
+	for _, tab := range m.tabs {
+		if tab != nil && tab.service != nil && tab.service.RepoPath() == repoPath {
+			return true
+		}
+	}
+	return false
+}
+
+// confirmForgetProject asks what to do with the selected project's sessions
+// before removing it from the picker
+func (m *Model) confirmForgetProject() {
+	if m.projectCursor >= len(m.filteredProjects) {
+		return
+	}
+	p := m.filteredProjects[m.projectCursor]
+	if m.projectOpen(p.RepoPath) {
+		m.err = fmt.Errorf("close %s's tab before removing it", p.RepoName)
+		return
+	}
+	m.err = nil
+	m.forgettingProject = p
+}
+
+// handleForgetProjectKeys handles the forget confirmation in the picker
+func (m *Model) handleForgetProjectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.forgettingProject
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "f", "F", "enter":
+		m.forgettingProject = nil
+		return m, m.forgetProject(p, forgetKeepSessions)
+	case "a", "A":
+		m.forgettingProject = nil
+		return m, m.forgetProject(p, forgetArchiveSessions)
+	case "d", "D":
+		m.forgettingProject = nil
+		return m, m.forgetProject(p, forgetDeleteSessions)
+	case "n", "N", "esc":
+		m.forgettingProject = nil
+	}
+	return m, nil
+}
+
+// forgetProject removes a project from the picker, first archiving or
+// deleting its sessions (which ends their agents) if asked to
+func (m *Model) forgetProject(p *database.Project, sessions int) tea.Cmd {
+	return func() tea.Msg {
+		if sessions != forgetKeepSessions {
+			svc, err := session.NewService(m.db, p.RepoPath, m.cfg)
+			if err != nil {
+				return errMsg{err}
+			}
+			list, err := svc.ListSessions("")
+			if err != nil {
+				return errMsg{err}
+			}
+			terminal.KillServer(terminal.SocketName(p.RepoPath))
+			for _, s := range list {
+				if s.RepoPath != p.RepoPath {
+					continue
+				}
+				if sessions == forgetDeleteSessions {
+					err = svc.DeleteSession(s.Name)
+				} else if s.Status != "archived" {
+					err = svc.ArchiveSession(s.Name)
+				}
+				if err != nil {
+					return errMsg{fmt.Errorf("%s: %w", s.Name, err)}
+				}
+			}
+		}
+		if err := m.db.RemoveProject(p.RepoPath); err != nil {
+			return errMsg{err}
+		}
+		return projectForgottenMsg{p.RepoName}
+	}
+}
+
+// viewForgetProject is the picker's forget confirmation
+func (m *Model) viewForgetProject() string {
+	p := m.forgettingProject
+	var b strings.Builder
+	b.WriteString(dialogTitleStyle.Render("Remove Project"))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render(fmt.Sprintf("Remove %s from the project list?", p.RepoName)))
+	b.WriteString("\n")
+	b.WriteString(metadataStyle.Render(truncatePath(p.RepoPath, 60)))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render("[F] Remove, keep its sessions"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("[A] Remove and archive its sessions"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("[D] Remove and delete its sessions and worktrees"))
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render("[N] Cancel"))
+	b.WriteString("\n\n")
+	b.WriteString(metadataStyle.Render("Archiving or deleting ends the project's running agents"))
+	return dialogBoxStyle.Render(b.String())
+}
+
+// projectPinnedMsg carries the project list reordered after a pin change
+type projectPinnedMsg struct {
+	projects []*database.Project
+	repoPath string
+}
+
+// toggleProjectPin pins or unpins a project in the picker
+func (m *Model) toggleProjectPin(p *database.Project) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.db.SetProjectPinned(p.RepoPath, !p.Pinned); err != nil {
+			return errMsg{err}
+		}
+		projects, err := m.db.ListProjects()
+		if err != nil {
+			return errMsg{err}
+		}
+		return projectPinnedMsg{projects: projects, repoPath: p.RepoPath}
+	}
+}
+
+// selectProject moves the picker's cursor to the project at repoPath,
+// scrolling it into view
+func (m *Model) selectProject(repoPath string) {
+	const maxVisible = 10
+	for i, p := range m.filteredProjects {
+		if p.RepoPath != repoPath {
+			continue
+		}
+		m.projectCursor = i
+		if i < m.projectScrollOffset {
+			m.projectScrollOffset = i
+		} else if i >= m.projectScrollOffset+maxVisible {
+			m.projectScrollOffset = i - maxVisible + 1
+		}
+		return
+	}
+}
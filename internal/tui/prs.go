@@ -0,0 +1,262 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/github"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+)
+
+// prStatusInterval is how often sessions' pull request and CI status is
+// refreshed
+const prStatusInterval = time.Minute
+
+// pullRequestsLoadedMsg carries the project's open pull requests
+type pullRequestsLoadedMsg struct {
+	prs []github.PullRequest
+}
+
+// pullRequestFetchedMsg reports that a pull request's head was fetched and
+// its review session can be created from ref, compared against base
+type pullRequestFetchedMsg struct {
+	ref  string
+	base string
+}
+
+// openPullRequests shows the project's open pull requests to create a review
+// session from
+func (m *Model) openPullRequests() tea.Cmd {
+	m.overlay = overlaySelectPullRequest
+	m.pullRequests = nil
+	m.pullRequestCursor = 0
+	m.pullRequestsLoading = true
+	m.err = nil
+	repoPath := m.service.RepoPath()
+	return func() tea.Msg {
+		prs, err := github.ListPullRequests(repoPath)
+		if err != nil {
+			return errMsg{err}
+		}
+		return pullRequestsLoadedMsg{prs}
+	}
+}
+
+// handlePullRequestKeys handles the pull request list
+func (m *Model) handlePullRequestKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		return m.dismissOverlay()
+	case "up", "k":
+		if m.pullRequestCursor > 0 {
+			m.pullRequestCursor--
+		}
+	case "down", "j":
+		if m.pullRequestCursor < len(m.pullRequests)-1 {
+			m.pullRequestCursor++
+		}
+	case "enter":
+		if m.pullRequestCursor >= len(m.pullRequests) {
+			return m, nil
+		}
+		return m, m.createPullRequestSession(m.pullRequests[m.pullRequestCursor])
+	}
+	return m, nil
+}
+
+// createPullRequestSession fetches a pull request's head and creates a
+// session on a new branch at it, named pr-<number>. The review prompt is
+// typed into the agent once it is ready for input, without sending it
+func (m *Model) createPullRequestSession(pr github.PullRequest) tea.Cmd {
+	name := fmt.Sprintf("pr-%d", pr.Number)
+	m.pendingSessionName = name
+	m.pendingPrompts[name] = reviewPrompt(pr)
+	m.fetching = true
+	m.overlay = overlayCreating
+	repoPath := m.service.RepoPath()
+	return func() tea.Msg {
+		ref, err := github.FetchPullRequest(repoPath, pr.Number)
+		if err != nil {
+			return errMsg{err}
+		}
+		return pullRequestFetchedMsg{ref, "origin/" + pr.BaseRefName}
+	}
+}
+
+// reviewPrompt is the prompt pre-filled in a pull request's review session
+func reviewPrompt(pr github.PullRequest) string {
+	return fmt.Sprintf("Review pull request #%d, %q by %s (%s). Its changes are the commits on this branch since origin/%s: look at `git diff origin/%s...HEAD`, and point out bugs, risky changes and missing tests.",
+		pr.Number, pr.Title, pr.Author.Login, pr.URL, pr.BaseRefName, pr.BaseRefName)
+}
+
+// pastePendingPrompt types a session's pre-filled prompt into its agent once
+// the agent first waits for input
+func (m *Model) pastePendingPrompt(name string) {
+	prompt, ok := m.pendingPrompts[name]
+	if !ok {
+		return
+	}
+	t, ok := m.terminals[name]
+	if !ok || !t.IsRunning() {
+		return
+	}
+	t.Paste(prompt)
+	delete(m.pendingPrompts, name)
+}
+
+// viewPullRequests is the pull request list
+func (m *Model) viewPullRequests() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Review Pull Request"))
+	b.WriteString("\n\n")
+
+	helpText := "[↑/↓] Navigate  [Enter] Create session  [Esc] Back"
+	switch {
+	case m.err != nil:
+		b.WriteString(errorStyle.Render(truncate(m.err.Error(), 70)))
+		b.WriteString("\n")
+	case m.pullRequestsLoading:
+		b.WriteString(m.spinner.View() + " Loading pull requests...")
+		b.WriteString("\n")
+	case len(m.pullRequests) == 0:
+		b.WriteString(metadataStyle.Render("  No open pull requests") + "\n")
+	default:
+		maxVisible := 10
+		startIdx := 0
+		if m.pullRequestCursor >= maxVisible {
+			startIdx = m.pullRequestCursor - maxVisible + 1
+		}
+		endIdx := min(startIdx+maxVisible, len(m.pullRequests))
+
+		labels := make([]string, len(m.pullRequests))
+		itemWidth := len(helpText)
+		for i, pr := range m.pullRequests {
+			label := fmt.Sprintf("#%d %s", pr.Number, truncate(pr.Title, 50))
+			if pr.IsDraft {
+				label += " (draft)"
+			}
+			label += "  " + pr.Author.Login
+			labels[i] = label
+			if i >= startIdx && i < endIdx && len(label) > itemWidth {
+				itemWidth = len(label)
+			}
+		}
+
+		if startIdx > 0 {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↑ %d more", startIdx)) + "\n")
+		}
+		for i := startIdx; i < endIdx; i++ {
+			if i == m.pullRequestCursor {
+				b.WriteString(selectedItemStyle.Width(itemWidth).Render(labels[i]) + "\n")
+			} else {
+				b.WriteString(normalItemStyle.Width(itemWidth).Render(labels[i]) + "\n")
+			}
+		}
+		if endIdx < len(m.pullRequests) {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↓ %d more", len(m.pullRequests)-endIdx)) + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(helpText))
+	return dialogBoxStyle.Render(b.String())
+}
+
+// prStatusTickMsg triggers a pull request status refresh
+type prStatusTickMsg struct{}
+
+// prStatusesMsg carries the pull request status of each polled session's
+// worktree that has a pull request
+type prStatusesMsg struct {
+	polled   []string
+	statuses map[string]github.PullRequestStatus
+}
+
+// prStatusTick schedules the next pull request status refresh
+func prStatusTick(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return prStatusTickMsg{}
+	})
+}
+
+// loadPRStatuses looks up the current project's sessions' pull requests in
+// the background. Failures (no gh, no GitHub remote) just leave them out
+func (m *Model) loadPRStatuses() tea.Cmd {
+	if m.service == nil {
+		return nil
+	}
+	branches := make(map[string]string)
+	for _, s := range m.activeSessions() {
+		branches[s.WorktreePath] = s.BranchName
+	}
+	if len(branches) == 0 {
+		return nil
+	}
+	repoPath := m.service.RepoPath()
+	return func() tea.Msg {
+		byBranch, err := github.PullRequestStatuses(repoPath)
+		if err != nil {
+			return nil
+		}
+		msg := prStatusesMsg{statuses: make(map[string]github.PullRequestStatus)}
+		for path, branch := range branches {
+			msg.polled = append(msg.polled, path)
+			if status, ok := byBranch[branch]; ok {
+				msg.statuses[path] = status
+			}
+		}
+		return msg
+	}
+}
+
+// updatePRStatuses records polled sessions' pull request status, keeping
+// other projects' until they are polled again
+func (m *Model) updatePRStatuses(msg prStatusesMsg) {
+	if m.prStatuses == nil {
+		m.prStatuses = make(map[string]github.PullRequestStatus)
+	}
+	for _, path := range msg.polled {
+		if status, ok := msg.statuses[path]; ok {
+			m.prStatuses[path] = status
+		} else {
+			delete(m.prStatuses, path)
+		}
+	}
+}
+
+// prSummary adds the session's pull request, if it has one, to its sidebar
+// summary: number, state and checks, like "#12 ✓" or "#12 merged"
+func (m *Model) prSummary(s *session.Session, summary string) string {
+	status, ok := m.prStatuses[s.WorktreePath]
+	if !ok {
+		return summary
+	}
+	label := fmt.Sprintf("#%d", status.Number)
+	switch {
+	case status.State == "MERGED":
+		label += " merged"
+	case status.State == "CLOSED":
+		label += " closed"
+	case status.IsDraft:
+		label += " draft"
+	}
+	if status.State == "OPEN" {
+		switch status.Checks {
+		case github.ChecksPassing:
+			label += " ✓"
+		case github.ChecksFailing:
+			label += " ✗"
+		case github.ChecksPending:
+			label += " …"
+		}
+	}
+	if summary == "" {
+		return label
+	}
+	return label + " · " + summary
+}
@@ -0,0 +1,178 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+// Each session can have a queue of prompts. Whenever its agent goes back to
+// waiting for input, the next one is typed in and sent, so the agent works
+// through them one by one. Queues live in this ATC instance only: they are
+// lost on quit and other instances don't send them.
+
+// openPromptQueue shows the selected session's prompt queue
+func (m *Model) openPromptQueue() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil || m.isProjectHeaderSelected() {
+		return m, nil
+	}
+	m.queueSession = sess.Name
+	m.queueCursor = max(len(m.promptQueues[sess.Name])-1, 0)
+	m.queueInput = textinput.New()
+	m.queueInput.Placeholder = "Prompt to queue..."
+	m.queueInput.CharLimit = 2000
+	m.queueInput.Width = 60
+	m.queueInput.Focus()
+	m.overlay = overlayPromptQueue
+	m.err = nil
+	return m, textinput.Blink
+}
+
+// handlePromptQueueKeys adds prompts to the open queue and removes them
+func (m *Model) handlePromptQueueKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	name := m.queueSession
+	queue := m.promptQueues[name]
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		return m.dismissOverlay()
+	case "up":
+		if m.queueCursor > 0 {
+			m.queueCursor--
+		}
+		return m, nil
+	case "down":
+		if m.queueCursor < len(queue)-1 {
+			m.queueCursor++
+		}
+		return m, nil
+	case "ctrl+x":
+		if m.queueCursor < len(queue) {
+			m.promptQueues[name] = append(queue[:m.queueCursor:m.queueCursor], queue[m.queueCursor+1:]...)
+			m.queueCursor = max(min(m.queueCursor, len(queue)-2), 0)
+		}
+		return m, nil
+	case "enter":
+		prompt := strings.TrimSpace(m.queueInput.Value())
+		if prompt == "" {
+			return m, nil
+		}
+		if m.promptQueues == nil {
+			m.promptQueues = make(map[string][]string)
+		}
+		m.promptQueues[name] = append(queue, prompt)
+		m.queueCursor = len(m.promptQueues[name]) - 1
+		m.queueInput.SetValue("")
+		m.sendQueuedPrompt(name)
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.queueInput, cmd = m.queueInput.Update(msg)
+	return m, cmd
+}
+
+// sendQueuedPrompt sends the session's next queued prompt if its agent is
+// waiting for input and has no pre-filled prompt left to edit, unless the
+// queue is paused for a usage limit
+func (m *Model) sendQueuedPrompt(name string) {
+	queue := m.promptQueues[name]
+	if len(queue) == 0 || m.queuePaused() {
+		return
+	}
+	if _, ok := m.pendingPrompts[name]; ok || m.queueSent[name] {
+		return
+	}
+	t, ok := m.terminals[name]
+	if !ok || !t.IsRunning() || t.State() != terminal.StateWaiting {
+		return
+	}
+	t.Paste(queue[0])
+	t.SendKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.queueSent == nil {
+		m.queueSent = make(map[string]bool)
+	}
+	// Its state only turns to working once the agent reacts, so hold back the
+	// next prompt until then
+	m.queueSent[name] = true
+	m.promptQueues[name] = queue[1:]
+	if len(queue) == 1 {
+		delete(m.promptQueues, name)
+	}
+	if m.queueSession == name {
+		m.queueCursor = max(m.queueCursor-1, 0)
+	}
+	m.message = fmt.Sprintf("Sent %s the next queued prompt", name)
+}
+
+// forgetPromptQueue drops a deleted or archived session's queue
+func (m *Model) forgetPromptQueue(name string) {
+	delete(m.promptQueues, name)
+	delete(m.queueSent, name)
+}
+
+// queueSummary puts how many prompts are queued for the session in front of
+// its sidebar summary
+func (m *Model) queueSummary(name, summary string) string {
+	n := len(m.promptQueues[name])
+	if n == 0 {
+		return summary
+	}
+	label := fmt.Sprintf("%d queued", n)
+	if summary == "" {
+		return label
+	}
+	return label + " · " + summary
+}
+
+// viewPromptQueue is the open session's prompt queue
+func (m *Model) viewPromptQueue() string {
+	queue := m.promptQueues[m.queueSession]
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(truncate("Prompt Queue: "+m.queueSession, 70)))
+	b.WriteString("\n")
+	subtitle := "Sent one at a time, each time the agent waits for input"
+	if m.queuePaused() {
+		subtitle = "Paused until the usage limit resets"
+	}
+	b.WriteString(subtitleStyle.Render(subtitle))
+	b.WriteString("\n\n")
+
+	helpText := "[Enter] Add  [↑/↓] Select  [^X] Remove  [Esc] Close"
+	if len(queue) == 0 {
+		b.WriteString(metadataStyle.Render("  Nothing queued") + "\n")
+	} else {
+		maxVisible := 10
+		startIdx := 0
+		if m.queueCursor >= maxVisible {
+			startIdx = m.queueCursor - maxVisible + 1
+		}
+		endIdx := min(startIdx+maxVisible, len(queue))
+		itemWidth := len(helpText)
+		if startIdx > 0 {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↑ %d more", startIdx)) + "\n")
+		}
+		for i := startIdx; i < endIdx; i++ {
+			label := fmt.Sprintf("%d. %s", i+1, truncate(strings.ReplaceAll(queue[i], "\n", " "), 60))
+			if i == m.queueCursor {
+				b.WriteString(selectedItemStyle.Width(itemWidth).Render(label) + "\n")
+			} else {
+				b.WriteString(normalItemStyle.Width(itemWidth).Render(label) + "\n")
+			}
+		}
+		if endIdx < len(queue) {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↓ %d more", len(queue)-endIdx)) + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.queueInput.View())
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render(helpText))
+	return dialogBoxStyle.Render(b.String())
+}
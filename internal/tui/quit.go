@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+// allTerminals returns the terminals of every open project
+func (m *Model) allTerminals() []terminal.Terminal {
+	var all []terminal.Terminal
+	for _, t := range m.terminals {
+		all = append(all, t)
+	}
+	for i, tab := range m.tabs {
+		if i != m.tabIndex && tab != nil {
+			for _, t := range tab.terminals {
+				all = append(all, t)
+			}
+		}
+	}
+	return all
+}
+
+// runningAgents counts the agents still running in every open project
+func (m *Model) runningAgents() int {
+	n := 0
+	for _, t := range m.allTerminals() {
+		if t.IsRunning() {
+			n++
+		}
+	}
+	return n
+}
+
+// handleQuit quits as on_quit says, asking first when it's "ask" and agents
+// are running
+func (m *Model) handleQuit() (tea.Model, tea.Cmd) {
+	switch m.cfg.OnQuit {
+	case "detach":
+		return m.quit(false)
+	case "kill":
+		return m.quit(true)
+	}
+	if m.runningAgents() == 0 {
+		return m.quit(false)
+	}
+	m.overlay = overlayQuitConfirm
+	return m, nil
+}
+
+// quit exits ATC, ending every open project's sessions if kill is set and
+// otherwise leaving them running in tmux. Where the user was is saved for the
+// next launch
+func (m *Model) quit(kill bool) (tea.Model, tea.Cmd) {
+	m.saveUIState()
+	for _, t := range m.allTerminals() {
+		if kill {
+			t.Close()
+		} else {
+			// Stop polling but leave the tmux session running
+			t.Detach()
+		}
+	}
+	return m, tea.Quit
+}
+
+func (m *Model) handleQuitConfirmKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "d", "D", "enter", m.keys.Quit, "ctrl+c":
+		return m.quit(false)
+	case "k", "K":
+		return m.quit(true)
+	case "n", "N", "esc":
+		m.overlay = overlayNone
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m *Model) viewQuitConfirm() string {
+	n := m.runningAgents()
+
+	var b strings.Builder
+	b.WriteString(dialogTitleStyle.Render("Quit ATC"))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render(fmt.Sprintf("%d %s still running.", n, plural(n, "agent is", "agents are"))))
+	b.WriteString("\n\n")
+	if m.backend == terminal.PTY {
+		b.WriteString(dialogTextStyle.Render("Without tmux, agents stop when ATC exits."))
+		b.WriteString("\n\n")
+		b.WriteString(dialogTextStyle.Render("[D] Quit    [N] Cancel"))
+	} else {
+		b.WriteString(dialogTextStyle.Render("[D] Detach, leave them running in tmux"))
+		b.WriteString("\n")
+		b.WriteString(dialogTextStyle.Render("[K] Kill all sessions"))
+		b.WriteString("\n")
+		b.WriteString(dialogTextStyle.Render("[N] Cancel"))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(metadataStyle.Render("Set on_quit in the config to skip this"))
+	return dialogBoxStyle.Render(b.String())
+}
@@ -0,0 +1,242 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/kevinzwang/air-traffic-control/internal/events"
+	"github.com/kevinzwang/air-traffic-control/internal/usage"
+)
+
+// Claude's usage limits are per account, so an agent running into one holds
+// up all of them: the sidebar shows a banner until the limit resets, and
+// queued prompts wait until then (pause_queue_on_limit). Limits are spotted
+// on an agent's screen as soon as it stops, and in its transcript, which
+// also tells when the agent gets a reply through again.
+
+// limitScreenLines is how many of the last non-empty screen lines are checked
+// for a limit message when an agent stops
+const limitScreenLines = 12
+
+// limitScreenGrace is how long a limit seen on screen holds without showing
+// up in the agent's transcript
+const limitScreenGrace = time.Minute
+
+// rateLimit is a usage limit a session's agent ran into
+type rateLimit struct {
+	usage.Limit
+	fromScreen bool // seen on screen, not (yet) in the transcript
+}
+
+// rateLimitsMsg carries the limits found in the checked sessions' transcripts
+type rateLimitsMsg struct {
+	checked []string
+	limits  map[string]usage.Limit
+}
+
+// rateLimitResetMsg fires when a limit's reset time has come
+type rateLimitResetMsg struct{}
+
+// checkRateLimit looks for a limit message on the screen of an agent that
+// just stopped, and has its transcript checked in the background
+func (m *Model) checkRateLimit(name string) tea.Cmd {
+	var cmd tea.Cmd
+	if t, ok := m.terminals[name]; ok && t.Window() == "" {
+		text := lastScreenLines(ansi.Strip(t.Render()), limitScreenLines)
+		// A message still on screen from before doesn't count again
+		if l, ok := usage.ParseLimit(text, time.Now()); ok && m.screenLimits[name] != text {
+			if m.screenLimits == nil {
+				m.screenLimits = make(map[string]string)
+			}
+			m.screenLimits[name] = text
+			cmd = m.recordRateLimit(name, rateLimit{Limit: l, fromScreen: true})
+		}
+	}
+	for _, s := range m.activeSessions() {
+		if s.Name == name {
+			return tea.Batch(cmd, loadRateLimits(map[string]string{name: s.WorktreePath}))
+		}
+	}
+	return cmd
+}
+
+// lastScreenLines returns the last n non-empty lines of a screen
+func lastScreenLines(screen string, n int) string {
+	var lines []string
+	all := strings.Split(screen, "\n")
+	for i := len(all) - 1; i >= 0 && len(lines) < n; i-- {
+		if line := strings.TrimSpace(all[i]); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	slices.Reverse(lines)
+	return strings.Join(lines, "\n")
+}
+
+// loadRateLimits checks the active sessions' transcripts for limits
+func (m *Model) loadRateLimits() tea.Cmd {
+	worktrees := make(map[string]string)
+	for _, s := range m.activeSessions() {
+		worktrees[s.Name] = s.WorktreePath
+	}
+	if len(worktrees) == 0 {
+		return nil
+	}
+	return loadRateLimits(worktrees)
+}
+
+// loadRateLimits checks the transcripts of the given sessions, by name, in
+// the background
+func loadRateLimits(worktrees map[string]string) tea.Cmd {
+	return func() tea.Msg {
+		msg := rateLimitsMsg{limits: make(map[string]usage.Limit)}
+		for name, path := range worktrees {
+			msg.checked = append(msg.checked, name)
+			if l, ok := usage.LimitForWorktree(path); ok {
+				msg.limits[name] = l
+			}
+		}
+		return msg
+	}
+}
+
+// updateRateLimits records the limits found in transcripts, and drops the
+// ones whose agent has had a reply since
+func (m *Model) updateRateLimits(msg rateLimitsMsg) tea.Cmd {
+	now := time.Now()
+	limited := len(m.rateLimits) > 0
+	var cmds []tea.Cmd
+	for _, name := range msg.checked {
+		if l, ok := msg.limits[name]; ok && l.Active(now) {
+			cmds = append(cmds, m.recordRateLimit(name, rateLimit{Limit: l}))
+			continue
+		}
+		if prev, ok := m.rateLimits[name]; ok && (!prev.fromScreen || now.Sub(prev.At) > limitScreenGrace) {
+			delete(m.rateLimits, name)
+		}
+	}
+	m.expireRateLimits(now, limited)
+	return tea.Batch(cmds...)
+}
+
+// recordRateLimit notes a limit a session's agent ran into, with a banner
+// and a notification the first time, and a wake-up for when it resets
+func (m *Model) recordRateLimit(name string, l rateLimit) tea.Cmd {
+	prev, seen := m.rateLimits[name]
+	if m.rateLimits == nil {
+		m.rateLimits = make(map[string]rateLimit)
+	}
+	m.rateLimits[name] = l
+	if seen && prev.Reset.Equal(l.Reset) {
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	if !l.Reset.IsZero() {
+		cmds = append(cmds, tea.Tick(time.Until(l.Reset), func(time.Time) tea.Msg {
+			return rateLimitResetMsg{}
+		}))
+	}
+	if !seen {
+		detail := ""
+		if !l.Reset.IsZero() {
+			detail = "resets " + l.Reset.Local().Format("Jan 02 15:04")
+		}
+		m.logEvent(name, events.KindLimited, detail)
+		cmds = append(cmds, m.notify("Usage limit reached", name))
+	}
+	return tea.Batch(cmds...)
+}
+
+// expireRateLimits drops the limits that have reset. Once the last one is
+// gone from the agents limited before, the prompts queued meanwhile are sent.
+func (m *Model) expireRateLimits(now time.Time, limited bool) {
+	for name, l := range m.rateLimits {
+		if !l.Active(now) {
+			delete(m.rateLimits, name)
+		}
+	}
+	if !limited || len(m.rateLimits) > 0 {
+		return
+	}
+	m.message = "The usage limit has lifted"
+	for name := range m.promptQueues {
+		m.sendQueuedPrompt(name)
+	}
+}
+
+// rateLimited reports whether the session's agent is held up by a limit
+func (m *Model) rateLimited(name string) bool {
+	l, ok := m.rateLimits[name]
+	return ok && l.Active(time.Now())
+}
+
+// currentRateLimit returns the limit that holds the agents up the longest,
+// and the session that ran into it
+func (m *Model) currentRateLimit() (string, rateLimit, bool) {
+	now := time.Now()
+	// A limit without a reset time outlasts any with one
+	holdsUntil := func(l rateLimit) time.Time {
+		if l.Reset.IsZero() {
+			return time.Unix(1<<62, 0)
+		}
+		return l.Reset
+	}
+	var name string
+	var limit rateLimit
+	found := false
+	for _, n := range slices.Sorted(maps.Keys(m.rateLimits)) {
+		l := m.rateLimits[n]
+		if l.Active(now) && (!found || holdsUntil(l).After(holdsUntil(limit))) {
+			name, limit, found = n, l, true
+		}
+	}
+	return name, limit, found
+}
+
+// queuePaused reports whether queued prompts wait for a usage limit to reset
+func (m *Model) queuePaused() bool {
+	if m.cfg == nil || !m.cfg.PauseQueueOnLimit {
+		return false
+	}
+	_, _, limited := m.currentRateLimit()
+	return limited
+}
+
+// viewRateLimitBanner is the sidebar banner shown while a limit holds the
+// agents up
+func (m *Model) viewRateLimitBanner(width int) string {
+	name, l, ok := m.currentRateLimit()
+	if !ok {
+		return ""
+	}
+	body := fmt.Sprintf("%s's agent ran into a Claude usage limit", name)
+	if l.Reset.IsZero() {
+		body += "; it holds until an agent gets a reply through again."
+	} else {
+		reset := l.Reset.Local()
+		when := reset.Format("15:04")
+		if reset.YearDay() != time.Now().YearDay() {
+			when = reset.Format("Mon Jan 02 15:04")
+		}
+		left := max(time.Until(l.Reset).Round(time.Minute), time.Minute)
+		body += fmt.Sprintf(". It resets at %s (in %s).", when, strings.TrimSuffix(left.String(), "0s"))
+	}
+	if m.queuePaused() && len(m.promptQueues) > 0 {
+		body += " Queued prompts wait until then."
+	}
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(danger).
+		Width(width-2).
+		Padding(0, 1)
+	text := warningStyle.Bold(true).Render("Usage limit reached") + "\n" + dialogTextStyle.Render(body)
+	return strings.TrimRight(box.Render(text), "\n")
+}
@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/procstat"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+const (
+	// resourceSampleInterval is how often agents' CPU and memory are read
+	resourceSampleInterval = 5 * time.Second
+	// An agent using more than hotCPUPercent of a core for hotWarnAfter is
+	// probably stuck in a loop, and the user is warned once
+	hotCPUPercent = 90
+	hotWarnAfter  = 2 * time.Minute
+)
+
+// sessionResources is the latest resource usage of a session's agent and the
+// processes it started
+type sessionResources struct {
+	cpu float64 // percent of one core since the previous sample
+	rss int64   // bytes
+
+	last     procstat.Usage
+	lastAt   time.Time
+	hotSince time.Time // when CPU use went above hotCPUPercent, zero if below
+	warned   bool
+}
+
+// resourceTickMsg triggers a periodic resource sample
+type resourceTickMsg struct{}
+
+// resourcesSampledMsg carries the usage of each running agent's process tree
+type resourcesSampledMsg struct {
+	at    time.Time
+	usage map[string]procstat.Usage
+}
+
+// resourceTick schedules the next resource sample
+func resourceTick() tea.Cmd {
+	return tea.Tick(pollInterval(resourceSampleInterval), func(time.Time) tea.Msg {
+		return resourceTickMsg{}
+	})
+}
+
+// sampleResources reads the CPU time and memory of every open project's
+// running agents in the background
+func (m *Model) sampleResources() tea.Cmd {
+	pids := make(map[string]int)
+	add := func(terminals map[string]terminal.Terminal) {
+		for name, t := range terminals {
+			if pid := t.PID(); pid > 0 {
+				pids[name] = pid
+			}
+		}
+	}
+	add(m.terminals)
+	for i, tab := range m.tabs {
+		if i != m.tabIndex && tab != nil {
+			add(tab.terminals)
+		}
+	}
+	if len(pids) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		roots := make([]int, 0, len(pids))
+		for _, pid := range pids {
+			roots = append(roots, pid)
+		}
+		trees, err := procstat.Trees(roots)
+		if err != nil {
+			return nil
+		}
+		usage := make(map[string]procstat.Usage, len(pids))
+		for name, pid := range pids {
+			if u, ok := trees[pid]; ok {
+				usage[name] = u
+			}
+		}
+		return resourcesSampledMsg{at: time.Now(), usage: usage}
+	}
+}
+
+// updateResources records a sample, warning about agents that have kept a
+// core busy for hotWarnAfter
+func (m *Model) updateResources(msg resourcesSampledMsg) tea.Cmd {
+	if m.resources == nil {
+		m.resources = make(map[string]*sessionResources)
+	}
+	for name := range m.resources {
+		if _, ok := msg.usage[name]; !ok {
+			delete(m.resources, name)
+		}
+	}
+
+	var cmds []tea.Cmd
+	for name, u := range msg.usage {
+		r, ok := m.resources[name]
+		if !ok {
+			m.resources[name] = &sessionResources{rss: u.RSS, last: u, lastAt: msg.at}
+			continue
+		}
+		if elapsed := msg.at.Sub(r.lastAt); elapsed > 0 {
+			// A respawned agent starts its CPU time again from zero
+			r.cpu = max(float64(u.CPUTime-r.last.CPUTime), 0) / float64(elapsed) * 100
+		}
+		r.rss, r.last, r.lastAt = u.RSS, u, msg.at
+
+		if r.cpu < hotCPUPercent {
+			r.hotSince, r.warned = time.Time{}, false
+			continue
+		}
+		if r.hotSince.IsZero() {
+			r.hotSince = msg.at
+		}
+		if !r.warned && msg.at.Sub(r.hotSince) >= hotWarnAfter {
+			r.warned = true
+			m.message = fmt.Sprintf("'%s' has kept a CPU core busy for %s — stuck in a loop?",
+				name, msg.at.Sub(r.hotSince).Round(time.Minute))
+			cmds = append(cmds, m.notify("Agent is using a lot of CPU", name))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// viewResources lists the CPU and memory use of this project's running
+// agents, busiest first, for the usage overlay
+func (m *Model) viewResources() string {
+	var names []string
+	for name := range m.terminals {
+		if _, ok := m.resources[name]; ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Slice(names, func(i, j int) bool { return m.resources[names[i]].cpu > m.resources[names[j]].cpu })
+
+	var b strings.Builder
+	b.WriteString(dialogTextStyle.Render("Processes (agent and the tools it runs)") + "\n")
+	for i, name := range names {
+		if i >= maxUsageRows {
+			break
+		}
+		r := m.resources[name]
+		label := name
+		if name == mainProjectTerminalKey {
+			label = m.repoName
+		}
+		line := fmt.Sprintf("%-24s %5.0f%% CPU  %8s", truncate(label, 24), r.cpu, formatBytes(r.rss))
+		if !r.hotSince.IsZero() && time.Since(r.hotSince) >= hotWarnAfter {
+			b.WriteString(warningStyle.Render(line) + "\n")
+		} else {
+			b.WriteString(metadataStyle.Render(line) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// formatBytes renders a memory size, e.g. "512 MB"
+func formatBytes(n int64) string {
+	const mb = 1 << 20
+	if n >= 1<<30 {
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	}
+	return fmt.Sprintf("%d MB", n/mb)
+}
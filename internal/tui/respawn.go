@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/events"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+// Crash-loop limits for auto-respawn (the delay and attempts are configured)
+const (
+	// maxRespawnDelay caps the doubling wait between restarts
+	maxRespawnDelay = 5 * time.Minute
+	// crashCountReset is how long a restarted agent must run before its
+	// next crash starts a new count
+	crashCountReset = 10 * time.Minute
+)
+
+// crashLoop tracks an agent's crashes in a row for auto-respawn
+type crashLoop struct {
+	count     int       // crashes in a row
+	restarted time.Time // when it was last restarted automatically
+	gaveUp    bool      // stopped restarting after too many crashes
+}
+
+// respawnMsg restarts a crashed agent once its backoff has passed
+type respawnMsg struct {
+	name string
+}
+
+// autoRespawn schedules a restart of an agent that crashed, if the policy
+// allows one. It returns nil when the agent is left stopped
+func (m *Model) autoRespawn(msg terminal.TerminalExitedMsg) tea.Cmd {
+	policy := m.cfg.AutoRespawn
+	if !policy.Enabled || !msg.Crashed {
+		return nil
+	}
+	if m.crashes == nil {
+		m.crashes = make(map[string]*crashLoop)
+	}
+	c, ok := m.crashes[msg.Name]
+	if !ok || c.gaveUp || time.Since(c.restarted) > crashCountReset {
+		// First crash, or the first since the user restarted it themselves
+		// or since it last ran for a while
+		c = &crashLoop{}
+		m.crashes[msg.Name] = c
+	}
+	if c.count >= policy.MaxRestarts {
+		c.gaveUp = true
+		return nil
+	}
+
+	delay := min(policy.Delay<<c.count, maxRespawnDelay)
+	c.count++
+	m.message = fmt.Sprintf("Agent in '%s' crashed, restarting in %s", msg.Name, delay.Round(time.Second))
+	return tea.Tick(delay, func(time.Time) tea.Msg { return respawnMsg{name: msg.Name} })
+}
+
+// handleRespawn restarts a crashed agent with --continue unless it has
+// already been restarted or closed
+func (m *Model) handleRespawn(msg respawnMsg) (tea.Model, tea.Cmd) {
+	terminals := m.terminals
+	if tab := m.tabForSession(msg.name); tab != nil {
+		terminals = tab.terminals
+	}
+	t, ok := terminals[msg.name]
+	c := m.crashes[msg.name]
+	if !ok || t.IsRunning() || c == nil {
+		return m, nil
+	}
+	if err := t.Respawn(true, ""); err != nil {
+		m.err = err
+		return m, nil
+	}
+	c.restarted = time.Now()
+	m.logEvent(msg.name, events.KindRespawned, fmt.Sprintf("auto-restart after crash %d", c.count))
+	return m, nil
+}
+
+// crashLooped reports whether auto-respawn gave up on a session's agent and
+// it hasn't been restarted since
+func (m *Model) crashLooped(name string) bool {
+	c, ok := m.crashes[name]
+	if !ok || !c.gaveUp {
+		return false
+	}
+	t, ok := m.terminals[name]
+	return ok && !t.IsRunning()
+}
@@ -0,0 +1,159 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/events"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+// Choices in the menu shown over a terminal whose agent has exited
+const (
+	restartContinue = iota // --continue the previous conversation
+	restartFresh           // a new conversation
+	restartFlags           // flags typed by the user
+	restartChoices
+)
+
+// restartMenu is the menu of ways to restart an exited agent. Only the
+// terminal it was last used in keeps its cursor; others show it reset
+type restartMenu struct {
+	term    terminal.Terminal
+	cursor  int
+	editing bool // the flags are being typed
+	input   textinput.Model
+}
+
+// restartMenuFor returns the menu state for a terminal, resetting it if it
+// was last used in another one
+func (m *Model) restartMenuFor(t terminal.Terminal) *restartMenu {
+	if m.restart.term != t {
+		input := textinput.New()
+		input.Prompt = "  flags: "
+		input.CharLimit = 500
+		// Keep the flags typed last time, which are likely wanted again
+		input.SetValue(m.restart.input.Value())
+		if input.Value() == "" {
+			input.SetValue("--continue ")
+		}
+		m.restart = restartMenu{term: t, input: input}
+	}
+	return &m.restart
+}
+
+// handleRestartKeys moves through the restart menu of an exited terminal
+// and restarts the agent the chosen way
+func (m *Model) handleRestartKeys(t terminal.Terminal, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	menu := m.restartMenuFor(t)
+	if menu.editing {
+		switch msg.Type {
+		case tea.KeyEnter:
+			menu.editing = false
+			menu.input.Blur()
+			return m, m.restartAgent(t, false, menu.input.Value())
+		case tea.KeyEscape:
+			menu.editing = false
+			menu.input.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		menu.input, cmd = menu.input.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		menu.cursor = (menu.cursor + restartChoices - 1) % restartChoices
+	case "down", "j":
+		menu.cursor = (menu.cursor + 1) % restartChoices
+	case "c":
+		return m, m.restartAgent(t, true, "")
+	case "f":
+		return m, m.restartAgent(t, false, "")
+	case "r":
+		menu.cursor = restartFlags
+		menu.editing = true
+		menu.input.CursorEnd()
+		return m, menu.input.Focus()
+	case "enter":
+		switch menu.cursor {
+		case restartContinue:
+			return m, m.restartAgent(t, true, "")
+		case restartFresh:
+			return m, m.restartAgent(t, false, "")
+		case restartFlags:
+			menu.editing = true
+			menu.input.CursorEnd()
+			return m, menu.input.Focus()
+		}
+	}
+	return m, nil
+}
+
+// restartAgent respawns an exited agent
+func (m *Model) restartAgent(t terminal.Terminal, continueSession bool, flags string) tea.Cmd {
+	if err := t.Respawn(continueSession, flags); err != nil {
+		m.err = err
+		return nil
+	}
+	m.restart.cursor = restartContinue
+	detail := "fresh conversation"
+	switch {
+	case strings.TrimSpace(flags) != "":
+		detail = "flags: " + strings.TrimSpace(flags)
+	case continueSession:
+		detail = "continued conversation"
+	}
+	m.logEvent(t.Name(), events.KindRespawned, detail)
+	return nil
+}
+
+// withRestartMenu puts the restart menu under an exited terminal's last
+// output, dropping lines from the top of the screen so all of it fits in th
+// lines
+func (m *Model) withRestartMenu(t terminal.Terminal, screen string, th int) string {
+	menu := strings.Split(m.viewRestartMenu(t), "\n")
+	lines := strings.Split(screen, "\n")
+	for len(lines) > 0 && strings.TrimSpace(stripANSI(lines[len(lines)-1])) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	lines = append(lines, "")
+	if extra := len(lines) + len(menu) - th; extra > 0 {
+		lines = lines[min(extra, len(lines)):]
+	}
+	return strings.Join(append(lines, menu...), "\n")
+}
+
+// viewRestartMenu renders the restart menu shown under an exited terminal
+func (m *Model) viewRestartMenu(t terminal.Terminal) string {
+	cursor, editing, input := restartContinue, false, ""
+	if m.restart.term == t {
+		cursor, editing = m.restart.cursor, m.restart.editing
+		input = m.restart.input.View()
+	}
+
+	items := []string{
+		"[c] Continue the conversation",
+		"[f] Start a new conversation",
+		"[r] Restart with flags…",
+	}
+	var b strings.Builder
+	b.WriteString("  Session ended.\n\n")
+	for i, item := range items {
+		if i == cursor {
+			b.WriteString("  " + selectedItemStyle.Render(item) + "\n")
+		} else {
+			b.WriteString("  " + normalItemStyle.Render(item) + "\n")
+		}
+	}
+	if editing {
+		b.WriteString("\n" + input + "\n")
+		b.WriteString(helpStyle.Render("  e.g. --model opus --dangerously-skip-permissions · enter restart · esc back"))
+	} else {
+		b.WriteString(helpStyle.Render("  ↑/↓ choose · enter restart"))
+	}
+	return b.String()
+}
@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+)
+
+// Scheduled sessions come from the schedule in the repo's .atc.yaml. While
+// ATC runs with the project selected, each schedule's session is created once
+// a day when it falls due, its prompt is queued for its agent, and the verify
+// command runs once the agent is done, so the sidebar shows how the run went.
+// Like setup commands, schedules only run once the user has trusted them.
+
+// scheduleInterval is how often the schedules are checked for a due run
+const scheduleInterval = 30 * time.Second
+
+// scheduleTickMsg triggers a check of the schedules
+type scheduleTickMsg struct{}
+
+// scheduleTick schedules the next check of the schedules
+func scheduleTick() tea.Cmd {
+	return tea.Tick(scheduleInterval, func(time.Time) tea.Msg {
+		return scheduleTickMsg{}
+	})
+}
+
+// runSchedules creates the session of a schedule that has fallen due, one
+// per check, unless the user is in the middle of something
+func (m *Model) runSchedules(now time.Time) tea.Cmd {
+	if m.service == nil || m.overlay != overlayNone {
+		return nil
+	}
+	for _, sc := range m.service.Config().Schedule {
+		name := sc.SessionName(now)
+		if !sc.Due(now) || m.scheduleRuns[name] {
+			continue
+		}
+		if m.scheduleRuns == nil {
+			m.scheduleRuns = make(map[string]bool)
+		}
+		m.scheduleRuns[name] = true
+		if m.sessionNameTaken(name) {
+			continue
+		}
+		if trusted, err := m.service.CommandsTrusted(); err != nil || !trusted {
+			m.message = fmt.Sprintf("Skipped scheduled session %s: the repo's commands and schedules aren't trusted yet", name)
+			continue
+		}
+
+		base := sc.BaseBranch
+		if base == "" {
+			base = m.service.DefaultBaseBranch()
+		}
+		if m.promptQueues == nil {
+			m.promptQueues = make(map[string][]string)
+		}
+		m.promptQueues[name] = []string{sc.Prompt}
+		if m.scheduledSessions == nil {
+			m.scheduledSessions = make(map[string]bool)
+		}
+		m.scheduledSessions[name] = true
+		m.pendingSessionName = name
+		m.fetchFirst = m.service.Config().FetchBeforeCreate
+		return m.doCreateSession(base, false)
+	}
+	return nil
+}
+
+// startScheduledTerminal starts a scheduled session's agent without taking
+// over the terminal pane
+func (m *Model) startScheduledTerminal(sess *session.Session) tea.Cmd {
+	agent := m.sessionAgent(sess)
+	return func() tea.Msg {
+		tw, th := m.terminalPaneDimensions()
+		if err := m.ensureTerminalResuming(sess, agent, tw, th, resumeLatest); err != nil {
+			return errMsg{err}
+		}
+		return nil
+	}
+}
+
+// forgetScheduledSession drops a scheduled session that failed to be created
+// or is gone
+func (m *Model) forgetScheduledSession(name string) {
+	if m.scheduledSessions[name] {
+		delete(m.scheduledSessions, name)
+		m.forgetPromptQueue(name)
+	}
+}
+
+// finishScheduledRun reports that a scheduled session's agent is done, with
+// the verify run that followed if the repo has a verify command
+func (m *Model) finishScheduledRun(name string, run *verifyRun) {
+	if !m.scheduledSessions[name] {
+		return
+	}
+	delete(m.scheduledSessions, name)
+	switch {
+	case run == nil:
+		m.message = fmt.Sprintf("Scheduled session %s is done", name)
+	case run.failed:
+		m.message = fmt.Sprintf("Scheduled session %s is done, but verify failed; press %s for the log", name, m.keys.Verify)
+	default:
+		m.message = fmt.Sprintf("Scheduled session %s is done and verify passed", name)
+	}
+}
+
+// scheduleSummary marks a scheduled session that is still running in front
+// of its sidebar summary
+func (m *Model) scheduleSummary(name, summary string) string {
+	if !m.scheduledSessions[name] {
+		return summary
+	}
+	if summary == "" {
+		return "scheduled"
+	}
+	return "scheduled · " + summary
+}
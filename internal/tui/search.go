@@ -0,0 +1,213 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+// scrollbackSearch is a search through a terminal's scrollback. It starts
+// with / while the terminal is scrolled back; n and N then move between
+// older and newer matches
+type scrollbackSearch struct {
+	term   terminal.Terminal // the terminal searched, nil when there's no search
+	typing bool              // the query is being entered
+	input  textinput.Model
+	query  string
+
+	// The current match, as a tmux line number (0 is the top of the screen,
+	// history is negative), and its place among all of them
+	line         int
+	hasMatch     bool
+	index, total int
+}
+
+// startSearch opens the search prompt for a terminal
+func (m *Model) startSearch(t terminal.Terminal) {
+	input := textinput.New()
+	input.Prompt = "/"
+	input.CharLimit = 200
+	input.SetValue(m.search.query)
+	input.Focus()
+	m.search = scrollbackSearch{term: t, typing: true, input: input, query: m.search.query}
+}
+
+// endSearch closes the search and returns the terminal to its live view
+func (m *Model) endSearch() {
+	if m.search.term != nil {
+		m.search.term.ExitScrollMode()
+	}
+	m.search.term = nil
+	m.search.typing = false
+}
+
+// handleSearchKeys handles a key press while a terminal is being searched
+func (m *Model) handleSearchKeys(t terminal.Terminal, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.search.typing {
+		switch msg.Type {
+		case tea.KeyEnter:
+			m.search.typing = false
+			m.search.query = m.search.input.Value()
+			m.search.hasMatch = false
+			if m.search.query == "" {
+				m.endSearch()
+				return m, nil
+			}
+			m.jumpToMatch(t, true)
+			return m, nil
+		case tea.KeyEscape:
+			m.endSearch()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.search.input, cmd = m.search.input.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "n":
+		m.jumpToMatch(t, true)
+	case "N":
+		m.jumpToMatch(t, false)
+	case "/":
+		m.startSearch(t)
+	default:
+		// Like scroll mode, any other key goes back to the live view
+		m.endSearch()
+	}
+	return m, nil
+}
+
+// jumpToMatch scrolls to the next older (or newer) match. The first jump
+// goes to the newest match at or above the bottom of the view
+func (m *Model) jumpToMatch(t terminal.Terminal, older bool) {
+	text, histSize := t.Scrollback()
+	matches := searchMatches(text, histSize, m.search.query)
+	m.search.total = len(matches)
+	if len(matches) == 0 {
+		m.message = fmt.Sprintf("No matches for %q", m.search.query)
+		return
+	}
+
+	_, th := m.terminalPaneDimensions()
+	ref := th - t.ScrollPosition() // just below the view
+	if m.search.hasMatch {
+		ref = m.search.line
+	}
+	next := -1
+	if older {
+		for i := len(matches) - 1; i >= 0; i-- {
+			if matches[i] < ref {
+				next = i
+				break
+			}
+		}
+	} else {
+		for i, line := range matches {
+			if line > ref {
+				next = i
+				break
+			}
+		}
+	}
+	if next < 0 {
+		if older {
+			m.message = "No older matches"
+		} else {
+			m.message = "No newer matches"
+		}
+		return
+	}
+
+	m.message = ""
+	m.search.line, m.search.hasMatch = matches[next], true
+	m.search.index = next + 1
+	// Put the match in the middle of the pane where the scrollback allows
+	t.ExitScrollMode()
+	t.ScrollUp(min(max(th/2-m.search.line, 0), histSize))
+}
+
+// searchMatches returns the tmux line numbers of the scrollback lines that
+// contain query, oldest first. The search ignores case unless the query has
+// capitals
+func searchMatches(scrollback string, histSize int, query string) []int {
+	foldCase := !strings.ContainsFunc(query, unicode.IsUpper)
+	if foldCase {
+		query = strings.ToLower(query)
+	}
+	var matches []int
+	for i, line := range strings.Split(strings.TrimSuffix(scrollback, "\n"), "\n") {
+		line = stripANSI(line)
+		if foldCase {
+			line = strings.ToLower(line)
+		}
+		if strings.Contains(line, query) {
+			matches = append(matches, i-histSize)
+		}
+	}
+	return matches
+}
+
+// highlightSearch highlights the search query wherever it appears in a
+// rendered terminal screen
+func (m *Model) highlightSearch(rendered string) string {
+	if m.search.query == "" {
+		return rendered
+	}
+	foldCase := !strings.ContainsFunc(m.search.query, unicode.IsUpper)
+	query := []rune(m.search.query)
+	if foldCase {
+		query = []rune(strings.ToLower(m.search.query))
+	}
+	lines := strings.Split(rendered, "\n")
+	for i, line := range lines {
+		text := []rune(stripANSI(line))
+		// Highlight from the right so earlier columns stay put
+		for col := len(text) - len(query); col >= 0; col-- {
+			if runesEqual(text[col:col+len(query)], query, foldCase) {
+				line = applyHighlightToLine(line, col, col+len(query)-1, selectionLightenFactor)
+				col -= len(query) - 1
+			}
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// runesEqual compares two equal-length rune slices, optionally ignoring case
+func runesEqual(a, b []rune, foldCase bool) bool {
+	for i := range a {
+		if a[i] != b[i] && !(foldCase && unicode.ToLower(a[i]) == b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// searchStatusLine puts the search prompt, or the query and which match is
+// shown, on the bottom line of a rendered terminal th lines high
+func (m *Model) searchStatusLine(rendered string, tw, th int) string {
+	var status string
+	switch {
+	case m.search.typing:
+		m.search.input.Width = max(tw-2, 1)
+		status = m.search.input.View()
+	case m.search.hasMatch:
+		status = scrollIndicatorStyle.Render(truncate(fmt.Sprintf(" /%s  %d/%d  n older · N newer ",
+			m.search.query, m.search.total-m.search.index+1, m.search.total), tw))
+	default:
+		status = scrollIndicatorStyle.Render(truncate(" /"+m.search.query+" ", tw))
+	}
+	lines := strings.Split(rendered, "\n")
+	for len(lines) < th {
+		lines = append(lines, "")
+	}
+	lines[th-1] = status + strings.Repeat(" ", max(tw-lipgloss.Width(status), 0))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,28 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSearchMatches(t *testing.T) {
+	scrollback := "old Error\nnothing\n\x1b[31merror\x1b[0m on screen\nlast line\n"
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"ignores case", "error", []int{-2, 0}},
+		{"capitals match case", "Error", []int{-2}},
+		{"ignores colors", "error on", []int{0}},
+		{"no match", "missing", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Two lines of history: the screen starts at line 0
+			if got := searchMatches(scrollback, 2, tt.query); !slices.Equal(got, tt.want) {
+				t.Errorf("searchMatches(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// shouldPreviewSetup reports whether to show the setup commands before
+// creating a session: only for a project's first session, and once per run.
+// Untrusted commands are shown by the trust prompt instead
+func (m *Model) shouldPreviewSetup() bool {
+	if m.service == nil || m.skipSetup || m.setupPreviewed || len(m.sessions) > 0 {
+		return false
+	}
+	return len(m.service.Config().Setup) > 0
+}
+
+// previewSetup defers a session creation until the user has seen the setup
+// commands that will run in the new worktree
+func (m *Model) previewSetup(baseBranch string, useExisting bool) {
+	m.pendingBaseBranch = baseBranch
+	m.pendingUseExisting = useExisting
+	m.overlay = overlaySetupPreview
+}
+
+func (m *Model) handleSetupPreviewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "y", "Y":
+		m.setupPreviewed = true
+		return m, m.doCreateSession(m.pendingBaseBranch, m.pendingUseExisting)
+	case "esc", "n", "N":
+		return m.dismissOverlay()
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *Model) viewSetupPreview() string {
+	cfg := m.service.Config()
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Setup Commands"))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render(fmt.Sprintf("These will run in the new worktree for \"%s\":", m.pendingSessionName)))
+	b.WriteString("\n\n")
+	for _, cmd := range cfg.Setup {
+		b.WriteString(normalItemStyle.Render("$ " + truncate(cmd, 60)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	for _, src := range cfg.SetupSources {
+		b.WriteString(metadataStyle.Render("from " + truncatePath(src, 60)))
+		b.WriteString("\n")
+	}
+	b.WriteString(metadataStyle.Render("Set `cursor:` in .atc.yaml to change how .cursor/worktrees.json is used"))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render("[Enter] Create session    [Esc] Cancel"))
+	return dialogBoxStyle.Render(b.String())
+}
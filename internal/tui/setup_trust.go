@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+)
+
+// Setup and teardown commands come from files in the repository, so ATC only
+// runs them automatically once the user has trusted them. They are read from
+// the repository root when the project opens, and those same commands are
+// shown, trusted and run, whatever the new session's branch has in its own
+// .atc.yaml. The trust prompt is shown before creating a session in an
+// untrusted repo, and again after creation for a session whose commands
+// weren't trusted by the time it was created. The repository's settings that
+// pick what runs (its agent, shell and archive destination) are trusted with
+// the commands: until then sessions start with the user's own.
+
+// shouldConfirmSetup reports whether to ask the user to trust the project's
+// setup commands and run settings before creating a session
+func (m *Model) shouldConfirmSetup() bool {
+	if m.service == nil || m.skipSetup {
+		return false
+	}
+	if len(m.service.Config().Setup) == 0 && len(m.service.RunSettings()) == 0 {
+		return false
+	}
+	trusted, err := m.service.CommandsTrusted()
+	return err != nil || !trusted
+}
+
+// confirmSetup defers a session creation until the user has decided whether
+// to trust the setup commands that will run in the new worktree
+func (m *Model) confirmSetup(baseBranch string, useExisting bool) {
+	m.pendingBaseBranch = baseBranch
+	m.pendingUseExisting = useExisting
+	m.overlay = overlaySetupTrust
+}
+
+// confirmCreatedSetup asks whether to run the setup commands of a session
+// whose worktree config isn't trusted yet
+func (m *Model) confirmCreatedSetup(sess *session.Session, commands []string) {
+	m.untrustedSession = sess
+	m.untrustedSetup = commands
+	m.overlay = overlaySetupTrust
+}
+
+// skipUntrustedSetup leaves a created session without running its setup
+func (m *Model) skipUntrustedSetup() {
+	if m.untrustedSession != nil {
+		m.message = fmt.Sprintf("Skipped setup for '%s'", m.untrustedSession.Name)
+	}
+	m.untrustedSession = nil
+	m.untrustedSetup = nil
+	m.overlay = overlayNone
+}
+
+func (m *Model) handleSetupTrustKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "y", "Y":
+		if sess := m.untrustedSession; sess != nil {
+			commands := m.untrustedSetup
+			m.untrustedSession = nil
+			m.untrustedSetup = nil
+			m.overlay = overlayNone
+			if err := m.service.TrustCommands(); err != nil {
+				m.err = err
+			}
+			m.settingUpSessions[sess.Name] = true
+			return m, m.runSetupInBackground(sess, commands)
+		}
+		if err := m.service.TrustCommands(); err != nil {
+			m.err = err
+			m.overlay = overlayNone
+			return m, nil
+		}
+		// They've just been shown
+		m.setupPreviewed = true
+		return m, m.doCreateSession(m.pendingBaseBranch, m.pendingUseExisting)
+	case "n", "N":
+		if m.untrustedSession != nil {
+			m.skipUntrustedSetup()
+			return m, nil
+		}
+		m.skipSetup = true
+		return m, m.doCreateSession(m.pendingBaseBranch, m.pendingUseExisting)
+	case "esc":
+		return m.dismissOverlay()
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *Model) viewSetupTrust() string {
+	cfg := m.service.Config()
+	commands, sources := cfg.Setup, cfg.SetupSources
+	target := m.pendingSessionName
+	if m.untrustedSession != nil {
+		commands, sources = m.untrustedSetup, nil
+		target = m.untrustedSession.Name
+	}
+
+	var settings []string
+	if m.untrustedSession == nil {
+		settings = m.service.RunSettings()
+	}
+
+	var b strings.Builder
+	if len(commands) == 0 {
+		b.WriteString(titleStyle.Render("Trust Repository Settings?"))
+	} else {
+		b.WriteString(titleStyle.Render("Trust Setup Commands?"))
+	}
+	b.WriteString("\n\n")
+	if len(commands) > 0 {
+		b.WriteString(dialogTextStyle.Render(fmt.Sprintf("This repository wants to run these in the worktree for \"%s\":", target)))
+		b.WriteString("\n\n")
+	}
+	for _, cmd := range commands {
+		b.WriteString(normalItemStyle.Render("$ " + truncate(cmd, 60)))
+		b.WriteString("\n")
+	}
+	if m.untrustedSession == nil && cfg.Verify != "" {
+		b.WriteString(normalItemStyle.Render("$ " + truncate(cfg.Verify, 60) + "  (verify, when you ask)"))
+		b.WriteString("\n")
+	}
+	if len(settings) > 0 {
+		if len(commands) > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(dialogTextStyle.Render(fmt.Sprintf("It sets what runs for \"%s\" and its other sessions:", target)))
+		b.WriteString("\n\n")
+		for _, setting := range settings {
+			b.WriteString(normalItemStyle.Render(truncate(setting, 60)))
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+	for _, src := range sources {
+		b.WriteString(metadataStyle.Render("from " + truncatePath(src, 60)))
+		b.WriteString("\n")
+	}
+	if m.untrustedSession == nil {
+		b.WriteString(metadataStyle.Render("Set `cursor:` in .atc.yaml to change how .cursor/worktrees.json is used"))
+		b.WriteString("\n")
+	}
+	b.WriteString(metadataStyle.Render("Only trust commands from repositories you trust. You'll be asked again if they change."))
+	b.WriteString("\n\n")
+	if m.untrustedSession != nil {
+		b.WriteString(dialogTextStyle.Render("[y] Trust and run    [n] Skip setup"))
+	} else {
+		b.WriteString(dialogTextStyle.Render("[y] Trust and create    [n] Create without them    [Esc] Cancel"))
+	}
+	return dialogBoxStyle.Render(b.String())
+}
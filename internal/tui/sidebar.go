@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Settings keys for the persisted sidebar layout
+const (
+	settingSidebarWidth     = "sidebar_width"
+	settingSidebarCollapsed = "sidebar_collapsed"
+	settingSidebarArchived  = "sidebar_archived"
+)
+
+// loadSidebarSettings restores the user's sidebar width, collapsed state and
+// whether archived sessions are shown in it
+func (m *Model) loadSidebarSettings() {
+	m.sidebarWidth = defaultSidebarWidth
+	if m.db == nil {
+		return
+	}
+	if v, err := m.db.GetSetting(settingSidebarWidth); err == nil && v != "" {
+		if w, err := strconv.Atoi(v); err == nil {
+			m.sidebarWidth = clampSidebarWidth(w)
+		}
+	}
+	if v, err := m.db.GetSetting(settingSidebarCollapsed); err == nil {
+		m.sidebarCollapsed = v == "true"
+	}
+	if v, err := m.db.GetSetting(settingSidebarArchived); err == nil {
+		m.archivedInline = v == "true"
+	}
+}
+
+// saveSidebarSettings persists the sidebar layout in the background
+func (m *Model) saveSidebarSettings() tea.Cmd {
+	if m.db == nil {
+		return nil
+	}
+	width := strconv.Itoa(m.sidebarWidth)
+	collapsed := strconv.FormatBool(m.sidebarCollapsed)
+	archived := strconv.FormatBool(m.archivedInline)
+	return func() tea.Msg {
+		if err := m.db.SetSetting(settingSidebarWidth, width); err != nil {
+			return errMsg{err}
+		}
+		if err := m.db.SetSetting(settingSidebarCollapsed, collapsed); err != nil {
+			return errMsg{err}
+		}
+		if err := m.db.SetSetting(settingSidebarArchived, archived); err != nil {
+			return errMsg{err}
+		}
+		return nil
+	}
+}
+
+// clampSidebarWidth limits a sidebar width to the supported range
+func clampSidebarWidth(w int) int {
+	if w < minSidebarWidth {
+		return minSidebarWidth
+	}
+	if w > maxSidebarWidth {
+		return maxSidebarWidth
+	}
+	return w
+}
+
+// resizeSidebar sets the sidebar width (clamped so the terminal pane keeps a
+// usable width) and resizes the active terminal to match
+func (m *Model) resizeSidebar(width int) {
+	width = clampSidebarWidth(width)
+	if m.windowWidth > 0 && width > m.windowWidth-20 {
+		width = max(minSidebarWidth, m.windowWidth-20)
+	}
+	m.sidebarWidth = width
+	m.resizeTerminalIfNeeded()
+}
+
+// toggleSidebar collapses or expands the sidebar. A collapsed sidebar behaves
+// like the narrow-screen layout: it is only shown while it has focus, so
+// focus moves to the active session's terminal when collapsing.
+func (m *Model) toggleSidebar() tea.Cmd {
+	m.sidebarCollapsed = !m.sidebarCollapsed
+	if m.sidebarCollapsed && m.activeSession != nil {
+		m.focus = focusTerminal
+	}
+	m.resizeTerminalIfNeeded()
+	return m.saveSidebarSettings()
+}
+
+// onSidebarBorder reports whether x is on the sidebar's right border or the
+// spacer next to it, where a drag resizes the sidebar
+func (m *Model) onSidebarBorder(x int) bool {
+	return x == m.sidebarWidth-1 || x == m.sidebarWidth
+}
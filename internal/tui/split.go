@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// minSplitPaneWidth is the narrowest pane width for which split view is shown
+const minSplitPaneWidth = 40
+
+// splitActive reports whether the pinned session is shown beside the active
+// one. Split view needs two different sessions, a running terminal for the
+// pinned one, and enough room for both panes.
+func (m *Model) splitActive() bool {
+	if m.pinnedSession == nil || m.activeSession == nil || m.pinnedSession.Name == m.activeSession.Name {
+		return false
+	}
+	if _, ok := m.terminals[m.pinnedSession.Name]; !ok {
+		return false
+	}
+	return m.terminalAreaWidth() >= 2*minSplitPaneWidth+1
+}
+
+// splitPaneWidths divides the terminal area between the pinned (left) and
+// active (right) panes, leaving one column for the divider
+func splitPaneWidths(total int) (pinned, active int) {
+	active = (total - 1) / 2
+	pinned = total - 1 - active
+	return pinned, active
+}
+
+// pinnedPaneWidth returns the pinned pane's width, or 0 when not split
+func (m *Model) pinnedPaneWidth() int {
+	if !m.splitActive() {
+		return 0
+	}
+	pinned, _ := splitPaneWidths(m.terminalAreaWidth())
+	return pinned
+}
+
+// togglePin pins the active session for split view, or unpins it if it is
+// already pinned
+func (m *Model) togglePin() tea.Cmd {
+	if m.activeSession == nil {
+		return nil
+	}
+	if m.pinnedSession != nil && m.pinnedSession.Name == m.activeSession.Name {
+		m.unpin()
+		m.message = "Split view closed"
+		return nil
+	}
+	m.pinnedSession = m.activeSession
+	m.message = fmt.Sprintf("Pinned '%s'; select another session to view it alongside", m.pinnedSession.Name)
+	m.resizeTerminalIfNeeded()
+	return nil
+}
+
+// unpin closes split view
+func (m *Model) unpin() {
+	m.pinnedSession = nil
+	if m.focus == focusPinned {
+		m.focus = focusTerminal
+	}
+	m.resizeTerminalIfNeeded()
+}
+
+// unpinIfNamed closes split view if the named session is the pinned one,
+// e.g. because it was archived or deleted
+func (m *Model) unpinIfNamed(name string) {
+	if m.pinnedSession != nil && m.pinnedSession.Name == name {
+		m.unpin()
+	}
+}
+
+// switchPane moves keyboard focus between the two panes of a split view
+func (m *Model) switchPane() {
+	m.hasSelection = false
+	m.selecting = false
+	if m.focus == focusPinned {
+		m.focus = focusTerminal
+	} else {
+		m.focus = focusPinned
+	}
+}
+
+// handlePinnedKeys forwards keys to the pinned session's terminal
+func (m *Model) handlePinnedKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.splitActive() {
+		m.focus = focusTerminal
+		return m.handleTerminalKeys(msg)
+	}
+	return m.forwardKeys(m.terminals[m.pinnedSession.Name], msg)
+}
+
+// viewPinned renders the pinned pane followed by the divider column
+func (m *Model) viewPinned() string {
+	_, th := m.terminalPaneDimensions()
+	pw := m.pinnedPaneWidth()
+	t := m.terminals[m.pinnedSession.Name]
+
+	pane := lipgloss.NewStyle().
+		Width(pw).
+		MaxWidth(pw).
+		Height(th).
+		MaxHeight(th).
+		Render(m.renderTerminal(t, pw, false, m.focus != focusPinned))
+
+	dividerColor := textDim
+	if m.focus == focusPinned || m.focus == focusTerminal {
+		dividerColor = textMuted
+	}
+	divider := lipgloss.NewStyle().
+		Foreground(dividerColor).
+		Render(strings.TrimSuffix(strings.Repeat("│\n", th), "\n"))
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, pane, divider)
+}
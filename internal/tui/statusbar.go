@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// The status bar is the bottom line of the screen: what keys go to, the
+// active session with its branch, agent state and working time, how far its
+// terminal is scrolled back, and on the right the latest message or error
+
+// statusBarHeight is the number of lines the status bar takes
+const statusBarHeight = 1
+
+// layoutHeight is the height of the panes above the status bar
+func (m *Model) layoutHeight() int {
+	return max(m.windowHeight-statusBarHeight, 1)
+}
+
+// statusMode names the pane that has focus, or the mode keys go to in it
+func (m *Model) statusMode() string {
+	switch {
+	case m.copy.term != nil:
+		return "COPY"
+	case m.search.term != nil:
+		return "SEARCH"
+	case m.focus == focusSidebar:
+		return "SIDEBAR"
+	case m.focus == focusPinned:
+		return "PINNED"
+	}
+	return "TERMINAL"
+}
+
+// statusSession describes the active session: its name, its branch if named
+// differently, its agent's state, how long it has worked and the window
+// shown in its pane
+func (m *Model) statusSession() []string {
+	sess := m.activeSession
+	if sess == nil {
+		return nil
+	}
+	name := sess.Name
+	if sess.BranchName != "" && sess.BranchName != sess.Name {
+		name += " (" + sess.BranchName + ")"
+	}
+	parts := []string{name}
+	t, ok := m.terminals[sess.Name]
+	switch {
+	case m.settingUpSessions[sess.Name]:
+		parts = append(parts, "setting up")
+	case !ok:
+		parts = append(parts, "not started")
+	case !t.IsRunning():
+		parts = append(parts, "exited")
+	default:
+		parts = append(parts, t.State().String())
+	}
+	if d := m.workTimes[sess.ID]; d >= time.Minute {
+		parts = append(parts, "worked "+formatWorkTime(d))
+	}
+	if ok {
+		if w := t.Window(); w != "" {
+			parts = append(parts, w+" window")
+		}
+		if pos := t.ScrollPosition(); pos > 0 {
+			parts = append(parts, fmt.Sprintf("scrolled back %d %s", pos, plural(pos, "line", "lines")))
+		}
+	}
+	return parts
+}
+
+// viewStatusBar renders the status bar the full width of the screen
+func (m *Model) viewStatusBar() string {
+	width := m.windowWidth
+	mode := statusModeStyle.Render(" " + m.statusMode() + " ")
+	left := mode
+	if parts := m.statusSession(); len(parts) > 0 {
+		left += " " + metadataStyle.Render(strings.Join(parts, " · "))
+	}
+
+	var right string
+	if m.err != nil {
+		right = errorStyle.Render(truncate(m.err.Error(), max(width-lipgloss.Width(mode)-2, 1)))
+	} else if m.message != "" {
+		right = successStyle.Render(truncate(m.message, max(width-lipgloss.Width(mode)-2, 1)))
+	}
+	// The message wins over the session's details when both don't fit
+	if right != "" {
+		left = ansi.Truncate(left, max(width-lipgloss.Width(right)-1, lipgloss.Width(mode)), "…")
+	} else {
+		left = ansi.Truncate(left, width, "…")
+	}
+	gap := max(width-lipgloss.Width(left)-lipgloss.Width(right), 0)
+	return left + strings.Repeat(" ", gap) + right
+}
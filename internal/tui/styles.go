@@ -6,12 +6,15 @@ import (
 
 // Layout constants
 const (
-	sidebarWidth         = 36
+	defaultSidebarWidth  = 36
+	minSidebarWidth      = 24
+	maxSidebarWidth      = 80
+	sidebarWidthStep     = 4
 	smallScreenThreshold = 100
 )
 
 var (
-	// Color palette
+	// Color palette, set from the active theme (see theme.go)
 	// Non-monochrome: primary, success, danger
 	// Monochrome: textNormal, textMuted, textDim
 	//
@@ -19,104 +22,160 @@ var (
 	//   primary    → textMuted
 	//   textNormal → textMuted
 	//   textMuted  → textDim
-	primary    = lipgloss.Color("#00d4ff") // Cyan
-	success    = lipgloss.Color("#00ff87") // Green
-	danger     = lipgloss.Color("#ff5f5f") // Red
-	textNormal = lipgloss.Color("#e4e4e4") // Light gray
-	textMuted  = lipgloss.Color("#6c757d") // Gray
-	textDim    = lipgloss.Color("#495057") // Dark gray
+	primary      lipgloss.Color
+	success      lipgloss.Color
+	danger       lipgloss.Color
+	textNormal   lipgloss.Color
+	textMuted    lipgloss.Color
+	textDim      lipgloss.Color
+	selectedText lipgloss.Color // text on primary/dim backgrounds
+
+	// Sidebar styles
+	sidebarFocusedStyle            lipgloss.Style
+	sidebarUnfocusedStyle          lipgloss.Style
+	sidebarSessionStyle            lipgloss.Style
+	sidebarSessionSelectedStyle    lipgloss.Style
+	sidebarSessionDimStyle         lipgloss.Style
+	sidebarSessionDimSelectedStyle lipgloss.Style
+
+	// Dialog styles
+	dialogBoxStyle    lipgloss.Style
+	dialogTitleStyle  lipgloss.Style
+	dialogTextStyle   lipgloss.Style
+	warningStyle      lipgloss.Style
+	selectedItemStyle lipgloss.Style
+	normalItemStyle   lipgloss.Style
+
+	// General text styles
+	titleStyle       lipgloss.Style
+	subtitleStyle    lipgloss.Style
+	metadataStyle    lipgloss.Style
+	helpStyle        lipgloss.Style
+	dividerStyle     lipgloss.Style
+	placeholderStyle lipgloss.Style
+
+	// Status styles
+	errorStyle           lipgloss.Style
+	successStyle         lipgloss.Style
+	scrollIndicatorStyle lipgloss.Style
+	statusModeStyle      lipgloss.Style
+)
+
+func init() {
+	buildStyles()
+}
+
+// buildStyles derives the palette and all styles from the active theme
+func buildStyles() {
+	primary = theme.Primary
+	success = theme.Success
+	danger = theme.Danger
+	textNormal = theme.TextNormal
+	textMuted = theme.TextMuted
+	textDim = theme.TextDim
+	selectedText = theme.SelectedText
 
 	// --- Sidebar styles ---
 
 	sidebarFocusedStyle = lipgloss.NewStyle().
-				Border(lipgloss.NormalBorder()).
-				BorderForeground(primary)
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(primary)
 
 	sidebarUnfocusedStyle = lipgloss.NewStyle().
-				Border(lipgloss.NormalBorder()).
-				BorderForeground(textDim)
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(textDim)
 
 	// Sidebar session list (focused)
-	sidebarSessionStyle = lipgloss.NewStyle().
-				Foreground(textNormal)
+	sidebarSessionStyle = textStyle(textNormal)
 
-	sidebarSessionSelectedStyle = lipgloss.NewStyle().
-					Background(primary).
-					Foreground(lipgloss.Color("#000000")).
-					Bold(true)
+	sidebarSessionSelectedStyle = barStyle(primary)
 
 	// Sidebar session list (unfocused)
-	sidebarSessionDimStyle = lipgloss.NewStyle().
-				Foreground(textMuted)
+	sidebarSessionDimStyle = textStyle(textMuted)
 
-	sidebarSessionDimSelectedStyle = lipgloss.NewStyle().
-					Background(textDim).
-					Foreground(lipgloss.Color("#000000")).
-					Bold(true)
+	sidebarSessionDimSelectedStyle = barStyle(textDim)
 
 	// --- Dialog styles ---
 
 	dialogBoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder()).
-			BorderForeground(primary).
-			Padding(1, 2)
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(primary).
+		Padding(1, 2)
 
 	dialogTitleStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(danger)
+		Bold(true).
+		Foreground(danger)
 
 	dialogTextStyle = lipgloss.NewStyle().
-			Foreground(textNormal)
+		Foreground(textNormal)
 
 	warningStyle = lipgloss.NewStyle().
-			Foreground(danger)
+		Foreground(danger)
 
 	// Dialog list items
-	selectedItemStyle = lipgloss.NewStyle().
-				Background(primary).
-				Foreground(lipgloss.Color("#000000")).
-				Bold(true).
-				PaddingLeft(1).
-				PaddingRight(1)
+	selectedItemStyle = barStyle(primary).
+		PaddingLeft(1).
+		PaddingRight(1)
 
 	normalItemStyle = lipgloss.NewStyle().
-			Foreground(textNormal).
-			PaddingLeft(1).
-			PaddingRight(1)
+		Foreground(textNormal).
+		PaddingLeft(1).
+		PaddingRight(1)
 
 	// --- General text styles ---
 
 	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(primary)
+		Bold(true).
+		Foreground(primary)
 
-	subtitleStyle = lipgloss.NewStyle().
-			Foreground(textMuted)
+	subtitleStyle = textStyle(textMuted)
 
-	metadataStyle = lipgloss.NewStyle().
-			Foreground(textMuted)
+	metadataStyle = textStyle(textMuted)
 
-	helpStyle = lipgloss.NewStyle().
-			Foreground(textMuted)
+	helpStyle = textStyle(textMuted)
 
-	dividerStyle = lipgloss.NewStyle().
-			Foreground(textDim)
+	dividerStyle = textStyle(textDim)
 
-	placeholderStyle = lipgloss.NewStyle().
-				Foreground(textDim).
-				Italic(true)
+	placeholderStyle = textStyle(textDim).
+		Italic(true)
 
 	// --- Status styles ---
 
 	errorStyle = lipgloss.NewStyle().
-			Foreground(danger).
-			Bold(true)
+		Foreground(danger).
+		Bold(true)
 
 	successStyle = lipgloss.NewStyle().
-			Foreground(success)
-
-	scrollIndicatorStyle = lipgloss.NewStyle().
-				Background(primary).
-				Foreground(lipgloss.Color("#000000")).
-				Bold(true)
-)
+		Foreground(success)
+
+	scrollIndicatorStyle = barStyle(primary)
+
+	statusModeStyle = barStyle(textMuted)
+
+	if accessible {
+		accessibleStyles()
+	}
+}
+
+// textStyle is text in one of the palette's colors. Without color, muted and
+// dim text is drawn faint instead
+func textStyle(c lipgloss.Color) lipgloss.Style {
+	s := lipgloss.NewStyle().Foreground(c)
+	if monochrome && (c == textMuted || c == textDim) {
+		s = s.Faint(true)
+	}
+	return s
+}
+
+// barStyle is bold text on a bar of color, as selections are drawn. Without
+// color the bar is reversed instead, and faint unless it's the primary one
+func barStyle(bg lipgloss.Color) lipgloss.Style {
+	s := lipgloss.NewStyle().
+		Background(bg).
+		Foreground(selectedText).
+		Bold(true)
+	if monochrome {
+		s = s.Reverse(true).Faint(bg != primary)
+	}
+	return s
+}
@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// summaryRefreshInterval is how often conversation summaries are re-read
+const summaryRefreshInterval = 15 * time.Second
+
+// sessionRowHeight is the number of sidebar lines per session: the name and
+// its conversation summary
+const sessionRowHeight = 2
+
+// summaryTickMsg triggers a periodic summary refresh
+type summaryTickMsg struct{}
+
+// summariesLoadedMsg carries the latest conversation summary per session name
+type summariesLoadedMsg struct {
+	summaries map[string]string
+}
+
+// summaryTick schedules the next summary refresh
+func summaryTick() tea.Cmd {
+	return tea.Tick(pollInterval(summaryRefreshInterval), func(time.Time) tea.Msg {
+		return summaryTickMsg{}
+	})
+}
+
+// loadSummaries reads the latest conversation summary of each active session
+// in the background
+func (m *Model) loadSummaries() tea.Cmd {
+	worktrees := make(map[string]string)
+	for _, s := range m.activeSessions() {
+		worktrees[s.Name] = s.WorktreePath
+	}
+	if len(worktrees) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		summaries := make(map[string]string, len(worktrees))
+		for name, path := range worktrees {
+			if summary := worktree.LatestSummary(path); summary != "" {
+				summaries[name] = strings.Join(strings.Fields(summary), " ")
+			}
+		}
+		return summariesLoadedMsg{summaries}
+	}
+}
@@ -0,0 +1,236 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+)
+
+// The quick switcher finds a session in any project by a few characters of
+// its project and name, like an editor's file switcher, and opens it,
+// switching projects if needed
+
+// switcherVisible is the number of matches shown at once
+const switcherVisible = 10
+
+// switcher is the open quick switcher
+type switcher struct {
+	input    textinput.Model
+	sessions []*database.Session // every project's active sessions, most recently used first
+	matches  []*database.Session
+	cursor   int
+	loading  bool
+}
+
+// switcherLoadedMsg carries the sessions the switcher searches
+type switcherLoadedMsg struct {
+	sessions []*database.Session
+	err      error
+}
+
+// openSwitcher loads every project's sessions into the quick switcher
+func (m *Model) openSwitcher() (tea.Model, tea.Cmd) {
+	if m.db == nil {
+		return m, nil
+	}
+	input := textinput.New()
+	input.Placeholder = "Session or project..."
+	input.Focus()
+	input.CharLimit = 100
+	input.Width = 40
+	m.switcher = &switcher{input: input, loading: true}
+	m.overlay = overlaySwitcher
+	m.err = nil
+	db := m.db
+	return m, tea.Batch(textinput.Blink, func() tea.Msg {
+		sessions, err := db.ListSessions("", "")
+		return switcherLoadedMsg{sessions: sessions, err: err}
+	})
+}
+
+// showSwitcherSessions fills in the switcher once the sessions are loaded
+func (m *Model) showSwitcherSessions(msg switcherLoadedMsg) {
+	s := m.switcher
+	if s == nil {
+		return
+	}
+	s.loading = false
+	if msg.err != nil {
+		m.err = msg.err
+		return
+	}
+	s.sessions = slices.DeleteFunc(msg.sessions, func(sess *database.Session) bool { return sess.Status == "archived" })
+	slices.SortStableFunc(s.sessions, func(a, b *database.Session) int {
+		return lastUsed(b).Compare(lastUsed(a))
+	})
+	s.filter()
+}
+
+// lastUsed is when a session was last opened, or else created
+func lastUsed(s *database.Session) time.Time {
+	if s.LastAccessed != nil {
+		return *s.LastAccessed
+	}
+	return s.CreatedAt
+}
+
+// filter ranks the sessions matching the query, best first
+func (s *switcher) filter() {
+	query := strings.TrimSpace(s.input.Value())
+	type match struct {
+		sess  *database.Session
+		score int
+	}
+	var matches []match
+	for _, sess := range s.sessions {
+		if score, ok := fuzzyScore(query, sess.RepoName+"/"+sess.Name); ok {
+			matches = append(matches, match{sess, score})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b match) int { return cmp.Compare(b.score, a.score) })
+	s.matches = nil
+	for _, mt := range matches {
+		s.matches = append(s.matches, mt.sess)
+	}
+	s.cursor = 0
+}
+
+// fuzzyScore reports whether query's characters appear in order in target,
+// ignoring case, and how well: characters in a row and at the start of a
+// word score more
+func fuzzyScore(query, target string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(target))
+	score, qi := 0, 0
+	prevMatched := false
+	for ti, r := range t {
+		if qi == len(q) {
+			break
+		}
+		if r != q[qi] {
+			prevMatched = false
+			continue
+		}
+		score++
+		if prevMatched {
+			score += 5
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 3
+		}
+		prevMatched = true
+		qi++
+	}
+	return score, qi == len(q)
+}
+
+// handleSwitcherKeys narrows the switcher down and opens the chosen session
+func (m *Model) handleSwitcherKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := m.switcher
+	if s == nil {
+		return m, nil
+	}
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", m.keys.Switcher:
+		return m.dismissOverlay()
+	case "up", "ctrl+p":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+		return m, nil
+	case "down", "ctrl+n":
+		if s.cursor < len(s.matches)-1 {
+			s.cursor++
+		}
+		return m, nil
+	case "enter":
+		if s.cursor >= len(s.matches) {
+			return m, nil
+		}
+		sess := s.matches[s.cursor]
+		m.switcher = nil
+		m.overlay = overlayNone
+		return m.switchToSession(sess)
+	}
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	s.filter()
+	return m, cmd
+}
+
+// switchToSession opens a session with the terminal focused, switching to
+// its project first if it isn't the current one
+func (m *Model) switchToSession(sess *database.Session) (tea.Model, tea.Cmd) {
+	if m.service == nil || m.service.RepoPath() != sess.RepoPath {
+		m.switchTo = sess.Name
+		return m, m.switchProject(&database.Project{RepoPath: sess.RepoPath, RepoName: sess.RepoName})
+	}
+	m.focus = focusTerminal
+	for i, s := range m.activeSessions() {
+		if s.Name == sess.Name {
+			return m.jumpToSession(i + 1)
+		}
+	}
+	return m, nil
+}
+
+// viewSwitcher shows the query and the sessions matching it
+func (m *Model) viewSwitcher() string {
+	s := m.switcher
+	if s == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Switch to Session"))
+	b.WriteString("\n\n")
+	b.WriteString(s.input.View())
+	b.WriteString("\n\n")
+
+	helpText := "[↑/↓] Navigate  [Enter] Open  [Esc] Cancel"
+	itemWidth := 60
+	switch {
+	case s.loading:
+		b.WriteString(m.spinner.View() + " Loading sessions...")
+		b.WriteString("\n")
+	case len(s.matches) == 0:
+		b.WriteString(metadataStyle.Render("  No matching sessions") + "\n")
+	default:
+		startIdx := 0
+		if s.cursor >= switcherVisible {
+			startIdx = s.cursor - switcherVisible + 1
+		}
+		endIdx := min(startIdx+switcherVisible, len(s.matches))
+		if startIdx > 0 {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↑ %d more", startIdx)) + "\n")
+		}
+		for i := startIdx; i < endIdx; i++ {
+			sess := s.matches[i]
+			label := fmt.Sprintf("%-38s  %s", truncate(sess.Name, 38), truncate(sess.RepoName, 18))
+			if i == s.cursor {
+				b.WriteString(selectedItemStyle.Width(itemWidth).Render(label) + "\n")
+			} else {
+				b.WriteString(normalItemStyle.Width(itemWidth).Render(label) + "\n")
+			}
+		}
+		if endIdx < len(s.matches) {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↓ %d more", len(s.matches)-endIdx)) + "\n")
+		}
+	}
+
+	if m.err != nil {
+		b.WriteString("\n" + errorStyle.Render(truncate(m.err.Error(), itemWidth)) + "\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(helpText))
+	return dialogBoxStyle.Render(b.String())
+}
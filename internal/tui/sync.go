@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// syncInterval is how often the database is checked for sessions created,
+// deleted or archived by other ATC instances
+const syncInterval = 2 * time.Second
+
+// syncTickMsg triggers a periodic check for changes by other instances
+type syncTickMsg struct{}
+
+// sessionsVersionMsg carries the database's sessions change counter
+type sessionsVersionMsg struct {
+	version int64
+}
+
+// syncTick schedules the next check
+func syncTick() tea.Cmd {
+	return tea.Tick(pollInterval(syncInterval), func(time.Time) tea.Msg {
+		return syncTickMsg{}
+	})
+}
+
+// checkSessionsVersion reads the sessions change counter in the background
+func (m *Model) checkSessionsVersion() tea.Cmd {
+	if m.db == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		version, err := m.db.SessionsVersion()
+		if err != nil {
+			return nil
+		}
+		return sessionsVersionMsg{version}
+	}
+}
+
+// updateSessionsVersion reloads the sessions when the counter moved since
+// the last check. The first check only records it
+func (m *Model) updateSessionsVersion(msg sessionsVersionMsg) tea.Cmd {
+	seen := m.sessionsVersionSeen
+	changed := msg.version != m.sessionsVersion
+	m.sessionsVersion, m.sessionsVersionSeen = msg.version, true
+	if !seen || !changed || m.service == nil {
+		return nil
+	}
+	return m.loadSessions()
+}
@@ -0,0 +1,243 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+// projectTab holds the state of an open project while another project's tab
+// is showing. The current tab's state lives directly on the Model; it is
+// captured into its projectTab on switch and restored when switching back, so
+// each project's terminals keep running and its cursor stays where it was.
+type projectTab struct {
+	service             *session.Service
+	repoName            string
+	tmuxSocket          string
+	currentBranch       string
+	sessions            []*session.Session
+	archivedTotal       int
+	cursor              int
+	scrollOffset        int
+	activeSession       *session.Session
+	pinnedSession       *session.Session
+	terminals           map[string]terminal.Terminal
+	settingUpSessions   map[string]bool
+	setupFailedSessions map[string]bool
+}
+
+// captureTab copies the current project's state into its tab
+func (m *Model) captureTab() {
+	if m.tabIndex >= len(m.tabs) {
+		return
+	}
+	m.tabs[m.tabIndex] = &projectTab{
+		service:             m.service,
+		repoName:            m.repoName,
+		tmuxSocket:          m.tmuxSocket,
+		currentBranch:       m.currentBranch,
+		sessions:            m.sessions,
+		archivedTotal:       m.archivedTotal,
+		cursor:              m.cursor,
+		scrollOffset:        m.scrollOffset,
+		activeSession:       m.activeSession,
+		pinnedSession:       m.pinnedSession,
+		terminals:           m.terminals,
+		settingUpSessions:   m.settingUpSessions,
+		setupFailedSessions: m.setupFailedSessions,
+	}
+}
+
+// restoreTab makes tab i the current project
+func (m *Model) restoreTab(i int) {
+	t := m.tabs[i]
+	m.tabIndex = i
+	m.service = t.service
+	m.repoName = t.repoName
+	m.tmuxSocket = t.tmuxSocket
+	m.currentBranch = t.currentBranch
+	m.sessions = t.sessions
+	m.archivedTotal = t.archivedTotal
+	m.cursor = t.cursor
+	m.scrollOffset = t.scrollOffset
+	m.activeSession = t.activeSession
+	m.pinnedSession = t.pinnedSession
+	m.terminals = t.terminals
+	m.settingUpSessions = t.settingUpSessions
+	m.setupFailedSessions = t.setupFailedSessions
+	m.watchRepo()
+}
+
+// resetProjectUIState clears transient UI state that belongs to whichever
+// project was showing (overlays, branch lists, selections, status messages)
+func (m *Model) resetProjectUIState() {
+	m.noProjectMode = false
+	m.overlay = overlayNone
+	m.branches = nil
+	m.filteredBranches = nil
+	m.branchesWithSessions = make(map[string]bool)
+	m.branchCursor = 0
+	m.branchScrollOffset = 0
+	m.selectedBranchName = ""
+	m.pendingSessionName = ""
+	m.selectAfterLoad = ""
+	m.activatingSession = ""
+	m.skipSetup = false
+	m.untrustedSession = nil
+	m.untrustedSetup = nil
+	m.setupPreviewed = false
+	m.selectedSession = nil
+	m.archivedList = nil
+	m.err = nil
+	m.message = ""
+	m.hasSelection = false
+	m.selecting = false
+	if m.focus == focusPinned {
+		m.focus = focusTerminal
+	}
+}
+
+// openProjectTab switches to the project's tab, opening a new tab for it if
+// it isn't open yet
+func (m *Model) openProjectTab(msg projectSwitchedMsg) tea.Cmd {
+	repoPath := msg.service.RepoPath()
+	for i, t := range m.tabs {
+		if i != m.tabIndex && t != nil && t.service != nil && t.service.RepoPath() == repoPath {
+			return m.switchTab(i)
+		}
+	}
+	if m.service != nil && m.service.RepoPath() == repoPath {
+		m.resetProjectUIState()
+		return m.loadSessions()
+	}
+
+	m.captureTab()
+	m.tabs = append(m.tabs, &projectTab{
+		service:             msg.service,
+		repoName:            msg.repoName,
+		tmuxSocket:          terminal.SocketName(repoPath),
+		currentBranch:       msg.currentBranch,
+		terminals:           make(map[string]terminal.Terminal),
+		settingUpSessions:   make(map[string]bool),
+		setupFailedSessions: make(map[string]bool),
+	})
+	m.restoreTab(len(m.tabs) - 1)
+	m.resetProjectUIState()
+	m.restoreUIState()
+	m.resizeTerminalIfNeeded()
+	return m.loadSessions()
+}
+
+// switchTab makes tab i the current project
+func (m *Model) switchTab(i int) tea.Cmd {
+	if i < 0 || i >= len(m.tabs) || i == m.tabIndex {
+		return nil
+	}
+	m.captureTab()
+	m.restoreTab(i)
+	m.resetProjectUIState()
+	m.resizeTerminalIfNeeded()
+	return m.loadSessions()
+}
+
+// cycleTab moves to the next (delta 1) or previous (delta -1) project tab
+func (m *Model) cycleTab(delta int) tea.Cmd {
+	if len(m.tabs) < 2 {
+		return nil
+	}
+	return m.switchTab((m.tabIndex + delta + len(m.tabs)) % len(m.tabs))
+}
+
+// closeTab closes the current project's tab. Its terminals are detached, so
+// agents keep running in tmux and reattach when the project is reopened.
+func (m *Model) closeTab() tea.Cmd {
+	if len(m.tabs) < 2 {
+		m.message = "Can't close the only open project"
+		return nil
+	}
+	for name := range m.terminals {
+		m.detachTerminal(name)
+	}
+	closing := m.tabIndex
+	m.tabs = append(m.tabs[:closing], m.tabs[closing+1:]...)
+	next := closing
+	if next >= len(m.tabs) {
+		next = len(m.tabs) - 1
+	}
+	m.restoreTab(next)
+	m.resetProjectUIState()
+	m.resizeTerminalIfNeeded()
+	return m.loadSessions()
+}
+
+// tabForSession returns the background tab owning the named session's
+// terminal or setup, or nil if it belongs to the current project
+func (m *Model) tabForSession(name string) *projectTab {
+	if _, ok := m.terminals[name]; ok || m.settingUpSessions[name] {
+		return nil
+	}
+	for i, t := range m.tabs {
+		if i == m.tabIndex || t == nil {
+			continue
+		}
+		if _, ok := t.terminals[name]; ok || t.settingUpSessions[name] {
+			return t
+		}
+	}
+	return nil
+}
+
+// projectFor returns the project a session belongs to, so status changes
+// from background tabs are attributed to the right repo
+func (m *Model) projectFor(name string) (string, *session.Service) {
+	if t := m.tabForSession(name); t != nil {
+		return t.repoName, t.service
+	}
+	return m.repoName, m.service
+}
+
+// setupStateFor returns the setup tracking maps of the project running the
+// named session's setup, or nils if no project is waiting on it
+func (m *Model) setupStateFor(name string) (settingUp, failed map[string]bool) {
+	if t := m.tabForSession(name); t != nil {
+		return t.settingUpSessions, t.setupFailedSessions
+	}
+	if m.settingUpSessions[name] {
+		return m.settingUpSessions, m.setupFailedSessions
+	}
+	return nil, nil
+}
+
+// tabBarHeight is the number of rows the tab strip takes above the terminal
+// pane; the strip is only shown when more than one project is open
+func (m *Model) tabBarHeight() int {
+	if len(m.tabs) > 1 {
+		return 1
+	}
+	return 0
+}
+
+// viewTabBar renders the project tab strip
+func (m *Model) viewTabBar(width int) string {
+	var parts []string
+	for i, t := range m.tabs {
+		name := m.repoName
+		if i != m.tabIndex && t != nil {
+			name = t.repoName
+		}
+		label := " " + truncate(name, 24) + " "
+		switch {
+		case i == m.tabIndex && m.focus == focusSidebar:
+			parts = append(parts, barStyle(primary).Render(label))
+		case i == m.tabIndex:
+			parts = append(parts, barStyle(textMuted).Render(label))
+		default:
+			parts = append(parts, metadataStyle.Render(label))
+		}
+	}
+	bar := strings.Join(parts, dividerStyle.Render("│"))
+	return lipgloss.NewStyle().Width(width).MaxWidth(width).Render(bar)
+}
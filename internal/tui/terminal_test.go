@@ -0,0 +1,1385 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/kevinzwang/air-traffic-control/internal/config"
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/plugin"
+	"github.com/kevinzwang/air-traffic-control/internal/procstat"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+	"github.com/kevinzwang/air-traffic-control/internal/watch"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// fakeBackend is a terminal.Backend that records what the TUI asks of it.
+type fakeBackend struct {
+	running  map[string]*fakeTerminal // terminals Exists reports
+	created  []string
+	attached []string
+	resumed  []string // conversation each new terminal's agent continues, "" for a fresh one
+}
+
+func (b *fakeBackend) New(name, worktreePath string, agent terminal.Agent, width, height int, continueSession bool, p *tea.Program, socket string) (terminal.Terminal, error) {
+	b.created = append(b.created, name)
+	resumed := ""
+	if continueSession {
+		resumed = cmp.Or(agent.Resume, "latest")
+	}
+	b.resumed = append(b.resumed, resumed)
+	return &fakeTerminal{name: name, running: true, width: width, height: height}, nil
+}
+
+func (b *fakeBackend) Attach(name string, agent terminal.Agent, width, height int, p *tea.Program, socket string) (terminal.Terminal, error) {
+	b.attached = append(b.attached, name)
+	t := b.running[name]
+	t.width, t.height = width, height
+	return t, nil
+}
+
+func (b *fakeBackend) Exists(socket, name string) bool {
+	return b.running[name] != nil
+}
+
+func (b *fakeBackend) AttachCommand(socket, name string) *exec.Cmd {
+	return nil
+}
+
+// fakeTerminal is an in-memory terminal.Terminal.
+type fakeTerminal struct {
+	name          string
+	running       bool
+	width, height int
+	keys          []tea.KeyMsg
+	respawns      int
+	scroll        int
+	detached      bool
+	focused       bool
+	window        string
+	windows       []string
+	pasted        []string
+	screen        string
+	wantsMouse    bool
+	mouse         []tea.MouseMsg
+	respawnedWith string // continueSession and flags of the last Respawn
+	worked        time.Duration
+}
+
+func (t *fakeTerminal) Name() string               { return t.name }
+func (t *fakeTerminal) SendKeys(msg tea.KeyMsg)    { t.keys = append(t.keys, msg) }
+func (t *fakeTerminal) Paste(text string)          { t.pasted = append(t.pasted, text) }
+func (t *fakeTerminal) Render() string             { return t.screen }
+func (t *fakeTerminal) Resize(width, height int)   { t.width, t.height = width, height }
+func (t *fakeTerminal) State() terminal.AgentState { return terminal.StateWaiting }
+func (t *fakeTerminal) IsRunning() bool            { return t.running }
+func (t *fakeTerminal) Close() error               { t.running = false; return nil }
+func (t *fakeTerminal) Detach()                    { t.detached = true }
+func (t *fakeTerminal) SetFocused(focused bool)    { t.focused = focused }
+func (t *fakeTerminal) Scrollback() (string, int)  { return "", 0 }
+func (t *fakeTerminal) ScrollUp(lines int)         { t.scroll += lines }
+func (t *fakeTerminal) ScrollDown(lines int)       { t.scroll = max(t.scroll-lines, 0) }
+func (t *fakeTerminal) IsScrollMode() bool         { return t.scroll > 0 }
+func (t *fakeTerminal) ScrollPosition() int        { return t.scroll }
+func (t *fakeTerminal) ExitScrollMode()            { t.scroll = 0 }
+func (t *fakeTerminal) Window() string             { return t.window }
+func (t *fakeTerminal) ShowAgent() error           { t.window = ""; return nil }
+func (t *fakeTerminal) OpenWindow(name, command string) error {
+	t.window = name
+	return nil
+}
+func (t *fakeTerminal) Windows() []string              { return t.windows }
+func (t *fakeTerminal) CycleWindow(forward bool) error { return nil }
+func (t *fakeTerminal) Respawn(continueSession bool, flags string) error {
+	t.respawns++
+	t.respawnedWith = fmt.Sprint(continueSession, " ", flags)
+	t.running = true
+	return nil
+}
+func (t *fakeTerminal) WantsMouse() bool { return t.wantsMouse }
+func (t *fakeTerminal) PID() int         { return 0 }
+func (t *fakeTerminal) SendMouse(msg tea.MouseMsg, col, row int) {
+	t.mouse = append(t.mouse, msg)
+}
+func (t *fakeTerminal) TakeWorkTime() time.Duration {
+	d := t.worked
+	t.worked = 0
+	return d
+}
+
+func newTestModel(b *fakeBackend) *Model {
+	return &Model{
+		cfg:          &config.GlobalConfig{},
+		terminals:    make(map[string]terminal.Terminal),
+		backend:      b,
+		tmuxSocket:   "atc-test",
+		windowWidth:  120,
+		windowHeight: 40,
+	}
+}
+
+func TestEnsureTerminal(t *testing.T) {
+	tests := []struct {
+		name         string
+		wrapper      *fakeTerminal // already in m.terminals
+		running      *fakeTerminal // left running by an earlier ATC
+		wantCreated  int
+		wantAttached int
+		wantRespawns int
+	}{
+		{"starts a new terminal", nil, nil, 1, 0, 0},
+		{"reuses a running wrapper", &fakeTerminal{name: "s", running: true}, nil, 0, 0, 0},
+		{"reattaches a running terminal", nil, &fakeTerminal{name: "s", running: true}, 0, 1, 0},
+		{"respawns an exited agent", nil, &fakeTerminal{name: "s"}, 0, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &fakeBackend{running: map[string]*fakeTerminal{}}
+			if tt.running != nil {
+				b.running["s"] = tt.running
+			}
+			m := newTestModel(b)
+			if tt.wrapper != nil {
+				m.terminals["s"] = tt.wrapper
+			}
+
+			sess := &session.Session{Name: "s", WorktreePath: t.TempDir()}
+			if err := m.ensureTerminal(sess, 80, 24); err != nil {
+				t.Fatalf("ensureTerminal: %v", err)
+			}
+			if len(b.created) != tt.wantCreated || len(b.attached) != tt.wantAttached {
+				t.Errorf("created %v, attached %v; want %d created, %d attached", b.created, b.attached, tt.wantCreated, tt.wantAttached)
+			}
+			ft := m.terminals["s"].(*fakeTerminal)
+			if ft.respawns != tt.wantRespawns {
+				t.Errorf("respawns = %d, want %d", ft.respawns, tt.wantRespawns)
+			}
+			if ft.width != 80 || ft.height != 24 {
+				t.Errorf("size = %dx%d, want 80x24", ft.width, ft.height)
+			}
+		})
+	}
+}
+
+func TestResumePicker(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	wt := filepath.Join(home, "wt")
+	dir := filepath.Join(home, ".claude", "projects", strings.ReplaceAll(wt, "/", "-"))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for i, id := range []string{"older", "newer"} {
+		path := filepath.Join(dir, id+".jsonl")
+		line := fmt.Sprintf(`{"type":"user","message":{"role":"user","content":"prompt %s"}}`+"\n", id)
+		if err := os.WriteFile(path, []byte(line), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(time.Duration(i-2) * time.Hour)
+		os.Chtimes(path, mtime, mtime)
+	}
+
+	b := &fakeBackend{}
+	m := newTestModel(b)
+	sess := &session.Session{Name: "s", WorktreePath: wt}
+	if !m.needsConversationChoice(sess) {
+		t.Fatal("no conversation choice with two conversations")
+	}
+	_, cmd := m.openConversationPicker(sess)
+	m.Update(cmd())
+	if p := m.resumePicker; len(p.conversations) != 2 || p.conversations[0].ID != "newer" || p.conversations[1].Summary != "prompt older" {
+		t.Fatalf("conversations = %+v, want newer then older", p.conversations)
+	}
+
+	m.handleResumeKeys(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd = m.handleResumeKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg := cmd(); msg != nil {
+		t.Fatalf("activating: %v", msg)
+	}
+	if len(b.resumed) != 1 || b.resumed[0] != "older" {
+		t.Errorf("agents resumed %q, want the older conversation", b.resumed)
+	}
+	if m.needsConversationChoice(sess) {
+		t.Error("asked again with the agent running")
+	}
+}
+
+func TestForwardKeys(t *testing.T) {
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	tests := []struct {
+		name         string
+		term         fakeTerminal
+		msg          tea.KeyMsg
+		wantKeys     int
+		wantRespawns int
+		wantScroll   bool
+	}{
+		{"sends keys to a running agent", fakeTerminal{running: true}, enter, 1, 0, false},
+		{"Enter restarts an exited agent", fakeTerminal{}, enter, 0, 1, false},
+		{"other keys are dropped when exited", fakeTerminal{}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}, 0, 0, false},
+		{"PgUp scrolls back", fakeTerminal{running: true}, tea.KeyMsg{Type: tea.KeyPgUp}, 0, 0, true},
+		{"a key leaves scroll mode", fakeTerminal{running: true, scroll: 5}, enter, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(&fakeBackend{})
+			ft := tt.term
+			m.forwardKeys(&ft, tt.msg)
+			if len(ft.keys) != tt.wantKeys {
+				t.Errorf("sent %d keys, want %d", len(ft.keys), tt.wantKeys)
+			}
+			if ft.respawns != tt.wantRespawns {
+				t.Errorf("respawns = %d, want %d", ft.respawns, tt.wantRespawns)
+			}
+			if ft.IsScrollMode() != tt.wantScroll {
+				t.Errorf("scroll mode = %v, want %v", ft.IsScrollMode(), tt.wantScroll)
+			}
+		})
+	}
+}
+
+func TestForwardPaste(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	ft := fakeTerminal{running: true, scroll: 5}
+	m.forwardKeys(&ft, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("one\ntwo"), Paste: true})
+	if len(ft.pasted) != 1 || ft.pasted[0] != "one\ntwo" || len(ft.keys) != 0 {
+		t.Errorf("pasted %q and sent %d keys, want one paste and no keys", ft.pasted, len(ft.keys))
+	}
+	if ft.IsScrollMode() {
+		t.Error("a paste should leave scroll mode")
+	}
+}
+
+func TestSyncTerminalFocus(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	active, pinned, background := &fakeTerminal{}, &fakeTerminal{}, &fakeTerminal{}
+	other := &fakeTerminal{focused: true}
+	m.terminals = map[string]terminal.Terminal{"active": active, "pinned": pinned, "background": background}
+	m.activeSession = &session.Session{Name: "active"}
+	m.pinnedSession = &session.Session{Name: "pinned"}
+	m.tabs = []*projectTab{{}, {terminals: map[string]terminal.Terminal{"other": other}}}
+
+	m.syncTerminalFocus()
+	if !active.focused || !pinned.focused {
+		t.Error("shown terminals should be focused")
+	}
+	if background.focused || other.focused {
+		t.Error("terminals that aren't shown should not be focused")
+	}
+}
+
+func TestNativeAttachResizesAll(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	active := &fakeTerminal{running: true, width: 200, height: 60}
+	background := &fakeTerminal{running: true, width: 200, height: 60}
+	m.terminals = map[string]terminal.Terminal{"active": active, "background": background}
+	m.activeSession = &session.Session{Name: "active"}
+
+	m.Update(nativeAttachFinishedMsg{})
+	tw, th := m.terminalPaneDimensions()
+	for name, ft := range map[string]*fakeTerminal{"active": active, "background": background} {
+		if ft.width != tw || ft.height != th {
+			t.Errorf("%s is %dx%d, want %dx%d", name, ft.width, ft.height, tw, th)
+		}
+	}
+}
+
+func TestSessionHealth(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	m.terminals = map[string]terminal.Terminal{
+		mainProjectTerminalKey: &fakeTerminal{name: mainProjectTerminalKey, running: true},
+	}
+	if c := m.sessionHealth(); !c.empty() {
+		t.Errorf("health with only the main project terminal = %+v, want nothing", c)
+	}
+	m.terminals["s"] = &fakeTerminal{name: "s", running: true}
+	if c := m.sessionHealth(); c != (healthCounts{waiting: 1}) {
+		t.Errorf("health = %+v, want one waiting session", c)
+	}
+}
+
+func TestRenderWindowTabs(t *testing.T) {
+	tests := []struct {
+		name     string
+		term     fakeTerminal
+		wantTabs bool
+	}{
+		{"agent only", fakeTerminal{windows: []string{""}}, false},
+		{"agent and git", fakeTerminal{windows: []string{"", "git"}, window: "git"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderWindowTabs(&tt.term, "hello world\nsecond", 40)
+			first, rest, _ := strings.Cut(got, "\n")
+			if rest != "second" {
+				t.Errorf("other lines changed: %q", rest)
+			}
+			hasTabs := strings.Contains(first, "agent") && strings.Contains(first, "git")
+			if hasTabs != tt.wantTabs {
+				t.Errorf("first line = %q, want tabs %v", first, tt.wantTabs)
+			}
+			if tt.wantTabs && (!strings.HasPrefix(first, "hello world") || ansi.StringWidth(first) != 40) {
+				t.Errorf("first line = %q, want the content kept and width 40", first)
+			}
+		})
+	}
+}
+
+func TestMousePassthrough(t *testing.T) {
+	click := tea.MouseMsg{X: 100, Y: 10, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+	tests := []struct {
+		name        string
+		term        fakeTerminal
+		mouseSelect bool
+		wantPassed  bool
+	}{
+		{"app uses the mouse", fakeTerminal{running: true, wantsMouse: true}, false, true},
+		{"app doesn't use the mouse", fakeTerminal{running: true}, false, false},
+		{"switched to selecting", fakeTerminal{running: true, wantsMouse: true}, true, false},
+		{"scrolled back", fakeTerminal{running: true, wantsMouse: true, scroll: 3}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(&fakeBackend{})
+			ft := tt.term
+			m.activeSession = &session.Session{Name: "s"}
+			m.terminals["s"] = &ft
+			m.mouseSelect = tt.mouseSelect
+			m.handleMouseMsg(click)
+			if passed := len(ft.mouse) == 1; passed != tt.wantPassed {
+				t.Errorf("passed through = %v, want %v", passed, tt.wantPassed)
+			}
+			if m.selecting == tt.wantPassed {
+				t.Errorf("selecting = %v, want %v", m.selecting, !tt.wantPassed)
+			}
+		})
+	}
+}
+
+func TestAutoRespawn(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	m.cfg.AutoRespawn = config.AutoRespawnConfig{Enabled: true, Delay: time.Millisecond, MaxRestarts: 2}
+	ft := &fakeTerminal{name: "s"}
+	m.terminals["s"] = ft
+	crash := terminal.TerminalExitedMsg{Name: "s", Crashed: true}
+
+	if m.autoRespawn(terminal.TerminalExitedMsg{Name: "s"}) != nil {
+		t.Error("a clean exit should not be restarted")
+	}
+	for i := range 2 {
+		if m.autoRespawn(crash) == nil {
+			t.Fatalf("crash %d should be restarted", i+1)
+		}
+		m.handleRespawn(respawnMsg{name: "s"})
+		ft.running = false
+	}
+	if ft.respawns != 2 {
+		t.Errorf("respawns = %d, want 2", ft.respawns)
+	}
+	if m.autoRespawn(crash) != nil || !m.crashLooped("s") {
+		t.Error("the third crash in a row should stop restarting and flag the session")
+	}
+
+	// Once the user restarts it, crashes are counted afresh
+	ft.running = true
+	if m.crashLooped("s") {
+		t.Error("a restarted session should not be flagged")
+	}
+	if m.autoRespawn(crash) == nil {
+		t.Error("a crash after the user restarted it should be restarted")
+	}
+}
+
+func TestRunawayWarning(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	start := time.Now()
+	sample := func(after, cpu time.Duration) {
+		m.updateResources(resourcesSampledMsg{
+			at:    start.Add(after),
+			usage: map[string]procstat.Usage{"s": {CPUTime: cpu, RSS: 1 << 20}},
+		})
+	}
+
+	sample(0, 0)
+	sample(time.Minute, 30*time.Second)
+	if r := m.resources["s"]; r.cpu != 50 || !r.hotSince.IsZero() {
+		t.Fatalf("cpu = %v, hotSince = %v; want 50%% and not hot", r.cpu, r.hotSince)
+	}
+	sample(2*time.Minute, 90*time.Second)
+	sample(3*time.Minute, 150*time.Second)
+	if m.message != "" {
+		t.Fatalf("warned after a minute: %q", m.message)
+	}
+	sample(4*time.Minute, 210*time.Second)
+	if !strings.Contains(m.message, "'s'") {
+		t.Fatalf("message = %q, want a warning about s", m.message)
+	}
+
+	m.message = ""
+	sample(5*time.Minute, 270*time.Second)
+	if m.message != "" {
+		t.Error("should warn only once per busy stretch")
+	}
+	sample(6*time.Minute, 270*time.Second)
+	if r := m.resources["s"]; r.warned || !r.hotSince.IsZero() {
+		t.Error("an idle agent should no longer be flagged")
+	}
+
+	m.updateResources(resourcesSampledMsg{at: start.Add(7 * time.Minute)})
+	if _, ok := m.resources["s"]; ok {
+		t.Error("an agent that stopped should be forgotten")
+	}
+}
+
+func TestRestartMenu(t *testing.T) {
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	tests := []struct {
+		name string
+		keys []tea.KeyMsg
+		want string
+	}{
+		{"enter continues", []tea.KeyMsg{enter}, "true "},
+		{"down then enter starts fresh", []tea.KeyMsg{{Type: tea.KeyDown}, enter}, "false "},
+		{"f starts fresh", []tea.KeyMsg{key("f")}, "false "},
+		{"flags", []tea.KeyMsg{key("r"), key("--model opus"), enter}, "false --continue --model opus"},
+		{"esc leaves the flags", []tea.KeyMsg{key("r"), {Type: tea.KeyEscape}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(&fakeBackend{})
+			ft := fakeTerminal{name: "s"}
+			for _, k := range tt.keys {
+				m.forwardKeys(&ft, k)
+			}
+			if ft.respawnedWith != tt.want {
+				t.Errorf("respawned with %q, want %q", ft.respawnedWith, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuit(t *testing.T) {
+	tests := []struct {
+		name        string
+		onQuit      string
+		running     bool
+		keys        []string
+		wantQuit    bool
+		wantKilled  bool
+		wantOverlay overlay
+	}{
+		{"no agents running", "ask", false, nil, true, false, overlayNone},
+		{"asks", "ask", true, nil, false, false, overlayQuitConfirm},
+		{"detach", "ask", true, []string{"d"}, true, false, overlayQuitConfirm},
+		{"kill", "ask", true, []string{"k"}, true, true, overlayQuitConfirm},
+		{"cancel", "ask", true, []string{"n"}, false, false, overlayNone},
+		{"configured detach", "detach", true, nil, true, false, overlayNone},
+		{"configured kill", "kill", true, nil, true, true, overlayNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(&fakeBackend{})
+			m.cfg.OnQuit = tt.onQuit
+			ft := &fakeTerminal{name: "s", running: tt.running}
+			m.terminals["s"] = ft
+
+			_, cmd := m.handleQuit()
+			for _, k := range tt.keys {
+				_, cmd = m.handleQuitConfirmKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+			}
+
+			quit := false
+			if cmd != nil {
+				_, quit = cmd().(tea.QuitMsg)
+			}
+			if quit != tt.wantQuit {
+				t.Errorf("quit = %v, want %v", quit, tt.wantQuit)
+			}
+			if killed := tt.running && !ft.running; killed != tt.wantKilled {
+				t.Errorf("killed = %v, want %v", killed, tt.wantKilled)
+			}
+			if quit && !tt.wantKilled && !ft.detached {
+				t.Error("terminal not detached")
+			}
+			if m.overlay != tt.wantOverlay {
+				t.Errorf("overlay = %v, want %v", m.overlay, tt.wantOverlay)
+			}
+		})
+	}
+}
+
+func TestUIStateRestore(t *testing.T) {
+	dir := t.TempDir()
+	db, err := database.Open(filepath.Join(dir, "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	svc, err := session.NewService(db, dir, config.DefaultGlobalConfig(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions := []*session.Session{{Name: "a"}, {Name: "b"}}
+
+	m := newTestModel(&fakeBackend{})
+	m.db, m.service = db, svc
+	m.tabs = []*projectTab{{}}
+	m.sessions, m.cursor, m.scrollOffset, m.focus = sessions, 1, 1, focusTerminal
+	m.saveUIState()
+
+	m = newTestModel(&fakeBackend{})
+	m.db, m.service = db, svc
+	m.restoreUIState()
+	m.Update(sessionsLoadedMsg{sessions: sessions, archived: 1})
+	if m.cursor != 1 || m.scrollOffset != 1 || m.focus != focusTerminal {
+		t.Errorf("cursor, scroll, focus = %d, %d, %v; want 1, 1, terminal", m.cursor, m.scrollOffset, m.focus)
+	}
+	if last, _ := db.GetSetting(settingLastProject); last != dir {
+		t.Errorf("last project = %q, want %q", last, dir)
+	}
+}
+
+func TestPendingPrompt(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	m.pendingPrompts = map[string]string{"pr-1": "Review it"}
+	ft := &fakeTerminal{name: "pr-1", running: true}
+	m.terminals["pr-1"] = ft
+
+	m.Update(terminal.TerminalStateMsg{Name: "pr-1", State: terminal.StateWorking})
+	if len(ft.pasted) != 0 {
+		t.Fatal("prompt pasted while the agent was starting")
+	}
+	m.Update(terminal.TerminalStateMsg{Name: "pr-1", State: terminal.StateWaiting})
+	m.Update(terminal.TerminalStateMsg{Name: "pr-1", State: terminal.StateWaiting})
+	if len(ft.pasted) != 1 || ft.pasted[0] != "Review it" {
+		t.Errorf("pasted %q, want the prompt once", ft.pasted)
+	}
+}
+
+func TestBaseSyncConflicts(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	ft := &fakeTerminal{name: "s", running: true}
+	m.terminals["s"] = ft
+	m.baseSync = &baseSync{session: &session.Session{Name: "s"}, base: "main", strategy: "rebase", running: true}
+	m.overlay = overlayBaseSync
+
+	m.handleBaseSyncKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	if len(ft.pasted) != 0 {
+		t.Fatal("keys acted on a sync that is still running")
+	}
+
+	m.finishBaseSync(baseSyncDoneMsg{conflicts: []string{"a.go", "b.go"}})
+	if m.overlay != overlayBaseSync {
+		t.Fatal("conflicts closed the sync overlay")
+	}
+	m.handleBaseSyncKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	if len(ft.pasted) != 1 || !strings.Contains(ft.pasted[0], "a.go, b.go") || !strings.Contains(ft.pasted[0], "rebase --continue") {
+		t.Errorf("pasted %q, want a prompt to resolve a.go and b.go", ft.pasted)
+	}
+	if len(ft.keys) != 1 || ft.keys[0].Type != tea.KeyEnter {
+		t.Errorf("sent keys %v, want the prompt submitted", ft.keys)
+	}
+	if m.overlay != overlayNone || m.baseSync != nil {
+		t.Error("handing the conflicts over left the overlay open")
+	}
+}
+
+func TestChangedFilesEscape(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	m.changedFiles = &changedFiles{session: &session.Session{Name: "s"}, base: "main", loading: true}
+	m.overlay = overlayChangedFiles
+	m.showChangedFiles(changedFilesMsg{files: []worktree.ChangedFile{{Status: 'M', Path: "a.go"}}})
+	m.showFileDiff(fileDiffMsg{diff: "@@ -1 +1 @@\n-a\n+b\n"})
+	if got := len(m.changedFiles.diff); got != 3 {
+		t.Fatalf("diff has %d lines, want 3", got)
+	}
+
+	esc := tea.KeyMsg{Type: tea.KeyEsc}
+	m.handleChangedFilesKeys(esc)
+	if m.overlay != overlayChangedFiles || m.changedFiles == nil || m.changedFiles.diff != nil {
+		t.Fatal("Esc in the diff viewer didn't go back to the file list")
+	}
+	m.handleChangedFilesKeys(esc)
+	if m.overlay != overlayNone || m.changedFiles != nil {
+		t.Error("Esc in the file list didn't close it")
+	}
+}
+
+func TestVerifyBadges(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	s := &session.Session{Name: "s", WorktreePath: "/wt/s"}
+	if got := m.verifySummary(s, "editing"); got != "editing" {
+		t.Errorf("summary without a run = %q", got)
+	}
+	m.verifyRuns = map[string]*verifyRun{s.WorktreePath: {command: "make test", running: true, started: time.Now()}}
+	if got := m.verifySummary(s, "editing"); got != "… verifying · editing" {
+		t.Errorf("summary while running = %q", got)
+	}
+	m.finishVerify(verifyDoneMsg{worktreePath: s.WorktreePath, name: s.Name, output: "FAIL\n", err: fmt.Errorf("exit status 1")})
+	if got := m.verifySummary(s, ""); got != "✗ verify failed" {
+		t.Errorf("summary after a failure = %q", got)
+	}
+	m.verifySession = s
+	if lines := m.verifyLogLines(); len(lines) != 1 || lines[0] != "FAIL" {
+		t.Errorf("log lines = %q, want [FAIL]", lines)
+	}
+}
+
+func TestPromptQueue(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	ft := &fakeTerminal{name: "s", running: true}
+	m.terminals["s"] = ft
+	m.promptQueues = map[string][]string{"s": {"first", "second"}}
+
+	m.sendQueuedPrompt("s")
+	if len(ft.pasted) != 1 || ft.pasted[0] != "first" || len(ft.keys) != 1 {
+		t.Fatalf("pasted %q with keys %v, want first sent", ft.pasted, ft.keys)
+	}
+	m.sendQueuedPrompt("s")
+	if len(ft.pasted) != 1 {
+		t.Fatal("sent the next prompt before the agent started on the last")
+	}
+
+	m.Update(terminal.TerminalStateMsg{Name: "s", State: terminal.StateWorking})
+	m.Update(terminal.TerminalStateMsg{Name: "s", State: terminal.StateWaiting})
+	if len(ft.pasted) != 2 || ft.pasted[1] != "second" {
+		t.Fatalf("pasted %q, want second sent once the agent waited again", ft.pasted)
+	}
+	if _, ok := m.promptQueues["s"]; ok {
+		t.Error("emptied queue was kept")
+	}
+}
+
+func TestRateLimitPausesQueue(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	m.cfg.PauseQueueOnLimit = true
+	limited := &fakeTerminal{name: "a", running: true, screen: "> fix it\n  ⎿  5-hour limit reached ∙ resets 3pm\n     /upgrade to increase your usage limit.\n\n> \n"}
+	other := &fakeTerminal{name: "b", running: true}
+	m.terminals["a"] = limited
+	m.terminals["b"] = other
+	m.promptQueues = map[string][]string{"a": {"next"}, "b": {"later"}}
+
+	m.Update(terminal.TerminalStateMsg{Name: "a", State: terminal.StateWaiting})
+	m.Update(terminal.TerminalStateMsg{Name: "b", State: terminal.StateWaiting})
+	if len(limited.pasted) != 0 || len(other.pasted) != 0 {
+		t.Fatalf("sent %q and %q while the usage limit holds", limited.pasted, other.pasted)
+	}
+	if m.viewRateLimitBanner(40) == "" {
+		t.Error("no banner for the usage limit")
+	}
+
+	// The same message still on screen doesn't count again once it lifts
+	l := m.rateLimits["a"]
+	l.Reset = time.Now().Add(-time.Second)
+	m.rateLimits["a"] = l
+	m.Update(rateLimitResetMsg{})
+	if len(limited.pasted) != 1 || len(other.pasted) != 1 {
+		t.Fatalf("sent %q and %q, want both queues resumed once the limit reset", limited.pasted, other.pasted)
+	}
+	delete(m.queueSent, "a")
+	m.promptQueues["a"] = []string{"again"}
+	m.Update(terminal.TerminalStateMsg{Name: "a", State: terminal.StateWaiting})
+	if len(limited.pasted) != 2 {
+		t.Errorf("pasted %q, want the stale limit message ignored", limited.pasted)
+	}
+}
+
+func TestHistorySearch(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	messages := []worktree.ConversationMessage{{Role: worktree.RoleUser, Text: "find the needle"}}
+	for i := range 60 {
+		messages = append(messages, worktree.ConversationMessage{Role: worktree.RoleAssistant, Text: fmt.Sprintf("line %d", i)})
+	}
+	messages = append(messages,
+		worktree.ConversationMessage{Role: worktree.RoleTool, Text: "Grep: needle"},
+		worktree.ConversationMessage{Role: worktree.RoleUser, Text: "thanks"},
+	)
+	m.history = &conversationHistory{session: &session.Session{Name: "s"}, loading: true}
+	m.overlay = overlayHistory
+	m.showHistory(historyLoadedMsg{messages: messages})
+	if m.history.scroll != m.maxHistoryScroll() || m.history.scroll == 0 {
+		t.Fatalf("scroll = %d, want the end (%d)", m.history.scroll, m.maxHistoryScroll())
+	}
+
+	m.history.scroll = 0
+	for _, key := range []string{"/", "N", "e", "e", "d", "l", "e"} {
+		m.handleHistoryKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+	m.handleHistoryKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	lines := m.historyLines()
+	if len(m.history.matches) != 2 {
+		t.Fatalf("matches = %v, want the prompt and the tool call", m.history.matches)
+	}
+	if got := lines[m.history.matches[m.history.match]].text; got != "find the needle" {
+		t.Errorf("first match is %q, want the prompt", got)
+	}
+	m.handleHistoryKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	current := m.history.matches[m.history.match]
+	if lines[current].role != worktree.RoleTool || current < m.history.scroll || current >= m.history.scroll+m.diffVisibleLines() {
+		t.Errorf("n moved to line %d (%q) with the view at %d", current, lines[current].text, m.history.scroll)
+	}
+}
+
+func TestHandoffs(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	review := &fakeTerminal{name: "review", running: true}
+	docs := &fakeTerminal{name: "docs", running: true}
+	m.terminals["review"] = review
+	m.terminals["docs"] = docs
+	m.handoffs = map[string][]handoff{"impl": {
+		{target: "review", prompt: "review impl"},
+		{target: "docs", prompt: "document impl"},
+	}}
+
+	m.verifyRuns = map[string]*verifyRun{"/wt/impl": {running: true}}
+	m.finishVerify(verifyDoneMsg{worktreePath: "/wt/impl", name: "impl", err: fmt.Errorf("exit status 1")})
+	if len(review.pasted) != 0 || len(m.handoffs["impl"]) != 2 {
+		t.Fatal("handed off after a failed verify run")
+	}
+
+	m.verifyRuns["/wt/impl"].running = true
+	m.finishVerify(verifyDoneMsg{worktreePath: "/wt/impl", name: "impl"})
+	if len(review.pasted) != 1 || review.pasted[0] != "review impl" {
+		t.Errorf("review pasted %q, want its hand-off", review.pasted)
+	}
+	if len(docs.pasted) != 1 || docs.pasted[0] != "document impl" {
+		t.Errorf("docs pasted %q, want its hand-off", docs.pasted)
+	}
+	if _, ok := m.handoffs["impl"]; ok {
+		t.Error("hand-offs were kept after running")
+	}
+}
+
+func TestForkName(t *testing.T) {
+	tests := []struct {
+		sessions []string
+		want     string
+	}{
+		{[]string{"api"}, "api-fork"},
+		{[]string{"api", "api-fork"}, "api-fork-2"},
+		{[]string{"api", "api-fork", "api-fork-2"}, "api-fork-3"},
+	}
+	for _, tt := range tests {
+		m := newTestModel(&fakeBackend{})
+		for _, name := range tt.sessions {
+			m.sessions = append(m.sessions, &session.Session{Name: name})
+		}
+		if got := m.forkName("api"); got != tt.want {
+			t.Errorf("forkName with %v = %q, want %q", tt.sessions, got, tt.want)
+		}
+	}
+}
+
+func TestStatusBar(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	m.activeSession = &session.Session{Name: "api", BranchName: "feature/api"}
+	m.terminals["api"] = &fakeTerminal{name: "api", running: true, scroll: 12}
+	m.focus = focusTerminal
+	m.message = "Session 'api' restored"
+
+	bar := ansi.Strip(m.viewStatusBar())
+	for _, want := range []string{"TERMINAL", "api (feature/api)", "waiting", "scrolled back 12 lines", "Session 'api' restored"} {
+		if !strings.Contains(bar, want) {
+			t.Errorf("status bar %q is missing %q", bar, want)
+		}
+	}
+	if w := ansi.StringWidth(bar); w != m.windowWidth {
+		t.Errorf("status bar is %d wide, want %d", w, m.windowWidth)
+	}
+
+	// A long error keeps the mode and fits the width
+	m.err = fmt.Errorf("%s", strings.Repeat("x", 300))
+	bar = ansi.Strip(m.viewStatusBar())
+	if !strings.HasPrefix(bar, " TERMINAL ") || ansi.StringWidth(bar) > m.windowWidth {
+		t.Errorf("status bar with a long error = %q", bar)
+	}
+}
+
+func TestJumpToSession(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	m.sessions = []*session.Session{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	m.terminals["b"] = &fakeTerminal{name: "b", running: true}
+	m.terminals["c"] = &fakeTerminal{name: "c", running: true}
+	m.focus = focusTerminal
+
+	for _, tc := range []struct {
+		key  tea.KeyMsg
+		want string
+	}{
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}, Alt: true}, "b"},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'3'}, Alt: true}, "c"},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'9'}, Alt: true}, "c"}, // no ninth session
+	} {
+		_, cmd := m.handleKeyMsg(tc.key)
+		if cmd != nil {
+			cmd()
+		}
+		if m.activeSession == nil || m.activeSession.Name != tc.want || m.focus != focusTerminal {
+			t.Errorf("%s: active session = %v, focus %v; want %s with the terminal focused", tc.key, m.activeSession, m.focus, tc.want)
+		}
+	}
+	if _, ok := jumpNumber(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}}); ok {
+		t.Error("2 without Alt is a jump")
+	}
+}
+
+func TestCycleSession(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	m.sessions = []*session.Session{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	for _, s := range m.sessions {
+		m.terminals[s.Name] = &fakeTerminal{name: s.Name, running: true}
+	}
+	m.keys = defaultKeyMap()
+	m.focus = focusTerminal
+	m.activeSession = m.sessions[1]
+
+	next := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}, Alt: true}
+	prev := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}, Alt: true}
+	for _, tc := range []struct {
+		key  tea.KeyMsg
+		want string
+	}{
+		{next, "c"},
+		{next, "a"}, // wraps around
+		{prev, "c"},
+		{prev, "b"},
+	} {
+		_, cmd := m.handleKeyMsg(tc.key)
+		if cmd != nil {
+			cmd()
+		}
+		if m.activeSession.Name != tc.want || m.cursor != slices.IndexFunc(m.sessions, func(s *session.Session) bool { return s.Name == tc.want }) {
+			t.Errorf("%s: active session %s, cursor %d; want %s", tc.key, m.activeSession.Name, m.cursor, tc.want)
+		}
+		if m.focus != focusTerminal {
+			t.Errorf("%s: focus moved off the terminal", tc.key)
+		}
+	}
+}
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		query, target string
+		ok            bool
+	}{
+		{"", "atc/fix-login", true},
+		{"fl", "atc/fix-login", true},
+		{"atc/fl", "atc/fix-login", true},
+		{"FIX", "atc/fix-login", true},
+		{"lf", "atc/fix-login", false},
+		{"fixx", "atc/fix-login", false},
+	}
+	for _, tt := range tests {
+		if _, ok := fuzzyScore(tt.query, tt.target); ok != tt.ok {
+			t.Errorf("fuzzyScore(%q, %q) matched = %v, want %v", tt.query, tt.target, ok, tt.ok)
+		}
+	}
+
+	// A run of characters beats the same characters scattered, and the start
+	// of a word beats its middle
+	run, _ := fuzzyScore("log", "atc/login")
+	scattered, _ := fuzzyScore("log", "atc/lazy-org-go")
+	if run <= scattered {
+		t.Errorf("run scored %d, scattered %d", run, scattered)
+	}
+	start, _ := fuzzyScore("l", "api/login")
+	middle, _ := fuzzyScore("l", "api/ui-pull")
+	if start <= middle {
+		t.Errorf("word start scored %d, middle %d", start, middle)
+	}
+}
+
+func TestWindowTitle(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	if got := m.windowTitle(); got != "ATC" {
+		t.Errorf("title without a project = %q", got)
+	}
+	m.repoName = "atc"
+	if m.updateWindowTitle() == nil || m.shownTitle != "ATC — atc" {
+		t.Errorf("title = %q, want it set to the project", m.shownTitle)
+	}
+	if m.updateWindowTitle() != nil {
+		t.Error("unchanged title was set again")
+	}
+	m.activeSession = &session.Session{Name: "api"}
+	if m.updateWindowTitle() == nil || m.shownTitle != "ATC — atc/api" {
+		t.Errorf("title = %q, want it to name the session", m.shownTitle)
+	}
+}
+
+func TestLogRing(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	m.err = fmt.Errorf("first")
+	m.captureLog()
+	m.captureLog() // still showing, not logged again
+	m.err = nil
+	m.message = "done"
+	m.captureLog()
+	m.err = fmt.Errorf("first")
+	m.captureLog()
+
+	var texts []string
+	for _, e := range m.log.newest(false) {
+		texts = append(texts, e.text)
+	}
+	if want := []string{"first", "done", "first"}; !slices.Equal(texts, want) {
+		t.Errorf("log = %q, want %q", texts, want)
+	}
+	if errs := m.log.newest(true); len(errs) != 2 {
+		t.Errorf("errors only = %d entries, want 2", len(errs))
+	}
+
+	// Once full, the oldest entries are dropped
+	for i := range logLimit + 3 {
+		m.addLog(logEvent, fmt.Sprint(i))
+	}
+	entries := m.log.newest(false)
+	if len(entries) != logLimit || entries[0].text != fmt.Sprint(logLimit+2) || entries[logLimit-1].text != "3" {
+		t.Errorf("full log has %d entries, newest %q, oldest %q", len(entries), entries[0].text, entries[len(entries)-1].text)
+	}
+}
+
+func TestExitTo(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	wt := t.TempDir()
+	m := newTestModel(&fakeBackend{})
+	m.keys = defaultKeyMap()
+	m.cfg = &config.GlobalConfig{Agent: "claude", Shell: "/bin/zsh"}
+	sess := &session.Session{Name: "api", WorktreePath: wt}
+
+	if got := m.exitToCommand(sess, false); !slices.Equal(got.Args, []string{"/bin/zsh"}) || got.Dir != wt {
+		t.Errorf("shell = %q in %s", got.Args, got.Dir)
+	}
+	if got := m.exitToCommand(sess, true).Args; !slices.Equal(got, []string{"/bin/zsh", "-c", "claude"}) {
+		t.Errorf("agent without a conversation = %q", got)
+	}
+
+	// With a conversation, the agent continues it and the copy in ATC ends
+	projectDir := filepath.Join(home, ".claude", "projects", strings.NewReplacer("/", "-", ".", "-").Replace(wt))
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "c.jsonl"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	term := &fakeTerminal{running: true}
+	m.terminals["api"] = term
+	m.selectedSession = sess
+	m.overlay = overlayExitTo
+	if _, cmd := m.handleExitToKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")}); cmd == nil {
+		t.Fatal("didn't quit")
+	}
+	if got := m.ExitCommand().Args; !slices.Equal(got, []string{"/bin/zsh", "-c", "claude --continue"}) {
+		t.Errorf("agent with a conversation = %q", got)
+	}
+	if term.running {
+		t.Error("agent left running in ATC")
+	}
+}
+
+func TestAccessibleMode(t *testing.T) {
+	SetAccessible(true)
+	t.Cleanup(func() { SetAccessible(false) })
+
+	m := newTestModel(&fakeBackend{})
+	m.keys = defaultKeyMap()
+	m.repoName = "atc"
+	m.sessions = []*session.Session{{Name: "api"}, {Name: "web"}}
+	m.terminals["web"] = &fakeTerminal{name: "web", running: true}
+	m.summaries = map[string]string{"web": "fixing the login form"}
+	m.cursor = 1
+
+	view := ansi.Strip(m.View())
+	for _, want := range []string{"  1. api, not started", "> 2. web, waiting: fixing the login form"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("sidebar is missing %q:\n%s", want, view)
+		}
+	}
+	if strings.ContainsAny(view, "┌─│└") {
+		t.Errorf("sidebar has box drawing:\n%s", view)
+	}
+
+	m.addLog(logEvent, "web working")
+	m.addLog(logEvent, "web waiting")
+	m.addLog(logError, "push failed")
+	if want := []string{"web waiting", "Error: push failed"}; !slices.Equal(m.announcements, want) {
+		t.Errorf("announcements = %q, want %q", m.announcements, want)
+	}
+	if m.printAnnouncements() == nil || m.announcements != nil {
+		t.Error("announcements weren't printed")
+	}
+}
+
+func TestASCIIOnly(t *testing.T) {
+	if got, want := toASCII("┌─ api ─┐ ↑ 3 more · 日本…"), "+- api -+ ^ 3 more . ????."; got != want {
+		t.Errorf("toASCII = %q, want %q", got, want)
+	}
+
+	m := newTestModel(&fakeBackend{})
+	m.keys = defaultKeyMap()
+	m.sidebarWidth = defaultSidebarWidth
+	m.repoName = "atc"
+	m.sessions = []*session.Session{{Name: "api"}, {Name: "web"}}
+	unicodeView := m.View()
+
+	SetASCII(true)
+	t.Cleanup(func() { SetASCII(false) })
+	view := m.View()
+	if i := strings.IndexFunc(view, func(r rune) bool { return r > unicode.MaxASCII }); i >= 0 {
+		t.Errorf("view has %q at %d", []rune(view[i:])[0], i)
+	}
+	got, want := strings.Split(view, "\n"), strings.Split(unicodeView, "\n")
+	for i := range want {
+		if ansi.StringWidth(got[i]) != ansi.StringWidth(want[i]) {
+			t.Errorf("line %d is %d wide, was %d: %q", i, ansi.StringWidth(got[i]), ansi.StringWidth(want[i]), got[i])
+		}
+	}
+}
+
+func TestReducedMotion(t *testing.T) {
+	SetReducedMotion(true)
+	t.Cleanup(func() { SetReducedMotion(false) })
+
+	m := NewModel(nil, &config.GlobalConfig{}, nil, "atc", "main")
+	frame := m.spinner.View()
+	for range 3 {
+		if _, cmd := m.Update(m.spinner.Tick()); cmd != nil {
+			t.Error("spinner tick scheduled another frame")
+		}
+	}
+	if got := m.spinner.View(); got != frame {
+		t.Errorf("spinner moved from %q to %q", frame, got)
+	}
+	if got := pollInterval(syncInterval); got != 4*syncInterval {
+		t.Errorf("pollInterval = %v, want %v", got, 4*syncInterval)
+	}
+	if terminal.BackgroundPollInterval != 4*backgroundPollInterval {
+		t.Errorf("BackgroundPollInterval = %v, want %v", terminal.BackgroundPollInterval, 4*backgroundPollInterval)
+	}
+
+	SetReducedMotion(false)
+	if terminal.BackgroundPollInterval != backgroundPollInterval {
+		t.Errorf("BackgroundPollInterval = %v after turning reduced motion off", terminal.BackgroundPollInterval)
+	}
+}
+
+func TestBranchCache(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	m.overlay = overlaySelectBaseBranch
+	m.defaultBaseBranch = "main"
+	m.initBranchInput()
+
+	listed := time.Now()
+	first := branchesLoadedMsg{repoPath: "/repo", refs: listed, sessionsVersion: 3, branches: []string{"api", "main", "web"}}
+	if m.cacheBranches(first) {
+		t.Error("first listing counted as a refresh")
+	}
+	m.showBranches(first, false)
+	if got := m.getSelectedBaseBranch(true); got != "main" {
+		t.Errorf("first listing selected %q, want main", got)
+	}
+
+	m.branchCursor = 3
+	again := branchesLoadedMsg{repoPath: "/repo", refs: listed.Add(time.Second), sessionsVersion: 3, branches: []string{"api", "db", "main", "web"}}
+	if !m.cacheBranches(again) {
+		t.Error("second listing not counted as a refresh")
+	}
+	m.showBranches(again, true)
+	if got := m.getSelectedBaseBranch(true); got != "web" {
+		t.Errorf("refresh moved the cursor to %q, want web", got)
+	}
+
+	c := m.branchCaches["/repo"]
+	tests := []struct {
+		refs            time.Time
+		refsOK          bool
+		sessionsVersion int64
+		want            bool
+	}{
+		{listed.Add(time.Second), true, 3, true},
+		{listed.Add(2 * time.Second), true, 3, false},
+		{listed.Add(time.Second), true, 4, false},
+		{listed.Add(time.Second), false, 3, false},
+	}
+	for _, tt := range tests {
+		if got := c.current(tt.refs, tt.refsOK, tt.sessionsVersion); got != tt.want {
+			t.Errorf("current(%v, %v, %d) = %v, want %v", tt.refs, tt.refsOK, tt.sessionsVersion, got, tt.want)
+		}
+	}
+	if (*branchCache)(nil).current(listed, true, 3) {
+		t.Error("a missing cache is current")
+	}
+}
+
+func TestArchivedPages(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	m.keys = defaultKeyMap()
+	dir := t.TempDir()
+	svc, err := session.NewService(nil, dir, config.DefaultGlobalConfig(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.service = svc
+	m.archivedTotal = 150
+	m.overlay = overlayArchivedSessions
+
+	page := func(offset, n int) archivedLoadedMsg {
+		msg := archivedLoadedMsg{repoPath: svc.RepoPath(), offset: offset}
+		for i := range n {
+			msg.sessions = append(msg.sessions, &session.Session{Name: fmt.Sprintf("old-%d", offset+i)})
+		}
+		return msg
+	}
+	m.Update(page(0, archivedPageSize))
+	if len(m.archivedList) != archivedPageSize {
+		t.Fatalf("loaded %d archived sessions, want %d", len(m.archivedList), archivedPageSize)
+	}
+	if cmd := m.moreArchived(m.archivedCursor); cmd != nil {
+		t.Error("fetched the next page with the cursor at the top")
+	}
+
+	m.archivedCursor = archivedPageSize - archivedPrefetch - 1
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyDown}); cmd == nil || !m.archivedLoading {
+		t.Fatal("didn't fetch the next page near the end of the first")
+	}
+	if cmd := m.moreArchived(m.archivedCursor); cmd != nil {
+		t.Error("fetched the next page twice")
+	}
+	m.Update(page(archivedPageSize, 50))
+	if len(m.archivedList) != 150 || m.archivedList[archivedPageSize].Name != "old-100" {
+		t.Fatalf("archived list after the second page: %d sessions", len(m.archivedList))
+	}
+
+	m.archivedCursor = 149
+	if cmd := m.moreArchived(m.archivedCursor); cmd != nil {
+		t.Error("fetched past the last archived session")
+	}
+	m.Update(page(0, 10))
+	if len(m.archivedList) != 10 || m.archivedCursor != 9 {
+		t.Errorf("after a reload: %d sessions, cursor %d; want 10, 9", len(m.archivedList), m.archivedCursor)
+	}
+}
+
+func TestArchivedInline(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	m.keys = defaultKeyMap()
+	m.sidebarWidth = defaultSidebarWidth
+	dir := t.TempDir()
+	svc, err := session.NewService(nil, dir, config.DefaultGlobalConfig(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.service = svc
+	m.sessions = []*session.Session{{Name: "one"}, {Name: "two"}}
+	m.archivedTotal = 2
+	m.cursor = 1
+
+	press := func(key string) tea.Cmd {
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		return cmd
+	}
+	if cmd := press("."); cmd == nil || !m.archivedInline || !m.archivedLoading {
+		t.Fatal("showing archived sessions didn't load them")
+	}
+	m.Update(archivedLoadedMsg{repoPath: svc.RepoPath(), sessions: []*session.Session{{Name: "old-1"}, {Name: "old-2"}}})
+	if got := m.maxSidebarCursor(); got != 4 {
+		t.Fatalf("max cursor = %d, want 4 (2 active, the archived line, 2 archived)", got)
+	}
+	if !strings.Contains(m.viewSidebar(), "old-2") {
+		t.Error("sidebar doesn't list the archived sessions")
+	}
+
+	for range 3 {
+		m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if s := m.cursorArchived(); s == nil || s.Name != "old-2" || m.cursorSession() != nil {
+		t.Fatalf("cursor %d isn't on old-2", m.cursor)
+	}
+	press("d")
+	if m.overlay != overlayDeleteConfirm || m.selectedSession.Name != "old-2" || m.deleteFromArchived {
+		t.Errorf("delete on an archived session: overlay %v, selected %v", m.overlay, m.selectedSession)
+	}
+	m.overlay = overlayNone
+	if press("a") == nil {
+		t.Error("archive on an archived session didn't unarchive it")
+	}
+
+	press(".")
+	if m.archivedInline || m.cursor != 2 || m.cursorArchived() != nil {
+		t.Errorf("after hiding archived sessions: cursor %d, want 2 (the archived line)", m.cursor)
+	}
+	if strings.Contains(m.viewSidebar(), "old-2") {
+		t.Error("sidebar still lists the archived sessions")
+	}
+}
+
+func TestWorkTime(t *testing.T) {
+	dir := t.TempDir()
+	db, err := database.Open(filepath.Join(dir, "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	m := newTestModel(&fakeBackend{})
+	m.db = db
+	sess := &session.Session{ID: "1", Name: "worker"}
+	m.sessions = []*session.Session{sess}
+	m.activeSession = sess
+	term := &fakeTerminal{name: "worker", running: true}
+	m.terminals["worker"] = term
+
+	for _, worked := range []time.Duration{50 * time.Minute, 25 * time.Minute} {
+		term.worked = worked
+		m.Update(m.saveWorkTime()())
+	}
+	if got := m.workTimes["1"]; got != 75*time.Minute {
+		t.Fatalf("working time after two saves = %v, want 1h15m", got)
+	}
+	if term.worked != 0 {
+		t.Error("saving didn't take the terminal's working time")
+	}
+	if got := strings.Join(m.statusSession(), " · "); !strings.Contains(got, "worked 1h15m") {
+		t.Errorf("status bar = %q, want the working time", got)
+	}
+}
+
+func TestWatchChange(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	dir := t.TempDir()
+	svc, err := session.NewService(nil, dir, config.DefaultGlobalConfig(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.service = svc
+
+	if cmd := m.handleWatchChange(watchChangeMsg{watch.RefsChanged}); cmd != nil {
+		t.Error("refreshed branches that were never listed")
+	}
+	m.cacheBranches(branchesLoadedMsg{repoPath: dir, branches: []string{"main"}})
+	if cmd := m.handleWatchChange(watchChangeMsg{watch.RefsChanged}); cmd == nil {
+		t.Error("didn't refresh the cached branches")
+	}
+	m.overlay = overlaySelectExistingBranch
+	if cmd := m.handleWatchChange(watchChangeMsg{watch.DBChanged}); cmd == nil {
+		t.Error("didn't refresh the open branch picker after a database change")
+	}
+}
+
+func TestDirtyMarker(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	m.keys = defaultKeyMap()
+	m.sidebarWidth = defaultSidebarWidth
+	m.sessions = []*session.Session{{Name: "api", WorktreePath: "/w/api"}, {Name: "web", WorktreePath: "/w/web"}}
+	m.dirtyChecking = true
+	m.updateDirty(dirtyCheckedMsg{checked: []string{"/w/api", "/w/web"}, dirty: map[string]bool{"/w/api": true}})
+	if m.dirtyChecking {
+		t.Error("check still marked as running")
+	}
+
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "api "+dirtyMarker) {
+		t.Error("no marker after the dirty session")
+	}
+	if strings.Contains(view, "web "+dirtyMarker) {
+		t.Error("marker after the clean session")
+	}
+
+	m.updateDirty(dirtyCheckedMsg{checked: []string{"/w/api"}})
+	if m.isDirty(m.sessions[0]) {
+		t.Error("session still dirty after a clean check")
+	}
+}
+
+func TestPluginActions(t *testing.T) {
+	m := newTestModel(&fakeBackend{})
+	dir := t.TempDir()
+	svc, err := session.NewService(nil, dir, config.DefaultGlobalConfig(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.service = svc
+	m.cfg.Plugins.Dir = t.TempDir()
+	m.cfg.Plugins.Keywords = []string{"FAIL"}
+	m.sessions = []*session.Session{{Name: "api"}}
+	term := &fakeTerminal{name: "api", running: true, screen: "ok\n--- FAIL: TestA\n"}
+	m.terminals["api"] = term
+
+	m.Update(pluginActionsMsg{repoPath: dir, actions: []plugin.Action{
+		{Action: plugin.Notify, Session: "api", Title: "Tests", Body: "failing", Plugin: "ci"},
+		{Action: plugin.Enqueue, Session: "api", Prompt: "fix the tests", Plugin: "ci"},
+		{Action: plugin.Enqueue, Session: "api", Prompt: "then lint", Plugin: "ci"},
+		{Action: plugin.Tag, Session: "api", Tag: "red", Plugin: "ci"},
+		{Action: plugin.Tag, Session: "api", Tag: "ci", Plugin: "ci"},
+		{Action: plugin.Tag, Session: "api", Tag: "red", Plugin: "ci"},
+		{Action: plugin.Tag, Session: "gone", Tag: "red", Plugin: "ci"},
+	}})
+	if !slices.Equal(term.pasted, []string{"fix the tests"}) {
+		t.Errorf("pasted %q, want the first queued prompt", term.pasted)
+	}
+	if got := m.promptQueues["api"]; !slices.Equal(got, []string{"then lint"}) {
+		t.Errorf("queue = %q, want the second prompt left", got)
+	}
+	if got := m.tagSummary("api", "summary"); got != "#red #ci · summary" {
+		t.Errorf("tagged summary = %q", got)
+	}
+	if _, ok := m.sessionTags["gone"]; ok {
+		t.Error("tagged a session that doesn't exist")
+	}
+
+	m.Update(pluginActionsMsg{repoPath: dir, actions: []plugin.Action{
+		{Action: plugin.Untag, Session: "api", Tag: "red", Plugin: "ci"},
+		{Action: plugin.Notify, Title: "Tests", Body: "passing", Plugin: "ci"},
+	}})
+	if got := m.tagSummary("api", ""); got != "#ci" {
+		t.Errorf("summary after untagging = %q, want #ci", got)
+	}
+	if m.message != "Tests: passing" {
+		t.Errorf("message = %q, want the notification", m.message)
+	}
+
+	// Another project's actions are dropped
+	m.Update(pluginActionsMsg{repoPath: "/elsewhere", actions: []plugin.Action{{Action: plugin.Untag, Session: "api", Tag: "ci"}}})
+	if len(m.sessionTags["api"]) != 1 {
+		t.Error("acted on another project's plugin actions")
+	}
+
+	// Only an agent that was working goes idle
+	m.pluginStateChanged("api", terminal.StateWaiting)
+	if m.pluginWorking["api"] {
+		t.Error("agent found waiting counted as working")
+	}
+	m.pluginStateChanged("api", terminal.StateWorking)
+	if !m.pluginWorking["api"] {
+		t.Error("working agent not recorded")
+	}
+	m.pluginStateChanged("api", terminal.StateWaiting)
+	if m.pluginWorking["api"] {
+		t.Error("idle agent still recorded as working")
+	}
+
+	// A keyword line counts once while it stays on screen
+	if m.scanPluginKeywords() == nil {
+		t.Error("keyword line not sent to plugins")
+	}
+	if m.scanPluginKeywords() != nil {
+		t.Error("keyword line still on screen sent again")
+	}
+	if !m.pluginMatches["api"]["--- FAIL: TestA"] {
+		t.Errorf("matches = %v", m.pluginMatches["api"])
+	}
+}
@@ -0,0 +1,281 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/kevinzwang/air-traffic-control/internal/config"
+)
+
+// rgb is a 24-bit color used by the ANSI dim/highlight math
+type rgb [3]int
+
+// Theme is a TUI color palette.
+//
+// The lipgloss colors style the sidebar, overlays and status text. The RGB
+// values describe the terminal pane's defaults for dimming and selection:
+// dimmed colors are blended toward Background, selected text is blended
+// toward HighlightTarget, and text using the terminal's default colors is
+// assumed to be Foreground on Background.
+type Theme struct {
+	Primary      lipgloss.Color
+	Success      lipgloss.Color
+	Danger       lipgloss.Color
+	TextNormal   lipgloss.Color
+	TextMuted    lipgloss.Color
+	TextDim      lipgloss.Color
+	SelectedText lipgloss.Color // text drawn on Primary/TextDim backgrounds
+
+	Foreground      rgb
+	Background      rgb
+	DimForeground   rgb
+	HighlightTarget rgb
+}
+
+var (
+	darkTheme = Theme{
+		Primary:      lipgloss.Color("#00d4ff"), // Cyan
+		Success:      lipgloss.Color("#00ff87"), // Green
+		Danger:       lipgloss.Color("#ff5f5f"), // Red
+		TextNormal:   lipgloss.Color("#e4e4e4"), // Light gray
+		TextMuted:    lipgloss.Color("#6c757d"), // Gray
+		TextDim:      lipgloss.Color("#495057"), // Dark gray
+		SelectedText: lipgloss.Color("#000000"),
+
+		Foreground:      rgb{229, 229, 229},
+		Background:      rgb{0, 0, 0},
+		DimForeground:   rgb{137, 150, 163},
+		HighlightTarget: rgb{255, 255, 255},
+	}
+
+	lightTheme = Theme{
+		Primary:      lipgloss.Color("#0077aa"), // Blue
+		Success:      lipgloss.Color("#1a7f37"), // Green
+		Danger:       lipgloss.Color("#cf222e"), // Red
+		TextNormal:   lipgloss.Color("#1f2328"), // Near black
+		TextMuted:    lipgloss.Color("#6e7781"), // Gray
+		TextDim:      lipgloss.Color("#afb8c1"), // Light gray
+		SelectedText: lipgloss.Color("#ffffff"),
+
+		Foreground:      rgb{31, 35, 40},
+		Background:      rgb{255, 255, 255},
+		DimForeground:   rgb{140, 149, 159},
+		HighlightTarget: rgb{0, 0, 0},
+	}
+
+	highContrastTheme = Theme{
+		Primary:      lipgloss.Color("#00ffff"),
+		Success:      lipgloss.Color("#00ff00"),
+		Danger:       lipgloss.Color("#ff3030"),
+		TextNormal:   lipgloss.Color("#ffffff"),
+		TextMuted:    lipgloss.Color("#d0d0d0"),
+		TextDim:      lipgloss.Color("#a0a0a0"),
+		SelectedText: lipgloss.Color("#000000"),
+
+		Foreground:      rgb{255, 255, 255},
+		Background:      rgb{0, 0, 0},
+		DimForeground:   rgb{190, 190, 190},
+		HighlightTarget: rgb{255, 255, 255},
+	}
+)
+
+// monochromeTheme is the palette of --no-color and NO_COLOR. Its "colors"
+// are names lipgloss can't parse, so nothing is colored, but muted and dim
+// text can still be told apart and drawn faint (see textStyle)
+var monochromeTheme = Theme{
+	Primary:      lipgloss.Color("primary"),
+	Success:      lipgloss.Color("success"),
+	Danger:       lipgloss.Color("danger"),
+	TextNormal:   lipgloss.Color("normal"),
+	TextMuted:    lipgloss.Color("muted"),
+	TextDim:      lipgloss.Color("dim"),
+	SelectedText: lipgloss.Color("selected"),
+
+	Foreground:      darkTheme.Foreground,
+	Background:      darkTheme.Background,
+	DimForeground:   darkTheme.DimForeground,
+	HighlightTarget: darkTheme.HighlightTarget,
+}
+
+// builtinThemes maps theme names accepted in the config to their palettes
+var builtinThemes = map[string]Theme{
+	"dark":          darkTheme,
+	"light":         lightTheme,
+	"high-contrast": highContrastTheme,
+}
+
+// autoThemeName selects dark or light based on the terminal's background
+const autoThemeName = "auto"
+
+// hasDarkBackground queries the terminal background color (OSC 11).
+// Replaced in tests.
+var hasDarkBackground = lipgloss.HasDarkBackground
+
+// theme is the active palette. Change it with ApplyTheme.
+var theme = darkTheme
+
+// ApplyTheme selects the named theme, either a built-in one or one of the
+// user-defined palettes, and rebuilds all styles from it.
+func ApplyTheme(name string, custom map[string]config.ThemeConfig) error {
+	t, err := resolveTheme(name, custom)
+	if err != nil {
+		return err
+	}
+	theme = t
+	buildStyles()
+	return nil
+}
+
+// monochrome is whether the TUI is drawn without color. Change it with
+// SetMonochrome.
+var monochrome bool
+
+// SetMonochrome turns color off, replacing the theme: selections and
+// highlights are reversed, muted text and unfocused panes are faint. lipgloss
+// drops every attribute, not just colors, when NO_COLOR is set, so the
+// renderer is kept on plain ANSI for bold, faint and reverse.
+func SetMonochrome(on bool) {
+	monochrome = on
+	if on {
+		theme = monochromeTheme
+		lipgloss.SetColorProfile(termenv.ANSI)
+	}
+	buildStyles()
+}
+
+// resolveTheme looks up a theme by name, preferring user-defined palettes
+func resolveTheme(name string, custom map[string]config.ThemeConfig) (Theme, error) {
+	if name == "" {
+		name = config.DefaultTheme
+	}
+	if tc, ok := custom[name]; ok {
+		return customTheme(name, tc)
+	}
+	if t, ok := builtinTheme(name); ok {
+		return t, nil
+	}
+	return Theme{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(themeNames(custom), ", "))
+}
+
+// customTheme layers a user-defined palette over its base theme
+func customTheme(name string, tc config.ThemeConfig) (Theme, error) {
+	base := tc.Base
+	if base == "" {
+		base = config.DefaultTheme
+	}
+	t, ok := builtinTheme(base)
+	if !ok {
+		return Theme{}, fmt.Errorf("theme %q: unknown base theme %q", name, base)
+	}
+
+	colors := []struct {
+		key   string
+		value string
+		dst   *lipgloss.Color
+	}{
+		{"primary", tc.Primary, &t.Primary},
+		{"success", tc.Success, &t.Success},
+		{"danger", tc.Danger, &t.Danger},
+		{"text", tc.Text, &t.TextNormal},
+		{"muted", tc.Muted, &t.TextMuted},
+		{"dim", tc.Dim, &t.TextDim},
+		{"selected_text", tc.SelectedText, &t.SelectedText},
+	}
+	for _, c := range colors {
+		if c.value == "" {
+			continue
+		}
+		if _, err := parseHex(c.value); err != nil {
+			return Theme{}, fmt.Errorf("theme %q: %s: %w", name, c.key, err)
+		}
+		*c.dst = lipgloss.Color(c.value)
+	}
+
+	if tc.Foreground != "" {
+		fg, err := parseHex(tc.Foreground)
+		if err != nil {
+			return Theme{}, fmt.Errorf("theme %q: foreground: %w", name, err)
+		}
+		t.Foreground = fg
+	}
+	if tc.Background != "" {
+		bg, err := parseHex(tc.Background)
+		if err != nil {
+			return Theme{}, fmt.Errorf("theme %q: background: %w", name, err)
+		}
+		t.Background = bg
+	}
+	return t, nil
+}
+
+// builtinTheme returns a built-in theme by name, resolving "auto" to dark or
+// light based on the terminal background
+func builtinTheme(name string) (Theme, bool) {
+	if name == autoThemeName {
+		if detectDarkBackground() {
+			return darkTheme, true
+		}
+		return lightTheme, true
+	}
+	t, ok := builtinThemes[name]
+	return t, ok
+}
+
+// detectDarkBackground reports whether the terminal has a dark background.
+// COLORFGBG (set by rxvt, Konsole, iTerm2 and others) is checked first since
+// it needs no terminal round-trip; otherwise the terminal is queried.
+func detectDarkBackground() bool {
+	if dark, ok := parseColorFGBG(os.Getenv("COLORFGBG")); ok {
+		return dark
+	}
+	return hasDarkBackground()
+}
+
+// parseColorFGBG interprets a COLORFGBG value such as "15;0" or
+// "default;default;0". The last field is the background's ANSI color index;
+// 7 (white) and 9–15 (bright colors) are light backgrounds.
+func parseColorFGBG(v string) (dark bool, ok bool) {
+	if v == "" {
+		return false, false
+	}
+	fields := strings.Split(v, ";")
+	bg, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || bg < 0 || bg > 15 {
+		return false, false
+	}
+	return bg != 7 && bg < 9, true
+}
+
+// parseHex parses a "#rrggbb" color
+func parseHex(s string) (rgb, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 || len(s) != 7 {
+		return rgb{}, fmt.Errorf("invalid color %q (expected #rrggbb)", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return rgb{}, fmt.Errorf("invalid color %q (expected #rrggbb)", s)
+	}
+	return rgb{int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff)}, nil
+}
+
+// themeNames returns the sorted names of all available themes
+func themeNames(custom map[string]config.ThemeConfig) []string {
+	names := []string{autoThemeName}
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	for name := range custom {
+		if _, ok := builtinThemes[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
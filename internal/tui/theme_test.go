@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kevinzwang/air-traffic-control/internal/config"
+)
+
+func TestResolveTheme(t *testing.T) {
+	custom := map[string]config.ThemeConfig{
+		"mine":  {Base: "light", Primary: "#123456", Background: "#fafafa"},
+		"bad":   {Primary: "blue"},
+		"noref": {Base: "neon"},
+	}
+
+	tests := []struct {
+		name    string
+		theme   string
+		want    func(Theme) bool
+		wantErr bool
+	}{
+		{"builtin dark", "dark", func(th Theme) bool { return th == darkTheme }, false},
+		{"builtin light", "light", func(th Theme) bool { return th == lightTheme }, false},
+		{"builtin high-contrast", "high-contrast", func(th Theme) bool { return th == highContrastTheme }, false},
+		{"custom overrides base", "mine", func(th Theme) bool {
+			return th.Primary == lipgloss.Color("#123456") &&
+				th.Background == rgb{250, 250, 250} &&
+				th.Danger == lightTheme.Danger
+		}, false},
+		{"invalid hex", "bad", nil, true},
+		{"unknown base", "noref", nil, true},
+		{"unknown theme", "nope", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			th, err := resolveTheme(tt.theme, custom)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveTheme(%q) error = %v, wantErr %v", tt.theme, err, tt.wantErr)
+			}
+			if tt.want != nil && !tt.want(th) {
+				t.Errorf("resolveTheme(%q) = %+v", tt.theme, th)
+			}
+		})
+	}
+}
+
+func TestParseColorFGBG(t *testing.T) {
+	tests := []struct {
+		value    string
+		wantDark bool
+		wantOK   bool
+	}{
+		{"15;0", true, true},
+		{"0;15", false, true},
+		{"default;default;0", true, true},
+		{"0;7", false, true},
+		{"7;8", true, true},
+		{"", false, false},
+		{"15;default", false, false},
+		{"0;42", false, false},
+	}
+
+	for _, tt := range tests {
+		dark, ok := parseColorFGBG(tt.value)
+		if dark != tt.wantDark || ok != tt.wantOK {
+			t.Errorf("parseColorFGBG(%q) = (%v, %v), want (%v, %v)", tt.value, dark, ok, tt.wantDark, tt.wantOK)
+		}
+	}
+}
+
+func TestAutoTheme(t *testing.T) {
+	orig := hasDarkBackground
+	defer func() { hasDarkBackground = orig }()
+
+	// COLORFGBG takes precedence over the terminal query
+	hasDarkBackground = func() bool { return true }
+	t.Setenv("COLORFGBG", "0;15")
+	if th, _ := resolveTheme("auto", nil); th != lightTheme {
+		t.Error("expected light theme from COLORFGBG")
+	}
+
+	t.Setenv("COLORFGBG", "")
+	hasDarkBackground = func() bool { return false }
+	if th, _ := resolveTheme("", nil); th != lightTheme {
+		t.Error("expected default auto theme to follow the terminal query")
+	}
+	hasDarkBackground = func() bool { return true }
+	if th, _ := resolveTheme("auto", nil); th != darkTheme {
+		t.Error("expected dark theme from terminal query")
+	}
+}
+
+func TestDimRGBLightTheme(t *testing.T) {
+	defer func() { theme = darkTheme }()
+	theme = lightTheme
+
+	// Dimming on a white background fades toward white
+	r, g, b := dimRGB(0, 0, 0, 0.5)
+	if r != 128 || g != 128 || b != 128 {
+		t.Errorf("dimRGB(0,0,0,0.5) = (%d,%d,%d), want (128,128,128)", r, g, b)
+	}
+	// Highlighting on a light theme darkens
+	r, g, b = lightenRGB(255, 255, 255, 0.5)
+	if r != 128 || g != 128 || b != 128 {
+		t.Errorf("lightenRGB(255,255,255,0.5) = (%d,%d,%d), want (128,128,128)", r, g, b)
+	}
+}
+
+func TestMonochrome(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	SetMonochrome(true)
+	t.Cleanup(func() {
+		monochrome = false
+		theme = darkTheme
+		lipgloss.SetColorProfile(profile)
+		buildStyles()
+	})
+
+	for name, got := range map[string]string{
+		"selection": selectedItemStyle.Render("api"),
+		"muted":     metadataStyle.Render("api"),
+		"error":     errorStyle.Render("api"),
+		"dimmed":    dimANSIColors("\x1b[31mapi", 0.75),
+		"highlight": applyHighlightToLine("api", 0, 2, selectionLightenFactor),
+	} {
+		if strings.Contains(got, "38;") || strings.Contains(got, "48;") {
+			t.Errorf("%s is colored: %q", name, got)
+		}
+	}
+	if got := selectedItemStyle.Render("api"); !strings.Contains(got, "\x1b[1;7mapi") {
+		t.Errorf("selection isn't reversed: %q", got)
+	}
+	if got := metadataStyle.Render("api"); got != "\x1b[2mapi\x1b[0m" {
+		t.Errorf("muted text isn't faint: %q", got)
+	}
+	if got, want := faintANSI("a\x1b[0mb\nc"), "\x1b[2ma\x1b[0m\x1b[2mb\n\x1b[2mc"; got != want {
+		t.Errorf("faintANSI = %q, want %q", got, want)
+	}
+	if got := applyHighlightToLine("api", 0, 2, selectionLightenFactor); !strings.Contains(got, "\x1b[7m") {
+		t.Errorf("highlight isn't reversed: %q", got)
+	}
+}
@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// The host terminal's title names the project and the session shown, so
+// window switchers tell ATC windows apart
+
+// windowTitle is the title for what's shown: "ATC — repo/session"
+func (m *Model) windowTitle() string {
+	title := "ATC"
+	if m.repoName == "" {
+		return title
+	}
+	if asciiOnly {
+		title += " - " + m.repoName
+	} else {
+		title += " — " + m.repoName
+	}
+	if m.activeSession != nil {
+		title += "/" + m.activeSession.Name
+	}
+	return title
+}
+
+// updateWindowTitle returns a command setting the title if what's shown
+// changed since it was last set
+func (m *Model) updateWindowTitle() tea.Cmd {
+	title := m.windowTitle()
+	if title == m.shownTitle {
+		return nil
+	}
+	m.shownTitle = title
+	return tea.SetWindowTitle(title)
+}
+
+// ResetWindowTitle clears the title ATC set, for the terminal or shell to
+// set its own
+func ResetWindowTitle() {
+	fmt.Fprint(os.Stdout, ansi.SetWindowTitle(""))
+}
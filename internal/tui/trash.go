@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/events"
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+)
+
+// Deleting a session moves its worktree to the trash, where the trash
+// overlay can restore it until `atc prune` removes it trash_days later
+
+// trashLoadedMsg carries the project's trashed sessions
+type trashLoadedMsg struct {
+	trashed []*session.TrashedSession
+	err     error
+}
+
+// sessionRestoredMsg reports a session restored from the trash
+type sessionRestoredMsg struct {
+	name string
+}
+
+// trashPurgedMsg reports a trashed session deleted for good
+type trashPurgedMsg struct {
+	name string
+}
+
+// openTrash shows the project's deleted sessions
+func (m *Model) openTrash() (tea.Model, tea.Cmd) {
+	if m.service == nil {
+		return m, nil
+	}
+	m.trashList = nil
+	m.trashCursor = 0
+	m.trashPurging = ""
+	m.overlay = overlayTrash
+	m.err = nil
+	return m, m.loadTrash()
+}
+
+// loadTrash lists the project's trashed sessions in the background
+func (m *Model) loadTrash() tea.Cmd {
+	svc := m.service
+	return func() tea.Msg {
+		trashed, err := svc.ListTrash()
+		return trashLoadedMsg{trashed: trashed, err: err}
+	}
+}
+
+// showTrash fills in the trash overlay once it is loaded
+func (m *Model) showTrash(msg trashLoadedMsg) {
+	if m.overlay != overlayTrash {
+		return
+	}
+	if msg.err != nil {
+		m.err = msg.err
+		return
+	}
+	m.trashList = msg.trashed
+	m.trashCursor = max(min(m.trashCursor, len(m.trashList)-1), 0)
+}
+
+// handleTrashKeys restores the selected session, or deletes it for good
+// once confirmed
+func (m *Model) handleTrashKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key != "D" {
+		m.trashPurging = ""
+	}
+	switch key {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		return m.dismissOverlay()
+	case "up", "k":
+		if m.trashCursor > 0 {
+			m.trashCursor--
+		}
+	case "down", "j":
+		if m.trashCursor < len(m.trashList)-1 {
+			m.trashCursor++
+		}
+	case "enter", "u":
+		if m.trashCursor >= len(m.trashList) {
+			return m, nil
+		}
+		t := m.trashList[m.trashCursor]
+		svc := m.service
+		return m, func() tea.Msg {
+			if _, err := svc.RestoreSession(t.ID); err != nil {
+				return errMsg{err}
+			}
+			return sessionRestoredMsg{t.Name}
+		}
+	case "D":
+		if m.trashCursor >= len(m.trashList) {
+			return m, nil
+		}
+		t := m.trashList[m.trashCursor]
+		if m.trashPurging != t.ID {
+			m.trashPurging = t.ID
+			return m, nil
+		}
+		m.trashPurging = ""
+		svc := m.service
+		return m, func() tea.Msg {
+			if err := svc.PurgeTrashed(t.ID); err != nil {
+				return errMsg{err}
+			}
+			return trashPurgedMsg{t.Name}
+		}
+	}
+	return m, nil
+}
+
+// viewTrash lists the project's deleted sessions
+func (m *Model) viewTrash() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Trash"))
+	b.WriteString("\n")
+	b.WriteString(subtitleStyle.Render(fmt.Sprintf("Deleted sessions, kept for %d %s", m.cfg.TrashDays, plural(m.cfg.TrashDays, "day", "days"))))
+	b.WriteString("\n\n")
+
+	helpText := "[↑/↓] Navigate  [u] Restore  [D] Delete forever  [Esc] Close"
+	if len(m.trashList) == 0 {
+		b.WriteString(metadataStyle.Render("  The trash is empty") + "\n")
+	} else {
+		maxVisible := 10
+		startIdx := 0
+		if m.trashCursor >= maxVisible {
+			startIdx = m.trashCursor - maxVisible + 1
+		}
+		endIdx := min(startIdx+maxVisible, len(m.trashList))
+		itemWidth := len(helpText)
+		if startIdx > 0 {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↑ %d more", startIdx)) + "\n")
+		}
+		for i := startIdx; i < endIdx; i++ {
+			t := m.trashList[i]
+			label := fmt.Sprintf("%s  deleted %s", truncate(t.Name, 40), t.TrashedAt.Local().Format("Jan 02 15:04"))
+			if i == m.trashCursor {
+				b.WriteString(selectedItemStyle.Width(itemWidth).Render(label) + "\n")
+			} else {
+				b.WriteString(normalItemStyle.Width(itemWidth).Render(label) + "\n")
+			}
+		}
+		if endIdx < len(m.trashList) {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("  ↓ %d more", len(m.trashList)-endIdx)) + "\n")
+		}
+	}
+
+	if m.trashPurging != "" && m.trashCursor < len(m.trashList) {
+		b.WriteString("\n" + warningStyle.Render(fmt.Sprintf("Press D again to delete %s and its local changes for good", truncate(m.trashList[m.trashCursor].Name, 30))) + "\n")
+	}
+	if m.err != nil {
+		b.WriteString("\n" + errorStyle.Render(truncate(m.err.Error(), 70)) + "\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(helpText))
+	return dialogBoxStyle.Render(b.String())
+}
+
+// restoredSession logs a restored session and selects it
+func (m *Model) restoredSession(msg sessionRestoredMsg) tea.Cmd {
+	m.logEvent(msg.name, events.KindRestored, "")
+	m.message = fmt.Sprintf("Session '%s' restored", msg.name)
+	m.overlay = overlayNone
+	m.trashList = nil
+	m.selectAfterLoad = msg.name
+	return m.loadSessions()
+}
@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tutorialStep is a stage of the `atc tutorial` walkthrough. Each step is
+// completed by the user performing the action its callout describes.
+type tutorialStep int
+
+const (
+	tutorialOff tutorialStep = iota
+	tutorialCreate
+	tutorialPrompt
+	tutorialScroll
+	tutorialArchive
+	tutorialDelete
+	tutorialDone
+)
+
+// EnableTutorial turns on the guided walkthrough callouts
+func (m *Model) EnableTutorial() {
+	m.tutorial = tutorialCreate
+}
+
+// advanceTutorial moves to the next step if the user just completed step
+func (m *Model) advanceTutorial(step tutorialStep) {
+	if m.tutorial == step && step != tutorialOff && step != tutorialDone {
+		m.tutorial++
+	}
+}
+
+// tutorialCallout returns the title and instructions for the current step
+func (m *Model) tutorialCallout() (string, string) {
+	switch m.tutorial {
+	case tutorialCreate:
+		return "1/5 Create a session",
+			fmt.Sprintf("Press %s, pick a base branch and name the session. ATC creates a git worktree and starts the agent in it.", m.keys.New)
+	case tutorialPrompt:
+		return "2/5 Send a prompt",
+			"The agent pane now has focus. Type a request, e.g. \"summarize the README\", and press Enter."
+	case tutorialScroll:
+		return "3/5 Scroll the output",
+			"Use the mouse wheel or PgUp/PgDn to scroll back through the agent's output. Any other key returns to the live view."
+	case tutorialArchive:
+		return "4/5 Archive the session",
+			fmt.Sprintf("Press Ctrl+C to return to the session list, then %s to archive. Archived sessions keep their worktree and branch.", m.keys.Archive)
+	case tutorialDelete:
+		return "5/5 Delete the session",
+			fmt.Sprintf("Select the archived line at the bottom of the list, press Enter, then %s and confirm to delete the session and its worktree.", m.keys.Delete)
+	case tutorialDone:
+		return "All done!",
+			fmt.Sprintf("That's the whole loop. Press %s to quit; the demo project is cleaned up on exit.", m.keys.Quit)
+	}
+	return "", ""
+}
+
+// viewTutorialCallout renders the current step as a bordered box for the
+// sidebar, or "" when the tutorial is off
+func (m *Model) viewTutorialCallout(width int) string {
+	title, body := m.tutorialCallout()
+	if title == "" {
+		return ""
+	}
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primary).
+		Width(width-2).
+		Padding(0, 1)
+	text := titleStyle.Render(title) + "\n" + dialogTextStyle.Render(body)
+	return strings.TrimRight(box.Render(text), "\n")
+}
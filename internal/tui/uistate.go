@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+)
+
+// Settings keys for the UI state restored on launch. Each project's state is
+// stored under settingUIStatePrefix + its repository path
+const (
+	settingLastProject   = "last_project"
+	settingUIStatePrefix = "ui_state:"
+)
+
+// uiState is where the user was in a project when ATC last quit
+type uiState struct {
+	Session  string `json:"session,omitempty"` // selected session
+	Header   bool   `json:"header,omitempty"`  // project header selected instead
+	Scroll   int    `json:"scroll"`
+	Terminal bool   `json:"terminal,omitempty"` // terminal pane had focus
+}
+
+// saveUIState records the selection and scroll of every open project, and
+// which one was showing, so the next launch starts where this one ends. It
+// runs while quitting, so it saves synchronously
+func (m *Model) saveUIState() {
+	if m.db == nil || m.service == nil || m.tutorial != tutorialOff {
+		return
+	}
+	m.captureTab()
+	for i, tab := range m.tabs {
+		if tab == nil || tab.service == nil {
+			continue
+		}
+		state := uiState{Scroll: tab.scrollOffset, Header: tab.cursor == -1}
+		active := tab.sessions[:0:0]
+		for _, s := range tab.sessions {
+			if s.Status != "archived" {
+				active = append(active, s)
+			}
+		}
+		if tab.cursor >= 0 && tab.cursor < len(active) {
+			state.Session = active[tab.cursor].Name
+		}
+		if i == m.tabIndex {
+			state.Terminal = m.focus == focusTerminal
+		}
+		data, err := json.Marshal(state)
+		if err != nil {
+			continue
+		}
+		m.db.SetSetting(settingUIStatePrefix+tab.service.RepoPath(), string(data))
+	}
+	m.db.SetSetting(settingLastProject, m.service.RepoPath())
+}
+
+// restoreUIState puts the current project's sidebar back the way it was
+// when ATC last quit. The selection is applied once its sessions load
+func (m *Model) restoreUIState() {
+	if m.db == nil || m.service == nil {
+		return
+	}
+	v, err := m.db.GetSetting(settingUIStatePrefix + m.service.RepoPath())
+	if err != nil || v == "" {
+		return
+	}
+	var state uiState
+	if json.Unmarshal([]byte(v), &state) != nil {
+		return
+	}
+	if state.Header {
+		m.cursor = -1
+	}
+	m.selectAfterLoad = state.Session
+	m.scrollOffset = max(state.Scroll, 0)
+	m.restoreFocus = state.Terminal
+}
+
+// openLastProject opens the project that was showing when ATC last quit, for
+// launches outside a git repository. It returns nil if there is none or it
+// no longer exists
+func (m *Model) openLastProject() tea.Cmd {
+	if m.db == nil {
+		return nil
+	}
+	repoPath, err := m.db.GetSetting(settingLastProject)
+	if err != nil || repoPath == "" {
+		return nil
+	}
+	if _, err := os.Stat(repoPath); err != nil {
+		return nil
+	}
+	return m.switchProject(&database.Project{RepoPath: repoPath, RepoName: filepath.Base(repoPath)})
+}
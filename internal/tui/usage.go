@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kevinzwang/air-traffic-control/internal/database"
+	"github.com/kevinzwang/air-traffic-control/internal/usage"
+)
+
+// maxUsageRows is the number of sessions listed in the usage overlay
+const maxUsageRows = 12
+
+// usageLoadedMsg carries the stored usage rollups of all sessions
+type usageLoadedMsg struct {
+	rows []*database.SessionUsage
+}
+
+// openUsageOverlay shows token usage, estimated cost and working time,
+// refreshing the current project's rollups from its transcripts in the
+// background
+func (m *Model) openUsageOverlay() (tea.Model, tea.Cmd) {
+	m.overlay = overlayUsage
+	m.usageLoading = true
+	return m, tea.Batch(m.saveWorkTime(), func() tea.Msg {
+		if m.service != nil {
+			if err := m.service.RefreshUsage(); err != nil {
+				return errMsg{err}
+			}
+		}
+		rows, err := m.db.ListUsage()
+		if err != nil {
+			return errMsg{err}
+		}
+		return usageLoadedMsg{rows}
+	})
+}
+
+func (m *Model) handleUsageKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", m.keys.Usage, m.keys.Quit:
+		m.overlay = overlayNone
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// toTotals converts a stored rollup to usage totals
+func toTotals(u *database.SessionUsage) usage.Totals {
+	return usage.Totals{
+		InputTokens:      u.InputTokens,
+		OutputTokens:     u.OutputTokens,
+		CacheWriteTokens: u.CacheWriteTokens,
+		CacheReadTokens:  u.CacheReadTokens,
+		CostUSD:          u.CostUSD,
+	}
+}
+
+func (m *Model) viewUsage() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Token Usage"))
+	b.WriteString("\n\n")
+
+	if m.usageLoading {
+		b.WriteString(m.spinner.View() + " Reading transcripts...")
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("[Esc] Close"))
+		return dialogBoxStyle.Render(b.String())
+	}
+
+	selected := ""
+	if m.activeSession != nil {
+		selected = m.activeSession.Name
+	}
+
+	// This project's sessions, most expensive first, with how long their
+	// agents worked
+	var project usage.Totals
+	byRepo := make(map[string]*usage.Totals)
+	var all usage.Totals
+	var projectWorked, allWorked time.Duration
+	workedByRepo := make(map[string]time.Duration)
+	row := func(label string, t usage.Totals, worked time.Duration) string {
+		return fmt.Sprintf("%-24s %8s tok  %8s  %7s worked", truncate(label, 24), usage.FormatTokens(t.Tokens()), usage.FormatCost(t.CostUSD), formatWorkTime(worked))
+	}
+	shown := 0
+	for _, u := range m.usageRows {
+		t := toTotals(u)
+		worked := m.workTimes[u.SessionID]
+		all.Add(t)
+		allWorked += worked
+		if byRepo[u.RepoName] == nil {
+			byRepo[u.RepoName] = &usage.Totals{}
+		}
+		byRepo[u.RepoName].Add(t)
+		workedByRepo[u.RepoName] += worked
+		if u.RepoName != m.repoName {
+			continue
+		}
+		project.Add(t)
+		projectWorked += worked
+		if shown >= maxUsageRows {
+			continue
+		}
+		shown++
+		label := u.SessionName
+		if u.Status == "archived" {
+			label += " (archived)"
+		}
+		line := row(label, t, worked)
+		if u.SessionName == selected {
+			b.WriteString(selectedItemStyle.Render(line) + "\n")
+		} else {
+			b.WriteString(normalItemStyle.Render(line) + "\n")
+		}
+	}
+	if shown == 0 {
+		b.WriteString(metadataStyle.Render("No conversations yet") + "\n")
+	}
+
+	if sess := m.activeSession; sess != nil {
+		for _, u := range m.usageRows {
+			if u.SessionName == sess.Name {
+				b.WriteString("\n")
+				b.WriteString(metadataStyle.Render(fmt.Sprintf("%s: %s in, %s out, %s cache write, %s cache read",
+					truncate(sess.Name, 24),
+					usage.FormatTokens(u.InputTokens), usage.FormatTokens(u.OutputTokens),
+					usage.FormatTokens(u.CacheWriteTokens), usage.FormatTokens(u.CacheReadTokens))))
+				b.WriteString("\n")
+				break
+			}
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(dialogTextStyle.Render(row(m.repoName+" total", project, projectWorked)))
+	b.WriteString("\n")
+
+	// Aggregate across projects
+	if len(byRepo) > 1 {
+		repos := make([]string, 0, len(byRepo))
+		for name := range byRepo {
+			if name != m.repoName {
+				repos = append(repos, name)
+			}
+		}
+		sort.Slice(repos, func(i, j int) bool { return byRepo[repos[i]].CostUSD > byRepo[repos[j]].CostUSD })
+		for _, name := range repos {
+			b.WriteString(metadataStyle.Render(row(name, *byRepo[name], workedByRepo[name])))
+			b.WriteString("\n")
+		}
+		b.WriteString(dialogTextStyle.Render(row("All projects", all, allWorked)))
+		b.WriteString("\n")
+	}
+
+	if processes := m.viewResources(); processes != "" {
+		b.WriteString("\n")
+		b.WriteString(processes)
+	}
+
+	b.WriteString("\n")
+	b.WriteString(metadataStyle.Render("Costs are estimates at API list prices"))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("[Esc] Close"))
+	return dialogBoxStyle.Render(b.String())
+}
@@ -0,0 +1,259 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/session"
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// The repo's verify command (a test or lint run) comes from its .atc.yaml, so
+// like setup commands it only runs once the user has trusted it. A failed
+// run's output is kept for the log overlay until the next run.
+
+// verifyRun is the latest run of the verify command in a session's worktree
+type verifyRun struct {
+	command  string
+	running  bool
+	failed   bool
+	output   string
+	started  time.Time
+	finished time.Time
+}
+
+// verifyDoneMsg reports how a verify run ended
+type verifyDoneMsg struct {
+	worktreePath string
+	name         string
+	output       string
+	err          error
+}
+
+// handleVerify runs the verify command in the selected session's worktree,
+// or shows the log of its last run if that failed or is still going
+func (m *Model) handleVerify() (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil || m.isProjectHeaderSelected() {
+		return m, nil
+	}
+	if run, ok := m.verifyRuns[sess.WorktreePath]; ok && (run.running || run.failed) {
+		m.openVerifyLog(sess)
+		return m, nil
+	}
+	return m, m.startVerify(sess)
+}
+
+// startVerify runs the verify command in the session's worktree in the
+// background, asking to trust it first if needed
+func (m *Model) startVerify(sess *session.Session) tea.Cmd {
+	command := m.service.Config().Verify
+	if command == "" {
+		m.message = "Set verify in .atc.yaml to a test or lint command to run it here"
+		return nil
+	}
+	if trusted, err := m.service.CommandsTrusted(); err != nil || !trusted {
+		m.verifySession = sess
+		m.overlay = overlayVerifyTrust
+		return nil
+	}
+
+	if m.verifyRuns == nil {
+		m.verifyRuns = make(map[string]*verifyRun)
+	}
+	m.verifyRuns[sess.WorktreePath] = &verifyRun{command: command, running: true, started: time.Now()}
+	env := m.portEnv(sess)
+	return func() tea.Msg {
+		var buf bytes.Buffer
+		err := worktree.RunSetupCommands(sess.WorktreePath, []string{command}, env, &buf)
+		return verifyDoneMsg{worktreePath: sess.WorktreePath, name: sess.Name, output: buf.String(), err: err}
+	}
+}
+
+// finishVerify records how a verify run ended
+func (m *Model) finishVerify(msg verifyDoneMsg) {
+	run, ok := m.verifyRuns[msg.worktreePath]
+	if !ok {
+		return
+	}
+	run.running = false
+	run.failed = msg.err != nil
+	run.output = msg.output
+	run.finished = time.Now()
+	took := run.finished.Sub(run.started).Round(time.Second)
+	if run.failed {
+		m.message = fmt.Sprintf("Verify failed in %s after %s; press %s for the log", msg.name, took, m.keys.Verify)
+	} else {
+		m.message = fmt.Sprintf("Verify passed in %s (%s)", msg.name, took)
+		m.runHandoffs(msg.name)
+	}
+	m.finishScheduledRun(msg.name, run)
+	if m.overlay == overlayVerifyLog && m.verifySession != nil && m.verifySession.WorktreePath == msg.worktreePath {
+		m.verifyScroll = m.maxVerifyScroll()
+	}
+}
+
+// handleVerifyTrustKeys asks to trust the repo's commands before the first
+// verify run
+func (m *Model) handleVerifyTrustKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "y", "Y":
+		sess := m.verifySession
+		m.verifySession = nil
+		m.overlay = overlayNone
+		if err := m.service.TrustCommands(); err != nil {
+			m.err = err
+			return m, nil
+		}
+		return m, m.startVerify(sess)
+	case "n", "N", "esc":
+		return m.dismissOverlay()
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// openVerifyLog shows the output of a session's last verify run
+func (m *Model) openVerifyLog(sess *session.Session) {
+	m.verifySession = sess
+	m.overlay = overlayVerifyLog
+	m.verifyScroll = m.maxVerifyScroll()
+}
+
+// verifyLogLines is the output of the verify run shown in the log overlay
+func (m *Model) verifyLogLines() []string {
+	if m.verifySession == nil {
+		return nil
+	}
+	run, ok := m.verifyRuns[m.verifySession.WorktreePath]
+	if !ok || run.output == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(run.output, "\n"), "\n")
+}
+
+// maxVerifyScroll scrolls the log overlay to the end, where failures are
+func (m *Model) maxVerifyScroll() int {
+	return max(len(m.verifyLogLines())-m.diffVisibleLines(), 0)
+}
+
+// handleVerifyLogKeys scrolls the log overlay and reruns the command
+func (m *Model) handleVerifyLogKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxScroll := m.maxVerifyScroll()
+	switch msg.String() {
+	case "esc", "q", m.keys.Verify:
+		return m.dismissOverlay()
+	case "up", "k":
+		m.verifyScroll = max(m.verifyScroll-1, 0)
+	case "down", "j":
+		m.verifyScroll = min(m.verifyScroll+1, maxScroll)
+	case "pgup", "ctrl+u":
+		m.verifyScroll = max(m.verifyScroll-m.diffVisibleLines(), 0)
+	case "pgdown", "ctrl+d", " ":
+		m.verifyScroll = min(m.verifyScroll+m.diffVisibleLines(), maxScroll)
+	case "r", "R":
+		sess := m.verifySession
+		if run, ok := m.verifyRuns[sess.WorktreePath]; ok && run.running {
+			return m, nil
+		}
+		m.verifySession = nil
+		m.overlay = overlayNone
+		return m, m.startVerify(sess)
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// verifySummary puts the session's last verify result in front of its
+// sidebar summary
+func (m *Model) verifySummary(s *session.Session, summary string) string {
+	run, ok := m.verifyRuns[s.WorktreePath]
+	if !ok {
+		return summary
+	}
+	label := "✓ verified"
+	switch {
+	case run.running:
+		label = "… verifying"
+	case run.failed:
+		label = "✗ verify failed"
+	}
+	if summary == "" {
+		return label
+	}
+	return label + " · " + summary
+}
+
+// viewVerifyTrust asks to trust the verify command before running it
+func (m *Model) viewVerifyTrust() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Trust Verify Command?"))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render("This repository's verify command is:"))
+	b.WriteString("\n\n")
+	b.WriteString(normalItemStyle.Render("$ " + truncate(m.service.Config().Verify, 60)))
+	b.WriteString("\n\n")
+	for _, src := range m.service.Config().SetupSources {
+		b.WriteString(metadataStyle.Render("from " + truncatePath(src, 60)))
+		b.WriteString("\n")
+	}
+	b.WriteString(metadataStyle.Render("Trusting it also trusts the repository's setup and teardown commands."))
+	b.WriteString("\n")
+	b.WriteString(metadataStyle.Render("Only trust commands from repositories you trust. You'll be asked again if they change."))
+	b.WriteString("\n\n")
+	b.WriteString(dialogTextStyle.Render("[y] Trust and run    [n] Cancel"))
+	return dialogBoxStyle.Render(b.String())
+}
+
+// viewVerifyLog is the output of a session's last verify run
+func (m *Model) viewVerifyLog() string {
+	sess := m.verifySession
+	if sess == nil {
+		return ""
+	}
+	run, ok := m.verifyRuns[sess.WorktreePath]
+	if !ok {
+		return ""
+	}
+	width := max(m.windowWidth-10, 40)
+
+	var b strings.Builder
+	status := "passed"
+	switch {
+	case run.running:
+		status = "running"
+	case run.failed:
+		status = "failed"
+	}
+	b.WriteString(titleStyle.Render(truncate(fmt.Sprintf("Verify %s: %s", status, sess.Name), width)))
+	b.WriteString("\n")
+	b.WriteString(metadataStyle.Render(truncate("$ "+run.command, width)))
+	b.WriteString("\n\n")
+
+	visible := m.diffVisibleLines()
+	if run.running {
+		b.WriteString(m.spinner.View() + fmt.Sprintf(" Running for %s...", time.Since(run.started).Round(time.Second)))
+		b.WriteString("\n")
+	} else {
+		lines := m.verifyLogLines()
+		end := min(m.verifyScroll+visible, len(lines))
+		for _, line := range lines[m.verifyScroll:end] {
+			b.WriteString(dialogTextStyle.Render(truncate(strings.ReplaceAll(line, "\t", "    "), width)))
+			b.WriteString("\n")
+		}
+		if len(lines) > visible {
+			b.WriteString(metadataStyle.Render(fmt.Sprintf("lines %d-%d of %d", m.verifyScroll+1, end, len(lines))))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("[↑/↓/PgUp/PgDn] Scroll  [R] Run again  [Esc] Close"))
+	return dialogBoxStyle.Render(b.String())
+}
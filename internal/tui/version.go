@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"encoding/json"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/update"
+)
+
+// ATC looks up its latest release at most once a day, remembering the
+// answer in the settings table, and notes a newer one under the tower
+
+// settingUpdateCheck is the settings key of the last release check
+const settingUpdateCheck = "update_check"
+
+// updateCheckInterval is how long a release check is trusted
+const updateCheckInterval = 24 * time.Hour
+
+// updateCheck is the last release check as stored in the settings table
+type updateCheck struct {
+	Checked time.Time `json:"checked"`
+	Latest  string    `json:"latest"`
+}
+
+// versionCheckedMsg carries the latest release's version
+type versionCheckedMsg struct {
+	latest string
+}
+
+// checkForUpdate finds the latest release in the background, from the last
+// check if it was recent enough
+func (m *Model) checkForUpdate() tea.Cmd {
+	if m.db == nil || m.cfg == nil || !m.cfg.CheckForUpdates || Version == "dev" {
+		return nil
+	}
+	db := m.db
+	return func() tea.Msg {
+		var last updateCheck
+		if v, err := db.GetSetting(settingUpdateCheck); err == nil && v != "" {
+			json.Unmarshal([]byte(v), &last)
+		}
+		if time.Since(last.Checked) < updateCheckInterval {
+			return versionCheckedMsg{latest: last.Latest}
+		}
+		latest, err := update.Latest()
+		if err != nil {
+			// Offline or rate limited: say nothing, and try again next launch
+			return versionCheckedMsg{latest: last.Latest}
+		}
+		if data, err := json.Marshal(updateCheck{Checked: time.Now(), Latest: latest}); err == nil {
+			db.SetSetting(settingUpdateCheck, string(data))
+		}
+		return versionCheckedMsg{latest: latest}
+	}
+}
+
+// updateNote is the sidebar's note of a newer release, or "" if ATC is up
+// to date
+func (m *Model) updateNote() string {
+	if !update.Newer(Version, m.latestVersion) {
+		return ""
+	}
+	return m.latestVersion + " is out: atc upgrade"
+}
@@ -0,0 +1,74 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kevinzwang/air-traffic-control/internal/watch"
+)
+
+// Besides polling (see sync.go), ATC watches the database and the current
+// project's refs, so sessions and branches created by another ATC instance,
+// git or any other tool show up right away
+
+// watchChangeMsg carries a change the watcher noticed
+type watchChangeMsg struct {
+	change watch.Change
+}
+
+// SetWatcher sets the watcher of the database and the current project's
+// refs
+func (m *Model) SetWatcher(w *watch.Watcher) {
+	m.watcher = w
+	m.watchRepo()
+}
+
+// watchRepo points the watcher at the current project's refs
+func (m *Model) watchRepo() {
+	if m.watcher == nil {
+		return
+	}
+	repoPath := ""
+	if m.service != nil {
+		repoPath = m.service.RepoPath()
+	}
+	m.watcher.WatchRepo(repoPath)
+}
+
+// waitForChange waits for the watcher's next change
+func (m *Model) waitForChange() tea.Cmd {
+	if m.watcher == nil {
+		return nil
+	}
+	changes := m.watcher.Changes()
+	return func() tea.Msg {
+		change, ok := <-changes
+		if !ok {
+			return nil
+		}
+		return watchChangeMsg{change}
+	}
+}
+
+// handleWatchChange reloads what a change may have touched: the sessions
+// if their change counter moved, and the project's branches if they were
+// listed before. Branch listings note which branches have sessions, so a
+// database change refreshes an open branch picker too
+func (m *Model) handleWatchChange(msg watchChangeMsg) tea.Cmd {
+	if m.service == nil {
+		return nil
+	}
+	picking := m.overlay == overlaySelectBaseBranch || m.overlay == overlaySelectExistingBranch
+	_, cached := m.branchCaches[m.service.RepoPath()]
+	switch msg.change {
+	case watch.DBChanged:
+		if picking {
+			return tea.Batch(m.checkSessionsVersion(), m.refreshBranches())
+		}
+		return m.checkSessionsVersion()
+	case watch.RefsChanged:
+		if picking || cached {
+			return m.refreshBranches()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kevinzwang/air-traffic-control/internal/container"
+	"github.com/kevinzwang/air-traffic-control/internal/terminal"
+)
+
+// Names of the tmux windows shown in the terminal pane in place of the agent
+const (
+	gitWindow   = "git"
+	shellWindow = "shell"
+)
+
+// handleGitWindow toggles the selected session's terminal pane between the
+// agent and a git UI in the worktree. Quitting the git UI also goes back
+func (m *Model) handleGitWindow() (tea.Model, tea.Cmd) {
+	gitUI := m.cfg.GitUI
+	if gitUI == "" {
+		gitUI = "lazygit"
+	}
+	return m.toggleWindow(gitWindow, gitUI)
+}
+
+// handleShellWindow toggles the selected session's terminal pane between the
+// agent and a shell in the worktree. The shell keeps running while the agent
+// is shown, until it exits
+func (m *Model) handleShellWindow() (tea.Model, tea.Cmd) {
+	command := m.userShell()
+	if cfg := m.runContainerConfig(); cfg.Enabled() {
+		if sess := m.cursorSession(); sess != nil {
+			return m.toggleWindow(shellWindow, container.ShellCommand(cfg, sess.WorktreePath))
+		}
+	}
+	if locale := m.agent().Locale; locale != "" {
+		command = "env " + shellJoin([]string{"LANG=" + locale, "LC_ALL=" + locale}) + " " + command
+	}
+	return m.toggleWindow(shellWindow, command)
+}
+
+// toggleWindow shows the selected session's window called name in its
+// terminal pane, starting command for it if needed, or the agent again if
+// that window is already shown. Either way the pane gets focus
+func (m *Model) toggleWindow(name, command string) (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil || m.settingUpSessions[sess.Name] {
+		return m, nil
+	}
+	if env := m.portEnv(sess); len(env) > 0 {
+		command = "env " + shellJoin(env) + " " + command
+	}
+	toggle := windowToggleMsg{session: sess.Name, name: name, command: command}
+	return m, tea.Sequence(m.activateSession(sess, true), func() tea.Msg { return toggle })
+}
+
+// windowToggleMsg asks for a window to be toggled once its session's terminal
+// is up (see toggleWindow)
+type windowToggleMsg struct {
+	session, name, command string
+}
+
+// showWindow does what toggleWindow asked for, now that the session is active
+func (m *Model) showWindow(msg windowToggleMsg) {
+	t, ok := m.terminals[msg.session]
+	if !ok {
+		return
+	}
+	var err error
+	if t.Window() == msg.name {
+		err = t.ShowAgent()
+	} else {
+		err = t.OpenWindow(msg.name, msg.command)
+	}
+	if err != nil {
+		m.err = err
+	}
+}
+
+// cycleWindow shows the next (or previous) window of the selected session in
+// its terminal pane
+func (m *Model) cycleWindow(forward bool) (tea.Model, tea.Cmd) {
+	sess := m.cursorSession()
+	if sess == nil {
+		return m, nil
+	}
+	if t, ok := m.terminals[sess.Name]; !ok || len(t.Windows()) < 2 {
+		m.message = "No other windows in this session"
+		return m, nil
+	}
+	cycle := windowCycleMsg{session: sess.Name, forward: forward}
+	return m, tea.Sequence(m.activateSession(sess, true), func() tea.Msg { return cycle })
+}
+
+// windowCycleMsg asks for the next (or previous) window once its session's
+// terminal is up (see cycleWindow)
+type windowCycleMsg struct {
+	session string
+	forward bool
+}
+
+// showNextWindow does what cycleWindow asked for, now that the session is
+// active
+func (m *Model) showNextWindow(msg windowCycleMsg) {
+	t, ok := m.terminals[msg.session]
+	if !ok {
+		return
+	}
+	if err := t.CycleWindow(msg.forward); err != nil {
+		m.err = err
+	}
+}
+
+// windowLabel is how a window is named in the window indicator
+func windowLabel(name string) string {
+	if name == "" {
+		return "agent"
+	}
+	return name
+}
+
+// renderWindowTabs draws the session's windows over the right end of the
+// terminal's first line when it has more than the agent's, highlighting the
+// one shown
+func renderWindowTabs(t terminal.Terminal, rendered string, tw int) string {
+	windows := t.Windows()
+	if len(windows) < 2 {
+		return rendered
+	}
+	shown := t.Window()
+	var parts []string
+	for _, w := range windows {
+		label := " " + truncate(windowLabel(w), 12) + " "
+		if w == shown {
+			parts = append(parts, scrollIndicatorStyle.Render(label))
+		} else {
+			parts = append(parts, metadataStyle.Render(label))
+		}
+	}
+	tabs := strings.Join(parts, "")
+
+	if lipgloss.Width(tabs) > tw {
+		return rendered
+	}
+	first, rest, multiline := strings.Cut(rendered, "\n")
+	first = overlayRight(first, tabs, tw)
+	if !multiline {
+		return first
+	}
+	return first + "\n" + rest
+}
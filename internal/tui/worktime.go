@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Agents' working time is the time their output kept changing, counted by
+// their terminals. It's saved to the database every workTimeInterval, so it
+// outlives ATC and adds up across runs
+
+// workTimeInterval is how often the agents' working time is saved
+const workTimeInterval = 10 * time.Second
+
+// workTimeTickMsg triggers saving the agents' working time
+type workTimeTickMsg struct{}
+
+// workTimesLoadedMsg carries every session's total working time, by session
+// ID
+type workTimesLoadedMsg struct {
+	times map[string]time.Duration
+}
+
+// workTimeTick schedules the next save
+func workTimeTick() tea.Cmd {
+	return tea.Tick(pollInterval(workTimeInterval), func(time.Time) tea.Msg {
+		return workTimeTickMsg{}
+	})
+}
+
+// saveWorkTime takes the time the current project's agents have worked since
+// the last save and adds it to their totals in the background, then loads
+// every session's total
+func (m *Model) saveWorkTime() tea.Cmd {
+	if m.db == nil {
+		return nil
+	}
+	worked := make(map[string]time.Duration)
+	for _, s := range m.sessions {
+		if t, ok := m.terminals[s.Name]; ok {
+			if d := t.TakeWorkTime(); d > 0 {
+				worked[s.ID] = d
+			}
+		}
+	}
+	db := m.db
+	return func() tea.Msg {
+		for id, d := range worked {
+			if err := db.AddWorkTime(id, d); err != nil {
+				return errMsg{err}
+			}
+		}
+		times, err := db.WorkTimes()
+		if err != nil {
+			return errMsg{err}
+		}
+		return workTimesLoadedMsg{times}
+	}
+}
+
+// formatWorkTime formats a working time to the minute, e.g. "45m" or "2h05m"
+func formatWorkTime(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
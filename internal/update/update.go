@@ -0,0 +1,177 @@
+// Package update finds ATC's latest GitHub release and replaces the running
+// binary with it.
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Repo is the GitHub repository releases are published to
+const Repo = "kevinzwang/air-traffic-control"
+
+// client is used for every request; downloads are a few megabytes
+var client = &http.Client{Timeout: 2 * time.Minute}
+
+// Latest returns the tag of the latest release, e.g. "v1.4.0"
+func Latest() (string, error) {
+	resp, err := client.Get("https://api.github.com/repos/" + Repo + "/releases/latest")
+	if err != nil {
+		return "", fmt.Errorf("failed to check for a new version: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to check for a new version: GitHub returned %s", resp.Status)
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("failed to read the latest release: %w", err)
+	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("the latest release has no version")
+	}
+	return release.TagName, nil
+}
+
+// Newer reports whether latest is a later version than current. Builds
+// without a release version ("dev") are never out of date.
+func Newer(current, latest string) bool {
+	cur, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	next, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	for i := range cur {
+		if next[i] != cur[i] {
+			return next[i] > cur[i]
+		}
+	}
+	return false
+}
+
+// parseVersion splits vMAJOR.MINOR.PATCH into its numbers, ignoring any
+// pre-release or build suffix
+func parseVersion(v string) ([3]int, bool) {
+	var out [3]int
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
+
+// AssetName is the release binary built for this platform
+func AssetName() string {
+	name := "atc-" + runtime.GOOS + "-" + runtime.GOARCH
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Install downloads version's binary for this platform, checks it against
+// the release's checksums and puts it in place of the binary at exe
+func Install(version, exe string) error {
+	asset := AssetName()
+	base := "https://github.com/" + Repo + "/releases/download/" + version + "/"
+	sums, err := download(base + "checksums.txt")
+	if err != nil {
+		return err
+	}
+	want, ok := checksum(sums, asset)
+	if !ok {
+		return fmt.Errorf("%s has no %s build", version, asset)
+	}
+	binary, err := download(base + asset)
+	if err != nil {
+		return err
+	}
+	if got := sha256.Sum256(binary); hex.EncodeToString(got[:]) != want {
+		return fmt.Errorf("%s doesn't match its checksum; not installed", asset)
+	}
+
+	// Write next to the old binary so the rename stays on one filesystem
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".atc-upgrade-*")
+	if err != nil {
+		return fmt.Errorf("can't write to %s: %w", filepath.Dir(exe), err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+	// Windows won't replace a running executable, but will rename it
+	if runtime.GOOS == "windows" {
+		os.Remove(exe + ".old")
+		if err := os.Rename(exe, exe+".old"); err != nil {
+			return fmt.Errorf("failed to move the old binary aside: %w", err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+	return nil
+}
+
+// download fetches a release file
+func download(url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	return data, nil
+}
+
+// checksum finds a file's SHA-256 in sha256sum output
+func checksum(sums []byte, name string) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,35 @@
+package update
+
+import "testing"
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            bool
+	}{
+		{"v1.2.3", "v1.2.4", true},
+		{"v1.2.3", "v1.10.0", true},
+		{"v1.2.3", "v2.0.0", true},
+		{"v1.2.3", "v1.2.3", false},
+		{"v1.3.0", "v1.2.9", false},
+		{"v1.2", "v1.2.1", true},
+		{"v1.2.3-rc1", "v1.2.3", false},
+		{"dev", "v1.2.3", false},
+		{"v1.2.3", "nightly", false},
+	}
+	for _, tt := range tests {
+		if got := Newer(tt.current, tt.latest); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	sums := []byte("abc123  atc-linux-amd64\nDEF456 *atc-windows-amd64.exe\n")
+	if got, ok := checksum(sums, "atc-windows-amd64.exe"); !ok || got != "def456" {
+		t.Errorf("checksum = %q, %v", got, ok)
+	}
+	if _, ok := checksum(sums, "atc-darwin-arm64"); ok {
+		t.Error("found a checksum for a missing file")
+	}
+}
@@ -0,0 +1,161 @@
+package usage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// Limit is a usage or rate limit the agent ran into
+type Limit struct {
+	At    time.Time // when the agent ran into it
+	Reset time.Time // when it lifts, zero if the message didn't say
+}
+
+// Active reports whether the limit is still in effect at now. One without a
+// reset time holds until the agent gets a reply through again.
+func (l Limit) Active(now time.Time) bool {
+	return l.Reset.IsZero() || l.Reset.After(now)
+}
+
+var (
+	// limitRe matches Claude Code's limit messages, e.g. "Claude usage limit
+	// reached", "5-hour limit reached", "API Error: ... rate_limit_error"
+	limitRe = regexp.MustCompile(`(?i)\b(?:usage|5-hour|session|weekly|opus|sonnet)\s+limit\s+reached|rate_limit_error`)
+
+	// epochRe matches the machine-readable form transcripts use, e.g.
+	// "Claude AI usage limit reached|1760000000"
+	epochRe = regexp.MustCompile(`limit reached\|(\d{9,})`)
+
+	// resetRe matches when the limit lifts, e.g. "resets 3pm", "reset at
+	// 3:30pm (Europe/Berlin)", "resets Oct 20, 9am"
+	resetRe = regexp.MustCompile(`(?i)\bresets?\s+(?:at\s+)?(?:([a-z]{3})\s+(\d{1,2}),?\s+(?:at\s+)?)?(\d{1,2})(?::(\d{2}))?\s*([ap]m)\b(?:\s*\(([^)]+)\))?`)
+)
+
+// ParseLimit recognizes the message Claude Code shows when the agent runs
+// into a usage or rate limit. Reset times without a date are taken to be the
+// next such time after at, when the message appeared.
+func ParseLimit(text string, at time.Time) (Limit, bool) {
+	if !limitRe.MatchString(text) {
+		return Limit{}, false
+	}
+	l := Limit{At: at}
+	if m := epochRe.FindStringSubmatch(text); m != nil {
+		if sec, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+			l.Reset = time.Unix(sec, 0)
+		}
+		return l, true
+	}
+	m := resetRe.FindStringSubmatch(text)
+	if m == nil {
+		return l, true
+	}
+	loc := at.Location()
+	if m[6] != "" {
+		if tz, err := time.LoadLocation(m[6]); err == nil {
+			loc = tz
+		}
+	}
+	hour, _ := strconv.Atoi(m[3])
+	minute, _ := strconv.Atoi(m[4])
+	if hour < 1 || hour > 12 || minute > 59 {
+		return l, true
+	}
+	hour %= 12
+	if strings.EqualFold(m[5], "pm") {
+		hour += 12
+	}
+	local := at.In(loc)
+	if m[1] != "" {
+		month, err := time.Parse("Jan", strings.ToUpper(m[1][:1])+strings.ToLower(m[1][1:]))
+		day, _ := strconv.Atoi(m[2])
+		if err != nil {
+			return l, true
+		}
+		l.Reset = time.Date(local.Year(), month.Month(), day, hour, minute, 0, 0, loc)
+		if l.Reset.Before(at) {
+			l.Reset = l.Reset.AddDate(1, 0, 0)
+		}
+		return l, true
+	}
+	l.Reset = time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+	if !l.Reset.After(at) {
+		l.Reset = l.Reset.AddDate(0, 0, 1)
+	}
+	return l, true
+}
+
+// limitEntry is the part of a transcript line needed to spot limit messages,
+// which Claude Code records as API error replies
+type limitEntry struct {
+	Type              string    `json:"type"`
+	Timestamp         time.Time `json:"timestamp"`
+	IsAPIErrorMessage bool      `json:"isApiErrorMessage"`
+	Message           struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+// text returns the entry's message text
+func (e *limitEntry) text() string {
+	var s string
+	if json.Unmarshal(e.Message.Content, &s) == nil {
+		return s
+	}
+	var blocks []struct {
+		Text string `json:"text"`
+	}
+	json.Unmarshal(e.Message.Content, &blocks)
+	texts := make([]string, len(blocks))
+	for i, b := range blocks {
+		texts[i] = b.Text
+	}
+	return strings.Join(texts, "\n")
+}
+
+// ParseLastLimit returns the limit the transcript's agent last ran into,
+// unless a reply has come through since
+func ParseLastLimit(r io.Reader) (Limit, bool) {
+	var limit Limit
+	limited := false
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if bytes.Contains(line, []byte(`"type":"assistant"`)) {
+			var e limitEntry
+			if json.Unmarshal(line, &e) == nil && e.Type == "assistant" {
+				if !e.IsAPIErrorMessage {
+					limited = false
+				} else if l, ok := ParseLimit(e.text(), e.Timestamp); ok {
+					limit, limited = l, true
+				}
+			}
+		}
+		if err != nil {
+			return limit, limited
+		}
+	}
+}
+
+// LimitForWorktree returns the limit the agent in a worktree last ran into,
+// from its most recent conversation, unless a reply has come through since
+func LimitForWorktree(worktreePath string) (Limit, bool) {
+	files := worktree.ConversationFilesNewestFirst(worktreePath)
+	if len(files) == 0 {
+		return Limit{}, false
+	}
+	f, err := os.Open(files[0])
+	if err != nil {
+		return Limit{}, false
+	}
+	defer f.Close()
+	return ParseLastLimit(f)
+}
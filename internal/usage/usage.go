@@ -0,0 +1,167 @@
+package usage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kevinzwang/air-traffic-control/internal/worktree"
+)
+
+// Totals is the token usage and estimated cost of one or more conversations
+type Totals struct {
+	InputTokens      int64
+	OutputTokens     int64
+	CacheWriteTokens int64
+	CacheReadTokens  int64
+	CostUSD          float64
+}
+
+// Add accumulates o into t
+func (t *Totals) Add(o Totals) {
+	t.InputTokens += o.InputTokens
+	t.OutputTokens += o.OutputTokens
+	t.CacheWriteTokens += o.CacheWriteTokens
+	t.CacheReadTokens += o.CacheReadTokens
+	t.CostUSD += o.CostUSD
+}
+
+// Tokens returns the total number of tokens of all kinds
+func (t Totals) Tokens() int64 {
+	return t.InputTokens + t.OutputTokens + t.CacheWriteTokens + t.CacheReadTokens
+}
+
+// price is a model's API price in USD per million tokens
+type price struct {
+	input, output, cacheWrite, cacheRead float64
+}
+
+// prices maps model name fragments to list prices, most specific first.
+// Costs are estimates: they use public API prices regardless of the plan the
+// agent is billed under.
+var prices = []struct {
+	match string
+	price price
+}{
+	{"opus-4-5", price{5, 25, 6.25, 0.50}},
+	{"opus", price{15, 75, 18.75, 1.50}},
+	{"sonnet", price{3, 15, 3.75, 0.30}},
+	{"haiku-4-5", price{1, 5, 1.25, 0.10}},
+	{"haiku", price{0.80, 4, 1, 0.08}},
+}
+
+// priceFor returns the price of a model, or false if it is unknown
+func priceFor(model string) (price, bool) {
+	for _, p := range prices {
+		if strings.Contains(model, p.match) {
+			return p.price, true
+		}
+	}
+	return price{}, false
+}
+
+// transcriptEntry is the part of a Claude Code transcript line that carries
+// token usage
+type transcriptEntry struct {
+	Type    string `json:"type"`
+	Message struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+// Parse sums the token usage of the assistant messages in a transcript.
+// Claude Code writes one line per content block, each repeating the usage of
+// its API response, so responses are counted once by message ID.
+func Parse(r io.Reader) (Totals, error) {
+	byID := make(map[string]Totals)
+	var order []string
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if bytes.Contains(line, []byte(`"usage"`)) {
+			var e transcriptEntry
+			if json.Unmarshal(line, &e) == nil && e.Type == "assistant" {
+				u := e.Message.Usage
+				t := Totals{
+					InputTokens:      u.InputTokens,
+					OutputTokens:     u.OutputTokens,
+					CacheWriteTokens: u.CacheCreationInputTokens,
+					CacheReadTokens:  u.CacheReadInputTokens,
+				}
+				if p, ok := priceFor(e.Message.Model); ok {
+					t.CostUSD = (float64(t.InputTokens)*p.input +
+						float64(t.OutputTokens)*p.output +
+						float64(t.CacheWriteTokens)*p.cacheWrite +
+						float64(t.CacheReadTokens)*p.cacheRead) / 1e6
+				}
+				id := e.Message.ID
+				if id == "" {
+					id = fmt.Sprintf("#%d", len(order))
+				}
+				if _, seen := byID[id]; !seen {
+					order = append(order, id)
+				}
+				byID[id] = t
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Totals{}, err
+		}
+	}
+
+	var total Totals
+	for _, id := range order {
+		total.Add(byID[id])
+	}
+	return total, nil
+}
+
+// ForWorktree sums the usage of all Claude Code conversations in a worktree
+func ForWorktree(worktreePath string) (Totals, error) {
+	var total Totals
+	for _, path := range worktree.ConversationFiles(worktreePath) {
+		f, err := os.Open(path)
+		if err != nil {
+			return Totals{}, err
+		}
+		t, err := Parse(f)
+		f.Close()
+		if err != nil {
+			return Totals{}, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		total.Add(t)
+	}
+	return total, nil
+}
+
+// FormatTokens renders a token count compactly, e.g. 950, 12.3k, 4.1M
+func FormatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// FormatCost renders an estimated cost in dollars
+func FormatCost(usd float64) string {
+	return fmt.Sprintf("$%.2f", usd)
+}
@@ -0,0 +1,94 @@
+package usage
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	transcript := strings.Join([]string{
+		`{"type":"user","message":{"role":"user","content":"hi"}}`,
+		// Two content blocks of the same response repeat its usage
+		`{"type":"assistant","message":{"id":"msg_1","model":"claude-sonnet-4-5","usage":{"input_tokens":1000,"output_tokens":10,"cache_creation_input_tokens":0,"cache_read_input_tokens":0}}}`,
+		`{"type":"assistant","message":{"id":"msg_1","model":"claude-sonnet-4-5","usage":{"input_tokens":1000,"output_tokens":200,"cache_creation_input_tokens":0,"cache_read_input_tokens":0}}}`,
+		`{"type":"assistant","message":{"id":"msg_2","model":"claude-opus-4-1","usage":{"input_tokens":0,"output_tokens":1000,"cache_creation_input_tokens":2000,"cache_read_input_tokens":10000}}}`,
+		`{"type":"assistant","message":{"id":"msg_3","model":"<synthetic>","usage":{"input_tokens":5,"output_tokens":5}}}`,
+		`not json "usage"`,
+	}, "\n")
+
+	got, err := Parse(strings.NewReader(transcript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Totals{
+		InputTokens:      1005,
+		OutputTokens:     1205,
+		CacheWriteTokens: 2000,
+		CacheReadTokens:  10000,
+	}
+	// sonnet: 1000*3 + 200*15; opus: 1000*75 + 2000*18.75 + 10000*1.5; synthetic: free
+	wantCost := (3000.0 + 3000 + 75000 + 37500 + 15000) / 1e6
+	if got.InputTokens != want.InputTokens || got.OutputTokens != want.OutputTokens ||
+		got.CacheWriteTokens != want.CacheWriteTokens || got.CacheReadTokens != want.CacheReadTokens {
+		t.Errorf("Parse = %+v, want %+v", got, want)
+	}
+	if math.Abs(got.CostUSD-wantCost) > 1e-9 {
+		t.Errorf("CostUSD = %v, want %v", got.CostUSD, wantCost)
+	}
+}
+
+func TestFormatTokens(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{12_345, "12.3k"},
+		{4_100_000, "4.1M"},
+	}
+	for _, tt := range tests {
+		if got := FormatTokens(tt.n); got != tt.want {
+			t.Errorf("FormatTokens(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	at := time.Date(2025, 10, 17, 14, 20, 0, 0, time.UTC)
+	tests := []struct {
+		text  string
+		ok    bool
+		reset time.Time
+	}{
+		{"Claude AI usage limit reached|1760716800", true, time.Unix(1760716800, 0)},
+		{"5-hour limit reached ∙ resets 3pm", true, time.Date(2025, 10, 17, 15, 0, 0, 0, time.UTC)},
+		{"Claude usage limit reached. Your limit will reset at 2am (UTC).", true, time.Date(2025, 10, 18, 2, 0, 0, 0, time.UTC)},
+		{"Session limit reached ∙ resets 1:30pm", true, time.Date(2025, 10, 18, 13, 30, 0, 0, time.UTC)},
+		{"Weekly limit reached ∙ resets Oct 20, 9am", true, time.Date(2025, 10, 20, 9, 0, 0, 0, time.UTC)},
+		{`API Error: 429 {"type":"error","error":{"type":"rate_limit_error"}}`, true, time.Time{}},
+		{"Add a rate limit to the API; the limit resets at 3pm", false, time.Time{}},
+	}
+	for _, tt := range tests {
+		l, ok := ParseLimit(tt.text, at)
+		if ok != tt.ok || !l.Reset.Equal(tt.reset) {
+			t.Errorf("ParseLimit(%q) = %v, %v; want reset %v, %v", tt.text, l.Reset, ok, tt.reset, tt.ok)
+		}
+	}
+}
+
+func TestParseLastLimit(t *testing.T) {
+	limited := `{"type":"assistant","timestamp":"2025-10-17T14:20:00Z","isApiErrorMessage":true,"message":{"model":"<synthetic>","content":[{"type":"text","text":"5-hour limit reached ∙ resets 3pm"}]}}`
+	reply := `{"type":"assistant","timestamp":"2025-10-17T15:05:00Z","message":{"content":[{"type":"text","text":"Done."}]}}`
+	user := `{"type":"user","message":{"role":"user","content":"go on"}}`
+
+	l, ok := ParseLastLimit(strings.NewReader(strings.Join([]string{reply, user, limited, user}, "\n")))
+	if !ok || !l.Reset.Equal(time.Date(2025, 10, 17, 15, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseLastLimit = %v, %v; want the limit resetting at 15:00", l, ok)
+	}
+	if _, ok := ParseLastLimit(strings.NewReader(strings.Join([]string{limited, user, reply}, "\n"))); ok {
+		t.Error("ParseLastLimit reported a limit the agent has replied since")
+	}
+}
@@ -0,0 +1,186 @@
+// Package watch notices changes other tools and ATC instances make to a
+// repository's branches and to ATC's database, so the TUI can refresh
+// without waiting for its next poll.
+package watch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Change is what a burst of file events changed
+type Change int
+
+const (
+	// RefsChanged means a branch of the watched repository was created,
+	// deleted, moved or fetched
+	RefsChanged Change = iota
+	// DBChanged means something wrote to the database; check whether the
+	// sessions changed
+	DBChanged
+)
+
+// settle is how long the files have to be quiet before a change is reported,
+// so that one git command or transaction is reported once
+const settle = 250 * time.Millisecond
+
+// Watcher watches the database and one repository's refs
+type Watcher struct {
+	fs      *fsnotify.Watcher
+	changes chan Change
+
+	dbDir, dbName string
+
+	mu      sync.Mutex
+	gitDir  string   // the watched repository's .git, "" if none
+	refDirs []string // the directories under .git/refs being watched
+}
+
+// New starts watching the database at dbPath. Close it when done.
+func New(dbPath string) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{
+		fs:      fsw,
+		changes: make(chan Change, 2),
+		dbDir:   filepath.Dir(dbPath),
+		dbName:  filepath.Base(dbPath),
+	}
+	// SQLite in WAL mode writes to sessions.db-wal next to the database
+	if err := fsw.Add(w.dbDir); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	go w.run()
+	return w, nil
+}
+
+// Changes delivers changes as they settle. It's closed when the watcher is.
+func (w *Watcher) Changes() <-chan Change {
+	return w.changes
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
+
+// WatchRepo watches repoPath's refs in place of the previous repository's.
+// An empty path stops watching refs. Repositories whose .git isn't a
+// directory aren't watched.
+func (w *Watcher) WatchRepo(repoPath string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	gitDir := ""
+	if repoPath != "" {
+		gitDir = filepath.Join(repoPath, ".git")
+	}
+	if gitDir == w.gitDir {
+		return
+	}
+	if w.gitDir != "" {
+		w.fs.Remove(w.gitDir)
+		for _, dir := range w.refDirs {
+			w.fs.Remove(dir)
+		}
+	}
+	w.gitDir, w.refDirs = "", nil
+	if info, err := os.Stat(gitDir); gitDir == "" || err != nil || !info.IsDir() {
+		return
+	}
+	// .git itself for packed-refs, which git rewrites when deleting a
+	// packed branch
+	if err := w.fs.Add(gitDir); err != nil {
+		return
+	}
+	w.gitDir = gitDir
+	w.addRefDirs(filepath.Join(gitDir, "refs"))
+}
+
+// addRefDirs watches dir and every directory under it. fsnotify doesn't
+// watch subdirectories, and branches like feature/x live in them.
+// w.mu must be held.
+func (w *Watcher) addRefDirs(dir string) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if w.fs.Add(path) == nil {
+			w.refDirs = append(w.refDirs, path)
+		}
+		return nil
+	})
+}
+
+// run collects file events until they settle, then reports what changed
+func (w *Watcher) run() {
+	defer close(w.changes)
+
+	pending := make(map[Change]bool)
+	timer := time.NewTimer(settle)
+	timer.Stop()
+	for {
+		select {
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if change, ok := w.classify(event); ok {
+				pending[change] = true
+				timer.Reset(settle)
+			}
+		case _, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+		case <-timer.C:
+			for change := range pending {
+				// A change already waiting to be picked up covers this one
+				select {
+				case w.changes <- change:
+				default:
+				}
+			}
+			clear(pending)
+		}
+	}
+}
+
+// classify says what a file event changed, if anything of interest
+func (w *Watcher) classify(event fsnotify.Event) (Change, bool) {
+	if event.Op == fsnotify.Chmod {
+		return 0, false
+	}
+	dir, name := filepath.Dir(event.Name), filepath.Base(event.Name)
+	if dir == w.dbDir && (name == w.dbName || name == w.dbName+"-wal") {
+		return DBChanged, true
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.gitDir == "" {
+		return 0, false
+	}
+	if dir == w.gitDir {
+		return RefsChanged, name == "packed-refs"
+	}
+	refs := filepath.Join(w.gitDir, "refs")
+	if event.Name != refs && !strings.HasPrefix(event.Name, refs+string(filepath.Separator)) {
+		return 0, false
+	}
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			w.addRefDirs(event.Name)
+		}
+	}
+	return RefsChanged, true
+}
@@ -0,0 +1,69 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	repo := filepath.Join(dir, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, ".git", "refs", "heads"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "atc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	w, err := New(filepath.Join(dir, "atc", "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.WatchRepo(repo)
+
+	expect := func(want Change, write func() error) {
+		t.Helper()
+		if err := write(); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case got := <-w.Changes():
+			if got != want {
+				t.Errorf("change = %v, want %v", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no change reported, want %v", want)
+		}
+	}
+	write := func(path string) func() error {
+		return func() error { return os.WriteFile(path, []byte("x"), 0644) }
+	}
+
+	expect(DBChanged, write(filepath.Join(dir, "atc", "sessions.db-wal")))
+	expect(RefsChanged, write(filepath.Join(repo, ".git", "refs", "heads", "main")))
+	expect(RefsChanged, func() error {
+		return os.Mkdir(filepath.Join(repo, ".git", "refs", "heads", "feature"), 0755)
+	})
+	// The new directory is watched too
+	expect(RefsChanged, write(filepath.Join(repo, ".git", "refs", "heads", "feature", "x")))
+	expect(RefsChanged, write(filepath.Join(repo, ".git", "packed-refs")))
+
+	// Other files under .git and next to the database are ignored
+	os.WriteFile(filepath.Join(repo, ".git", "index"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(dir, "atc", "events.log"), []byte("x"), 0644)
+	select {
+	case got := <-w.Changes():
+		t.Errorf("reported %v for unrelated files", got)
+	case <-time.After(2 * settle):
+	}
+
+	w.WatchRepo("")
+	os.WriteFile(filepath.Join(repo, ".git", "refs", "heads", "dev"), []byte("x"), 0644)
+	select {
+	case got := <-w.Changes():
+		t.Errorf("reported %v after the repo stopped being watched", got)
+	case <-time.After(2 * settle):
+	}
+}
@@ -1,9 +1,17 @@
 package worktree
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 // getClaudeProjectDir returns the Claude Code project directory for a worktree path.
@@ -49,3 +57,282 @@ func HasExistingConversation(worktreePath string) bool {
 	return false
 }
 
+// ConversationFiles returns the paths of all Claude Code conversation
+// transcripts (.jsonl) for the given worktree path
+func ConversationFiles(worktreePath string) []string {
+	projectDir := getClaudeProjectDir(worktreePath)
+	if projectDir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		return nil
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".jsonl") {
+			files = append(files, filepath.Join(projectDir, entry.Name()))
+		}
+	}
+	return files
+}
+
+// ConversationFilesNewestFirst returns the worktree's conversation
+// transcripts, most recently written first
+func ConversationFilesNewestFirst(worktreePath string) []string {
+	files := ConversationFiles(worktreePath)
+	modTimes := make(map[string]int64, len(files))
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil {
+			modTimes[f] = info.ModTime().UnixNano()
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return modTimes[files[i]] > modTimes[files[j]] })
+	return files
+}
+
+// summaries remembers the last summary of each transcript read, with the
+// modification time and size it had, so transcripts that haven't changed
+// since aren't read again
+var summaries = struct {
+	sync.Mutex
+	byPath map[string]cachedSummary
+}{byPath: make(map[string]cachedSummary)}
+
+type cachedSummary struct {
+	modTime time.Time
+	size    int64
+	summary string
+}
+
+// LatestSummary returns the most recent conversation summary Claude Code
+// recorded for the worktree, or "" if there is none. Transcripts are checked
+// newest first and the last summary entry of the first one that has any wins.
+func LatestSummary(worktreePath string) string {
+	for _, f := range ConversationFilesNewestFirst(worktreePath) {
+		if summary := transcriptSummary(f); summary != "" {
+			return summary
+		}
+	}
+	return ""
+}
+
+// transcriptSummary returns the last summary in a transcript, reading it only
+// if it changed since it was last read
+func transcriptSummary(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return ""
+	}
+
+	summaries.Lock()
+	cached, ok := summaries.byPath[path]
+	summaries.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.summary
+	}
+
+	summary := lastSummary(file)
+	summaries.Lock()
+	summaries.byPath[path] = cachedSummary{modTime: info.ModTime(), size: info.Size(), summary: summary}
+	summaries.Unlock()
+	return summary
+}
+
+// lastSummary returns the text of the last {"type":"summary"} entry in a
+// transcript
+func lastSummary(r io.Reader) string {
+	var summary string
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		// Transcript lines can be huge (tool output); only decode likely matches
+		if bytes.Contains(line, []byte(`"summary"`)) {
+			var entry struct {
+				Type    string `json:"type"`
+				Summary string `json:"summary"`
+			}
+			if json.Unmarshal(line, &entry) == nil && entry.Type == "summary" && entry.Summary != "" {
+				summary = entry.Summary
+			}
+		}
+		if err != nil {
+			return summary
+		}
+	}
+}
+
+// Conversation is one of a worktree's Claude Code conversations
+type Conversation struct {
+	ID       string // session ID, for claude --resume
+	Summary  string // its summary, or its first prompt if it has none yet
+	Modified time.Time
+}
+
+// ListConversations returns the worktree's conversations, most recently
+// active first
+func ListConversations(worktreePath string) []Conversation {
+	var conversations []Conversation
+	for _, path := range ConversationFilesNewestFirst(worktreePath) {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		c := Conversation{ID: strings.TrimSuffix(filepath.Base(path), ".jsonl")}
+		if info, err := f.Stat(); err == nil {
+			c.Modified = info.ModTime()
+		}
+		c.Summary = lastSummary(f)
+		if c.Summary == "" {
+			f.Seek(0, io.SeekStart)
+			c.Summary = firstPrompt(f)
+		}
+		f.Close()
+		conversations = append(conversations, c)
+	}
+	return conversations
+}
+
+// firstPrompt returns the first prompt in a transcript
+func firstPrompt(r io.Reader) string {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if bytes.Contains(line, []byte(`"type":"user"`)) {
+			var e conversationEntry
+			if json.Unmarshal(line, &e) == nil && e.Type == RoleUser && !e.IsMeta && !e.IsSidechain {
+				for _, msg := range entryMessages(&e) {
+					if msg.Role == RoleUser {
+						return msg.Text
+					}
+				}
+			}
+		}
+		if err != nil {
+			return ""
+		}
+	}
+}
+
+// Roles of conversation messages
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool" // a tool the agent used, e.g. "Bash: go test ./..."
+)
+
+// ConversationMessage is a message of a Claude Code conversation: a prompt
+// the agent was given, its reply, or a tool it used
+type ConversationMessage struct {
+	Role string
+	Text string
+	Time time.Time
+}
+
+// conversationEntry is the part of a transcript line needed to show it
+type conversationEntry struct {
+	Type        string    `json:"type"`
+	Timestamp   time.Time `json:"timestamp"`
+	IsMeta      bool      `json:"isMeta"`
+	IsSidechain bool      `json:"isSidechain"`
+	Message     struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+// contentBlock is a block of a message's content
+type contentBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text"`
+	Name  string          `json:"name"`
+	Input json.RawMessage `json:"input"`
+}
+
+// toolInputKeys are the tool input fields that best describe a tool call,
+// in order of preference
+var toolInputKeys = []string{"command", "file_path", "path", "pattern", "url", "query", "description", "prompt"}
+
+// describeToolUse renders a tool call as one line, e.g. "Bash: go test ./..."
+func describeToolUse(b contentBlock) string {
+	var input map[string]any
+	json.Unmarshal(b.Input, &input)
+	for _, key := range toolInputKeys {
+		if v, ok := input[key].(string); ok && v != "" {
+			return b.Name + ": " + strings.Join(strings.Fields(v), " ")
+		}
+	}
+	return b.Name
+}
+
+// ReadConversation parses a transcript into the messages of its main
+// conversation. Tool output, the agent's thinking, subagent conversations
+// and messages Claude Code adds itself are left out.
+func ReadConversation(r io.Reader) ([]ConversationMessage, error) {
+	var messages []ConversationMessage
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		var e conversationEntry
+		if len(bytes.TrimSpace(line)) > 0 && json.Unmarshal(line, &e) == nil &&
+			(e.Type == RoleUser || e.Type == RoleAssistant) && !e.IsMeta && !e.IsSidechain {
+			messages = append(messages, entryMessages(&e)...)
+		}
+		if err == io.EOF {
+			return messages, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// entryMessages returns the messages of a transcript entry
+func entryMessages(e *conversationEntry) []ConversationMessage {
+	var text string
+	if json.Unmarshal(e.Message.Content, &text) == nil {
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		return []ConversationMessage{{Role: e.Type, Text: text, Time: e.Timestamp}}
+	}
+	var blocks []contentBlock
+	if json.Unmarshal(e.Message.Content, &blocks) != nil {
+		return nil
+	}
+	var messages []ConversationMessage
+	for _, b := range blocks {
+		switch {
+		case b.Type == "text" && strings.TrimSpace(b.Text) != "":
+			messages = append(messages, ConversationMessage{Role: e.Type, Text: b.Text, Time: e.Timestamp})
+		case b.Type == "tool_use":
+			messages = append(messages, ConversationMessage{Role: RoleTool, Text: describeToolUse(b), Time: e.Timestamp})
+		}
+	}
+	return messages
+}
+
+// LatestConversation returns the messages of the worktree's most recent
+// Claude Code conversation, or nil if it has none
+func LatestConversation(worktreePath string) ([]ConversationMessage, error) {
+	files := ConversationFilesNewestFirst(worktreePath)
+	if len(files) == 0 {
+		return nil, nil
+	}
+	f, err := os.Open(files[0])
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	messages, err := ReadConversation(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", files[0], err)
+	}
+	return messages, nil
+}
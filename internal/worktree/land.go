@@ -0,0 +1,145 @@
+package worktree
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/kevinzwang/air-traffic-control/internal/logging"
+)
+
+// CommitSubjects returns the subjects of the commits on branch that aren't on
+// base, oldest first
+func CommitSubjects(repoPath, base, branch string) ([]string, error) {
+	cmd := exec.Command("git", "log", "--reverse", "--format=%s", base+".."+branch)
+	cmd.Dir = repoPath
+	output, err := logging.CombinedOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits since %s: %w\nOutput: %s", base, err, string(output))
+	}
+	var subjects []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+	return subjects, nil
+}
+
+// SquashLand squash-merges branch into the local branch target as a single
+// commit with message, without checking out either of them. If target is
+// checked out somewhere (usually the main checkout), that worktree is
+// fast-forwarded to the new commit, which fails rather than overwrite local
+// changes there. It returns the commit, or "" if target already has all of
+// branch's changes
+func SquashLand(repoPath, branch, target, message string) (string, error) {
+	cmd := exec.Command("git", "merge-tree", "--write-tree", "--name-only", "--no-messages", target, branch)
+	cmd.Dir = repoPath
+	output, err := logging.Output(cmd)
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 && len(output) > 0 {
+			return "", fmt.Errorf("%s conflicts with %s; sync it first", branch, target)
+		}
+		return "", fmt.Errorf("failed to merge %s into %s: %w", branch, target, err)
+	}
+	tree := strings.TrimSpace(string(output))
+
+	cmd = exec.Command("git", "rev-parse", target, target+"^{tree}")
+	cmd.Dir = repoPath
+	output, err = logging.CombinedOutput(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w\nOutput: %s", target, err, string(output))
+	}
+	var parent, parentTree string
+	if _, err := fmt.Sscan(string(output), &parent, &parentTree); err != nil {
+		return "", fmt.Errorf("failed to parse rev-parse output %q: %w", output, err)
+	}
+	if tree == parentTree {
+		return "", nil
+	}
+
+	cmd = exec.Command("git", "commit-tree", tree, "-p", parent, "-F", "-")
+	cmd.Dir = repoPath
+	cmd.Stdin = strings.NewReader(message)
+	output, err = logging.CombinedOutput(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to create the squash commit: %w\nOutput: %s", err, string(output))
+	}
+	commit := strings.TrimSpace(string(output))
+
+	checkout, err := branchCheckout(repoPath, target)
+	if err != nil {
+		return "", err
+	}
+	if checkout != "" {
+		cmd = exec.Command("git", "merge", "--ff-only", commit)
+		cmd.Dir = checkout
+	} else {
+		cmd = exec.Command("git", "update-ref", "refs/heads/"+target, commit, parent)
+		cmd.Dir = repoPath
+	}
+	if output, err := logging.CombinedOutput(cmd); err != nil {
+		return "", fmt.Errorf("failed to move %s to the squash commit: %w\nOutput: %s", target, err, string(output))
+	}
+	return commit, nil
+}
+
+// branchCheckout returns the worktree that has branch checked out, or "" if
+// none does
+func branchCheckout(repoPath, branch string) (string, error) {
+	cmd := exec.Command("git", "worktree", "list", "--porcelain")
+	cmd.Dir = repoPath
+	output, err := logging.Output(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	var path string
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if p, ok := strings.CutPrefix(line, "worktree "); ok {
+			path = p
+		} else if line == "branch refs/heads/"+branch {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// PushBranch pushes a local branch to the branch it tracks and returns that
+// upstream, e.g. origin/main. A branch that tracks nothing isn't pushed and
+// "" is returned
+func PushBranch(repoPath, branch string) (string, error) {
+	cmd := exec.Command("git", "config", "branch."+branch+".remote")
+	cmd.Dir = repoPath
+	output, err := logging.Output(cmd)
+	remote := strings.TrimSpace(string(output))
+	if err != nil || remote == "" || remote == "." {
+		return "", nil
+	}
+	cmd = exec.Command("git", "config", "branch."+branch+".merge")
+	cmd.Dir = repoPath
+	output, err = logging.Output(cmd)
+	if err != nil {
+		return "", nil
+	}
+	merge := strings.TrimSpace(string(output))
+
+	cmd = exec.Command("git", "push", remote, "refs/heads/"+branch+":"+merge)
+	cmd.Dir = repoPath
+	if output, err := logging.CombinedOutput(cmd); err != nil {
+		return "", fmt.Errorf("failed to push %s to %s: %w\nOutput: %s", branch, remote, err, string(output))
+	}
+	return remote + "/" + strings.TrimPrefix(merge, "refs/heads/"), nil
+}
+
+// DeleteBranch deletes a local branch, merged or not
+func DeleteBranch(repoPath, branch string) error {
+	cmd := exec.Command("git", "branch", "-D", branch)
+	cmd.Dir = repoPath
+	if output, err := logging.CombinedOutput(cmd); err != nil {
+		return fmt.Errorf("failed to delete branch %s: %w\nOutput: %s", branch, err, string(output))
+	}
+	return nil
+}
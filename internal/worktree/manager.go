@@ -5,12 +5,17 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/kevinzwang/air-traffic-control/internal/logging"
 )
 
 // CreateWorktree creates a new git worktree
 // If useExisting is true, it attaches to an existing branch instead of creating a new one
-// baseBranch specifies the base for new branches (ignored when useExisting is true)
+// baseBranch specifies the base for new branches. With useExisting, a remote-tracking
+// baseBranch (like origin/feature) creates branchName from it, tracking it
 func CreateWorktree(repoPath, sessionName, branchName, targetPath, baseBranch string, useExisting bool) error {
 	// Ensure target directory's parent exists
 	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
@@ -18,18 +23,22 @@ func CreateWorktree(repoPath, sessionName, branchName, targetPath, baseBranch st
 	}
 
 	var cmd *exec.Cmd
-	if useExisting {
+	if useExisting && baseBranch != "" && baseBranch != branchName {
+		// Check out a remote branch as a local branch tracking it
+		cmd = exec.Command("git", "worktree", "add", "--track", "-b", branchName, targetPath, baseBranch)
+	} else if useExisting {
 		// Attach worktree to existing branch
 		cmd = exec.Command("git", "worktree", "add", targetPath, branchName)
 	} else {
-		// Create new branch from base
+		// Create new branch from base. It doesn't track a remote base, so a
+		// push can't land on someone else's branch
 		if baseBranch == "" {
 			baseBranch = "HEAD"
 		}
-		cmd = exec.Command("git", "worktree", "add", "-b", branchName, targetPath, baseBranch)
+		cmd = exec.Command("git", "worktree", "add", "--no-track", "-b", branchName, targetPath, baseBranch)
 	}
 	cmd.Dir = repoPath
-	output, err := cmd.CombinedOutput()
+	output, err := logging.CombinedOutput(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to create worktree: %w\nOutput: %s", err, string(output))
 	}
@@ -39,43 +48,142 @@ func CreateWorktree(repoPath, sessionName, branchName, targetPath, baseBranch st
 
 // DeleteWorktree removes a git worktree
 func DeleteWorktree(worktreePath string) error {
-	// Get the parent git repository to execute the command from
-	// We need to find the main repo by looking at the worktree's .git file
+	mainRepoPath, err := MainRepoPath(worktreePath)
+	if err != nil {
+		return err
+	}
+
+	// Remove the worktree
+	cmd := exec.Command("git", "worktree", "remove", worktreePath, "--force")
+	cmd.Dir = mainRepoPath
+	output, err := logging.CombinedOutput(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to remove worktree: %w\nOutput: %s", err, string(output))
+	}
+
+	return nil
+}
+
+// MoveWorktree moves a git worktree to newPath, which must not exist yet
+func MoveWorktree(worktreePath, newPath string) error {
+	mainRepoPath, err := MainRepoPath(worktreePath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return fmt.Errorf("failed to create target directory: %w", err)
+	}
+
+	cmd := exec.Command("git", "worktree", "move", worktreePath, newPath)
+	cmd.Dir = mainRepoPath
+	output, err := logging.CombinedOutput(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to move worktree: %w\nOutput: %s", err, string(output))
+	}
+
+	return nil
+}
+
+// MainRepoPath returns the repository a worktree belongs to, read from the
+// worktree's .git file
+func MainRepoPath(worktreePath string) (string, error) {
 	gitFile := filepath.Join(worktreePath, ".git")
 	data, err := os.ReadFile(gitFile)
 	if err != nil {
-		return fmt.Errorf("failed to read .git file: %w", err)
+		return "", fmt.Errorf("failed to read .git file: %w", err)
 	}
 
 	// Parse "gitdir: /path/to/main/repo/.git/worktrees/name"
 	gitdir := strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
 	if gitdir == "" {
-		return fmt.Errorf("invalid .git file format")
+		return "", fmt.Errorf("invalid .git file format")
 	}
 
-	// Extract main repo path (remove /.git/worktrees/name)
-	parts := strings.Split(gitdir, "/.git/worktrees/")
+	// Extract main repo path (remove /.git/worktrees/name). git writes
+	// forward slashes, even on Windows
+	parts := strings.Split(filepath.ToSlash(gitdir), "/.git/worktrees/")
 	if len(parts) != 2 {
-		return fmt.Errorf("unexpected gitdir format: %s", gitdir)
+		return "", fmt.Errorf("unexpected gitdir format: %s", gitdir)
 	}
-	mainRepoPath := parts[0]
+	return filepath.FromSlash(parts[0]), nil
+}
 
-	// Remove the worktree
-	cmd := exec.Command("git", "worktree", "remove", worktreePath, "--force")
-	cmd.Dir = mainRepoPath
-	output, err := cmd.CombinedOutput()
+// Worktree is a linked worktree of a repository
+type Worktree struct {
+	Path   string
+	Branch string // "" if its HEAD is detached
+}
+
+// ListWorktrees returns a repository's linked worktrees, leaving out the main
+// checkout and worktrees whose directories are gone
+func ListWorktrees(repoPath string) ([]Worktree, error) {
+	cmd := exec.Command("git", "worktree", "list", "--porcelain")
+	cmd.Dir = repoPath
+	output, err := logging.CombinedOutput(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to remove worktree: %w\nOutput: %s", err, string(output))
+		return nil, fmt.Errorf("failed to list worktrees: %w\nOutput: %s", err, string(output))
+	}
+
+	var worktrees []Worktree
+	// Entries are separated by blank lines; the first is the main checkout
+	for i, entry := range strings.Split(strings.TrimSpace(string(output)), "\n\n") {
+		if i == 0 {
+			continue
+		}
+		var wt Worktree
+		skip := false
+		for _, line := range strings.Split(entry, "\n") {
+			key, value, _ := strings.Cut(line, " ")
+			switch key {
+			case "worktree":
+				wt.Path = filepath.FromSlash(value)
+			case "branch":
+				wt.Branch = strings.TrimPrefix(value, "refs/heads/")
+			case "bare", "prunable":
+				skip = true
+			}
+		}
+		if !skip && wt.Path != "" {
+			worktrees = append(worktrees, wt)
+		}
+	}
+	return worktrees, nil
+}
+
+// WorktreeRoot returns the top-level directory of the worktree containing dir
+func WorktreeRoot(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	output, err := logging.Output(cmd)
+	if err != nil {
+		return "", fmt.Errorf("%s is not in a git worktree", dir)
 	}
+	return filepath.FromSlash(strings.TrimSpace(string(output))), nil
+}
 
+// PruneWorktrees removes git's records of worktrees whose directories no
+// longer exist
+func PruneWorktrees(repoPath string) error {
+	cmd := exec.Command("git", "worktree", "prune")
+	cmd.Dir = repoPath
+	if output, err := logging.CombinedOutput(cmd); err != nil {
+		return fmt.Errorf("failed to prune worktrees: %w\nOutput: %s", err, string(output))
+	}
 	return nil
 }
 
+// BranchExists reports whether a local branch exists in the repository
+func BranchExists(repoPath, branch string) bool {
+	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+	cmd.Dir = repoPath
+	return logging.Run(cmd) == nil
+}
+
 // ListBranches returns all local branch names for a repository
 func ListBranches(repoPath string) ([]string, error) {
 	cmd := exec.Command("git", "branch", "--format=%(refname:short)")
 	cmd.Dir = repoPath
-	output, err := cmd.CombinedOutput()
+	output, err := logging.CombinedOutput(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list branches: %w\nOutput: %s", err, string(output))
 	}
@@ -92,11 +200,100 @@ func ListBranches(repoPath string) ([]string, error) {
 	return branches, nil
 }
 
+// ListRemoteBranches returns the repository's remote-tracking branches, like
+// origin/main, leaving out the remotes' HEAD aliases
+func ListRemoteBranches(repoPath string) ([]string, error) {
+	cmd := exec.Command("git", "branch", "-r", "--format=%(refname:short)")
+	cmd.Dir = repoPath
+	output, err := logging.CombinedOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote branches: %w\nOutput: %s", err, string(output))
+	}
+
+	branches := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		line = strings.TrimSpace(line)
+		// origin/HEAD shortens to just "origin" in newer git
+		if !strings.Contains(line, "/") || strings.HasSuffix(line, "/HEAD") {
+			continue
+		}
+		branches = append(branches, line)
+	}
+
+	return branches, nil
+}
+
+// RefsModTime returns the last time a repository's branches or tags changed
+// on disk: the newest modification time of its ref directories and
+// packed-refs. Creating, deleting or fetching a branch moves it. ok is false
+// if the refs can't be read, e.g. because .git is a file
+func RefsModTime(repoPath string) (latest time.Time, ok bool) {
+	gitDir := filepath.Join(repoPath, ".git")
+	if info, err := os.Stat(gitDir); err != nil || !info.IsDir() {
+		return time.Time{}, false
+	}
+	newer := func(info os.FileInfo) {
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	for _, dir := range []string{"refs", "reftable"} {
+		filepath.WalkDir(filepath.Join(gitDir, dir), func(path string, d os.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				newer(info)
+			}
+			return nil
+		})
+	}
+	if info, err := os.Stat(filepath.Join(gitDir, "packed-refs")); err == nil {
+		newer(info)
+	}
+	return latest, !latest.IsZero()
+}
+
+// FetchBranch fetches a branch from origin, or from the remote a
+// remote-tracking branch name starts with, and returns the remote-tracking
+// branch to start from. A detached HEAD is returned as is
+func FetchBranch(repoPath, branch string) (string, error) {
+	if branch == "" || branch == "HEAD" {
+		return branch, nil
+	}
+
+	remote, name := "origin", branch
+	if r, n, ok := strings.Cut(branch, "/"); ok {
+		cmd := exec.Command("git", "remote")
+		cmd.Dir = repoPath
+		if output, err := logging.Output(cmd); err == nil && slices.Contains(strings.Fields(string(output)), r) {
+			remote, name = r, n
+		}
+	}
+
+	cmd := exec.Command("git", "fetch", remote, name)
+	cmd.Dir = repoPath
+	output, err := logging.CombinedOutput(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s from %s: %w\nOutput: %s", name, remote, err, string(output))
+	}
+	return remote + "/" + name, nil
+}
+
+// LocalBranchName returns the local branch name for a remote-tracking branch:
+// feature for origin/feature
+func LocalBranchName(remoteBranch string) string {
+	if _, name, ok := strings.Cut(remoteBranch, "/"); ok {
+		return name
+	}
+	return remoteBranch
+}
+
 // GetCurrentBranch returns the name of the current HEAD branch
 func GetCurrentBranch(repoPath string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
 	cmd.Dir = repoPath
-	output, err := cmd.CombinedOutput()
+	output, err := logging.CombinedOutput(cmd)
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w\nOutput: %s", err, string(output))
 	}
@@ -151,3 +348,51 @@ func isValidBranchChar(r rune) bool {
 		return false
 	}
 }
+
+// RepoRoot returns the root directory of the git repository containing dir.
+// If dir is in a worktree, it returns the main repository's path
+func RepoRoot(dir string) (string, error) {
+	// First, get the common git directory (main repo's .git, even in worktrees)
+	cmdCommon := exec.Command("git", "rev-parse", "--git-common-dir")
+	cmdCommon.Dir = dir
+	commonOutput, err := logging.Output(cmdCommon)
+	if err != nil {
+		return "", err
+	}
+	// git prints forward slashes, even on Windows
+	commonDir := filepath.FromSlash(strings.TrimSpace(string(commonOutput)))
+
+	// Get the regular git directory
+	cmdGitDir := exec.Command("git", "rev-parse", "--git-dir")
+	cmdGitDir.Dir = dir
+	gitDirOutput, err := logging.Output(cmdGitDir)
+	if err != nil {
+		return "", err
+	}
+	gitDir := filepath.FromSlash(strings.TrimSpace(string(gitDirOutput)))
+
+	// Both may be relative to dir
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(dir, commonDir)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+
+	// If they differ, we're in a worktree - use the main repo path
+	if commonDir != gitDir {
+		if sep := string(filepath.Separator); strings.HasSuffix(commonDir, sep+".git") {
+			return strings.TrimSuffix(commonDir, sep+".git"), nil
+		}
+		return filepath.Dir(commonDir), nil
+	}
+
+	// Not in a worktree, use regular toplevel
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	output, err := logging.Output(cmd)
+	if err != nil {
+		return "", err
+	}
+	return filepath.FromSlash(strings.TrimSpace(string(output))), nil
+}
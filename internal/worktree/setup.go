@@ -1,14 +1,23 @@
 package worktree
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinzwang/air-traffic-control/internal/logging"
+	"github.com/kevinzwang/air-traffic-control/internal/shell"
 )
 
 // RunSetupCommands executes a list of shell commands in the worktree directory
 // Streams output to stdout for user visibility
-func RunSetupCommands(worktreePath string, commands []string, output io.Writer) error {
+// Also used for teardown commands before a worktree is removed
+// env is added to the commands' environment
+func RunSetupCommands(worktreePath string, commands []string, env []string, output io.Writer) error {
 	for _, cmdStr := range commands {
 		if cmdStr == "" {
 			continue
@@ -17,15 +26,138 @@ func RunSetupCommands(worktreePath string, commands []string, output io.Writer)
 		fmt.Fprintf(output, "  $ %s\n", cmdStr)
 
 		// Execute command using shell to support piping, environment variables, etc.
-		cmd := exec.Command("sh", "-c", cmdStr)
+		cmd := shell.Command(cmdStr)
 		cmd.Dir = worktreePath
+		cmd.Env = append(os.Environ(), env...)
 		cmd.Stdout = output
 		cmd.Stderr = output
 
-		if err := cmd.Run(); err != nil {
+		if err := logging.Run(cmd); err != nil {
 			return fmt.Errorf("command failed: %s: %w", cmdStr, err)
 		}
 	}
 
 	return nil
 }
+
+// CopyFiles copies files matching the glob patterns (relative to srcRoot) to
+// the same relative paths under dstRoot. This carries untracked files such as
+// .env into new worktrees. Files that already exist in dstRoot are left alone
+// so tracked content is never overwritten. Patterns that are absolute or
+// climb out of srcRoot with ".." are rejected, since they come from repo
+// config.
+func CopyFiles(srcRoot, dstRoot string, patterns []string) error {
+	for _, pattern := range patterns {
+		if !filepath.IsLocal(pattern) {
+			return fmt.Errorf("copy pattern %q reaches outside the repository", pattern)
+		}
+		matches, err := filepath.Glob(filepath.Join(srcRoot, pattern))
+		if err != nil {
+			return fmt.Errorf("invalid copy pattern %q: %w", pattern, err)
+		}
+
+		for _, src := range matches {
+			info, err := os.Stat(src)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+
+			rel, err := filepath.Rel(srcRoot, src)
+			if err != nil || !filepath.IsLocal(rel) {
+				continue
+			}
+			dst := filepath.Join(dstRoot, rel)
+			if _, err := os.Stat(dst); err == nil {
+				continue
+			}
+
+			if err := copyFile(src, dst, info.Mode()); err != nil {
+				return fmt.Errorf("failed to copy %s: %w", rel, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// copyFile copies a single file, creating parent directories as needed
+func copyFile(src, dst string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode.Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// CopyChanges copies a worktree's uncommitted changes, staged or not and
+// untracked files included, to another worktree checked out at the same
+// commit. Untracked files the other worktree already has are left alone.
+func CopyChanges(srcPath, dstPath string) error {
+	diffCmd := exec.Command("git", "diff", "HEAD", "--binary")
+	diffCmd.Dir = srcPath
+	diff, err := logging.Output(diffCmd)
+	if err != nil {
+		return fmt.Errorf("failed to diff uncommitted changes: %w", err)
+	}
+	if len(diff) > 0 {
+		applyCmd := exec.Command("git", "apply", "--binary", "-")
+		applyCmd.Dir = dstPath
+		applyCmd.Stdin = bytes.NewReader(diff)
+		if output, err := logging.CombinedOutput(applyCmd); err != nil {
+			return fmt.Errorf("failed to apply uncommitted changes: %w\nOutput: %s", err, string(output))
+		}
+	}
+
+	lsCmd := exec.Command("git", "ls-files", "--others", "--exclude-standard", "-z")
+	lsCmd.Dir = srcPath
+	output, err := logging.Output(lsCmd)
+	if err != nil {
+		return fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	for _, rel := range strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00") {
+		if rel == "" {
+			continue
+		}
+		src, dst := filepath.Join(srcPath, rel), filepath.Join(dstPath, rel)
+		info, err := os.Lstat(src)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Lstat(dst); err == nil {
+			continue
+		}
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(src)
+			if err == nil {
+				err = os.MkdirAll(filepath.Dir(dst), 0755)
+			}
+			if err == nil {
+				err = os.Symlink(target, dst)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to copy %s: %w", rel, err)
+			}
+		case info.Mode().IsRegular():
+			if err := copyFile(src, dst, info.Mode()); err != nil {
+				return fmt.Errorf("failed to copy %s: %w", rel, err)
+			}
+		}
+	}
+	return nil
+}
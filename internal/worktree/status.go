@@ -0,0 +1,184 @@
+package worktree
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/kevinzwang/air-traffic-control/internal/logging"
+)
+
+// Status is the state of a worktree's branch and files
+type Status struct {
+	Ahead  int // commits on the branch that aren't on the base
+	Behind int // commits on the base that aren't on the branch
+	Dirty  int // changed and untracked files
+}
+
+// GetStatus compares a worktree's HEAD against base and counts its
+// uncommitted files. Without a base only Dirty is filled in
+func GetStatus(worktreePath, base string) (Status, error) {
+	var st Status
+
+	cmd := exec.Command("git", "status", "--porcelain")
+	cmd.Dir = worktreePath
+	output, err := logging.CombinedOutput(cmd)
+	if err != nil {
+		return st, fmt.Errorf("failed to get status: %w\nOutput: %s", err, string(output))
+	}
+	st.Dirty = countLines(string(output))
+
+	if base == "" {
+		return st, nil
+	}
+	cmd = exec.Command("git", "rev-list", "--left-right", "--count", base+"...HEAD")
+	cmd.Dir = worktreePath
+	output, err = logging.CombinedOutput(cmd)
+	if err != nil {
+		return st, fmt.Errorf("failed to compare with %s: %w\nOutput: %s", base, err, string(output))
+	}
+	if _, err := fmt.Sscanf(string(output), "%d %d", &st.Behind, &st.Ahead); err != nil {
+		return st, fmt.Errorf("failed to parse rev-list output %q: %w", output, err)
+	}
+	return st, nil
+}
+
+// countLines counts the non-empty lines of command output
+func countLines(output string) int {
+	n := 0
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Conflicts returns the files that would conflict merging base into the
+// worktree's HEAD, without touching the worktree or index. It needs git 2.38
+// or later for merge-tree --write-tree
+func Conflicts(worktreePath, base string) ([]string, error) {
+	cmd := exec.Command("git", "merge-tree", "--write-tree", "--name-only", "--no-messages", base, "HEAD")
+	cmd.Dir = worktreePath
+	output, err := logging.Output(cmd)
+	if err == nil {
+		return nil, nil
+	}
+	// With conflicts, merge-tree exits with status 1 and prints the merged
+	// tree, then the conflicted files. It also exits with 1 for a bad ref,
+	// printing nothing
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 1 || len(output) == 0 {
+		if ok {
+			return nil, fmt.Errorf("failed to check conflicts with %s: %w\nOutput: %s", base, err, string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("failed to check conflicts with %s: %w", base, err)
+	}
+	var files []string
+	for i, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if i > 0 && line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// LineChanges is the size of a branch's changes
+type LineChanges struct {
+	Files   int
+	Added   int
+	Deleted int
+}
+
+// DiffStat sums the changes committed on the worktree's branch since it
+// forked from base
+func DiffStat(worktreePath, base string) (LineChanges, error) {
+	cmd := exec.Command("git", "diff", "--shortstat", base+"...HEAD")
+	cmd.Dir = worktreePath
+	output, err := logging.CombinedOutput(cmd)
+	if err != nil {
+		return LineChanges{}, fmt.Errorf("failed to diff against %s: %w\nOutput: %s", base, err, string(output))
+	}
+	return parseShortstat(string(output)), nil
+}
+
+// parseShortstat reads git diff --shortstat output, e.g. " 9 files changed,
+// 412 insertions(+), 87 deletions(-)", either count of which is left out when
+// zero. Nothing is printed for no changes
+func parseShortstat(output string) LineChanges {
+	var c LineChanges
+	for _, part := range strings.Split(strings.TrimSpace(output), ",") {
+		var n int
+		var what string
+		if _, err := fmt.Sscan(part, &n, &what); err != nil {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(what, "file"):
+			c.Files = n
+		case strings.HasPrefix(what, "insertion"):
+			c.Added = n
+		case strings.HasPrefix(what, "deletion"):
+			c.Deleted = n
+		}
+	}
+	return c
+}
+
+// ResolveRevisions returns the commit IDs of revisions, resolved in dir
+func ResolveRevisions(dir string, revisions ...string) ([]string, error) {
+	cmd := exec.Command("git", append([]string{"rev-parse"}, revisions...)...)
+	cmd.Dir = dir
+	output, err := logging.CombinedOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w\nOutput: %s", strings.Join(revisions, ", "), err, string(output))
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// ChangedFile is a file changed on a branch
+type ChangedFile struct {
+	Status  byte   // A, M, D, R (renamed), C (copied) or T (type changed)
+	Path    string // the new path for renames and copies
+	OldPath string // the original path for renames and copies
+}
+
+// ChangedFiles lists the files changed on the worktree's branch since it
+// forked from base
+func ChangedFiles(worktreePath, base string) ([]ChangedFile, error) {
+	cmd := exec.Command("git", "diff", "--name-status", "-z", base+"...HEAD")
+	cmd.Dir = worktreePath
+	output, err := logging.CombinedOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changes since %s: %w\nOutput: %s", base, err, string(output))
+	}
+	// -z prints NUL-terminated fields: the status, then the path, or the old
+	// and new paths for renames and copies
+	fields := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	var files []ChangedFile
+	for i := 0; i+1 < len(fields); i += 2 {
+		f := ChangedFile{Status: fields[i][0], Path: fields[i+1]}
+		if (f.Status == 'R' || f.Status == 'C') && i+2 < len(fields) {
+			f.OldPath, f.Path = f.Path, fields[i+2]
+			i++
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// FileDiff returns the diff of one file on the worktree's branch since it
+// forked from base
+func FileDiff(worktreePath, base string, file ChangedFile) (string, error) {
+	args := []string{"diff", "--no-color", "--no-ext-diff", base + "...HEAD", "--"}
+	if file.OldPath != "" {
+		args = append(args, file.OldPath)
+	}
+	cmd := exec.Command("git", append(args, file.Path)...)
+	cmd.Dir = worktreePath
+	output, err := logging.CombinedOutput(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff %s: %w\nOutput: %s", file.Path, err, string(output))
+	}
+	return string(output), nil
+}
@@ -0,0 +1,64 @@
+package worktree
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/kevinzwang/air-traffic-control/internal/logging"
+)
+
+// Sync brings the worktree's branch up to date with base by rebasing onto it
+// or merging it, as strategy says, stashing uncommitted changes around it. If
+// it stops on conflicts, it returns the conflicted files and leaves the
+// rebase or merge in progress to be resolved or aborted with AbortSync
+func Sync(worktreePath, base, strategy string) ([]string, error) {
+	var cmd *exec.Cmd
+	action := "rebase onto " + base
+	if strategy == "merge" {
+		cmd = exec.Command("git", "merge", "--autostash", "--no-edit", base)
+		action = "merge " + base
+	} else {
+		cmd = exec.Command("git", "rebase", "--autostash", base)
+	}
+	cmd.Dir = worktreePath
+	output, err := logging.CombinedOutput(cmd)
+	if err == nil {
+		return nil, nil
+	}
+
+	files, listErr := conflictedFiles(worktreePath)
+	if listErr == nil && len(files) > 0 {
+		return files, nil
+	}
+	return nil, fmt.Errorf("failed to %s: %w\nOutput: %s", action, err, string(output))
+}
+
+// AbortSync abandons a rebase or merge Sync stopped on conflicts, putting the
+// branch and stashed changes back as they were
+func AbortSync(worktreePath, strategy string) error {
+	cmd := exec.Command("git", strategy, "--abort")
+	cmd.Dir = worktreePath
+	output, err := logging.CombinedOutput(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to abort %s: %w\nOutput: %s", strategy, err, string(output))
+	}
+	return nil
+}
+
+// conflictedFiles lists the worktree's unmerged files
+func conflictedFiles(worktreePath string) ([]string, error) {
+	cmd := exec.Command("git", "diff", "--name-only", "--diff-filter=U")
+	cmd.Dir = worktreePath
+	output, err := logging.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}